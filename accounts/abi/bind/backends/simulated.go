@@ -866,9 +866,16 @@ func (fb *filterBackend) ChainDb() ethdb.Database  { return fb.db }
 func (fb *filterBackend) EventMux() *event.TypeMux { panic("not supported") }
 
 func (fb *filterBackend) HeaderByNumber(ctx context.Context, block rpc.BlockNumber) (*types.Header, error) {
-	if block == rpc.LatestBlockNumber {
+	if block == rpc.LatestBlockNumber || block == rpc.FinalizedBlockNumber {
 		return fb.bc.GetLastFinalizedHeader(), nil
 	}
+	if block == rpc.CheckpointBlockNumber {
+		cp := fb.bc.GetLastCoordinatedCheckpoint()
+		if cp == nil {
+			return nil, nil
+		}
+		return fb.bc.GetHeaderByHash(cp.Spine), nil
+	}
 	return fb.bc.GetHeaderByNumber(uint64(block.Int64())), nil
 }
 
@@ -920,6 +927,10 @@ func (fb *filterBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event
 	return nullSubscription()
 }
 
+func (fb *filterBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return fb.bc.SubscribeChainHeadEvent(ch)
+}
+
 func (fb *filterBackend) BloomStatus() (uint64, uint64) { return 4096, 0 }
 
 func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.MatcherSession) {
@@ -104,7 +104,10 @@ The dumpgenesis command dumps the genesis block configuration in JSON format to
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 The import command imports blocks from an RLP-encoded form. The form can be one file
-with several RLP-encoded blocks, or several files can be used.
+with several RLP-encoded blocks, or several files can be used. Files written by the
+export command also restore the dag metadata (block dags, children, epochs, eras,
+validator sync data and coordinated checkpoints), and the imported blocks are
+finalized in the exported order. Legacy files of plain RLP blocks are still supported.
 
 If only one file is used, import error will result in failure. If several files are used,
 processing will proceed even if an individual RLP-file import failure occurs.`,
@@ -123,9 +126,10 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 		Description: `
 Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
-last block to write. In this mode, the file will be appended
+last finalized block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
-be gzipped.`,
+be gzipped. The finalized blocks are written in the versioned
+dag export format together with the dag metadata.`,
 	}
 	importPreimagesCommand = cli.Command{
 		Action:    utils.MigrateFlags(importPreimages),
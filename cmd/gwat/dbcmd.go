@@ -29,6 +29,7 @@ import (
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/console/prompt"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
@@ -63,6 +64,7 @@ Remove blockchain and state databases`,
 			dbPutCmd,
 			dbGetSlotsCmd,
 			dbDumpFreezerIndex,
+			dbPruneDagCmd,
 		},
 	}
 	dbInspectCmd = cli.Command{
@@ -172,6 +174,26 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		},
 		Description: "This command displays information about the freezer index.",
 	}
+	dbPruneDagDryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Print the stale dag data summary without deleting anything",
+	}
+	dbPruneDagCmd = cli.Command{
+		Action: utils.MigrateFlags(dbPruneDag),
+		Name:   "prune-dag",
+		Usage:  "Remove the stale unfinalized dag data",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.TestNet8Flag,
+			dbPruneDagDryRunFlag,
+		},
+		Description: `This command removes the blocks which were never finalized and are older than
+the last coordinated checkpoint, and are not reachable from the current tips,
+together with their bodies, receipts, block dags, children entries and tx lookups.
+The summary of the stale data is printed and confirmed before deleting anything.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -494,3 +516,42 @@ func parseHexOrString(str string) ([]byte, error) {
 	}
 	return b, err
 }
+
+// dbPruneDag removes the stale unfinalized dag data.
+func dbPruneDag(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	summary, err := core.FindStaleDag(db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Checkpoint slot: %d\n", summary.CpSlot)
+	fmt.Printf("Stale blocks:    %d\n", len(summary.Blocks))
+	fmt.Printf("Slots:           %d\n", summary.Slots)
+	fmt.Printf("Tx lookups:      %d\n", summary.TxLookups)
+	fmt.Printf("Size:            %v\n", summary.Size)
+	if n := len(summary.Blocks); n > 0 {
+		fmt.Printf("Slot range:      %d - %d\n", summary.Blocks[0].Slot, summary.Blocks[n-1].Slot)
+	}
+	if ctx.Bool(dbPruneDagDryRunFlag.Name) || len(summary.Blocks) == 0 {
+		return nil
+	}
+	confirm, err := prompt.Stdin.PromptConfirm("Remove the stale dag data?")
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		log.Info("Dag pruning aborted")
+		return nil
+	}
+	start := time.Now()
+	if err := core.PruneStaleDag(db, summary); err != nil {
+		return err
+	}
+	log.Info("Pruned stale dag data", "blocks", len(summary.Blocks), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
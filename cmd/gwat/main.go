@@ -40,6 +40,9 @@ import (
 	"gitlab.waterfall.network/waterfall/protocol/gwat/metrics"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/node"
 	"gopkg.in/urfave/cli.v1"
+
+	// Force-load the native tracers, to trigger registration
+	_ "gitlab.waterfall.network/waterfall/protocol/gwat/eth/tracers/native"
 )
 
 const (
@@ -60,6 +63,7 @@ var (
 		utils.BootnodesFlag,
 		utils.DataDirFlag,
 		utils.AncientFlag,
+		utils.AncientErasFlag,
 		utils.MinFreeDiskSpaceFlag,
 		utils.KeyStoreDirFlag,
 		utils.ExternalSignerFlag,
@@ -79,11 +83,17 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolValidatorSlotsFlag,
+		utils.TxPoolValidatorPriceBumpFlag,
+		utils.TxPoolValidatorJournalFlag,
+		utils.TxPoolSnapshotFlag,
+		utils.TxPoolCreatorsBroadcastFlag,
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.DagPruneIntervalFlag,
 		// TODO: uncomment when light client is ready
 		//utils.LightServeFlag,
 		//utils.LightIngressFlag,
@@ -96,6 +106,7 @@ var (
 		//utils.UltraLightOnlyAnnounceFlag,
 		//utils.LightNoSyncServeFlag,
 		utils.WhitelistFlag,
+		utils.SyncCheckpointFlag,
 		utils.BloomFilterSizeFlag,
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
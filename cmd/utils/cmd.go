@@ -18,6 +18,7 @@
 package utils
 
 import (
+	"bufio"
 	"compress/gzip"
 	"fmt"
 	"io"
@@ -155,7 +156,18 @@ func ImportChain(chain *core.BlockChain, fn string) error {
 			return err
 		}
 	}
-	stream := rlp.NewStream(reader, 0)
+	// Import the dag export with its metadata.
+	br := bufio.NewReader(reader)
+	if core.IsDagExport(br) {
+		n, err := chain.ImportDag(br, checkInterrupt)
+		if err != nil {
+			return fmt.Errorf("at block %d: %v", n, err)
+		}
+		log.Info("Imported dag chain", "blocks", n)
+		return nil
+	}
+	// Fall back to the legacy plain blocks export.
+	stream := rlp.NewStream(br, 0)
 
 	// Run actual the import.
 	blocks := make(types.Blocks, importBatchSize)
@@ -235,8 +247,8 @@ func ExportChain(blockchain *core.BlockChain, fn string) error {
 		writer = gzip.NewWriter(writer)
 		defer writer.(*gzip.Writer).Close()
 	}
-	// Iterate over the blocks and export them
-	if err := blockchain.Export(writer); err != nil {
+	// Iterate over the finalized blocks and export them with the dag metadata
+	if err := blockchain.ExportDag(writer, 0, blockchain.GetLastFinalizedBlock().Nr()); err != nil {
 		return err
 	}
 	log.Info("Exported blockchain", "file", fn)
@@ -262,7 +274,7 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 		defer writer.(*gzip.Writer).Close()
 	}
 	// Iterate over the blocks and export them
-	if err := blockchain.ExportN(writer, first, last); err != nil {
+	if err := blockchain.ExportDag(writer, first, last); err != nil {
 		return err
 	}
 	log.Info("Exported blockchain to", "file", fn)
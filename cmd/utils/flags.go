@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -111,6 +112,11 @@ var (
 		Name:  "datadir.ancient",
 		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
 	}
+	AncientErasFlag = cli.Uint64Flag{
+		Name:  "datadir.ancient.eras",
+		Usage: "Number of recent eras whose finalized blocks are not moved to the ancient store (0 = disabled)",
+		Value: ethconfig.Defaults.AncientEras,
+	}
 	MinFreeDiskSpaceFlag = DirectoryFlag{
 		Name:  "datadir.minfreedisk",
 		Usage: "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
@@ -212,6 +218,11 @@ var (
 		Usage: "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value: ethconfig.Defaults.TxLookupLimit,
 	}
+	DagPruneIntervalFlag = cli.DurationFlag{
+		Name:  "dagprune.interval",
+		Usage: "Time interval to prune the stale unfinalized dag data (0 = disabled)",
+		Value: ethconfig.Defaults.DagPruneInterval,
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -220,6 +231,10 @@ var (
 		Name:  "whitelist",
 		Usage: "Comma separated block number-to-hash mappings to enforce (<number>=<hash>)",
 	}
+	SyncCheckpointFlag = cli.StringFlag{
+		Name:  "synccheckpoint",
+		Usage: "JSON file with the trusted checkpoint and its era to start the chain from by the checkpoint state sync",
+	}
 	BloomFilterSizeFlag = cli.Uint64Flag{
 		Name:  "bloomfilter.size",
 		Usage: "Megabytes of memory allocated to bloom-filter for pruning",
@@ -331,6 +346,30 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.TxPool.Lifetime,
 	}
+	TxPoolValidatorSlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.validatorslots",
+		Usage: "Maximum number of validator operations reserved in the pool (a quarter is kept for exits)",
+		Value: ethconfig.Defaults.TxPool.ValidatorSlots,
+	}
+	TxPoolValidatorPriceBumpFlag = cli.Uint64Flag{
+		Name:  "txpool.validatorpricebump",
+		Usage: "Price bump percentage to replace an already existing validator operation",
+		Value: ethconfig.Defaults.TxPool.ValidatorPriceBump,
+	}
+	TxPoolCreatorsBroadcastFlag = cli.BoolFlag{
+		Name:  "txpool.creatorsbroadcast",
+		Usage: "Sends the transactions directly to the peers of the creators assigned to include them",
+	}
+	TxPoolValidatorJournalFlag = cli.StringFlag{
+		Name:  "txpool.validatorjournal",
+		Usage: "Disk journal for validator operations to survive node restarts",
+		Value: core.DefaultTxPoolConfig.ValidatorJournal,
+	}
+	TxPoolSnapshotFlag = cli.StringFlag{
+		Name:  "txpool.snapshot",
+		Usage: "Disk snapshot of the whole transaction pool to warm it up on node restarts (disabled if empty)",
+		Value: core.DefaultTxPoolConfig.Snapshot,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -1273,6 +1312,18 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolValidatorSlotsFlag.Name) {
+		cfg.ValidatorSlots = ctx.GlobalUint64(TxPoolValidatorSlotsFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolValidatorPriceBumpFlag.Name) {
+		cfg.ValidatorPriceBump = ctx.GlobalUint64(TxPoolValidatorPriceBumpFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolValidatorJournalFlag.Name) {
+		cfg.ValidatorJournal = ctx.GlobalString(TxPoolValidatorJournalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolSnapshotFlag.Name) {
+		cfg.Snapshot = ctx.GlobalString(TxPoolSnapshotFlag.Name)
+	}
 }
 
 func setMiner(ctx *cli.Context, cfg *creator.Config) {
@@ -1326,6 +1377,25 @@ func setWhitelist(ctx *cli.Context, cfg *ethconfig.Config) {
 	}
 }
 
+func setSyncCheckpoint(ctx *cli.Context, cfg *ethconfig.Config) {
+	path := ctx.GlobalString(SyncCheckpointFlag.Name)
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		Fatalf("Failed to read sync checkpoint file %s: %v", path, err)
+	}
+	syncCp := new(downloader.SyncCheckpoint)
+	if err = json.Unmarshal(data, syncCp); err != nil {
+		Fatalf("Invalid sync checkpoint %s: %v", path, err)
+	}
+	if syncCp.Checkpoint == nil || syncCp.Era == nil {
+		Fatalf("Invalid sync checkpoint %s: checkpoint and era required", path)
+	}
+	cfg.SyncCheckpoint = syncCp
+}
+
 // CheckExclusive verifies that only a single instance of the provided flags was
 // set by the user. Each flag might optionally be followed by a string type to
 // specialize it further.
@@ -1389,6 +1459,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setTxPool(ctx, &cfg.TxPool)
 	setMiner(ctx, &cfg.Creator)
 	setWhitelist(ctx, cfg)
+	setSyncCheckpoint(ctx, cfg)
 	//setLes(ctx, cfg) TODO: uncomment after light client is implemented
 
 	// Cap the cache allowance and tune the garbage collector
@@ -1424,6 +1495,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(AncientFlag.Name) {
 		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
 	}
+	if ctx.GlobalIsSet(AncientErasFlag.Name) {
+		cfg.AncientEras = ctx.GlobalUint64(AncientErasFlag.Name)
+	}
 
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -1443,6 +1517,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(DagPruneIntervalFlag.Name) {
+		cfg.DagPruneInterval = ctx.GlobalDuration(DagPruneIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolCreatorsBroadcastFlag.Name) {
+		cfg.TxCreatorsBroadcast = ctx.GlobalBool(TxPoolCreatorsBroadcastFlag.Name)
+	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
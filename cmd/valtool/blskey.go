@@ -0,0 +1,76 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/cmd/utils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto/bls_sig"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputGenerateBlsKey struct {
+	PubKey string
+}
+
+var commandGenerateBlsKey = cli.Command{
+	Name:      "generate",
+	Usage:     "generate new BLS validator key",
+	ArgsUsage: "[ <keyfile> ]",
+	Description: `
+Generate a new BLS secret key of validator and store it hex encoded
+to the keyfile. The key file must be kept offline.
+`,
+	Flags: []cli.Flag{
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		keyfilepath := ctx.Args().First()
+		if keyfilepath == "" {
+			keyfilepath = defaultBlsKeyfileName
+		}
+		if _, err := os.Stat(keyfilepath); err == nil {
+			utils.Fatalf("Keyfile already exists at %s.", keyfilepath)
+		} else if !os.IsNotExist(err) {
+			utils.Fatalf("Error checking if keyfile exists: %v", err)
+		}
+
+		sk, err := bls_sig.RandKey()
+		if err != nil {
+			utils.Fatalf("Failed to generate random BLS key: %v", err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(keyfilepath), 0700); err != nil {
+			utils.Fatalf("Could not create directory %s", filepath.Dir(keyfilepath))
+		}
+		if err := os.WriteFile(keyfilepath, []byte(hexutil.Encode(sk.Marshal())), 0600); err != nil {
+			utils.Fatalf("Failed to write keyfile to %s: %v", keyfilepath, err)
+		}
+
+		out := outputGenerateBlsKey{
+			PubKey: hexutil.Encode(sk.PublicKey().Marshal()),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("PubKey:", out.PubKey)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,164 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/cmd/utils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/operation"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputDeposit struct {
+	PubKey    string
+	Signature string
+	Data      string
+}
+
+type outputVerifyDeposit struct {
+	Success           bool
+	PubKey            string
+	CreatorAddress    string
+	WithdrawalAddress string
+	DelegatingStake   bool
+}
+
+var delegatingStakeFlag = cli.StringFlag{
+	Name:  "delegating-stake",
+	Usage: "JSON file with delegating stake rules (same format as delegating_stake of wat_validator_DepositData)",
+}
+
+var commandDeposit = cli.Command{
+	Name:  "deposit",
+	Usage: "build and sign validator deposit data",
+	Description: `
+Build deposit data of the validator and sign it with the BLS key.
+The result is the data field of the deposit transaction
+which must be sent to the validators state address.
+`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		blsKeyFlag,
+		creatorFlag,
+		withdrawalFlag,
+		delegatingStakeFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if !ctx.IsSet(blsKeyFlag.Name) {
+			utils.Fatalf("Missing required flag --%s", blsKeyFlag.Name)
+		}
+		sk := loadBlsKey(ctx.String(blsKeyFlag.Name))
+		creator := mustAddress(ctx, creatorFlag)
+		withdrawal := mustAddress(ctx, withdrawalFlag)
+
+		var delegatingStake *operation.DelegatingStakeData
+		if file := ctx.String(delegatingStakeFlag.Name); file != "" {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				utils.Fatalf("Failed to read delegating stake file '%s': %v", file, err)
+			}
+			args := new(validator.DelegatingStakeArgs)
+			if err := json.Unmarshal(content, args); err != nil {
+				utils.Fatalf("Failed to parse delegating stake file: %v", err)
+			}
+			if delegatingStake, err = args.DelegatingStakeData(); err != nil {
+				utils.Fatalf("Invalid delegating stake: %v", err)
+			}
+		}
+
+		sig, err := operation.SignDeposit(sk, creator, withdrawal)
+		if err != nil {
+			utils.Fatalf("Failed to sign deposit: %v", err)
+		}
+		pubKey := common.BytesToBlsPubKey(sk.PublicKey().Marshal())
+		op, err := operation.NewDepositOperation(pubKey, creator, withdrawal, sig, delegatingStake)
+		if err != nil {
+			utils.Fatalf("Failed to create deposit operation: %v", err)
+		}
+		data, err := operation.EncodeToBytes(op)
+		if err != nil {
+			utils.Fatalf("Failed to encode deposit operation: %v", err)
+		}
+
+		out := outputDeposit{
+			PubKey:    pubKey.Hex(),
+			Signature: sig.Hex(),
+			Data:      hexutil.Encode(data),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("PubKey:", out.PubKey)
+			fmt.Println("Signature:", out.Signature)
+			fmt.Println("Data:", out.Data)
+		}
+		return nil
+	},
+}
+
+var commandVerifyDeposit = cli.Command{
+	Name:      "verifydeposit",
+	Usage:     "verify the signature of a deposit data",
+	ArgsUsage: "<data>",
+	Description: `
+Decode the deposit data and verify its BLS signature.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		raw, err := hexutil.Decode(strings.TrimSpace(ctx.Args().First()))
+		if err != nil {
+			utils.Fatalf("Deposit data is not hex encoded: %v", err)
+		}
+		op, err := operation.DecodeBytes(raw)
+		if err != nil {
+			utils.Fatalf("Failed to decode deposit data: %v", err)
+		}
+		deposit, ok := op.(operation.Deposit)
+		if !ok {
+			utils.Fatalf("Data is not a deposit operation (op code %#x)", op.OpCode())
+		}
+
+		err = operation.VerifyDepositSig(deposit.Signature(), deposit.PubKey(), deposit.CreatorAddress(), deposit.WithdrawalAddress())
+		out := outputVerifyDeposit{
+			Success:           err == nil,
+			PubKey:            deposit.PubKey().Hex(),
+			CreatorAddress:    deposit.CreatorAddress().Hex(),
+			WithdrawalAddress: deposit.WithdrawalAddress().Hex(),
+			DelegatingStake:   deposit.DelegatingStake() != nil,
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			if out.Success {
+				fmt.Println("Signature verification successful!")
+			} else {
+				fmt.Println("Signature verification failed!")
+			}
+			fmt.Println("PubKey:", out.PubKey)
+			fmt.Println("Creator address:", out.CreatorAddress)
+			fmt.Println("Withdrawal address:", out.WithdrawalAddress)
+			fmt.Println("Delegating stake:", out.DelegatingStake)
+		}
+		return err
+	},
+}
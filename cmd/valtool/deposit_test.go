@@ -0,0 +1,64 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDepositSignVerify(t *testing.T) {
+	tmpdir, err := os.MkdirTemp("", "valtool-test")
+	if err != nil {
+		t.Fatal("Can't create temporary directory:", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	keyfile := filepath.Join(tmpdir, "the-blskey")
+
+	// Create the key.
+	generate := runValtool(t, "generate", keyfile)
+	_, matches := generate.ExpectRegexp(`PubKey: (0x[0-9a-f]{96})\n`)
+	pubKey := matches[1]
+	generate.ExpectExit()
+
+	// Build the deposit data.
+	deposit := runValtool(t, "deposit",
+		"--blskey", keyfile,
+		"--creator", "0x6e9e76fa278190cfb2404e5923d3ccd7e8f6c777",
+		"--withdrawal", "0xa7e558cc6efa1c41270ef4aa227b3dd6b4a3951e",
+	)
+	_, matches = deposit.ExpectRegexp(`PubKey: (0x[0-9a-f]{96})
+Signature: 0x[0-9a-f]{192}
+Data: (0x[0-9a-f]+)
+`)
+	if matches[1] != pubKey {
+		t.Error("deposit public key doesn't match generated key")
+	}
+	data := matches[2]
+	deposit.ExpectExit()
+
+	// Verify the deposit data.
+	verify := runValtool(t, "verifydeposit", data)
+	verify.Expect(`
+Signature verification successful!
+PubKey: ` + pubKey + `
+Creator address: 0x6E9E76FA278190cFb2404E5923D3cCd7E8F6c777
+Withdrawal address: 0xa7e558Cc6efA1c41270eF4Aa227b3dd6B4a3951E
+Delegating stake: false
+`)
+	verify.ExpectExit()
+}
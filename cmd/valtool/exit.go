@@ -0,0 +1,132 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/cmd/utils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/operation"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputOpData struct {
+	Data string
+}
+
+var (
+	exitEpochFlag = cli.Uint64Flag{
+		Name:  "exit-epoch",
+		Usage: "epoch after which the validator exits (optional)",
+	}
+	amountFlag = cli.StringFlag{
+		Name:  "amount",
+		Usage: "amount to withdraw in wei",
+	}
+	delegatorFlag = cli.StringFlag{
+		Name:  "delegator",
+		Usage: "delegator address (sender of the transaction)",
+	}
+)
+
+var commandExit = cli.Command{
+	Name:  "exit",
+	Usage: "build validator exit data",
+	Description: `
+Build the data field of the validator exit transaction.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		pubKeyFlag,
+		creatorFlag,
+		exitEpochFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		pubKey := mustPubKey(ctx, pubKeyFlag)
+		creator := mustAddress(ctx, creatorFlag)
+
+		var exitEpoch *uint64
+		if ctx.IsSet(exitEpochFlag.Name) {
+			epoch := ctx.Uint64(exitEpochFlag.Name)
+			exitEpoch = &epoch
+		}
+		op, err := operation.NewExitOperation(pubKey, creator, exitEpoch)
+		if err != nil {
+			utils.Fatalf("Failed to create exit operation: %v", err)
+		}
+		printOpData(ctx, op)
+		return nil
+	},
+}
+
+var commandWithdrawal = cli.Command{
+	Name:  "withdrawal",
+	Usage: "build validator withdrawal data",
+	Description: `
+Build the data field of the validator withdrawal transaction.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		creatorFlag,
+		amountFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		creator := mustAddress(ctx, creatorFlag)
+		op, err := operation.NewWithdrawalOperation(creator, mustBigInt(ctx, amountFlag))
+		if err != nil {
+			utils.Fatalf("Failed to create withdrawal operation: %v", err)
+		}
+		printOpData(ctx, op)
+		return nil
+	},
+}
+
+var commandDelegatorWithdrawal = cli.Command{
+	Name:  "delegatorwithdrawal",
+	Usage: "build withdrawal data of the delegator's stake",
+	Description: `
+Build the data field of the withdrawal transaction of the delegator's share
+of the stake of a validator with delegating stake.
+Zero amount withdraws the whole share of the delegator.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		creatorFlag,
+		delegatorFlag,
+		amountFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		creator := mustAddress(ctx, creatorFlag)
+		delegator := mustAddress(ctx, delegatorFlag)
+		op, err := operation.NewDelegatorWithdrawalOperation(creator, delegator, mustBigInt(ctx, amountFlag))
+		if err != nil {
+			utils.Fatalf("Failed to create delegator withdrawal operation: %v", err)
+		}
+		printOpData(ctx, op)
+		return nil
+	},
+}
+
+// printOpData encodes the validator operation and prints it.
+func printOpData(ctx *cli.Context, op operation.Operation) {
+	data, err := operation.EncodeToBytes(op)
+	if err != nil {
+		utils.Fatalf("Failed to encode operation: %v", err)
+	}
+	out := outputOpData{Data: hexutil.Encode(data)}
+	if ctx.Bool(jsonFlag.Name) {
+		mustPrintJSON(out)
+	} else {
+		fmt.Println("Data:", out.Data)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// valtool is an offline utility to prepare and sign validator operations.
+// It never connects to a node, so validator keys may be kept in cold storage.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/internal/flags"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	defaultBlsKeyfileName = "blskey.hex"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "an offline validator operations tool")
+	app.Commands = []cli.Command{
+		commandGenerateBlsKey,
+		commandDeposit,
+		commandVerifyDeposit,
+		commandExit,
+		commandWithdrawal,
+		commandDelegatorWithdrawal,
+		commandSignTx,
+	}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+// Commonly used command line flags.
+var (
+	passphraseFlag = cli.StringFlag{
+		Name:  "passwordfile",
+		Usage: "the file that contains the password for the keyfile",
+	}
+	jsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "output JSON instead of human-readable format",
+	}
+	blsKeyFlag = cli.StringFlag{
+		Name:  "blskey",
+		Usage: "file containing the hex encoded BLS secret key of validator",
+	}
+	pubKeyFlag = cli.StringFlag{
+		Name:  "pubkey",
+		Usage: "hex encoded BLS public key of validator",
+	}
+	creatorFlag = cli.StringFlag{
+		Name:  "creator",
+		Usage: "creator address of validator",
+	}
+	withdrawalFlag = cli.StringFlag{
+		Name:  "withdrawal",
+		Usage: "withdrawal address of validator",
+	}
+)
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/pkg/reexec"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/internal/cmdtest"
+)
+
+type testValtool struct {
+	*cmdtest.TestCmd
+}
+
+// spawns valtool with the given command line args.
+func runValtool(t *testing.T, args ...string) *testValtool {
+	tt := new(testValtool)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+	tt.Run("valtool-test", args...)
+	return tt
+}
+
+func TestMain(m *testing.M) {
+	// Run the app if we've been exec'd as "valtool-test" in runValtool.
+	reexec.Register("valtool-test", func() {
+		if err := app.Run(os.Args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	})
+	// check if we have been reexec'd
+	if reexec.Init() {
+		return
+	}
+	os.Exit(m.Run())
+}
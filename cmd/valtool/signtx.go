@@ -0,0 +1,168 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/accounts/keystore"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/cmd/utils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputSignTx struct {
+	Hash string
+	From string
+	Raw  string
+}
+
+var (
+	chainIDFlag = cli.Uint64Flag{
+		Name:  "chainid",
+		Usage: "chain id of the network",
+		Value: params.MainnetChainConfig.ChainID.Uint64(),
+	}
+	nonceFlag = cli.Uint64Flag{
+		Name:  "nonce",
+		Usage: "nonce of the sender account",
+	}
+	gasFlag = cli.Uint64Flag{
+		Name:  "gas",
+		Usage: "gas limit of the transaction",
+		Value: 1000000,
+	}
+	gasTipCapFlag = cli.StringFlag{
+		Name:  "tipcap",
+		Usage: "max priority fee per gas in wei",
+		Value: "0",
+	}
+	gasFeeCapFlag = cli.StringFlag{
+		Name:  "feecap",
+		Usage: "max fee per gas in wei",
+	}
+	toFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "recipient address (defaults to the validators state address of mainnet)",
+		Value: params.MainnetChainConfig.ValidatorsStateAddress.Hex(),
+	}
+	valueFlag = cli.StringFlag{
+		Name:  "value",
+		Usage: "value to transfer in wei (deposit amount)",
+		Value: "0",
+	}
+	dataFlag = cli.StringFlag{
+		Name:  "data",
+		Usage: "hex encoded data of the transaction (validator operation)",
+	}
+)
+
+var commandSignTx = cli.Command{
+	Name:      "signtx",
+	Usage:     "sign a validator operation transaction",
+	ArgsUsage: "<keyfile>",
+	Description: `
+Sign the transaction carrying the validator operation with the keyfile.
+The raw transaction can be submitted later by any networked node
+with eth_sendRawTransaction.
+`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+		chainIDFlag,
+		nonceFlag,
+		gasFlag,
+		gasTipCapFlag,
+		gasFeeCapFlag,
+		toFlag,
+		valueFlag,
+		dataFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		keyfilepath := ctx.Args().First()
+		if keyfilepath == "" {
+			utils.Fatalf("Missing keyfile argument")
+		}
+		if !ctx.IsSet(nonceFlag.Name) {
+			utils.Fatalf("Missing required flag --%s", nonceFlag.Name)
+		}
+		to := mustAddress(ctx, toFlag)
+		data, err := hexutil.Decode(ctx.String(dataFlag.Name))
+		if err != nil {
+			utils.Fatalf("Invalid data: %v", err)
+		}
+
+		txData := &types.DynamicFeeTx{
+			ChainID:   new(big.Int).SetUint64(ctx.Uint64(chainIDFlag.Name)),
+			Nonce:     ctx.Uint64(nonceFlag.Name),
+			GasTipCap: mustBigInt(ctx, gasTipCapFlag),
+			GasFeeCap: mustBigInt(ctx, gasFeeCapFlag),
+			Gas:       ctx.Uint64(gasFlag.Name),
+			To:        &to,
+			Value:     mustBigInt(ctx, valueFlag),
+			Data:      data,
+		}
+
+		keyjson, err := os.ReadFile(keyfilepath)
+		if err != nil {
+			utils.Fatalf("Failed to read the keyfile at '%s': %v", keyfilepath, err)
+		}
+		passphrase := getPassphrase(ctx, false)
+		key, err := keystore.DecryptKey(keyjson, passphrase)
+		if err != nil {
+			utils.Fatalf("Error decrypting key: %v", err)
+		}
+
+		tx, err := types.SignNewTx(key.PrivateKey, types.NewLondonSigner(txData.ChainID), txData)
+		if err != nil {
+			utils.Fatalf("Failed to sign transaction: %v", err)
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			utils.Fatalf("Failed to encode transaction: %v", err)
+		}
+
+		out := outputSignTx{
+			Hash: tx.Hash().Hex(),
+			From: key.Address.Hex(),
+			Raw:  hexutil.Encode(raw),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Hash:", out.Hash)
+			fmt.Println("From:", out.From)
+			fmt.Println("Raw:", out.Raw)
+		}
+		return nil
+	},
+}
+
+// mustBigInt reads a required decimal or hex integer flag.
+func mustBigInt(ctx *cli.Context, flag cli.StringFlag) *big.Int {
+	str := ctx.String(flag.Name)
+	if str == "" {
+		utils.Fatalf("Missing required flag --%s", flag.Name)
+	}
+	v, ok := new(big.Int).SetString(str, 0)
+	if !ok {
+		utils.Fatalf("Invalid value for --%s: %q", flag.Name, str)
+	}
+	return v
+}
@@ -0,0 +1,95 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/cmd/utils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto/bls_sig"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// getPassphrase obtains a passphrase given by the user. It first checks the
+// --passwordfile command line flag and ultimately prompts the user for a
+// passphrase.
+func getPassphrase(ctx *cli.Context, confirmation bool) string {
+	passphraseFile := ctx.String(passphraseFlag.Name)
+	if passphraseFile != "" {
+		content, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			utils.Fatalf("Failed to read password file '%s': %v",
+				passphraseFile, err)
+		}
+		return strings.TrimRight(string(content), "\r\n")
+	}
+	return utils.GetPassPhrase("", confirmation)
+}
+
+// loadBlsKey reads the hex encoded BLS secret key from the given file.
+func loadBlsKey(file string) *bls_sig.SecretKey {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		utils.Fatalf("Failed to read BLS key file '%s': %v", file, err)
+	}
+	raw, err := hexutil.Decode(strings.TrimSpace(string(content)))
+	if err != nil {
+		utils.Fatalf("BLS key file '%s' is not hex encoded: %v", file, err)
+	}
+	sk, err := bls_sig.SecretKeyFromBytes(raw)
+	if err != nil {
+		utils.Fatalf("Invalid BLS key: %v", err)
+	}
+	return sk
+}
+
+// mustAddress reads a required address flag.
+func mustAddress(ctx *cli.Context, flag cli.StringFlag) common.Address {
+	str := ctx.String(flag.Name)
+	if str == "" {
+		utils.Fatalf("Missing required flag --%s", flag.Name)
+	}
+	if !common.IsHexAddress(str) {
+		utils.Fatalf("Invalid address for --%s: %s", flag.Name, str)
+	}
+	return common.HexToAddress(str)
+}
+
+// mustPubKey reads a required BLS public key flag.
+func mustPubKey(ctx *cli.Context, flag cli.StringFlag) common.BlsPubKey {
+	raw, err := hexutil.Decode(ctx.String(flag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid public key for --%s: %v", flag.Name, err)
+	}
+	if len(raw) != common.BlsPubKeyLength {
+		utils.Fatalf("Public key must be %d bytes", common.BlsPubKeyLength)
+	}
+	return common.BytesToBlsPubKey(raw)
+}
+
+// mustPrintJSON prints the JSON encoding of the given object and
+// exits the program with an error message when the marshaling fails.
+func mustPrintJSON(jsonObject interface{}) {
+	str, err := json.MarshalIndent(jsonObject, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to marshal JSON object: %v", err)
+	}
+	fmt.Println(string(str))
+}
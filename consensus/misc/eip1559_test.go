@@ -30,24 +30,26 @@ import (
 // do not use e.g. SetInt() on the numbers. For testing only
 func copyConfig(original *params.ChainConfig) *params.ChainConfig {
 	return &params.ChainConfig{
-		ChainID:                original.ChainID,
-		SecondsPerSlot:         original.SecondsPerSlot,
-		SlotsPerEpoch:          original.SlotsPerEpoch,
-		EpochsPerEra:           original.EpochsPerEra,
-		TransitionPeriod:       original.TransitionPeriod,
-		ValidatorsStateAddress: original.ValidatorsStateAddress,
-		ValidatorsPerSlot:      original.ValidatorsPerSlot,
-		EffectiveBalance:       original.EffectiveBalance,
-		ValidatorOpExpireSlots: original.ValidatorOpExpireSlots,
-		ForkSlotSubNet1:        original.ForkSlotSubNet1,
-		ForkSlotDelegate:       original.ForkSlotDelegate,
-		ForkSlotPrefixFin:      original.ForkSlotPrefixFin,
-		ForkSlotShanghai:       original.ForkSlotShanghai,
-		ForkSlotValOpTracking:  original.ForkSlotValOpTracking,
-		ForkSlotReduceBaseFee:  original.ForkSlotReduceBaseFee,
-		ForkSlotValSyncProc:    original.ForkSlotValSyncProc,
-		StartEpochsPerEra:      original.StartEpochsPerEra,
-		AcceptCpRootOnFinEpoch: original.AcceptCpRootOnFinEpoch,
+		ChainID:                     original.ChainID,
+		SecondsPerSlot:              original.SecondsPerSlot,
+		SlotsPerEpoch:               original.SlotsPerEpoch,
+		EpochsPerEra:                original.EpochsPerEra,
+		TransitionPeriod:            original.TransitionPeriod,
+		ValidatorsStateAddress:      original.ValidatorsStateAddress,
+		ValidatorsPerSlot:           original.ValidatorsPerSlot,
+		EffectiveBalance:            original.EffectiveBalance,
+		ValidatorOpExpireSlots:      original.ValidatorOpExpireSlots,
+		ForkSlotSubNet1:             original.ForkSlotSubNet1,
+		ForkSlotDelegate:            original.ForkSlotDelegate,
+		ForkSlotPrefixFin:           original.ForkSlotPrefixFin,
+		ForkSlotShanghai:            original.ForkSlotShanghai,
+		ForkSlotValOpTracking:       original.ForkSlotValOpTracking,
+		ForkSlotReduceBaseFee:       original.ForkSlotReduceBaseFee,
+		ForkSlotValSyncProc:         original.ForkSlotValSyncProc,
+		ForkSlotBatchDeposit:        original.ForkSlotBatchDeposit,
+		ForkSlotDelegatorWithdrawal: original.ForkSlotDelegatorWithdrawal,
+		StartEpochsPerEra:           original.StartEpochsPerEra,
+		AcceptCpRootOnFinEpoch:      original.AcceptCpRootOnFinEpoch,
 	}
 }
 
@@ -147,6 +147,7 @@ type CacheConfig struct {
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
+	DagPruneInterval    time.Duration // Time interval to prune the stale unfinalized dag data (0 = disabled)
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -213,6 +214,7 @@ type BlockChain struct {
 	eraInfo                era.EraInfo  // Current Era
 	lastCoordinatedCp      atomic.Value // Current last coordinated checkpoint
 	notProcValSyncOps      map[common.Hash]*types.ValidatorSync
+	valSyncOpsMu           sync.Mutex // guards notProcValSyncOps
 	valSyncCache           *lru.Cache
 
 	stateCache            state.Database // State database to reuse between imports (contains state cache)
@@ -284,7 +286,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		checkpointCache:       checkpointCache,
 		vmConfig:              vmConfig,
 		syncProvider:          nil,
-		validatorStorage:      valStore.NewStorage(chainConfig),
+		validatorStorage:      valStore.NewStorageWithDatabase(chainConfig, db),
 	}
 	bc.validator = NewBlockValidator(chainConfig, bc)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc)
@@ -429,7 +431,15 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		}()
 	}
 
-	bc.notProcValSyncOps = bc.GetNotProcessedValidatorSyncData()
+	// Start the stale dag pruner if required.
+	if bc.cacheConfig.DagPruneInterval > 0 {
+		bc.wg.Add(1)
+		go bc.pruneDagLoop(bc.cacheConfig.DagPruneInterval)
+	}
+
+	bc.valSyncOpsMu.Lock()
+	bc.notProcessedValidatorSyncOps()
+	bc.valSyncOpsMu.Unlock()
 
 	return bc, nil
 }
@@ -654,56 +664,53 @@ func (bc *BlockChain) GetEpoch(epoch uint64) common.Hash {
 }
 
 // GetValidatorSyncData retrieves a validator sync data from the database by
-// creator addr and op, caching it if found.
-func (bc *BlockChain) GetValidatorSyncData(initTxHash common.Hash) *types.ValidatorSync {
+// initial tx hash and creator addr, caching it if found.
+func (bc *BlockChain) GetValidatorSyncData(initTxHash common.Hash, creator common.Address) *types.ValidatorSync {
+	key := types.ValidatorSyncKey(initTxHash, creator)
 	// Short circuit if the body's already in the cache, retrieve otherwise
-	if cached, ok := bc.valSyncCache.Get(initTxHash); ok {
+	if cached, ok := bc.valSyncCache.Get(key); ok {
 		vs := cached.(*types.ValidatorSync)
 		return vs
 	}
-	vs := rawdb.ReadValidatorSync(bc.db, initTxHash)
+	vs := rawdb.ReadValidatorSync(bc.db, initTxHash, creator)
 	if vs == nil {
 		return nil
 	}
 	// Cache the found data for next time and return
-	bc.valSyncCache.Add(initTxHash, vs)
+	bc.valSyncCache.Add(key, vs)
 	return vs
 }
 
 func (bc *BlockChain) SetValidatorSyncData(validatorSync *types.ValidatorSync) {
+	bc.valSyncOpsMu.Lock()
+	defer bc.valSyncOpsMu.Unlock()
+
 	key := validatorSync.Key()
 	if _, ok := bc.valSyncCache.Get(key); ok {
 		bc.valSyncCache.Remove(key)
 	}
 	bc.valSyncCache.Add(key, validatorSync)
 	rawdb.WriteValidatorSync(bc.db, validatorSync)
-	if validatorSync.TxHash != nil && bc.notProcValSyncOps[key] != nil {
-		notProcValSyncOps := map[common.Hash]*types.ValidatorSync{}
-		for k, vs := range bc.notProcValSyncOps {
-			if k != key {
-				notProcValSyncOps[k] = vs
-			}
-		}
-		bc.notProcValSyncOps = notProcValSyncOps
-		vsArr := make([]*types.ValidatorSync, 0, len(bc.notProcValSyncOps))
-		for _, vs := range bc.notProcValSyncOps {
-			vsArr = append(vsArr, vs)
-		}
-		rawdb.WriteNotProcessedValidatorSyncOps(bc.db, vsArr)
+	if validatorSync.TxHash != nil && bc.notProcessedValidatorSyncOps()[key] != nil {
+		delete(bc.notProcValSyncOps, key)
+		bc.writeNotProcessedValidatorSyncData(bc.db)
 	}
 }
 
 // AppendNotProcessedValidatorSyncData append to not processed validators sync data.
 // skips currently existed items
 func (bc *BlockChain) AppendNotProcessedValidatorSyncData(valSyncData []*types.ValidatorSync) {
-	currOps := bc.GetNotProcessedValidatorSyncData()
+	bc.valSyncOpsMu.Lock()
+	defer bc.valSyncOpsMu.Unlock()
+
+	currOps := bc.notProcessedValidatorSyncOps()
 	isUpdated := false
 	valSyncDataKeys := map[common.Hash]interface{}{}
 	for _, vs := range valSyncData {
 		valSyncDataKeys[vs.Key()] = struct{}{}
 		if npvs := currOps[vs.Key()]; npvs == nil || npvs.ProcEpoch > vs.ProcEpoch {
 			// check in saved op
-			savedValSync := bc.GetValidatorSyncData(vs.InitTxHash)
+			savedValSync := bc.GetValidatorSyncData(vs.InitTxHash, vs.Creator)
 			if savedValSync == nil || (savedValSync.ProcEpoch > vs.ProcEpoch && savedValSync.TxHash != nil) {
 				bc.notProcValSyncOps[vs.Key()] = vs
 				isUpdated = true
@@ -719,16 +726,27 @@ func (bc *BlockChain) AppendNotProcessedValidatorSyncData(valSyncData []*types.V
 	}
 
 	if isUpdated {
-		vsArr := make([]*types.ValidatorSync, 0, len(bc.notProcValSyncOps))
-		for _, vs := range bc.notProcValSyncOps {
-			vsArr = append(vsArr, vs)
-		}
-		rawdb.WriteNotProcessedValidatorSyncOps(bc.db, vsArr)
+		bc.writeNotProcessedValidatorSyncData(bc.db)
 	}
 }
 
-// GetNotProcessedValidatorSyncData get current not processed validator sync data.
+// GetNotProcessedValidatorSyncData get a copy of current not processed validator sync data.
 func (bc *BlockChain) GetNotProcessedValidatorSyncData() map[common.Hash]*types.ValidatorSync {
+	bc.valSyncOpsMu.Lock()
+	defer bc.valSyncOpsMu.Unlock()
+
+	ops := bc.notProcessedValidatorSyncOps()
+	cpy := make(map[common.Hash]*types.ValidatorSync, len(ops))
+	for k, vs := range ops {
+		cpy[k] = vs.Copy()
+	}
+	return cpy
+}
+
+// notProcessedValidatorSyncOps returns current not processed validator sync data,
+// the data is loaded from db at first call.
+// Must be called with valSyncOpsMu held.
+func (bc *BlockChain) notProcessedValidatorSyncOps() map[common.Hash]*types.ValidatorSync {
 	if bc.notProcValSyncOps == nil {
 		ops := rawdb.ReadNotProcessedValidatorSyncOps(bc.db)
 		bc.notProcValSyncOps = make(map[common.Hash]*types.ValidatorSync, len(ops))
@@ -743,6 +761,71 @@ func (bc *BlockChain) GetNotProcessedValidatorSyncData() map[common.Hash]*types.
 	return bc.notProcValSyncOps
 }
 
+// RequeueValidatorSyncData returns the validator sync operation to the not processed list.
+// Only the operation which is not processed yet can be requeued:
+// the operation processed by a successful tx is rejected.
+func (bc *BlockChain) RequeueValidatorSyncData(initTxHash common.Hash, creator common.Address) (*types.ValidatorSync, error) {
+	bc.valSyncOpsMu.Lock()
+	defer bc.valSyncOpsMu.Unlock()
+
+	key := types.ValidatorSyncKey(initTxHash, creator)
+	vs := bc.notProcessedValidatorSyncOps()[key]
+	if saved := bc.GetValidatorSyncData(initTxHash, creator); saved != nil && (vs == nil || saved.TxHash != nil) {
+		vs = saved
+	}
+	if vs == nil {
+		return nil, ErrValSyncOpNF
+	}
+	if vs.TxHash != nil {
+		if rc, _, _ := bc.GetTransactionReceipt(*vs.TxHash); rc != nil && rc.Status == types.ReceiptStatusSuccessful {
+			return nil, ErrValSyncOpProcessed
+		}
+	}
+	requeued := vs.Copy()
+	requeued.TxHash = nil
+
+	batch := bc.db.NewBatch()
+	rawdb.WriteValidatorSync(batch, requeued)
+	bc.notProcValSyncOps[key] = requeued
+	bc.writeNotProcessedValidatorSyncData(batch)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to requeue validator sync op", "err", err)
+	}
+	bc.valSyncCache.Remove(key)
+	bc.valSyncCache.Add(key, requeued)
+
+	log.Info("Validator sync op requeued", "initTxHash", initTxHash.Hex(), "creator", creator.Hex(), "op", requeued.Print())
+	return requeued.Copy(), nil
+}
+
+// DropValidatorSyncData removes the validator sync operation from the not processed list.
+// The saved data of the operation is kept to validate the operation txs.
+func (bc *BlockChain) DropValidatorSyncData(initTxHash common.Hash, creator common.Address) (*types.ValidatorSync, error) {
+	bc.valSyncOpsMu.Lock()
+	defer bc.valSyncOpsMu.Unlock()
+
+	key := types.ValidatorSyncKey(initTxHash, creator)
+	vs := bc.notProcessedValidatorSyncOps()[key]
+	if vs == nil {
+		return nil, ErrValSyncOpNF
+	}
+	delete(bc.notProcValSyncOps, key)
+	bc.writeNotProcessedValidatorSyncData(bc.db)
+
+	log.Info("Validator sync op dropped", "initTxHash", initTxHash.Hex(), "creator", creator.Hex(), "op", vs.Print())
+	return vs.Copy(), nil
+}
+
+// writeNotProcessedValidatorSyncData stores the not processed validator sync data.
+// Must be called with valSyncOpsMu held.
+func (bc *BlockChain) writeNotProcessedValidatorSyncData(db ethdb.KeyValueWriter) {
+	vsArr := make([]*types.ValidatorSync, 0, len(bc.notProcValSyncOps))
+	for _, vs := range bc.notProcValSyncOps {
+		vsArr = append(vsArr, vs)
+	}
+	rawdb.WriteNotProcessedValidatorSyncOps(db, vsArr)
+}
+
 // SetHead rewinds the local chain to a new head.
 // The method searches finalised block which provide chain consistency,
 // starting from passed hash;
@@ -855,6 +938,7 @@ func (bc *BlockChain) setHeadRecursive(head common.Hash) error {
 				break
 			}
 			rawdb.DeleteEra(bc.db, eraNr)
+			bc.ValidatorStorage().ResetValidatorsCache(eraNr)
 		}
 	}
 	// clean checkpoints && epochs
@@ -974,7 +1058,12 @@ func (bc *BlockChain) rmBlockData(hash common.Hash, slot *uint64) {
 		rawdb.DeleteSlotBlockHash(bc.db, *slot, hash)
 	}
 	rawdb.DeleteBlockWithoutNumber(bc.db, hash)
+	bc.clearBlockCaches(hash, slot)
+}
 
+// clearBlockCaches removes the block from the blockchain caches.
+// slot - optional.
+func (bc *BlockChain) clearBlockCaches(hash common.Hash, slot *uint64) {
 	// clear bc caches
 	bc.bodyCache.Remove(hash)
 	bc.bodyRLPCache.Remove(hash)
@@ -1005,6 +1094,7 @@ func (bc *BlockChain) rmBlockData(hash common.Hash, slot *uint64) {
 // 1. set the passed block as the last finalized,
 // 2. removes finalisation data from last finalized up to lfNr
 // 3. move all unfinalized blocks to dag and provide consistented tips
+// 4. notifies subscribers about the logs of the rolled back blocks
 func (bc *BlockChain) RollbackFinalization(spineHash common.Hash, lfNr uint64) error {
 	ctx := context.Background()
 	newLfBlock := bc.GetBlock(ctx, spineHash)
@@ -1052,6 +1142,7 @@ func (bc *BlockChain) RollbackFinalization(spineHash common.Hash, lfNr uint64) e
 	defer bc.ResetRollbackActive()
 
 	//reorg finalized and dag chains in accordance with spineHash
+	var deletedLogs []*types.Log
 	for i := lfNr; i > newLfBlock.Nr(); i-- {
 		blockHeader := bc.GetHeaderByNumber(i)
 		if blockHeader == nil {
@@ -1071,11 +1162,19 @@ func (bc *BlockChain) RollbackFinalization(spineHash common.Hash, lfNr uint64) e
 				OrderedAncestorsHashes: ancestors.Hashes(),
 			})
 		}
+		logs := bc.collectRemovedLogs(blockHeader.Hash())
 		err := bc.rollbackBlockFinalization(i)
 		if err != nil {
 			log.Error("Rollback finalization: rollback block finalization error", "finNr", i, "hash", blockHeader.Hash().Hex(), "err", err)
+			continue
 		}
+		deletedLogs = append(deletedLogs, logs...)
+	}
+	if len(deletedLogs) > 0 {
+		bc.rmLogsFeed.Send(RemovedLogsEvent{Logs: deletedLogs})
 	}
+	// the next era validators could be prepared by rolled back state
+	bc.ValidatorStorage().ResetValidatorsCache(rawdb.ReadCurrentEra(bc.db) + 1)
 	// update head of finalized chain
 	if err := bc.WriteFinalizedBlock(newLfBlock.Nr(), newLfBlock, true); err != nil {
 		return err
@@ -1122,6 +1221,90 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	return nil
 }
 
+// InsertCheckpointAnchor anchors the chain at the trusted checkpoint
+// which state and the state of its era root are retrieved by the checkpoint sync.
+// It sets the checkpoint spine as the last finalized block
+// and restores the era info and the validators of the checkpoint era.
+func (bc *BlockChain) InsertCheckpointAnchor(cp *types.Checkpoint, cpEra *era.Era, block *types.Block) error {
+	if block.Hash() != cp.Spine {
+		return fmt.Errorf("%w: spine mismatch %#x != %#x", ErrBadCheckpointAnchor, block.Hash(), cp.Spine)
+	}
+	finNr := block.Nr()
+	if finNr == 0 {
+		return fmt.Errorf("%w: spine is not finalized", ErrBadCheckpointAnchor)
+	}
+	if !cpEra.IsContainsEpoch(cp.FinEpoch) {
+		return fmt.Errorf("%w: era %d does not contain epoch %d", ErrBadCheckpointAnchor, cpEra.Number, cp.FinEpoch)
+	}
+	if block.Root() != (common.Hash{}) && block.Root() != cp.Root {
+		return fmt.Errorf("%w: root mismatch %#x != %#x", ErrBadCheckpointAnchor, block.Root(), cp.Root)
+	}
+	if !bc.HasState(cp.Root) {
+		return fmt.Errorf("%w: root=%#x", ErrSpineStateNF, cp.Root)
+	}
+	// the era validators are built from the state of the era root.
+	if !bc.HasState(cpEra.Root) {
+		return fmt.Errorf("%w: era root=%#x", ErrSpineStateNF, cpEra.Root)
+	}
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	if block.Root() == (common.Hash{}) {
+		// the root is not a part of the block hash, so set the trusted one.
+		header := block.Header()
+		header.Root = cp.Root
+		block = types.NewBlockWithHeader(header).WithBody(block.Transactions())
+	}
+
+	batch := bc.db.NewBatch()
+	rawdb.WriteBlock(batch, block)
+	rawdb.WriteEra(batch, cpEra.Number, *cpEra)
+	rawdb.WriteCurrentEra(batch, cpEra.Number)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write checkpoint anchor", "err", err)
+	}
+	if err := bc.writeFinalizedBlock(finNr, block, true); err != nil {
+		return err
+	}
+	bc.SetLastCoordinatedCheckpoint(cp)
+	bc.SetNewEraInfo(*cpEra)
+
+	// the ancestors of the anchor are not available,
+	// so the anchor is the only tip and its own checkpoint.
+	bc.hc.ClearBlockDag()
+	bc.SaveBlockDag(&types.BlockDAG{
+		Hash:                   block.Hash(),
+		Height:                 block.Height(),
+		Slot:                   block.Slot(),
+		CpHash:                 block.Hash(),
+		CpHeight:               block.Height(),
+		OrderedAncestorsHashes: common.HashArray{},
+	})
+	rawdb.WriteTipsHashes(bc.db, common.HashArray{block.Hash()})
+	if err := bc.hc.loadTips(); err != nil {
+		return err
+	}
+
+	if err := bc.ValidatorStorage().RestoreEraValidators(bc, cpEra); err != nil {
+		return err
+	}
+	if bc.snaps != nil {
+		bc.snaps.Rebuild(cp.Root)
+	}
+
+	log.Info("Chain anchored at checkpoint",
+		"finNr", finNr,
+		"slot", block.Slot(),
+		"spine", cp.Spine.Hex(),
+		"root", cp.Root.Hex(),
+		"finEpoch", cp.FinEpoch,
+		"era", cpEra.Number,
+	)
+	return nil
+}
+
 // Export writes the active chain to the given writer.
 func (bc *BlockChain) Export(w io.Writer) error {
 	return bc.ExportN(w, uint64(0), bc.GetLastFinalizedBlock().Nr())
@@ -1606,6 +1789,20 @@ func (bc *BlockChain) IsRollbackActive() bool {
 	return bc.hc.IsRollbackActive()
 }
 
+// collectRemovedLogs returns copies of the logs of the finalized block
+// marked as removed.
+func (bc *BlockChain) collectRemovedLogs(hash common.Hash) []*types.Log {
+	var logs []*types.Log
+	for _, receipt := range bc.GetReceiptsByHash(hash) {
+		for _, l := range receipt.Logs {
+			rl := *l
+			rl.Removed = true
+			logs = append(logs, &rl)
+		}
+	}
+	return logs
+}
+
 // rollbackBlockFinalization reset block's finalization data only.
 func (bc *BlockChain) rollbackBlockFinalization(finNr uint64) error {
 	if !bc.chainmu.TryLock() {
@@ -1921,6 +2118,26 @@ func IsAddressAssigned(address common.Address, creators []common.Address, creato
 	return pos == creatorNr
 }
 
+// AssignedCreator returns the creator of the slot which includes the transactions
+// of the address. The creators include only their own transactions, the others
+// are distributed between the creators by IsAddressAssigned.
+func AssignedCreator(address common.Address, creators []common.Address) (common.Address, bool) {
+	if len(creators) == 0 {
+		return common.Address{}, false
+	}
+	for _, creator := range creators {
+		if creator == address {
+			return creator, true
+		}
+	}
+	for i, creator := range creators {
+		if IsAddressAssigned(address, creators, int64(i)) {
+			return creator, true
+		}
+	}
+	return common.Address{}, false
+}
+
 // Deprecated, used for tests only
 // syncInsertChain is the internal implementation of SyncInsertChain, which assumes that
 // 1) chains are contiguous, and 2) The chain mutex is held.
@@ -2196,6 +2413,11 @@ func (bc *BlockChain) CacheInvalidBlock(block *types.Block) {
 	bc.invalidBlocksCache.Add(block.Hash(), struct{}{})
 }
 
+// IsInvalidBlock returns true if the block is cached as invalid.
+func (bc *BlockChain) IsInvalidBlock(hash common.Hash) bool {
+	return bc.invalidBlocksCache.Contains(hash)
+}
+
 // VerifyBlock validate block
 func (bc *BlockChain) VerifyBlock(block *types.Block) (bool, error) {
 	defer func(ts time.Time) {
@@ -3750,6 +3972,37 @@ func (bc *BlockChain) CollectStateDataByFinalizedBlock(block *types.Block) (stat
 	return statedb, stateBlock, recommitBlocks, nil
 }
 
+// StateByTips returns the state derived from the current tips and the header
+// of the block which would be created on top of them. The transactions of the
+// red blocks are applied to the spine state without any side effects.
+func (bc *BlockChain) StateByTips() (*state.StateDB, *types.Header, error) {
+	tips := bc.GetTips()
+	statedb, stateBlock, recommitBlocks, calcHeight, err := bc.CollectStateDataByParents(tips.GetHashes())
+	if err != nil {
+		return nil, nil, err
+	}
+	if statedb == nil {
+		return nil, nil, ErrSpineStateNF
+	}
+	for _, block := range recommitBlocks {
+		_, errs, _ := bc.applyBlockTransactions(block, statedb)
+		for i, err := range errs {
+			if err != nil {
+				log.Debug("Skipping transaction of tips state", "hash", block.Transactions()[i].Hash().Hex(), "err", err)
+			}
+		}
+	}
+	header := types.CopyHeader(stateBlock.Header())
+	header.ParentHashes = tips.GetHashes()
+	header.Height = calcHeight
+	header.Number = nil
+	header.Era = bc.GetEraInfo().Number()
+	if slotInfo := bc.GetSlotInfo(); slotInfo != nil {
+		header.Slot = slotInfo.CurrentSlot()
+	}
+	return statedb, header, nil
+}
+
 // CollectStateDataByBlock collects state data of current dag chain to insert new block.
 func (bc *BlockChain) CollectStateDataByBlock(block *types.Block) (statedb *state.StateDB, stateBlock *types.Block, err error) {
 	finNr := block.Nr()
@@ -3852,6 +4105,26 @@ func (bc *BlockChain) CommitBlockTransactions(block *types.Block, statedb *state
 	return statedb, receipts, rlogs, *gasUsed
 }
 
+// applyBlockTransactions applies the transactions of the block to the state
+// to recommit the tips state, the receipts and the errors
+// of the transactions are handled by the caller.
+func (bc *BlockChain) applyBlockTransactions(block *types.Block, statedb *state.StateDB) ([]*types.Receipt, []error, uint64) {
+	var (
+		header   = block.Header()
+		gasPool  = new(GasPool).AddGas(block.GasLimit())
+		gasUsed  uint64
+		receipts = make([]*types.Receipt, 0, len(block.Transactions()))
+		errs     = make([]error, 0, len(block.Transactions()))
+	)
+	for i, tx := range block.Transactions() {
+		statedb.Prepare(tx.Hash(), i)
+		receipt, err := ApplyTransaction(bc.chainConfig, bc, &header.Coinbase, gasPool, statedb, header, tx, &gasUsed, *bc.GetVMConfig(), bc)
+		receipts = append(receipts, receipt)
+		errs = append(errs, err)
+	}
+	return receipts, errs, gasUsed
+}
+
 func (bc *BlockChain) EstimateGas(msg types.Message, header *types.Header) (uint64, error) {
 	if len(msg.Data()) == 0 {
 		return params.TxGas, nil
@@ -4879,7 +5152,7 @@ func (bc *BlockChain) RestoreValidatorSyncOp(tx *types.Transaction, header *type
 	}
 	switch v := op.(type) {
 	case validatorOp.ValidatorSync:
-		savedValSync := bc.GetValidatorSyncData(v.InitTxHash())
+		savedValSync := bc.GetValidatorSyncData(v.InitTxHash(), v.Creator())
 		if savedValSync == nil {
 			log.Error("Validator sync tx: restore op fail 222",
 				"failedTx", fmt.Sprintf("%#x", tx.Hash()),
@@ -4909,21 +5182,27 @@ func (bc *BlockChain) RestoreValidatorSyncOp(tx *types.Transaction, header *type
 			if rc != nil && rc.Status == types.ReceiptStatusSuccessful {
 				return nil
 			} else {
-				bc.notProcValSyncOps[savedValSync.Key()].TxHash = nil
+				bc.valSyncOpsMu.Lock()
+				if npOp := bc.notProcessedValidatorSyncOps()[savedValSync.Key()]; npOp != nil {
+					npOp.TxHash = nil
+				}
+				bc.valSyncOpsMu.Unlock()
 			}
 		}
 		//reset tx hash
 		savedValSync.TxHash = nil
-		if bc.notProcValSyncOps[savedValSync.Key()] != nil &&
-			bc.notProcValSyncOps[savedValSync.Key()].TxHash != nil &&
-			*bc.notProcValSyncOps[savedValSync.Key()].TxHash == tx.Hash() {
-			bc.notProcValSyncOps[savedValSync.Key()].TxHash = nil
+		bc.valSyncOpsMu.Lock()
+		if npOp := bc.notProcessedValidatorSyncOps()[savedValSync.Key()]; npOp != nil &&
+			npOp.TxHash != nil &&
+			*npOp.TxHash == tx.Hash() {
+			npOp.TxHash = nil
 		}
+		bc.valSyncOpsMu.Unlock()
 
 		//restore as not processed
 		bc.AppendNotProcessedValidatorSyncData([]*types.ValidatorSync{savedValSync})
 
-		updop := bc.notProcValSyncOps[savedValSync.Key()]
+		updop := bc.GetNotProcessedValidatorSyncData()[savedValSync.Key()]
 		log.Info("Validator sync tx: restore op",
 			"failedTx", fmt.Sprintf("%#x", tx.Hash()),
 			"OpType", v.OpType(),
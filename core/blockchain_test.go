@@ -0,0 +1,247 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
+	valStore "gitlab.waterfall.network/waterfall/protocol/gwat/validator/storage"
+)
+
+func TestRequeueDropValidatorSyncData(t *testing.T) {
+	bc, _ := getTestBlockchainAndBlocks()
+
+	vs := &types.ValidatorSync{
+		InitTxHash: common.Hash{0x11},
+		OpType:     types.UpdateBalance,
+		ProcEpoch:  10,
+		Index:      3,
+		Creator:    common.Address{0x22},
+		Amount:     big.NewInt(1000),
+		Balance:    big.NewInt(2000),
+	}
+	bc.AppendNotProcessedValidatorSyncData([]*types.ValidatorSync{vs})
+	if bc.GetNotProcessedValidatorSyncData()[vs.Key()] == nil {
+		t.Fatal("validator sync op not found")
+	}
+
+	// drop
+	dropped, err := bc.DropValidatorSyncData(vs.InitTxHash, vs.Creator)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, vs.InitTxHash, dropped.InitTxHash)
+	testutils.AssertNil(t, bc.GetNotProcessedValidatorSyncData()[vs.Key()])
+	testutils.AssertEqual(t, 0, len(rawdb.ReadNotProcessedValidatorSyncOps(bc.db)))
+	if bc.GetValidatorSyncData(vs.InitTxHash, vs.Creator) == nil {
+		t.Fatal("validator sync op not found")
+	}
+
+	_, err = bc.DropValidatorSyncData(vs.InitTxHash, vs.Creator)
+	testutils.AssertError(t, err, ErrValSyncOpNF)
+
+	// dropped op is not appended again by coordinator data
+	bc.AppendNotProcessedValidatorSyncData([]*types.ValidatorSync{vs})
+	testutils.AssertNil(t, bc.GetNotProcessedValidatorSyncData()[vs.Key()])
+
+	// requeue op with failed processing tx
+	failedTx := common.Hash{0x33}
+	processed := vs.Copy()
+	processed.TxHash = &failedTx
+	bc.SetValidatorSyncData(processed)
+
+	requeued, err := bc.RequeueValidatorSyncData(vs.InitTxHash, vs.Creator)
+	testutils.AssertNoError(t, err)
+	testutils.AssertNil(t, requeued.TxHash)
+	if bc.GetNotProcessedValidatorSyncData()[vs.Key()] == nil {
+		t.Fatal("validator sync op not found")
+	}
+	testutils.AssertNil(t, bc.GetValidatorSyncData(vs.InitTxHash, vs.Creator).TxHash)
+	testutils.AssertEqual(t, 1, len(rawdb.ReadNotProcessedValidatorSyncOps(bc.db)))
+	// the requeued op is persisted
+	testutils.AssertNil(t, rawdb.ReadValidatorSync(bc.db, vs.InitTxHash, vs.Creator).TxHash)
+
+	_, err = bc.RequeueValidatorSyncData(common.Hash{0x44}, vs.Creator)
+	testutils.AssertError(t, err, ErrValSyncOpNF)
+}
+
+func TestValidatorSyncDataConcurrency(t *testing.T) {
+	bc, _ := getTestBlockchainAndBlocks()
+
+	ops := make([]*types.ValidatorSync, 8)
+	for i := range ops {
+		ops[i] = &types.ValidatorSync{
+			InitTxHash: common.Hash{byte(i + 1)},
+			OpType:     types.UpdateBalance,
+			ProcEpoch:  10,
+			Index:      uint64(i),
+			Creator:    common.Address{0x22},
+			Amount:     big.NewInt(1000),
+			Balance:    big.NewInt(2000),
+		}
+	}
+	bc.AppendNotProcessedValidatorSyncData(ops)
+
+	// the accessors return copies
+	bc.GetNotProcessedValidatorSyncData()[ops[0].Key()].TxHash = &common.Hash{0x33}
+	testutils.AssertNil(t, bc.GetNotProcessedValidatorSyncData()[ops[0].Key()].TxHash)
+
+	var wg sync.WaitGroup
+	for _, vs := range ops {
+		wg.Add(4)
+		go func(vs *types.ValidatorSync) {
+			defer wg.Done()
+			bc.AppendNotProcessedValidatorSyncData([]*types.ValidatorSync{vs})
+		}(vs)
+		go func(vs *types.ValidatorSync) {
+			defer wg.Done()
+			bc.DropValidatorSyncData(vs.InitTxHash, vs.Creator)
+		}(vs)
+		go func(vs *types.ValidatorSync) {
+			defer wg.Done()
+			bc.RequeueValidatorSyncData(vs.InitTxHash, vs.Creator)
+		}(vs)
+		go func() {
+			defer wg.Done()
+			for _, op := range bc.GetNotProcessedValidatorSyncData() {
+				_ = op.TxHash
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the list in db matches the cached one
+	testutils.AssertEqual(t, len(bc.GetNotProcessedValidatorSyncData()), len(rawdb.ReadNotProcessedValidatorSyncOps(bc.db)))
+}
+
+func TestValidatorSyncDataByCreator(t *testing.T) {
+	bc, _ := getTestBlockchainAndBlocks()
+
+	// the batch deposit initiates the activations of several validators by a single tx
+	initTxHash := common.Hash{0x11}
+	ops := []*types.ValidatorSync{
+		{InitTxHash: initTxHash, OpType: types.Activate, ProcEpoch: 10, Index: 3, Creator: common.Address{0x22}},
+		{InitTxHash: initTxHash, OpType: types.Activate, ProcEpoch: 10, Index: 4, Creator: common.Address{0x33}},
+	}
+	bc.AppendNotProcessedValidatorSyncData(ops)
+	testutils.AssertEqual(t, len(ops), len(bc.GetNotProcessedValidatorSyncData()))
+	testutils.AssertEqual(t, len(ops), len(rawdb.ReadNotProcessedValidatorSyncOps(bc.db)))
+
+	processed := ops[0].Copy()
+	processed.TxHash = &common.Hash{0x44}
+	bc.SetValidatorSyncData(processed)
+
+	testutils.AssertEqual(t, processed.TxHash, bc.GetValidatorSyncData(initTxHash, ops[0].Creator).TxHash)
+	testutils.AssertNil(t, bc.GetValidatorSyncData(initTxHash, ops[1].Creator).TxHash)
+	testutils.AssertEqual(t, ops[1].Index, bc.GetValidatorSyncData(initTxHash, ops[1].Creator).Index)
+	if bc.GetNotProcessedValidatorSyncData()[ops[1].Key()] == nil {
+		t.Fatal("validator sync op not found")
+	}
+}
+
+func TestInsertCheckpointAnchor(t *testing.T) {
+	bc, _ := getTestBlockchainAndBlocks()
+	genesis := bc.Genesis()
+	// use the genesis config, which contains the validators state address.
+	bc.validatorStorage = valStore.NewStorageWithDatabase(params.AllEthashProtocolChanges, bc.db)
+
+	finNr := uint64(5)
+	block := types.NewBlockWithHeader(&types.Header{
+		ParentHashes: common.HashArray{genesis.Hash()},
+		Slot:         40,
+		Height:       5,
+		Number:       &finNr,
+		Root:         genesis.Root(),
+	})
+	cp := &types.Checkpoint{
+		Epoch:    2,
+		FinEpoch: 1,
+		Root:     genesis.Root(),
+		Spine:    block.Hash(),
+	}
+	cpEra := &era.Era{Number: 0, From: 0, To: 10, Root: genesis.Root(), BlockHash: common.Hash{0x22}}
+
+	// spine mismatch
+	badCp := cp.Copy()
+	badCp.Spine = common.Hash{0x33}
+	err := bc.InsertCheckpointAnchor(badCp, cpEra, block)
+	testutils.AssertError(t, err, ErrBadCheckpointAnchor)
+
+	// era does not contain checkpoint
+	err = bc.InsertCheckpointAnchor(cp, &era.Era{Number: 1, From: 11, To: 20}, block)
+	testutils.AssertError(t, err, ErrBadCheckpointAnchor)
+
+	// spine root mismatch
+	badCp = cp.Copy()
+	badCp.Root = common.Hash{0x44}
+	err = bc.InsertCheckpointAnchor(badCp, cpEra, block)
+	testutils.AssertError(t, err, ErrBadCheckpointAnchor)
+
+	// state not retrieved, the root is not a part of the block hash
+	noRootHeader := block.Header()
+	noRootHeader.Root = common.Hash{}
+	err = bc.InsertCheckpointAnchor(badCp, cpEra, types.NewBlockWithHeader(noRootHeader))
+	testutils.AssertError(t, err, ErrSpineStateNF)
+
+	// era state not retrieved
+	badEra := *cpEra
+	badEra.Root = common.Hash{0x11}
+	err = bc.InsertCheckpointAnchor(cp, &badEra, block)
+	testutils.AssertError(t, err, ErrSpineStateNF)
+
+	err = bc.InsertCheckpointAnchor(cp, cpEra, block)
+	testutils.AssertNoError(t, err)
+
+	testutils.AssertEqual(t, block.Hash(), bc.GetLastFinalizedBlock().Hash())
+	testutils.AssertEqual(t, finNr, bc.GetLastFinalizedNumber())
+	testutils.AssertEqual(t, cp.FinEpoch, bc.GetLastCoordinatedCheckpoint().FinEpoch)
+	testutils.AssertEqual(t, cp.Spine, bc.GetEpoch(cp.FinEpoch))
+	testutils.AssertEqual(t, *cpEra, *bc.GetEraInfo().GetEra())
+	testutils.AssertEqual(t, *cpEra, *rawdb.ReadEra(bc.db, cpEra.Number))
+	testutils.AssertEqual(t, common.HashArray{block.Hash()}, bc.GetTips().GetHashes())
+	// the era validators are persisted with the era root they are built from
+	data := rawdb.ReadEraValidators(bc.db, cpEra.Number)
+	if data == nil {
+		t.Fatal("era validators not restored")
+	}
+	testutils.AssertEqual(t, cpEra.Root, common.BytesToHash(data[:common.HashLength]))
+}
+
+func TestAssignedCreator(t *testing.T) {
+	creators := make([]common.Address, 0, 4)
+	for i := 0; i < 4; i++ {
+		creators = append(creators, common.BytesToAddress(testutils.RandomData(20)))
+	}
+
+	_, ok := AssignedCreator(creators[0], nil)
+	testutils.AssertEqual(t, false, ok)
+
+	// the creator includes its own transactions
+	creator, ok := AssignedCreator(creators[2], creators)
+	testutils.AssertEqual(t, true, ok)
+	testutils.AssertEqual(t, creators[2], creator)
+
+	sender := common.BytesToAddress(testutils.RandomData(20))
+	creator, ok = AssignedCreator(sender, creators)
+	testutils.AssertEqual(t, true, ok)
+	pos := new(big.Int).Mod(sender.Hash().Big(), big.NewInt(int64(len(creators)))).Int64()
+	testutils.AssertEqual(t, creators[pos], creator)
+}
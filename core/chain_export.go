@@ -0,0 +1,388 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rlp"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
+)
+
+// DagExportVersion is the current version of the dag export format.
+const DagExportVersion = 1
+
+// dagExportMagic starts each segment of the dag export. It never collides
+// with the legacy export, which starts with an RLP list.
+var dagExportMagic = []byte("gwatdag")
+
+var (
+	errBadDagExport         = errors.New("bad dag export")
+	errDagExportGenesis     = errors.New("dag export of other genesis")
+	errDagExportVersion     = errors.New("unsupported dag export version")
+	errDagExportMismatch    = errors.New("imported block state mismatch")
+	errDagImportInterrupted = errors.New("dag import interrupted")
+)
+
+// DagExportHeader starts each segment of the dag export.
+type DagExportHeader struct {
+	Version     uint64
+	Genesis     common.Hash
+	GenesisRoot common.Hash // the genesis hash does not cover the genesis state
+	First       uint64      // first exported finalized number
+	Last        uint64      // last exported finalized number
+}
+
+// dagExportKind is the type of the dag export record.
+type dagExportKind uint8
+
+const (
+	dagExportBlock dagExportKind = iota
+	dagExportBlockDag
+	dagExportChildren
+	dagExportCheckpoint
+	dagExportValidatorSync
+	dagExportEra
+)
+
+// dagExportRecord is a single item of the dag export.
+type dagExportRecord struct {
+	Kind dagExportKind
+	Data []byte
+}
+
+// dagExportChildrenEntry is the children index entry of the block.
+type dagExportChildrenEntry struct {
+	Parent   common.Hash
+	Children common.HashArray
+}
+
+// IsDagExport reports whether the reader is positioned at the start
+// of the dag export segment.
+func IsDagExport(r *bufio.Reader) bool {
+	magic, err := r.Peek(len(dagExportMagic))
+	return err == nil && bytes.Equal(magic, dagExportMagic)
+}
+
+func writeDagExportRecord(w io.Writer, kind dagExportKind, val interface{}) error {
+	data, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, &dagExportRecord{Kind: kind, Data: data})
+}
+
+// ExportDag writes a segment of the finalized chain to the given writer
+// together with the dag metadata: eras, coordinated checkpoints of the
+// segment with the epoch mapping, validator sync data, block dags
+// and children index.
+func (bc *BlockChain) ExportDag(w io.Writer, first uint64, last uint64) error {
+	if !bc.chainmu.TryLock() {
+		return errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	log.Info("Exporting dag chain", "first", first, "last", last)
+
+	if _, err := w.Write(dagExportMagic); err != nil {
+		return err
+	}
+	header := &DagExportHeader{
+		Version:     DagExportVersion,
+		Genesis:     bc.genesisBlock.Hash(),
+		GenesisRoot: bc.genesisBlock.Root(),
+		First:       first,
+		Last:        last,
+	}
+	if err := rlp.Encode(w, header); err != nil {
+		return err
+	}
+	// eras
+	for number := uint64(0); number <= rawdb.ReadCurrentEra(bc.db); number++ {
+		if e := rawdb.ReadEra(bc.db, number); e != nil {
+			if err := writeDagExportRecord(w, dagExportEra, e); err != nil {
+				return err
+			}
+		}
+	}
+	// coordinated checkpoints with spines finalized in the segment
+	if lastCp := bc.GetLastCoordinatedCheckpoint(); lastCp != nil {
+		for epoch := uint64(0); epoch <= lastCp.FinEpoch; epoch++ {
+			spine := bc.GetEpoch(epoch)
+			if spine == (common.Hash{}) {
+				continue
+			}
+			nr := rawdb.ReadFinalizedNumberByHash(bc.db, spine)
+			if nr == nil || *nr < first || *nr > last {
+				continue
+			}
+			if cp := bc.GetCoordinatedCheckpoint(spine); cp != nil {
+				if err := writeDagExportRecord(w, dagExportCheckpoint, cp); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// validator sync data
+	for _, vs := range rawdb.ReadAllValidatorSync(bc.db) {
+		data, err := vs.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if err := writeDagExportRecord(w, dagExportValidatorSync, data); err != nil {
+			return err
+		}
+	}
+	// blocks
+	start, reported := time.Now(), time.Now()
+	for nr := first; nr <= last; nr++ {
+		block := bc.GetBlockByNumber(nr)
+		if block == nil {
+			return fmt.Errorf("export failed on #%d: not found", nr)
+		}
+		if err := writeDagExportRecord(w, dagExportBlock, block); err != nil {
+			return err
+		}
+		if blockDag := bc.GetBlockDag(block.Hash()); blockDag != nil {
+			if err := writeDagExportRecord(w, dagExportBlockDag, blockDag.ToBytes()); err != nil {
+				return err
+			}
+		}
+		if children := bc.ReadChildren(block.Hash()); len(children) > 0 {
+			if err := writeDagExportRecord(w, dagExportChildren, &dagExportChildrenEntry{Parent: block.Hash(), Children: children}); err != nil {
+				return err
+			}
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Exporting dag chain", "exported", nr-first+1, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	return nil
+}
+
+// ImportDag imports the dag export segments written by ExportDag. The dag
+// metadata is restored as is, the blocks are inserted and finalized in
+// the exported order with the state replay. The imported blocks are
+// verified by the replayed state and receipts roots, which must match
+// the exported ones. The interrupt function is checked between the
+// blocks if provided. Returns the number of imported blocks.
+func (bc *BlockChain) ImportDag(r io.Reader, interrupt func() bool) (int, error) {
+	if bc.GetSlotInfo() == nil {
+		if err := bc.SetSlotInfo(&types.SlotInfo{
+			GenesisTime:    bc.genesisBlock.Time(),
+			SecondsPerSlot: bc.Config().SecondsPerSlot,
+			SlotsPerEpoch:  bc.Config().SlotsPerEpoch,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	var (
+		br        = bufio.NewReader(r)
+		stream    = rlp.NewStream(br, 0)
+		cps       = make(map[common.Hash]*types.Checkpoint)
+		imported  = 0
+		hasHeader = false
+	)
+	for {
+		if interrupt != nil && interrupt() {
+			return imported, errDagImportInterrupted
+		}
+		if IsDagExport(br) {
+			if _, err := br.Discard(len(dagExportMagic)); err != nil {
+				return imported, err
+			}
+			header := new(DagExportHeader)
+			if err := stream.Decode(header); err != nil {
+				return imported, fmt.Errorf("%w: header: %v", errBadDagExport, err)
+			}
+			if header.Version > DagExportVersion {
+				return imported, fmt.Errorf("%w: %d", errDagExportVersion, header.Version)
+			}
+			if header.Genesis != bc.genesisBlock.Hash() || header.GenesisRoot != bc.genesisBlock.Root() {
+				return imported, fmt.Errorf("%w: %#x", errDagExportGenesis, header.Genesis)
+			}
+			log.Info("Importing dag chain", "version", header.Version, "first", header.First, "last", header.Last)
+			hasHeader = true
+			continue
+		}
+		record := new(dagExportRecord)
+		if err := stream.Decode(record); err == io.EOF {
+			break
+		} else if err != nil {
+			return imported, fmt.Errorf("%w: record %d: %v", errBadDagExport, imported, err)
+		}
+		if !hasHeader {
+			return imported, fmt.Errorf("%w: no header", errBadDagExport)
+		}
+		stream.Reset(br, 0)
+		isBlock, err := bc.importDagRecord(record, cps)
+		if err != nil {
+			return imported, err
+		}
+		if isBlock {
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+// importDagRecord restores a single record of the dag export.
+func (bc *BlockChain) importDagRecord(record *dagExportRecord, cps map[common.Hash]*types.Checkpoint) (bool, error) {
+	switch record.Kind {
+	case dagExportEra:
+		e := new(era.Era)
+		if err := rlp.DecodeBytes(record.Data, e); err != nil {
+			return false, fmt.Errorf("%w: era: %v", errBadDagExport, err)
+		}
+		rawdb.WriteEra(bc.db, e.Number, *e)
+
+	case dagExportCheckpoint:
+		cp := new(types.Checkpoint)
+		if err := rlp.DecodeBytes(record.Data, cp); err != nil {
+			return false, fmt.Errorf("%w: checkpoint: %v", errBadDagExport, err)
+		}
+		// the checkpoint becomes the last coordinated one when its spine is finalized
+		rawdb.WriteCoordinatedCheckpoint(bc.db, cp)
+		rawdb.WriteEpoch(bc.db, cp.FinEpoch, cp.Spine)
+		cps[cp.Spine] = cp
+
+	case dagExportValidatorSync:
+		var data []byte
+		if err := rlp.DecodeBytes(record.Data, &data); err != nil {
+			return false, fmt.Errorf("%w: validator sync: %v", errBadDagExport, err)
+		}
+		vs := new(types.ValidatorSync)
+		if err := vs.UnmarshalJSON(data); err != nil {
+			return false, fmt.Errorf("%w: validator sync: %v", errBadDagExport, err)
+		}
+		rawdb.WriteValidatorSync(bc.db, vs)
+
+	case dagExportBlockDag:
+		var data []byte
+		if err := rlp.DecodeBytes(record.Data, &data); err != nil {
+			return false, fmt.Errorf("%w: block dag: %v", errBadDagExport, err)
+		}
+		if blockDag := new(types.BlockDAG).SetBytes(data); blockDag != nil {
+			bc.SaveBlockDag(blockDag)
+		}
+
+	case dagExportChildren:
+		children := new(dagExportChildrenEntry)
+		if err := rlp.DecodeBytes(record.Data, children); err != nil {
+			return false, fmt.Errorf("%w: children: %v", errBadDagExport, err)
+		}
+		known := rawdb.ReadChildren(bc.db, children.Parent)
+		rawdb.WriteChildren(bc.db, children.Parent, append(known, children.Children...))
+
+	case dagExportBlock:
+		block := new(types.Block)
+		if err := rlp.DecodeBytes(record.Data, block); err != nil {
+			return false, fmt.Errorf("%w: block: %v", errBadDagExport, err)
+		}
+		imported, err := bc.importFinalizedBlock(block)
+		if err != nil {
+			return false, err
+		}
+		if cp := cps[block.Hash()]; cp != nil {
+			if lastCp := bc.GetLastCoordinatedCheckpoint(); lastCp == nil || lastCp.FinEpoch < cp.FinEpoch {
+				bc.SetLastCoordinatedCheckpoint(cp)
+			}
+			delete(cps, block.Hash())
+		}
+		return imported, nil
+
+	default:
+		log.Warn("Skip unknown dag export record", "kind", record.Kind)
+	}
+	return false, nil
+}
+
+// importFinalizedBlock inserts the exported finalized block and replays its
+// finalization. Returns false if the block is already finalized.
+func (bc *BlockChain) importFinalizedBlock(block *types.Block) (bool, error) {
+	var (
+		hash        = block.Hash()
+		finNr       = block.Nr()
+		root        = block.Root()
+		receiptHash = block.ReceiptHash()
+	)
+	if nr := rawdb.ReadFinalizedNumberByHash(bc.db, hash); nr != nil {
+		if *nr != finNr {
+			return false, fmt.Errorf("%w: block %#x finalized as %d, exported %d", errDagExportMismatch, hash, *nr, finNr)
+		}
+		return false, nil
+	}
+	if block.Height() == 0 {
+		return false, fmt.Errorf("%w: genesis %#x", errDagExportGenesis, hash)
+	}
+	lastFinBlock := bc.GetLastFinalizedBlock()
+	if finNr != lastFinBlock.Nr()+1 {
+		return false, fmt.Errorf("non contiguous import: block %#x is #%d, last finalized #%d", hash, finNr, lastFinBlock.Nr())
+	}
+	// the exported blocks are verified by the state replay
+	if bl, err := bc.WriteSyncBlocks(types.Blocks{block}, false); err != nil {
+		if bl != nil {
+			return false, fmt.Errorf("failed to insert block #%d %#x: %w", finNr, bl.Hash(), err)
+		}
+		return false, err
+	}
+	bc.importEraOf(block)
+
+	block.SetNumber(&finNr)
+	if err := bc.UpdateFinalizingState(block, lastFinBlock); err != nil {
+		return false, err
+	}
+	if block.Root() != root || block.ReceiptHash() != receiptHash {
+		return false, fmt.Errorf("%w: #%d %#x root %#x (exported %#x), receipts %#x (exported %#x)",
+			errDagExportMismatch, finNr, hash, block.Root(), root, block.ReceiptHash(), receiptHash)
+	}
+	if err := bc.WriteFinalizedBlock(finNr, block, true); err != nil {
+		return false, err
+	}
+	bc.FinalizeTips(common.HashArray{hash}, hash, block.Height())
+	return true, nil
+}
+
+// importEraOf switches the current era to the era of the imported block.
+func (bc *BlockChain) importEraOf(block *types.Block) {
+	epoch := bc.GetSlotInfo().SlotToEpoch(block.Slot())
+	if cur := bc.eraInfo.GetEra(); cur != nil && cur.IsContainsEpoch(epoch) {
+		return
+	}
+	for number := rawdb.ReadCurrentEra(bc.db); ; number++ {
+		e := rawdb.ReadEra(bc.db, number)
+		if e == nil {
+			return
+		}
+		if e.IsContainsEpoch(epoch) {
+			rawdb.WriteCurrentEra(bc.db, number)
+			bc.SetNewEraInfo(*e)
+			return
+		}
+	}
+}
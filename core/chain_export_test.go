@@ -0,0 +1,102 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rlp"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+func TestExportDag(t *testing.T) {
+	bc, blocks := getTestBlockchainAndBlocks()
+	testutils.AssertNoError(t, AddBlocksToFinalized(bc, blocks))
+
+	buf := new(bytes.Buffer)
+	testutils.AssertNoError(t, bc.ExportDag(buf, 0, uint64(len(blocks))))
+
+	br := bufio.NewReader(buf)
+	if !IsDagExport(br) {
+		t.Fatal("dag export magic not found")
+	}
+	_, err := br.Discard(len(dagExportMagic))
+	testutils.AssertNoError(t, err)
+
+	stream := rlp.NewStream(br, 0)
+	header := new(DagExportHeader)
+	testutils.AssertNoError(t, stream.Decode(header))
+	testutils.AssertEqual(t, uint64(DagExportVersion), header.Version)
+	testutils.AssertEqual(t, bc.Genesis().Hash(), header.Genesis)
+	testutils.AssertEqual(t, uint64(0), header.First)
+	testutils.AssertEqual(t, uint64(len(blocks)), header.Last)
+
+	kinds := make(map[dagExportKind]int)
+	for {
+		record := new(dagExportRecord)
+		if err := stream.Decode(record); err != nil {
+			break
+		}
+		kinds[record.Kind]++
+	}
+	testutils.AssertEqual(t, len(blocks)+1, kinds[dagExportBlock])
+	testutils.AssertEqual(t, 1, kinds[dagExportEra])
+	testutils.AssertEqual(t, 1, kinds[dagExportCheckpoint])
+	// genesis and all blocks except the last one have children
+	testutils.AssertEqual(t, len(blocks), kinds[dagExportChildren])
+
+	if IsDagExport(bufio.NewReader(bytes.NewReader([]byte{0xc0}))) {
+		t.Fatal("legacy export detected as dag export")
+	}
+}
+
+func TestImportDag(t *testing.T) {
+	bc, blocks := getTestBlockchainAndBlocks()
+	testutils.AssertNoError(t, AddBlocksToFinalized(bc, blocks))
+
+	// appended segments
+	buf := new(bytes.Buffer)
+	testutils.AssertNoError(t, bc.ExportDag(buf, 0, 1))
+	testutils.AssertNoError(t, bc.ExportDag(buf, 2, uint64(len(blocks))))
+	export := buf.Bytes()
+
+	// drop the metadata to be restored
+	rawdb.DeleteChildren(bc.db, blocks[0].Hash())
+	rawdb.DeleteEpoch(bc.db, 0)
+
+	// the finalized blocks are skipped
+	imported, err := bc.ImportDag(bytes.NewReader(export), nil)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, 0, imported)
+	testutils.AssertEqual(t, common.HashArray{blocks[1].Hash()}, bc.ReadChildren(blocks[0].Hash()))
+	testutils.AssertEqual(t, bc.Genesis().Hash(), rawdb.ReadEpoch(bc.db, 0))
+
+	// interrupted
+	_, err = bc.ImportDag(bytes.NewReader(export), func() bool { return true })
+	testutils.AssertError(t, err, errDagImportInterrupted)
+
+	// other genesis
+	other, _ := getTestBlockchainAndBlocks()
+	_, err = other.ImportDag(bytes.NewReader(export), nil)
+	testutils.AssertError(t, err, errDagExportGenesis)
+
+	// no header
+	_, err = bc.ImportDag(bytes.NewReader(export[len(dagExportMagic):]), nil)
+	testutils.AssertError(t, err, errBadDagExport)
+}
@@ -0,0 +1,222 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"errors"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+)
+
+// errNoCheckpoint is returned if the last coordinated checkpoint is unknown.
+var errNoCheckpoint = errors.New("last coordinated checkpoint not found")
+
+// StaleDagBlock is a not finalized block unreachable from the dag.
+type StaleDagBlock struct {
+	Hash common.Hash
+	Slot uint64
+}
+
+// DagPruneSummary describes the stale dag data to prune.
+type DagPruneSummary struct {
+	CpSlot    uint64             // slot of the last coordinated checkpoint spine
+	FromSlot  uint64             // slot the search is started from
+	NextSlot  uint64             // slot the next search starts from
+	Blocks    []StaleDagBlock    // stale blocks
+	Slots     int                // number of slots containing the stale blocks
+	TxLookups int                // number of tx lookups pointing to the stale blocks
+	Size      common.StorageSize // approximate size of the stale data
+}
+
+// FindStaleDag collects the not finalized blocks older than the last
+// coordinated checkpoint, which are unreachable from the current tips.
+// Such blocks will never be finalized. The search starts from the slot
+// the stale dag is already pruned up to.
+func FindStaleDag(db ethdb.Database) (*DagPruneSummary, error) {
+	cp := rawdb.ReadLastCoordinatedCheckpoint(db)
+	if cp == nil {
+		return nil, errNoCheckpoint
+	}
+	cpHeader := rawdb.ReadHeader(db, cp.Spine)
+	if cpHeader == nil {
+		return nil, errBlockNotFound
+	}
+	// the tips and their ancestors are still reachable
+	reachable := make(map[common.Hash]struct{})
+	for _, tip := range rawdb.ReadTipsHashes(db) {
+		reachable[tip] = struct{}{}
+		if blockDag := rawdb.ReadBlockDag(db, tip); blockDag != nil {
+			for _, h := range blockDag.OrderedAncestorsHashes {
+				reachable[h] = struct{}{}
+			}
+		}
+	}
+	fromSlot := rawdb.ReadDagPruneSlot(db)
+	summary := &DagPruneSummary{
+		CpSlot:   cpHeader.Slot,
+		FromSlot: fromSlot,
+		NextSlot: cpHeader.Slot,
+	}
+	if fromSlot > cpHeader.Slot {
+		// the checkpoint is rolled back
+		summary.NextSlot = fromSlot
+	}
+	rawdb.IterateSlotBlocksHashes(db, fromSlot, func(slot uint64, hashes common.HashArray) bool {
+		if slot >= cpHeader.Slot {
+			return false
+		}
+		stale := 0
+		for _, hash := range hashes {
+			if _, ok := reachable[hash]; ok {
+				// the block can become stale later, so its slot is searched again
+				if slot < summary.NextSlot && rawdb.ReadFinalizedNumberByHash(db, hash) == nil {
+					summary.NextSlot = slot
+				}
+				continue
+			}
+			if rawdb.ReadFinalizedNumberByHash(db, hash) != nil {
+				continue
+			}
+			summary.Blocks = append(summary.Blocks, StaleDagBlock{Hash: hash, Slot: slot})
+			summary.Size += common.StorageSize(len(rawdb.ReadHeaderRLP(db, hash)) +
+				len(rawdb.ReadBodyRLP(db, hash)) + len(rawdb.ReadReceiptsRLP(db, hash)))
+			if body := rawdb.ReadBody(db, hash); body != nil {
+				for _, tx := range body.Transactions {
+					if rawdb.ReadTxLookupEntry(db, tx.Hash()) == hash {
+						summary.TxLookups++
+					}
+				}
+			}
+			stale++
+		}
+		if stale > 0 {
+			summary.Slots++
+		}
+		return true
+	})
+	return summary, nil
+}
+
+// PruneStaleDag removes the data of the stale blocks: headers, bodies,
+// receipts, block dags, children entries, tx lookups and slot index,
+// and stores the slot the next search starts from.
+// The blocks finalized after the search are kept.
+func PruneStaleDag(db ethdb.Database, summary *DagPruneSummary) error {
+	var (
+		batch     = db.NewBatch()
+		children  = make(map[common.Hash]common.HashArray)
+		slotStale common.HashArray
+	)
+	for i, stale := range summary.Blocks {
+		if rawdb.ReadFinalizedNumberByHash(db, stale.Hash) == nil {
+			pruneStaleBlock(db, batch, stale.Hash, children)
+			slotStale = append(slotStale, stale.Hash)
+		}
+		// the slot index is updated once all stale blocks of the slot are removed
+		if i+1 < len(summary.Blocks) && summary.Blocks[i+1].Slot == stale.Slot {
+			continue
+		}
+		if len(slotStale) > 0 {
+			hashes := rawdb.ReadSlotBlocksHashes(db, stale.Slot)
+			rawdb.WriteSlotBlocksHashes(batch, stale.Slot, hashes.Difference(slotStale))
+			slotStale = nil
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	rawdb.WriteDagPruneSlot(batch, summary.NextSlot)
+	return batch.Write()
+}
+
+// pruneStaleBlock writes the removal of the stale block data to the batch.
+// The children entries updated by the batch are tracked in the given map.
+func pruneStaleBlock(db ethdb.Reader, batch ethdb.Batch, hash common.Hash, children map[common.Hash]common.HashArray) {
+	if header := rawdb.ReadHeader(db, hash); header != nil {
+		for _, parent := range header.ParentHashes {
+			parentChildren, ok := children[parent]
+			if !ok {
+				parentChildren = rawdb.ReadChildren(db, parent)
+			}
+			if parentChildren.Has(hash) {
+				children[parent] = parentChildren.Difference(common.HashArray{hash})
+				rawdb.WriteChildren(batch, parent, children[parent])
+			}
+		}
+	}
+	if body := rawdb.ReadBody(db, hash); body != nil {
+		for _, tx := range body.Transactions {
+			if rawdb.ReadTxLookupEntry(db, tx.Hash()) == hash {
+				rawdb.DeleteTxLookupEntry(batch, tx.Hash())
+			}
+		}
+	}
+	rawdb.DeleteBlockWithoutNumber(batch, hash)
+}
+
+// pruneDag removes the stale dag data and the related cache entries.
+// The stale blocks are searched without the chain lock,
+// which is held for the removal only.
+func (bc *BlockChain) pruneDag() {
+	start := time.Now()
+	summary, err := FindStaleDag(bc.db)
+	if err != nil {
+		log.Warn("Dag pruning skipped", "err", err)
+		return
+	}
+	if len(summary.Blocks) == 0 && summary.NextSlot == summary.FromSlot {
+		return
+	}
+
+	if !bc.chainmu.TryLock() {
+		return
+	}
+	defer bc.chainmu.Unlock()
+
+	if err := PruneStaleDag(bc.db, summary); err != nil {
+		log.Error("Dag pruning failed", "err", err)
+		return
+	}
+	for _, stale := range summary.Blocks {
+		slot := stale.Slot
+		bc.clearBlockCaches(stale.Hash, &slot)
+	}
+	log.Info("Pruned stale dag data", "blocks", len(summary.Blocks), "slots", summary.Slots,
+		"txLookups", summary.TxLookups, "size", summary.Size, "nextSlot", summary.NextSlot,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+}
+
+// pruneDagLoop periodically removes the stale dag data.
+func (bc *BlockChain) pruneDagLoop(interval time.Duration) {
+	defer bc.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			bc.pruneDag()
+		case <-bc.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+func TestPruneStaleDag(t *testing.T) {
+	bc, blocks := getTestBlockchainAndBlocks()
+	testutils.AssertNoError(t, AddBlocksToFinalized(bc, blocks[:2]))
+
+	// orphaned block of slot 1
+	forks, _ := GenerateChain(params.AllEthashProtocolChanges, bc.genesisBlock, bc.db, 1, func(i int, b *BlockGen) {
+		b.SetExtra([]byte("fork"))
+	})
+	fork := forks[0]
+	testutils.AssertEqual(t, blocks[0].Slot(), fork.Slot())
+
+	rawdb.WriteLastCoordinatedCheckpoint(bc.db, &types.Checkpoint{Epoch: 0, FinEpoch: 0, Spine: blocks[1].Hash()})
+	rawdb.WriteTipsHashes(bc.db, common.HashArray{blocks[2].Hash()})
+
+	summary, err := FindStaleDag(bc.db)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, blocks[1].Slot(), summary.CpSlot)
+	testutils.AssertEqual(t, uint64(0), summary.FromSlot)
+	testutils.AssertEqual(t, blocks[1].Slot(), summary.NextSlot)
+	testutils.AssertEqual(t, []StaleDagBlock{{Hash: fork.Hash(), Slot: fork.Slot()}}, summary.Blocks)
+	testutils.AssertEqual(t, 1, summary.Slots)
+	if !bc.ReadChildren(bc.genesisBlock.Hash()).Has(fork.Hash()) {
+		t.Fatal("fork is not a child of genesis")
+	}
+
+	testutils.AssertNoError(t, PruneStaleDag(bc.db, summary))
+	testutils.AssertNil(t, rawdb.ReadHeader(bc.db, fork.Hash()))
+	testutils.AssertNil(t, rawdb.ReadBody(bc.db, fork.Hash()))
+	testutils.AssertEqual(t, common.HashArray{blocks[0].Hash()}, rawdb.ReadSlotBlocksHashes(bc.db, fork.Slot()))
+	testutils.AssertEqual(t, common.HashArray{blocks[0].Hash()}, rawdb.ReadChildren(bc.db, bc.genesisBlock.Hash()))
+	testutils.AssertEqual(t, summary.NextSlot, rawdb.ReadDagPruneSlot(bc.db))
+
+	// the finalized and recent blocks are kept
+	for _, block := range blocks {
+		if rawdb.ReadHeader(bc.db, block.Hash()) == nil {
+			t.Fatalf("block %d removed", block.Height())
+		}
+	}
+	// the next search starts from the pruned slot
+	summary, err = FindStaleDag(bc.db)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, 0, len(summary.Blocks))
+	testutils.AssertEqual(t, blocks[1].Slot(), summary.FromSlot)
+}
+
+func TestPruneStaleDag_FinalizedAfterSearch(t *testing.T) {
+	bc, blocks := getTestBlockchainAndBlocks()
+	testutils.AssertNoError(t, AddBlocksToFinalized(bc, blocks[:2]))
+
+	forks, _ := GenerateChain(params.AllEthashProtocolChanges, bc.genesisBlock, bc.db, 1, func(i int, b *BlockGen) {
+		b.SetExtra([]byte("fork"))
+	})
+	fork := forks[0]
+
+	rawdb.WriteLastCoordinatedCheckpoint(bc.db, &types.Checkpoint{Epoch: 0, FinEpoch: 0, Spine: blocks[1].Hash()})
+	rawdb.WriteTipsHashes(bc.db, common.HashArray{blocks[2].Hash()})
+
+	summary, err := FindStaleDag(bc.db)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, []StaleDagBlock{{Hash: fork.Hash(), Slot: fork.Slot()}}, summary.Blocks)
+
+	// the block is finalized between the search and the removal
+	rawdb.WriteFinalizedHashNumber(bc.db, fork.Hash(), 100)
+	testutils.AssertNoError(t, PruneStaleDag(bc.db, summary))
+	if rawdb.ReadHeader(bc.db, fork.Hash()) == nil {
+		t.Fatal("finalized block removed")
+	}
+	if !rawdb.ReadSlotBlocksHashes(bc.db, fork.Slot()).Has(fork.Hash()) {
+		t.Fatal("finalized block removed from the slot index")
+	}
+}
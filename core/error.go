@@ -46,6 +46,15 @@ var (
 	ErrSpineStateNF = errors.New("spine state not found")
 
 	ErrBlockNotFound = errors.New("block not found")
+
+	// ErrValSyncOpNF is returned when validator sync operation not found.
+	ErrValSyncOpNF = errors.New("validator sync operation not found")
+
+	// ErrValSyncOpProcessed is returned when validator sync operation is already processed successfully.
+	ErrValSyncOpProcessed = errors.New("validator sync operation already processed")
+
+	// ErrBadCheckpointAnchor is returned when the chain can't be anchored at the checkpoint.
+	ErrBadCheckpointAnchor = errors.New("bad checkpoint anchor")
 )
 
 // List of evm-call-message pre-checking errors. All state transition messages will
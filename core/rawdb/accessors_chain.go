@@ -662,9 +662,30 @@ func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *type
 	if err := op.Append(freezerReceiptTable, num, receipts); err != nil {
 		return fmt.Errorf("can't append block %d receipts: %v", num, err)
 	}
+	// the dag data is not known for the imported blocks
+	if err := op.AppendRaw(freezerBlockDagTable, num, nil); err != nil {
+		return fmt.Errorf("can't append block %d dag: %v", num, err)
+	}
+	if err := op.AppendRaw(freezerChildrenTable, num, nil); err != nil {
+		return fmt.Errorf("can't append block %d children: %v", num, err)
+	}
 	return nil
 }
 
+// readAncientDagData retrieves the per-hash dag data of the frozen block
+// from the ancient table of the given kind.
+func readAncientDagData(db ethdb.Reader, kind string, hash common.Hash) []byte {
+	number := ReadFinalizedNumberByHash(db, hash)
+	if number == nil {
+		return nil
+	}
+	if h, err := db.Ancient(freezerHashTable, *number); err != nil || common.BytesToHash(h) != hash {
+		return nil
+	}
+	data, _ := db.Ancient(kind, *number)
+	return data
+}
+
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, finNr *uint64) {
 	DeleteReceipts(db, hash)
@@ -900,11 +921,14 @@ func DeleteEpoch(db ethdb.KeyValueWriter, epoch uint64) {
 
 /**** ValidatorSync ***/
 
-func parseValidatorSyncKey(validatorSyncKey []byte) (initTxHash common.Hash) {
+func parseValidatorSyncKey(validatorSyncKey []byte) (initTxHash common.Hash, creator common.Address) {
 	start := len(valSyncOpPrefix)
 	end := start + common.HashLength
 	initTxHash = common.BytesToHash(validatorSyncKey[start:end])
-	return initTxHash
+	if len(validatorSyncKey) >= end+common.AddressLength {
+		creator = common.BytesToAddress(validatorSyncKey[end : end+common.AddressLength])
+	}
+	return initTxHash, creator
 }
 
 func decodeValidatorSync(initTxHash common.Hash, data []byte) *types.ValidatorSync {
@@ -944,9 +968,16 @@ func encodeValidatorSync(vs types.ValidatorSync) ([]byte, error) {
 }
 
 // ReadValidatorSync retrieves the ValidatorSync data.
-func ReadValidatorSync(db ethdb.KeyValueReader, initTxHash common.Hash) *types.ValidatorSync {
-	data, _ := db.Get(validatorSyncKey(initTxHash))
-	return decodeValidatorSync(initTxHash, data)
+func ReadValidatorSync(db ethdb.KeyValueReader, initTxHash common.Hash, creator common.Address) *types.ValidatorSync {
+	if data, _ := db.Get(validatorSyncKey(initTxHash, creator)); len(data) > 0 {
+		return decodeValidatorSync(initTxHash, data)
+	}
+	// Depracated: the data stored by initTxHash only
+	data, _ := db.Get(legacyValidatorSyncKey(initTxHash))
+	if vs := decodeValidatorSync(initTxHash, data); vs != nil && vs.Creator == creator {
+		return vs
+	}
+	return nil
 }
 
 // WriteValidatorSync stores the ValidatorSync data.
@@ -966,7 +997,7 @@ func WriteValidatorSync(db ethdb.KeyValueWriter, vs *types.ValidatorSync) {
 		"Creator", vs.Creator.Hex(),
 	)
 
-	key := validatorSyncKey(vs.InitTxHash)
+	key := validatorSyncKey(vs.InitTxHash, vs.Creator)
 	enc, err := encodeValidatorSync(*vs)
 	if err != nil {
 		log.Crit("Failed to store ValidatorSync data", "err", err)
@@ -977,20 +1008,45 @@ func WriteValidatorSync(db ethdb.KeyValueWriter, vs *types.ValidatorSync) {
 }
 
 // DeleteValidatorSync delete the ValidatorSync data..
-func DeleteValidatorSync(db ethdb.KeyValueWriter, initTxHash common.Hash) {
-	key := validatorSyncKey(initTxHash)
-	if err := db.Delete(key); err != nil {
+func DeleteValidatorSync(db ethdb.KeyValueWriter, initTxHash common.Hash, creator common.Address) {
+	if err := db.Delete(validatorSyncKey(initTxHash, creator)); err != nil {
+		log.Crit("Failed to delete validators sync data", "err", err)
+	}
+	if err := db.Delete(legacyValidatorSyncKey(initTxHash)); err != nil {
 		log.Crit("Failed to delete validators sync data", "err", err)
 	}
 }
 
+// ReadAllValidatorSync retrieves all the ValidatorSync data stored in db.
+func ReadAllValidatorSync(db ethdb.Iteratee) []*types.ValidatorSync {
+	prefix := valSyncOpPrefix
+	res := []*types.ValidatorSync{}
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(validatorSyncKey(common.Hash{}, common.Address{})) && len(key) != len(legacyValidatorSyncKey(common.Hash{})) {
+			continue
+		}
+		initTxHash, _ := parseValidatorSyncKey(key)
+		if vs := decodeValidatorSync(initTxHash, it.Value()); vs != nil {
+			res = append(res, vs)
+		}
+	}
+	return res
+}
+
 // ReadNotProcessedValidatorSyncOps retrieves the not processed validator sync operations.
 func ReadNotProcessedValidatorSyncOps(db ethdb.KeyValueReader) []*types.ValidatorSync {
-	data, err := db.Get(valSyncNotProcKey)
+	keyLen := len(validatorSyncKey(common.Hash{}, common.Address{}))
+	data, err := db.Get(valSyncNotProcOps)
 	if err != nil {
-		return nil
+		// Depracated: the operations tracked by initTxHash only
+		if data, err = db.Get(valSyncNotProcKey); err != nil {
+			return nil
+		}
+		keyLen = len(legacyValidatorSyncKey(common.Hash{}))
 	}
-	keyLen := len(validatorSyncKey(common.Hash{}))
 	if len(data)%keyLen != 0 {
 		// alternate return nil
 		log.Crit("Failed to read the not processed validator sync operations: bad data length", "err", err)
@@ -1001,32 +1057,43 @@ func ReadNotProcessedValidatorSyncOps(db ethdb.KeyValueReader) []*types.Validato
 		start := i * keyLen
 		end := start + keyLen
 		opKey := data[start:end]
-		initTxHash := parseValidatorSyncKey(opKey)
-		res[i] = ReadValidatorSync(db, initTxHash)
+		initTxHash, creator := parseValidatorSyncKey(opKey)
+		if keyLen == len(legacyValidatorSyncKey(common.Hash{})) {
+			enc, _ := db.Get(opKey)
+			res[i] = decodeValidatorSync(initTxHash, enc)
+			continue
+		}
+		res[i] = ReadValidatorSync(db, initTxHash, creator)
 	}
 	return res
 }
 
 // WriteNotProcessedValidatorSyncOps stores the not processed validator sync operations.
 func WriteNotProcessedValidatorSyncOps(db ethdb.KeyValueWriter, valSyncOps []*types.ValidatorSync) {
-	keyLen := len(validatorSyncKey(common.Hash{}))
+	keyLen := len(validatorSyncKey(common.Hash{}, common.Address{}))
 	dataLen := keyLen * len(valSyncOps)
 	data := make([]byte, 0, dataLen)
 	for _, vs := range valSyncOps {
-		key := validatorSyncKey(vs.InitTxHash)
+		key := validatorSyncKey(vs.InitTxHash, vs.Creator)
 		WriteValidatorSync(db, vs)
 		data = append(data, key...)
 	}
-	if err := db.Put(valSyncNotProcKey, data); err != nil {
+	if err := db.Put(valSyncNotProcOps, data); err != nil {
 		log.Crit("Failed to store the not processed validator sync operations", "err", err)
 	}
+	if err := db.Delete(valSyncNotProcKey); err != nil {
+		log.Crit("Failed to delete the deprecated not processed validator sync operations", "err", err)
+	}
 }
 
 /**** BlockDag ***/
 
 // ReadBlockDag retrieves the BlockDag structure by hash.
-func ReadBlockDag(db ethdb.KeyValueReader, hash common.Hash) *types.BlockDAG {
+func ReadBlockDag(db ethdb.Reader, hash common.Hash) *types.BlockDAG {
 	data, _ := db.Get(blockDagKey(hash))
+	if len(data) == 0 {
+		data = readAncientDagData(db, freezerBlockDagTable, hash)
+	}
 	minSize := common.HashLength + common.HashLength + 8
 	if len(data) < minSize {
 		return nil
@@ -1083,9 +1150,12 @@ func WriteTipsHashes(db ethdb.KeyValueWriter, hashes common.HashArray) {
 /**** CHILDREN ***/
 
 // ReadChildren retrieves the hashes of the children
-func ReadChildren(db ethdb.KeyValueReader, parent common.Hash) common.HashArray {
+func ReadChildren(db ethdb.Reader, parent common.Hash) common.HashArray {
 	key := childrenKey(parent)
 	data, _ := db.Get(key)
+	if len(data) == 0 {
+		data = readAncientDagData(db, freezerChildrenTable, parent)
+	}
 	return common.HashArrayFromBytes(data)
 }
 
@@ -1193,6 +1263,46 @@ func FindEra(db ethdb.KeyValueReader, curEra uint64) *era.Era {
 	return lastEra
 }
 
+// ReadEraValidators retrieves the cached active validators data of the era.
+func ReadEraValidators(db ethdb.KeyValueReader, number uint64) []byte {
+	data, _ := db.Get(eraValidatorsKey(number))
+	return data
+}
+
+// WriteEraValidators stores the cached active validators data of the era.
+func WriteEraValidators(db ethdb.KeyValueWriter, number uint64, data []byte) {
+	if err := db.Put(eraValidatorsKey(number), data); err != nil {
+		log.Crit("Failed to store era validators", "err", err)
+	}
+}
+
+// DeleteEraValidators removes the cached active validators data of the era.
+func DeleteEraValidators(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(eraValidatorsKey(number)); err != nil {
+		log.Crit("Failed to delete era validators", "err", err)
+	}
+}
+
+// DeleteEraValidatorsFrom removes the cached active validators data
+// of all eras starting from the given one.
+func DeleteEraValidatorsFrom(db ethdb.KeyValueStore, from uint64) {
+	prefix := eraValidatorsPrefix
+	it := db.NewIterator(prefix, Uint64ToByteSlice(from))
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		if key := it.Key(); len(key) == len(prefix)+8 {
+			if err := batch.Delete(key); err != nil {
+				log.Crit("Failed to delete era validators", "err", err)
+			}
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete era validators", "err", err)
+	}
+}
+
 func WriteSlotBlocksHashes(db ethdb.KeyValueWriter, slot uint64, hashes common.HashArray) {
 	key := slotBlocksKey(slot)
 	err := db.Put(key, hashes.ToBytes())
@@ -1211,6 +1321,38 @@ func ReadSlotBlocksHashes(db ethdb.KeyValueReader, slot uint64) common.HashArray
 	return common.HashArray{}
 }
 
+// IterateSlotBlocksHashes iterates the slot blocks index in ascending order
+// of slots starting from the given one, the iteration stops if fn returns false.
+func IterateSlotBlocksHashes(db ethdb.Iteratee, from uint64, fn func(slot uint64, hashes common.HashArray) bool) {
+	it := db.NewIterator(slotBlockKey, encodeBlockNumber(from))
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(slotBlockKey)+8 {
+			continue
+		}
+		if !fn(binary.BigEndian.Uint64(key[len(slotBlockKey):]), common.HashArrayFromBytes(it.Value())) {
+			return
+		}
+	}
+}
+
+// ReadDagPruneSlot retrieves the slot the stale dag is pruned up to.
+func ReadDagPruneSlot(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(dagPruneSlotKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteDagPruneSlot stores the slot the stale dag is pruned up to.
+func WriteDagPruneSlot(db ethdb.KeyValueWriter, slot uint64) {
+	if err := db.Put(dagPruneSlotKey, encodeBlockNumber(slot)); err != nil {
+		log.Crit("Failed to store the dag prune slot", "err", err)
+	}
+}
+
 func DeleteSlotBlockHash(db ethdb.Database, slot uint64, hash common.Hash) {
 	hashes := ReadSlotBlocksHashes(db, slot)
 	for i := 0; i < len(hashes); i++ {
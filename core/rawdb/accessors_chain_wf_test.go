@@ -19,7 +19,6 @@ package rawdb
 import (
 	"fmt"
 	"math/big"
-	"reflect"
 	"testing"
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
@@ -160,13 +159,13 @@ func TestValidatorSyncWf_Ok(t *testing.T) {
 	src_1.Amount.SetString("32789456000000", 10)
 
 	WriteValidatorSync(db, src_1)
-	entry := ReadValidatorSync(db, src_1.InitTxHash)
+	entry := ReadValidatorSync(db, src_1.InitTxHash, src_1.Creator)
 	if fmt.Sprintf("%v", entry) != fmt.Sprintf("%v", src_1) {
 		t.Fatalf("ValidatorSync W-R failed:  %#v != %#v", entry, src_1)
 	}
 
-	DeleteValidatorSync(db, src_1.InitTxHash)
-	if entry := ReadValidatorSync(db, src_1.InitTxHash); entry != nil {
+	DeleteValidatorSync(db, src_1.InitTxHash, src_1.Creator)
+	if entry := ReadValidatorSync(db, src_1.InitTxHash, src_1.Creator); entry != nil {
 		t.Fatalf("ValidatorSync D-R failed:  %#v != nil", entry)
 	}
 }
@@ -186,13 +185,13 @@ func TestValidatorSyncWf_Ok_noTxHash(t *testing.T) {
 	src_1.Amount.SetString("32789456000000", 10)
 
 	WriteValidatorSync(db, src_1)
-	entry := ReadValidatorSync(db, src_1.InitTxHash)
+	entry := ReadValidatorSync(db, src_1.InitTxHash, src_1.Creator)
 	if fmt.Sprintf("%v", entry) != fmt.Sprintf("%v", src_1) {
 		t.Fatalf("ValidatorSync W-R failed:  %#v != %#v", entry, src_1)
 	}
 
-	DeleteValidatorSync(db, src_1.InitTxHash)
-	if entry := ReadValidatorSync(db, src_1.InitTxHash); entry != nil {
+	DeleteValidatorSync(db, src_1.InitTxHash, src_1.Creator)
+	if entry := ReadValidatorSync(db, src_1.InitTxHash, src_1.Creator); entry != nil {
 		t.Fatalf("ValidatorSync D-R failed:  %#v != nil", entry)
 	}
 }
@@ -212,13 +211,13 @@ func TestValidatorSyncWf_Ok_noAmount(t *testing.T) {
 	//src_1.Amount.SetString("32789456000000", 10)
 
 	WriteValidatorSync(db, src_1)
-	entry := ReadValidatorSync(db, src_1.InitTxHash)
+	entry := ReadValidatorSync(db, src_1.InitTxHash, src_1.Creator)
 	if fmt.Sprintf("%v", entry) != fmt.Sprintf("%v", src_1) {
 		t.Fatalf("ValidatorSync W-R failed:  %#v != %#v", entry, src_1)
 	}
 
-	DeleteValidatorSync(db, src_1.InitTxHash)
-	if entry := ReadValidatorSync(db, src_1.InitTxHash); entry != nil {
+	DeleteValidatorSync(db, src_1.InitTxHash, src_1.Creator)
+	if entry := ReadValidatorSync(db, src_1.InitTxHash, src_1.Creator); entry != nil {
 		t.Fatalf("ValidatorSync D-R failed:  %#v != nil", entry)
 	}
 }
@@ -258,7 +257,46 @@ func TestNotProcessedValidatorSyncWf(t *testing.T) {
 	valSyncOps := []*types.ValidatorSync{src_1, src_2, src_3}
 
 	WriteNotProcessedValidatorSyncOps(db, valSyncOps)
-	if entry := ReadNotProcessedValidatorSyncOps(db); reflect.DeepEqual(entry, valSyncOps) {
-		t.Fatalf("ValidatorSync W-R failed:  %#v != %#v", entry, valSyncOps)
+	// The operations initiated by the same tx are kept by creator
+	entry := ReadNotProcessedValidatorSyncOps(db)
+	if len(entry) != len(valSyncOps) {
+		t.Fatalf("ValidatorSync W-R failed:  %d != %d", len(entry), len(valSyncOps))
+	}
+	for i, vs := range valSyncOps {
+		if entry[i].Print() != vs.Print() {
+			t.Fatalf("ValidatorSync W-R failed:  %s != %s", entry[i].Print(), vs.Print())
+		}
+	}
+}
+
+func TestValidatorSyncWf_legacy(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	src_1 := &types.ValidatorSync{
+		OpType:     types.Activate,
+		ProcEpoch:  45645,
+		Index:      45645,
+		Creator:    common.Address{0x11, 0xff},
+		Amount:     new(big.Int),
+		InitTxHash: common.Hash{1, 2, 3},
+	}
+	enc, err := encodeValidatorSync(*src_1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(legacyValidatorSyncKey(src_1.InitTxHash), enc); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(valSyncNotProcKey, legacyValidatorSyncKey(src_1.InitTxHash)); err != nil {
+		t.Fatal(err)
+	}
+	if entry := ReadValidatorSync(db, src_1.InitTxHash, src_1.Creator); entry == nil || entry.Print() != src_1.Print() {
+		t.Fatalf("ValidatorSync legacy read failed:  %s != %s", entry.Print(), src_1.Print())
+	}
+	if entry := ReadValidatorSync(db, src_1.InitTxHash, common.Address{0x22}); entry != nil {
+		t.Fatalf("ValidatorSync legacy read of other creator:  %s", entry.Print())
+	}
+	if entry := ReadNotProcessedValidatorSyncOps(db); len(entry) != 1 || entry[0].Print() != src_1.Print() {
+		t.Fatalf("ValidatorSync legacy not processed read failed:  %v", entry)
 	}
 }
@@ -219,6 +219,17 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, freezer string, namespace st
 	}, nil
 }
 
+// SetFreezerEraThreshold sets the number of recent eras whose finalized blocks
+// are kept in the key-value store (0 = disabled). It's a noop for the database
+// without a freezer.
+func SetFreezerEraThreshold(db ethdb.Database, eras uint64) {
+	if frdb, ok := db.(*freezerdb); ok {
+		if f, ok := frdb.AncientStore.(*freezer); ok {
+			atomic.StoreUint64(&f.eras, eras)
+		}
+	}
+}
+
 // NewMemoryDatabase creates an ephemeral in-memory key-value database without a
 // freezer moving immutable chain segments into cold storage.
 func NewMemoryDatabase() ethdb.Database {
@@ -322,6 +333,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		slotBlock       stat
 		valSyncOp       stat
 		era             stat
+		eraValidators   stat
 
 		// Ancient store statistics
 		ancientHeadersSize  common.StorageSize
@@ -409,13 +421,15 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			valSyncOp.Add(size)
 		case bytes.HasPrefix(key, eraPrefix):
 			era.Add(size)
+		case bytes.HasPrefix(key, eraValidatorsPrefix) && len(key) == len(eraValidatorsPrefix)+8:
+			eraValidators.Add(size)
 
 		default:
 			var accounted bool
 			for _, meta := range [][]byte{
 				currentEraPrefix, headFastBlockKey,
 				lastFinalizedHashKey, lastCanonicalHashKey, lastCoordCpKey,
-				databaseVersionKey, tipsHashesKey, lastPivotKey, valSyncNotProcKey,
+				databaseVersionKey, tipsHashesKey, lastPivotKey, valSyncNotProcKey, valSyncNotProcOps,
 				fastTrieProgressKey, snapshotDisabledKey, snapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey,
 				fastTxLookupLimitKey, uncleanShutdownKey, badBlockKey,
@@ -478,6 +492,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "slotBlock", slotBlock.Size(), slotBlock.Count()},
 		{"Key-Value store", "valSyncOp", valSyncOp.Size(), valSyncOp.Count()},
 		{"Key-Value store", "era", era.Size(), era.Count()},
+		{"Key-Value store", "eraValidators", eraValidators.Size(), eraValidators.Count()},
 
 		{"Ancient store", "Headers", ancientHeadersSize.String(), ancients.String()},
 		{"Ancient store", "Bodies", ancientBodiesSize.String(), ancients.String()},
@@ -80,6 +80,7 @@ type freezer struct {
 	// so take advantage of that (https://golang.org/pkg/sync/atomic/#pkg-note-BUG).
 	frozen    uint64 // Number of blocks already frozen
 	threshold uint64 // Number of recent blocks not to freeze (params.FullImmutabilityThreshold apart from tests)
+	eras      uint64 // Number of recent eras not to freeze (0 = disabled)
 
 	// This lock synchronizes writers and the truncate operation.
 	writeLock  sync.Mutex
@@ -144,6 +145,15 @@ func newFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 		freezer.tables[name] = table
 	}
 
+	// Back-fill the dag tables created for an existing freezer.
+	if err := freezer.backfill(); err != nil {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		lock.Release()
+		return nil, err
+	}
+
 	// Truncate all tables to common length.
 	if err := freezer.repair(); err != nil {
 		for _, table := range freezer.tables {
@@ -300,6 +310,37 @@ func (f *freezer) Sync() error {
 	return nil
 }
 
+// backfill appends empty items to the newly created dag tables of the freezer
+// containing the blocks frozen without the dag data, so that the repair does not
+// truncate the frozen blocks.
+func (f *freezer) backfill() error {
+	hashes, ok := f.tables[freezerHashTable]
+	if !ok || f.readonly {
+		return nil
+	}
+	frozen := atomic.LoadUint64(&hashes.items)
+	for _, kind := range freezerDagTables {
+		table, ok := f.tables[kind]
+		if !ok || atomic.LoadUint64(&table.items) > 0 || frozen == 0 {
+			continue
+		}
+		batch := table.newBatch()
+		for number := uint64(0); number < frozen; number++ {
+			if err := batch.AppendRaw(number, nil); err != nil {
+				return err
+			}
+		}
+		if err := batch.commit(); err != nil {
+			return err
+		}
+		if err := table.Sync(); err != nil {
+			return err
+		}
+		log.Info("Back-filled ancient table", "table", kind, "items", frozen)
+	}
+	return nil
+}
+
 // repair truncates all data tables to the same length.
 func (f *freezer) repair() error {
 	min := uint64(math.MaxUint64)
@@ -392,6 +433,17 @@ func (f *freezer) freeze(db ethdb.KeyValueStore) {
 			first, _ = f.Ancients()
 			limit    = *number - threshold
 		)
+		if eras := atomic.LoadUint64(&f.eras); eras > 0 {
+			eraLimit, ok := freezeEraLimit(nfdb, eras)
+			if !ok || eraLimit < first {
+				log.Debug("Current era not old enough", "eras", eras, "frozen", first)
+				backoff = true
+				continue
+			}
+			if eraLimit < limit {
+				limit = eraLimit
+			}
+		}
 		if limit-first > freezerBatchLimit {
 			limit = first + freezerBatchLimit
 		}
@@ -509,6 +561,9 @@ func (f *freezer) freezeRange(nfdb *nofreezedb, number, limit uint64) (hashes []
 			if len(receipts) == 0 {
 				return fmt.Errorf("block receipts missing, can't freeze block %d", number)
 			}
+			// The dag data is missing for the blocks imported without dag.
+			blockDag, _ := nfdb.Get(blockDagKey(hash))
+			children, _ := nfdb.Get(childrenKey(hash))
 			// Write to the batch.
 			if err := op.AppendRaw(freezerHashTable, number, hash[:]); err != nil {
 				return fmt.Errorf("can't write hash to freezer: %v", err)
@@ -522,6 +577,12 @@ func (f *freezer) freezeRange(nfdb *nofreezedb, number, limit uint64) (hashes []
 			if err := op.AppendRaw(freezerReceiptTable, number, receipts); err != nil {
 				return fmt.Errorf("can't write receipts to freezer: %v", err)
 			}
+			if err := op.AppendRaw(freezerBlockDagTable, number, blockDag); err != nil {
+				return fmt.Errorf("can't write block dag to freezer: %v", err)
+			}
+			if err := op.AppendRaw(freezerChildrenTable, number, children); err != nil {
+				return fmt.Errorf("can't write children to freezer: %v", err)
+			}
 			hashes = append(hashes, hash)
 		}
 		return nil
@@ -529,3 +590,30 @@ func (f *freezer) freezeRange(nfdb *nofreezedb, number, limit uint64) (hashes []
 
 	return hashes, err
 }
+
+// freezeEraLimit returns the last finalized number allowed to be frozen
+// keeping the given number of recent eras in the key-value store.
+func freezeEraLimit(db ethdb.KeyValueReader, eras uint64) (uint64, bool) {
+	current := ReadCurrentEra(db)
+	if current+1 <= eras {
+		return 0, false
+	}
+	keep := ReadEra(db, current+1-eras)
+	lastCp := ReadLastCoordinatedCheckpoint(db)
+	if keep == nil || lastCp == nil {
+		return 0, false
+	}
+	// the first finalized spine of the kept era bounds the frozen blocks
+	for epoch := keep.From; epoch <= lastCp.FinEpoch; epoch++ {
+		spine := ReadEpoch(db, epoch)
+		if spine == (common.Hash{}) {
+			continue
+		}
+		number := ReadFinalizedNumberByHash(db, spine)
+		if number == nil || *number == 0 {
+			return 0, false
+		}
+		return *number - 1, true
+	}
+	return 0, false
+}
@@ -27,8 +27,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb/memorydb"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/rlp"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
 )
 
 var freezerTestTableDef = map[string]bool{"test": true}
@@ -298,3 +302,126 @@ func checkAncientCount(t *testing.T, f *freezer, kind string, n uint64) {
 		t.Errorf("Ancient(%q, %d) returned unexpected error %q", kind, index, err)
 	}
 }
+
+func TestFreezerBackfillDagTables(t *testing.T) {
+	t.Parallel()
+
+	// Open the freezer without the dag tables.
+	tables := map[string]bool{
+		freezerHeaderTable:  false,
+		freezerHashTable:    true,
+		freezerBodiesTable:  false,
+		freezerReceiptTable: false,
+	}
+	f, dir := newFreezerForTesting(t, tables)
+	defer os.RemoveAll(dir)
+
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 3; i++ {
+			for kind := range tables {
+				if err := op.AppendRaw(kind, i, getChunk(32, int(i))); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Reopen with the dag tables, the frozen blocks must be kept.
+	f, err = newFreezer(dir, "", false, 2049, FreezerNoSnappy)
+	require.NoError(t, err)
+	defer f.Close()
+
+	checkAncientCount(t, f, freezerHashTable, 3)
+	for _, kind := range freezerDagTables {
+		data, err := f.Ancient(kind, 2)
+		require.NoError(t, err)
+		require.Empty(t, data)
+	}
+}
+
+func TestFreezeRangeDagData(t *testing.T) {
+	t.Parallel()
+
+	f, dir := newFreezerForTesting(t, FreezerNoSnappy)
+	defer os.RemoveAll(dir)
+	defer f.Close()
+
+	kvdb := memorydb.New()
+	nfdb := &nofreezedb{KeyValueStore: kvdb}
+	blocks := make([]*types.Block, 3)
+	for i := range blocks {
+		nr := uint64(i)
+		header := &types.Header{Height: nr, Extra: []byte("test block")}
+		if i > 0 {
+			header.ParentHashes = common.HashArray{blocks[i-1].Hash()}
+		}
+		blocks[i] = types.NewBlockWithHeader(header)
+		blocks[i].SetNumber(&nr)
+		hash := blocks[i].Hash()
+
+		WriteBlock(nfdb, blocks[i])
+		WriteReceipts(nfdb, hash, types.Receipts{})
+		WriteFinalizedHashNumber(nfdb, hash, nr)
+		WriteBlockDag(nfdb, &types.BlockDAG{Hash: hash, Height: nr, Slot: nr})
+		if i > 0 {
+			WriteChildren(nfdb, blocks[i-1].Hash(), common.HashArray{hash})
+		}
+	}
+	hashes, err := f.freezeRange(nfdb, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, hashes, 3)
+	for _, hash := range hashes {
+		DeleteBlockWithoutNumber(kvdb, hash)
+	}
+
+	db := &freezerdb{KeyValueStore: kvdb, AncientStore: f}
+	for i, block := range blocks {
+		blockDag := ReadBlockDag(db, block.Hash())
+		require.NotNil(t, blockDag)
+		require.Equal(t, block.Height(), blockDag.Height)
+		if i < len(blocks)-1 {
+			require.Equal(t, common.HashArray{blocks[i+1].Hash()}, ReadChildren(db, block.Hash()))
+		} else {
+			require.Empty(t, ReadChildren(db, block.Hash()))
+		}
+	}
+	// The not frozen data is read from the key-value store.
+	require.Nil(t, ReadBlockDag(db, common.Hash{0x01}))
+	WriteBlockDag(kvdb, &types.BlockDAG{Hash: common.Hash{0x01}, Height: 10})
+	require.Equal(t, uint64(10), ReadBlockDag(db, common.Hash{0x01}).Height)
+}
+
+func TestFreezeEraLimit(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	// Eras of 4 epochs, spines finalized as 4*epoch.
+	for number := uint64(0); number < 3; number++ {
+		WriteEra(db, number, era.Era{Number: number, From: number * 4, To: number*4 + 3})
+	}
+	for epoch := uint64(0); epoch < 12; epoch++ {
+		spine := common.Hash{byte(epoch + 1)}
+		WriteEpoch(db, epoch, spine)
+		WriteFinalizedHashNumber(db, spine, epoch*4)
+	}
+	WriteLastCoordinatedCheckpoint(db, &types.Checkpoint{FinEpoch: 11})
+
+	WriteCurrentEra(db, 1)
+	_, ok := freezeEraLimit(db, 2)
+	require.False(t, ok)
+
+	limit, ok := freezeEraLimit(db, 1)
+	require.True(t, ok)
+	require.Equal(t, uint64(15), limit)
+
+	WriteCurrentEra(db, 2)
+	limit, ok = freezeEraLimit(db, 2)
+	require.True(t, ok)
+	require.Equal(t, uint64(15), limit)
+
+	limit, ok = freezeEraLimit(db, 1)
+	require.True(t, ok)
+	require.Equal(t, uint64(31), limit)
+}
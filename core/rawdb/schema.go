@@ -81,6 +81,9 @@ var (
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	fastTxLookupLimitKey = []byte("FastTransactionLookupLimit")
 
+	// dagPruneSlotKey tracks the slot the stale dag is pruned up to.
+	dagPruneSlotKey = []byte("DagPruneSlot")
+
 	// badBlockKey tracks the list of bad blocks seen by local
 	badBlockKey = []byte("InvalidBlock")
 
@@ -100,8 +103,11 @@ var (
 	slotBlockKey                = []byte("slotBlocks")
 
 	// validator sync data
-	valSyncOpPrefix   = []byte("vsop")        // valSyncOpPrefix + initTxHash -> procEpoch + index + txHash + amountBigInt
-	valSyncNotProcKey = []byte("vsnprockeys") // tracks the not processed validators' sync operation.
+	valSyncOpPrefix   = []byte("vsop")        // valSyncOpPrefix + initTxHash + creator -> ValidatorSync
+	valSyncNotProcKey = []byte("vsnprockeys") // tracks the not processed validators' sync operation (deprecated: initTxHash keys).
+	valSyncNotProcOps = []byte("vsnprocops")  // tracks the not processed validators' sync operation.
+
+	eraValidatorsPrefix = []byte("vce") // eraValidatorsPrefix + era (uint64 big endian) -> cached active validators of era
 
 	blockBodyPrefix     = []byte("b") // blockBodyPrefix + hash -> block body
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
@@ -134,17 +140,29 @@ const (
 
 	// freezerReceiptTable indicates the name of the freezer receipts table.
 	freezerReceiptTable = "receipts"
+
+	// freezerBlockDagTable indicates the name of the freezer block dag table.
+	freezerBlockDagTable = "blockdags"
+
+	// freezerChildrenTable indicates the name of the freezer block children table.
+	freezerChildrenTable = "children"
 )
 
 // FreezerNoSnappy configures whether compression is disabled for the ancient-tables.
 // Hashes and difficulties don't compress well.
 var FreezerNoSnappy = map[string]bool{
-	freezerHeaderTable:  false,
-	freezerHashTable:    true,
-	freezerBodiesTable:  false,
-	freezerReceiptTable: false,
+	freezerHeaderTable:   false,
+	freezerHashTable:     true,
+	freezerBodiesTable:   false,
+	freezerReceiptTable:  false,
+	freezerBlockDagTable: false,
+	freezerChildrenTable: true,
 }
 
+// freezerDagTables are the ancient-tables added to the existing freezers,
+// they are back-filled with empty items on the first opening.
+var freezerDagTables = []string{freezerBlockDagTable, freezerChildrenTable}
+
 // LegacyTxLookupEntry is the legacy TxLookupEntry definition with some unnecessary
 // fields.
 type LegacyTxLookupEntry struct {
@@ -259,8 +277,18 @@ func eraKey(era uint64) []byte {
 	return append(eraPrefix, Uint64ToByteSlice(era)...)
 }
 
-// validatorSyncKey = valSyncOpPrefix + initTxHash
-func validatorSyncKey(initTxHash common.Hash) []byte {
+// eraValidatorsKey = eraValidatorsPrefix + era
+func eraValidatorsKey(era uint64) []byte {
+	return append(eraValidatorsPrefix, Uint64ToByteSlice(era)...)
+}
+
+// validatorSyncKey = valSyncOpPrefix + initTxHash + creator
+func validatorSyncKey(initTxHash common.Hash, creator common.Address) []byte {
+	return append(append(valSyncOpPrefix, initTxHash.Bytes()...), creator.Bytes()...)
+}
+
+// legacyValidatorSyncKey = valSyncOpPrefix + initTxHash
+func legacyValidatorSyncKey(initTxHash common.Hash) []byte {
 	return append(valSyncOpPrefix, initTxHash.Bytes()...)
 }
 
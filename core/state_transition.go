@@ -377,9 +377,19 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 			if err != nil {
 				return nil, err
 			}
-			ret, vmerr = st.tp.Call(sender, st.to(), st.value, op)
+			tp, frame := st.tp, st.nativeOpFrame(vm.NativeOpToken)
+			if frame != nil {
+				tp = tp.WithTracer(frame)
+			}
+			ret, vmerr = tp.Call(sender, st.to(), st.value, op)
+			st.captureNativeOp(frame, ret, vmerr)
 		} else if isValidatorOp {
-			ret, vmerr = st.vp.Call(sender, st.to(), st.value, st.msg)
+			vp, frame := st.vp, st.nativeOpFrame(vm.NativeOpValidator)
+			if frame != nil {
+				vp = vp.WithTracer(frame)
+			}
+			ret, vmerr = vp.Call(sender, st.to(), st.value, st.msg)
+			st.captureNativeOp(frame, ret, vmerr)
 		} else {
 			// Increment the nonce for the next transaction
 			st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
@@ -413,6 +423,31 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}, nil
 }
 
+// nativeOpFrame creates the trace frame of the native token or validator
+// operation if the tracer captures native operations.
+func (st *StateTransition) nativeOpFrame(typ string) *vm.NativeOpFrame {
+	if !st.evm.Config.Debug {
+		return nil
+	}
+	if _, ok := st.evm.Config.Tracer.(vm.NativeOpLogger); !ok {
+		return nil
+	}
+	return vm.NewNativeOpFrame(typ, st.msg.From(), st.to(), st.data, st.initialGas, st.value)
+}
+
+// captureNativeOp passes the frame of the executed native operation to the tracer.
+func (st *StateTransition) captureNativeOp(frame *vm.NativeOpFrame, ret []byte, err error) {
+	if frame == nil {
+		return
+	}
+	// The address of the created token is returned by the operation
+	if frame.To == (common.Address{}) && err == nil && len(ret) == common.AddressLength {
+		frame.To = common.BytesToAddress(ret)
+	}
+	frame.End(ret, st.gasUsed(), err)
+	st.evm.Config.Tracer.(vm.NativeOpLogger).CaptureNativeOp(st.evm, frame)
+}
+
 func (st *StateTransition) refundGas(refundQuotient uint64) {
 	// Apply refund counter, capped to a refund quotient
 	refund := st.gasUsed() / refundQuotient
@@ -26,6 +26,7 @@ import (
 	"sync"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common/prque"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/consensus/misc"
@@ -48,6 +49,10 @@ const (
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
 
+	// removedTxsCacheLimit is the number of recently removed transactions
+	// tracked to report the dropped status.
+	removedTxsCacheLimit = 4096
+
 	// txSlotSize is used to calculate how many data slots a single transaction
 	// takes up based on its size. The slots are used as DoS protection, ensuring
 	// that validating a new transaction remains a constant operation (in reality
@@ -143,6 +148,7 @@ const (
 	TxStatusPending
 	TxStatusProcessing
 	TxStatusIncluded
+	TxStatusDropped
 )
 
 // blockChain provides the state of blockchain and current gas limit to do
@@ -192,6 +198,12 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	ValidatorSlots     uint64 // Maximum number of validator operations reserved in the pool
+	ValidatorPriceBump uint64 // Minimum price bump percentage to replace an already existing validator operation
+	ValidatorJournal   string // Journal of validator operations to survive node restarts
+
+	Snapshot string // Snapshot of the whole pool to warm up it on node restarts (disabled if empty)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -211,6 +223,10 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	ValidatorSlots:     1024,
+	ValidatorPriceBump: 10,
+	ValidatorJournal:   "validator_ops.rlp",
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -249,6 +265,14 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.ValidatorSlots < 1 {
+		log.Warn("Sanitizing invalid txpool validator slots", "provided", conf.ValidatorSlots, "updated", DefaultTxPoolConfig.ValidatorSlots)
+		conf.ValidatorSlots = DefaultTxPoolConfig.ValidatorSlots
+	}
+	if conf.ValidatorPriceBump < 1 {
+		log.Warn("Sanitizing invalid txpool validator price bump", "provided", conf.ValidatorPriceBump, "updated", DefaultTxPoolConfig.ValidatorPriceBump)
+		conf.ValidatorPriceBump = DefaultTxPoolConfig.ValidatorPriceBump
+	}
 	return conf
 }
 
@@ -276,6 +300,11 @@ type TxPool struct {
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	valOps     *validatorOpSet // Validator operations reserved in the pool
+	valJournal *txJournal      // Journal of validator operations to back up to disk
+
+	snapshot *txSnapshot // Snapshot of the whole pool to back up to disk
+
 	pending    map[common.Address]*txList // All currently processable transactions
 	queue      map[common.Address]*txList // Queued but non-processable transactions
 	processing map[common.Address]*txList
@@ -321,6 +350,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		processing:      make(map[common.Address]*txList),
 		beats:           make(map[common.Address]time.Time),
 		all:             newTxLookup(),
+		valOps:          newValidatorOpSet(config.ValidatorSlots),
 		chainHeadCh:     make(chan ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
@@ -375,6 +405,27 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If validator operations journaling is enabled, load them from disk
+	if config.ValidatorJournal != "" {
+		pool.valJournal = newTxJournal(config.ValidatorJournal)
+
+		if err := pool.valJournal.load(pool.AddRemotesSync); err != nil {
+			log.Warn("Failed to load validator operations journal", "err", err)
+		}
+		pool.mu.Lock()
+		if err := pool.valJournal.rotate(pool.valOps.transactions()); err != nil {
+			log.Warn("Failed to rotate validator operations journal", "err", err)
+		}
+		pool.mu.Unlock()
+	}
+	// If the pool snapshot is enabled, warm up the pool with the remote transactions
+	if config.Snapshot != "" {
+		pool.snapshot = newTxSnapshot(config.Snapshot)
+
+		if err := pool.snapshot.load(pool.AddRemotesSync); err != nil {
+			log.Warn("Failed to load transaction pool snapshot", "err", err)
+		}
+	}
 
 	// Subscribe events from blockchain and start the main event loop.
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
@@ -443,13 +494,17 @@ func (pool *TxPool) loop() {
 				if pool.locals.contains(addr) {
 					continue
 				}
-				// Any non-locals old enough should be removed
+				// Any non-locals old enough should be removed, except the validator operations
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
-					list := pool.queue[addr].Flatten()
-					for _, tx := range list {
+					var evicted int64
+					for _, tx := range pool.queue[addr].Flatten() {
+						if pool.valOps.has(tx.Hash()) {
+							continue
+						}
 						pool.removeTx(tx.Hash(), true)
+						evicted++
 					}
-					queuedEvictionMeter.Mark(int64(len(list)))
+					queuedEvictionMeter.Mark(evicted)
 				}
 			}
 			pool.mu.Unlock()
@@ -463,6 +518,21 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			if pool.valJournal != nil {
+				pool.mu.Lock()
+				pool.valOps.prune(pool.all)
+				if err := pool.valJournal.rotate(pool.valOps.transactions()); err != nil {
+					log.Warn("Failed to rotate validator operations journal", "err", err)
+				}
+				pool.mu.Unlock()
+			}
+			if pool.snapshot != nil {
+				pool.mu.Lock()
+				if err := pool.snapshot.write(pool.content()); err != nil {
+					log.Warn("Failed to write transaction pool snapshot", "err", err)
+				}
+				pool.mu.Unlock()
+			}
 
 		case txs := <-pool.processingCh:
 			func() {
@@ -533,6 +603,16 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.valJournal != nil {
+		pool.valJournal.close()
+	}
+	if pool.snapshot != nil {
+		pool.mu.Lock()
+		if err := pool.snapshot.write(pool.content()); err != nil {
+			log.Warn("Failed to write transaction pool snapshot", "err", err)
+		}
+		pool.mu.Unlock()
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -723,6 +803,22 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// content retrieves all currently known transactions including the processing
+// ones, grouped by origin account and sorted by nonce.
+func (pool *TxPool) content() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, processing := range pool.processing {
+		txs[addr] = append(txs[addr], processing.Flatten()...)
+	}
+	for addr, pending := range pool.pending {
+		txs[addr] = append(txs[addr], pending.Flatten()...)
+	}
+	for addr, queued := range pool.queue {
+		txs[addr] = append(txs[addr], queued.Flatten()...)
+	}
+	return txs
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -819,10 +915,15 @@ func (pool *TxPool) handleValidatorTransaction(txData []byte, from common.Addres
 		switch v := op.(type) {
 		case valOperation.Exit:
 			return pool.checkExitOperation(v, from)
+		// DelegatorWithdrawal extends the Withdrawal method set, so it must be matched first.
+		case valOperation.DelegatorWithdrawal:
+			return pool.checkDelegatorWithdrawalOperation(v, from)
 		case valOperation.Withdrawal:
 			return pool.checkWithdrawalOperation(v, from)
 		case valOperation.Deposit:
 			return pool.checkDepositOperation(v, from, value)
+		case valOperation.BatchDeposit:
+			return pool.checkBatchDepositOperation(v, from, value)
 		}
 		return nil
 	}
@@ -1008,6 +1109,82 @@ func (pool *TxPool) checkWithdrawalOperation(op valOperation.Withdrawal, from co
 	return nil
 }
 
+func (pool *TxPool) checkDelegatorWithdrawalOperation(op valOperation.DelegatorWithdrawal, from common.Address) error {
+	var curSlot uint64
+	if pool.chain.GetSlotInfo() != nil {
+		curSlot = pool.chain.GetSlotInfo().CurrentSlot()
+	}
+	if !pool.chainconfig.IsForkSlotDelegatorWithdrawal(curSlot) {
+		return valOperation.ErrDelegatorWithdrawalForkRequire
+	}
+	// check amount can add to log
+	if !common.BnCanCastToUint64(new(big.Int).Div(op.Amount(), common.BigGwei)) {
+		return val.ErrInvalidAmount
+	}
+	if from != op.DelegatorAddress() {
+		return val.ErrInvalidFromAddresses
+	}
+	validator, err := pool.chain.ValidatorStorage().GetValidator(pool.currentState, op.CreatorAddress())
+	if err != nil {
+		return err
+	}
+	if validator == nil {
+		return val.ErrUnknownValidator
+	}
+	if validator.GetActivationEra() == math.MaxUint64 {
+		return val.ErrNotActivatedValidator
+	}
+	isTrial := false
+	if validator.HasDelegatingStake() {
+		if isTrial, err = pool.isValidatorTrialPeriod(validator); err != nil {
+			return err
+		}
+	}
+	if _, err = val.ValidateDelegatorWithdrawal(validator, from, op.Amount(), isTrial, pool.chainconfig.EffectiveBalance); err != nil {
+		return err
+	}
+
+	//if operation already has been requested, check it expiration
+	prevOpTx := validator.GetWithdrawalTx()
+	if prevOpTx != nil {
+		rc, blHash, _ := pool.chain.GetTransactionReceipt(*prevOpTx)
+		//check prev op succes
+		if rc != nil && rc.Status == types.ReceiptStatusSuccessful {
+			//check prev operation expiration
+			prevHeader := pool.chain.GetHeaderByHash(blHash)
+			expiration := pool.chain.Config().ValidatorOpExpireSlots
+			if prevHeader != nil && curSlot < prevHeader.Slot+expiration {
+				return val.ErrValOpBlocked
+			}
+		}
+	}
+
+	return nil
+}
+
+func (pool *TxPool) checkBatchDepositOperation(op valOperation.BatchDeposit, from common.Address, amount *big.Int) error {
+	var curSlot uint64
+	if pool.chain.GetSlotInfo() != nil {
+		curSlot = pool.chain.GetSlotInfo().CurrentSlot()
+	}
+	if !pool.chainconfig.IsForkSlotBatchDeposit(curSlot) {
+		return valOperation.ErrBatchDepositForkRequire
+	}
+	if amount == nil || amount.Cmp(op.TotalValue()) != 0 {
+		return val.ErrMismatchBatchValue
+	}
+	for i, entry := range op.Deposits() {
+		if err := pool.checkDepositOperation(entry.Deposit, from, entry.Value); err != nil {
+			return &val.BatchDepositError{
+				Index:   i,
+				Creator: entry.Deposit.CreatorAddress(),
+				Err:     err,
+			}
+		}
+	}
+	return nil
+}
+
 func (pool *TxPool) checkDepositOperation(op valOperation.Deposit, from common.Address, amount *big.Int) error {
 	// validate deposit signature
 	if err := valOperation.VerifyDepositSig(op.Signature(), op.PubKey(), op.CreatorAddress(), op.WithdrawalAddress()); err != nil {
@@ -1105,12 +1282,28 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		invalidTxMeter.Mark(1)
 		return false, err
 	}
+	// Validator operations are kept in the reserved capacity of the sub-pool
+	// and skip the global capacity constraints.
+	valOpKind, isValidatorOp := getValidatorOpKind(tx, pool.chainconfig.ValidatorsStateAddress)
+	if isValidatorOp {
+		pool.valOps.prune(pool.all)
+		if !pool.valOps.hasRoom(valOpKind) {
+			log.Trace("Discarding overflown validator operation", "hash", hash)
+			validatorOverflowMeter.Mark(1)
+			return false, ErrValidatorPoolOverflow
+		}
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	if err := pool.checkValidatorOpReplace(from, tx, isValidatorOp); err != nil {
+		return false, err
+	}
+
 	// If the transaction pool is full, discard underpriced transactions
 	allSlots := pool.all.Slots()
 	nrSlots := numSlots(tx)
 	globalSlots := pool.config.GlobalSlots
 	globalQueue := pool.config.GlobalQueue
-	if uint64(allSlots+nrSlots) > globalSlots+globalQueue {
+	if uint64(allSlots+nrSlots) > globalSlots+globalQueue && !isValidatorOp {
 		// If the new transaction is underpriced, don't accept it
 		if !isLocal && pool.priced.Underpriced(tx) {
 			log.Trace("Discarding underpriced transaction", "hash", hash, "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
@@ -1139,18 +1332,22 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		}
 		// Bump the counter of rejections-since-reorg
 		pool.changesSinceReorg += len(drop)
-		// Kick out the underpriced remote transactions.
+		// Kick out the underpriced remote transactions, keeping the validator
+		// operations which have the reserved capacity.
 		for _, tx := range drop {
+			if pool.valOps.has(tx.Hash()) {
+				pool.priced.Put(tx, false)
+				continue
+			}
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
 			underpricedTxMeter.Mark(1)
 			pool.removeTx(tx.Hash(), false)
 		}
 	}
 	// Try to replace an existing transaction in the pending pool
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
-		inserted, old := list.Add(tx, pool.config.PriceBump)
+		inserted, old := list.Add(tx, pool.priceBump(tx))
 		if !inserted {
 			pendingDiscardMeter.Mark(1)
 			return false, ErrReplaceUnderpriced
@@ -1163,6 +1360,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
+		pool.trackValidatorOp(from, tx, valOpKind, isValidatorOp)
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
@@ -1176,6 +1374,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	if err != nil {
 		return false, err
 	}
+	pool.trackValidatorOp(from, tx, valOpKind, isValidatorOp)
 	// Mark local addresses and journal local transactions
 	if local && !pool.locals.contains(from) {
 		log.Info("Setting new local account", "address", from)
@@ -1200,7 +1399,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction, local boo
 	if pool.queue[from] == nil {
 		pool.queue[from] = newTxList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
+	inserted, old := pool.queue[from].Add(tx, pool.priceBump(tx))
 	if !inserted {
 		// An older transaction was better, discard this
 		queuedDiscardMeter.Mark(1)
@@ -1243,6 +1442,57 @@ func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
 	}
 }
 
+// trackValidatorOp adds the pooled validator operation to the sub-pool and
+// journals it.
+func (pool *TxPool) trackValidatorOp(from common.Address, tx *types.Transaction, kind validatorOpKind, isValidatorOp bool) {
+	if !isValidatorOp {
+		return
+	}
+	pool.valOps.add(tx, from, kind)
+	if pool.valJournal == nil {
+		return
+	}
+	if err := pool.valJournal.insert(tx); err != nil {
+		log.Warn("Failed to journal validator operation", "err", err)
+	}
+}
+
+// checkValidatorOpReplace ensures the pooled validator operation with the same
+// nonce is replaced only by another validator operation.
+func (pool *TxPool) checkValidatorOpReplace(from common.Address, tx *types.Transaction, isValidatorOp bool) error {
+	if isValidatorOp {
+		return nil
+	}
+	for _, list := range []*txList{pool.pending[from], pool.queue[from]} {
+		if list == nil {
+			continue
+		}
+		if old := list.txs.Get(tx.Nonce()); old != nil && pool.valOps.has(old.Hash()) {
+			return ErrValidatorOpReplace
+		}
+	}
+	return nil
+}
+
+// priceBump returns the price bump percentage required to replace
+// an already existing transaction by the given one.
+func (pool *TxPool) priceBump(tx *types.Transaction) uint64 {
+	if _, ok := getValidatorOpKind(tx, pool.chainconfig.ValidatorsStateAddress); ok {
+		return pool.config.ValidatorPriceBump
+	}
+	return pool.config.PriceBump
+}
+
+// ValidatorOps returns the hashes of the pooled validator operations split by
+// priority: the exits and the others.
+func (pool *TxPool) ValidatorOps() (exits map[common.Hash]struct{}, others map[common.Hash]struct{}) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.valOps.prune(pool.all)
+	return pool.valOps.hashes()
+}
+
 // promoteTx adds a transaction to the pending (processable) list of transactions
 // and returns whether it was inserted or an older was better.
 //
@@ -1393,13 +1643,16 @@ func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error,
 	return errs, dirty
 }
 
-// Status returns the status (unknown/pending/queued) of a batch of transactions
-// identified by their hashes.
+// Status returns the status (unknown/pending/queued/processing/dropped) of a batch
+// of transactions identified by their hashes.
 func (pool *TxPool) Status(hashes []common.Hash) []TxStatus {
 	status := make([]TxStatus, len(hashes))
 	for i, hash := range hashes {
 		tx := pool.Get(hash)
 		if tx == nil {
+			if pool.all.IsDropped(hash) {
+				status[i] = TxStatusDropped
+			}
 			continue
 		}
 		from, _ := types.Sender(pool.signer, tx) // already validated
@@ -1418,6 +1671,24 @@ func (pool *TxPool) Status(hashes []common.Hash) []TxStatus {
 	return status
 }
 
+// ProcessingBlocks returns the hashes of the dag blocks containing
+// the processing transaction.
+func (pool *TxPool) ProcessingBlocks(hash common.Hash) common.HashArray {
+	tx := pool.Get(hash)
+	if tx == nil {
+		return nil
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if txList := pool.processing[from]; txList != nil {
+		return txList.GetTxBlocksHashes(hash).Copy()
+	}
+	return nil
+}
+
 // Get returns a transaction if it is contained in the pool and nil otherwise.
 func (pool *TxPool) Get(hash common.Hash) *types.Transaction {
 	return pool.all.Get(hash)
@@ -1934,6 +2205,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		}
 	}
 	// Ensure pool.queue and pool.pending sizes stay within the configured limits.
+	pool.valOps.prune(pool.all)
 	pool.truncatePending()
 	pool.truncateQueue()
 
@@ -2161,25 +2433,16 @@ func (pool *TxPool) truncatePending() {
 
 			// Iteratively reduce all offenders until below limit or threshold reached
 			for pending > pool.config.GlobalSlots && pool.pending[offenders[len(offenders)-2]].Len() > threshold {
+				dropped := false
 				for i := 0; i < len(offenders)-1; i++ {
-					list := pool.pending[offenders[i]]
-
-					caps := list.Cap(list.Len() - 1)
-					for _, tx := range caps {
-						// Drop the transaction from the global pools too
-						hash := tx.Hash()
-						pool.all.Remove(hash)
-
-						// Update the account nonce to the dropped transaction
-						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
-						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+					if pool.capPending(offenders[i], threshold) {
+						dropped = true
+						pending--
 					}
-					pool.priced.Removed(len(caps))
-					pendingGauge.Dec(int64(len(caps)))
-					if pool.locals.contains(offenders[i]) {
-						localGauge.Dec(int64(len(caps)))
-					}
-					pending--
+				}
+				// Stop if only the validator operations are left to drop
+				if !dropped {
+					break
 				}
 			}
 		}
@@ -2188,31 +2451,48 @@ func (pool *TxPool) truncatePending() {
 	// If still above threshold, reduce to limit or min allowance
 	if pending > pool.config.GlobalSlots && len(offenders) > 0 {
 		for pending > pool.config.GlobalSlots && uint64(pool.pending[offenders[len(offenders)-1]].Len()) > pool.config.AccountSlots {
+			dropped := false
 			for _, addr := range offenders {
-				list := pool.pending[addr]
-
-				caps := list.Cap(list.Len() - 1)
-				for _, tx := range caps {
-					// Drop the transaction from the global pools too
-					hash := tx.Hash()
-					pool.all.Remove(hash)
-
-					// Update the account nonce to the dropped transaction
-					pool.pendingNonces.setIfLower(addr, tx.Nonce())
-					log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
-				}
-				pool.priced.Removed(len(caps))
-				pendingGauge.Dec(int64(len(caps)))
-				if pool.locals.contains(addr) {
-					localGauge.Dec(int64(len(caps)))
+				if pool.capPending(addr, int(pool.config.AccountSlots)) {
+					dropped = true
+					pending--
 				}
-				pending--
+			}
+			// Stop if only the validator operations are left to drop
+			if !dropped {
+				break
 			}
 		}
 	}
 	pendingRateLimitMeter.Mark(int64(pendingBeforeCap - pending))
 }
 
+// capPending drops the highest nonce pending transaction of the account if the
+// account has more than threshold pending transactions. The validator operation
+// is never dropped, the account is reduced down to it at most.
+func (pool *TxPool) capPending(addr common.Address, threshold int) bool {
+	list := pool.pending[addr]
+	if list == nil || list.Len() <= threshold || pool.valOps.has(list.LastElement().Hash()) {
+		return false
+	}
+	caps := list.Cap(list.Len() - 1)
+	for _, tx := range caps {
+		// Drop the transaction from the global pools too
+		hash := tx.Hash()
+		pool.all.Remove(hash)
+
+		// Update the account nonce to the dropped transaction
+		pool.pendingNonces.setIfLower(addr, tx.Nonce())
+		log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+	}
+	pool.priced.Removed(len(caps))
+	pendingGauge.Dec(int64(len(caps)))
+	if pool.locals.contains(addr) {
+		localGauge.Dec(int64(len(caps)))
+	}
+	return len(caps) > 0
+}
+
 // truncateQueue drops the oldes transactions in the queue if the pool is above the global queue limit.
 func (pool *TxPool) truncateQueue() {
 	queued := uint64(0)
@@ -2239,18 +2519,26 @@ func (pool *TxPool) truncateQueue() {
 
 		addresses = addresses[:len(addresses)-1]
 
-		// Drop all transactions if they are less than the overflow
+		// Drop all transactions if they are less than the overflow, keeping the validator operations
 		if size := uint64(list.Len()); size <= drop {
+			var dropped uint64
 			for _, tx := range list.Flatten() {
+				if pool.valOps.has(tx.Hash()) {
+					continue
+				}
 				pool.removeTx(tx.Hash(), true)
+				dropped++
 			}
-			drop -= size
-			queuedRateLimitMeter.Mark(int64(size))
+			drop -= dropped
+			queuedRateLimitMeter.Mark(int64(dropped))
 			continue
 		}
 		// Otherwise drop only last few transactions
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+			if pool.valOps.has(txs[i].Hash()) {
+				continue
+			}
 			pool.removeTx(txs[i].Hash(), true)
 			drop--
 			queuedRateLimitMeter.Mark(1)
@@ -2435,16 +2723,24 @@ type txLookup struct {
 	lock    sync.RWMutex
 	locals  map[common.Hash]*types.Transaction
 	remotes map[common.Hash]*types.Transaction
+	removed *lru.Cache // recently removed transactions
 }
 
 // newTxLookup returns a new txLookup structure.
 func newTxLookup() *txLookup {
+	removed, _ := lru.New(removedTxsCacheLimit)
 	return &txLookup{
 		locals:  make(map[common.Hash]*types.Transaction),
 		remotes: make(map[common.Hash]*types.Transaction),
+		removed: removed,
 	}
 }
 
+// IsDropped returns whether the transaction was recently removed from the lookup.
+func (t *txLookup) IsDropped(hash common.Hash) bool {
+	return t.removed.Contains(hash)
+}
+
 // Range calls f on each key and value present in the map. The callback passed
 // should return the indicator whether the iteration needs to be continued.
 // Callers need to specify which set (or both) to be iterated.
@@ -2542,6 +2838,7 @@ func (t *txLookup) Add(tx *types.Transaction, local bool) {
 	} else {
 		t.remotes[tx.Hash()] = tx
 	}
+	t.removed.Remove(tx.Hash())
 }
 
 // Remove removes a transaction from the lookup.
@@ -2562,6 +2859,7 @@ func (t *txLookup) Remove(hash common.Hash) {
 
 	delete(t.locals, hash)
 	delete(t.remotes, hash)
+	t.removed.Add(hash, struct{}{})
 }
 
 // RemoteToLocals migrates the transactions belongs to the given locals to locals
@@ -56,6 +56,7 @@ var (
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.ValidatorJournal = ""
 
 	testTxPoolConfig.AccountSlots = 16
 	testTxPoolConfig.GlobalSlots = (4096 + 1024) * 20
@@ -2463,6 +2464,65 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that the remote transactions survive a restart via the pool snapshot and
+// the stale ones are dropped on revalidation.
+func TestTransactionPoolSnapshot(t *testing.T) {
+	// Create a temporary file for the snapshot
+	file, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary snapshot: %v", err)
+	}
+	snapshot := file.Name()
+	defer os.Remove(snapshot)
+
+	file.Close()
+	os.Remove(snapshot)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := defaultTestBC1(statedb)
+
+	config := testTxPoolConfig
+	config.NoLocals = true
+	config.Snapshot = snapshot
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	remote, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Add two pending and a queued remote transactions
+	for _, nonce := range []uint64{0, 1, 3} {
+		if err := pool.addRemoteSync(pricedTransaction(nonce, 21000, big.NewInt(1), remote)); err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	pending, queued, _ := pool.Stats()
+	if pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 2)
+	}
+	if queued != 1 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 1)
+	}
+	// Terminate the old pool, bump the remote nonce, create a new pool and ensure relevant transactions survive
+	pool.Stop()
+	statedb.SetNonce(crypto.PubkeyToAddress(remote.PublicKey), 1)
+	blockchain = defaultTestBC1(statedb)
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pending, queued, _ = pool.Stats()
+	if pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if queued != 1 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
@@ -2517,6 +2577,14 @@ func TestTransactionStatusCheck(t *testing.T) {
 			t.Errorf("transaction %d: status mismatch: have %v, want %v", i, statuses[i], expect[i])
 		}
 	}
+	// Removed transactions are reported as dropped
+	pool.mu.Lock()
+	pool.removeTx(hashes[3], true)
+	pool.mu.Unlock()
+
+	if status := pool.Status(hashes[3:4])[0]; status != TxStatusDropped {
+		t.Errorf("removed transaction: status mismatch: have %v, want %v", status, TxStatusDropped)
+	}
 }
 
 // Test the transaction slots consumption is computed correctly
@@ -0,0 +1,196 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"errors"
+	"sort"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/metrics"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token/operation"
+	valOperation "gitlab.waterfall.network/waterfall/protocol/gwat/validator/operation"
+)
+
+var (
+	// ErrValidatorPoolOverflow is returned if the validator operations sub-pool
+	// is full and can't accept another operation of the kind.
+	ErrValidatorPoolOverflow = errors.New("validator operations pool is full")
+
+	// ErrValidatorOpReplace is returned if a pooled validator operation is
+	// attempted to be replaced with a regular transaction.
+	ErrValidatorOpReplace = errors.New("validator operation can not be replaced by a regular transaction")
+)
+
+var (
+	validatorOpGauge       = metrics.NewRegisteredGauge("txpool/validator", nil)
+	validatorOpExitGauge   = metrics.NewRegisteredGauge("txpool/validator/exit", nil)
+	validatorOverflowMeter = metrics.NewRegisteredMeter("txpool/validator/overflowed", nil)
+)
+
+// validatorOpKind is the kind of the validator operation, which defines
+// its priority in the pool.
+type validatorOpKind uint8
+
+const (
+	validatorOpOther validatorOpKind = iota
+	validatorOpDeposit
+	validatorOpWithdrawal
+	validatorOpExit
+)
+
+// pooledValidatorOp is a validator operation tracked by the sub-pool.
+type pooledValidatorOp struct {
+	tx   *types.Transaction
+	from common.Address
+	kind validatorOpKind
+}
+
+// validatorOpSet is the sub-pool of the validator operations. The operations
+// are kept in the common pool lists to preserve the nonce ordering of the
+// senders, the set reserves them the separate capacity, protects them from
+// the eviction one by one and prioritizes them for the block creation.
+//
+// Note, the set is guarded by the pool lock.
+type validatorOpSet struct {
+	slots uint64 // Maximum number of the operations
+	exits uint64 // Number of the slots reserved for the exits
+
+	ops   map[common.Hash]*pooledValidatorOp
+	count map[validatorOpKind]uint64
+}
+
+// newValidatorOpSet creates the sub-pool of the validator operations with the
+// given capacity, a quarter of which is reserved for the exits.
+func newValidatorOpSet(slots uint64) *validatorOpSet {
+	return &validatorOpSet{
+		slots: slots,
+		exits: slots / 4,
+		ops:   make(map[common.Hash]*pooledValidatorOp),
+		count: make(map[validatorOpKind]uint64),
+	}
+}
+
+// getValidatorOpKind returns the kind of the validator operation and whether
+// the transaction is a validator operation at all.
+func getValidatorOpKind(tx *types.Transaction, validatorsAddress *common.Address) (validatorOpKind, bool) {
+	if tx.To() == nil || validatorsAddress == nil || *tx.To() != *validatorsAddress {
+		return validatorOpOther, false
+	}
+	if len(tx.Data()) == 0 {
+		return validatorOpOther, false
+	}
+	if _, err := operation.GetOpCode(tx.Data()); err == nil {
+		return validatorOpOther, false
+	}
+	op, err := valOperation.DecodeBytes(tx.Data())
+	if err != nil {
+		return validatorOpOther, false
+	}
+	switch op.(type) {
+	case valOperation.Exit:
+		return validatorOpExit, true
+	case valOperation.Withdrawal, valOperation.DelegatorWithdrawal:
+		return validatorOpWithdrawal, true
+	case valOperation.Deposit, valOperation.BatchDeposit:
+		return validatorOpDeposit, true
+	}
+	return validatorOpOther, true
+}
+
+// has returns whether the transaction is tracked by the set.
+func (s *validatorOpSet) has(hash common.Hash) bool {
+	_, ok := s.ops[hash]
+	return ok
+}
+
+// len returns the number of tracked validator operations.
+func (s *validatorOpSet) len() int {
+	return len(s.ops)
+}
+
+// hasRoom returns whether another operation of the kind fits into the set.
+// The operations other than exits can't take the slots reserved for exits.
+func (s *validatorOpSet) hasRoom(kind validatorOpKind) bool {
+	total := uint64(len(s.ops))
+	if kind == validatorOpExit {
+		return total < s.slots
+	}
+	others := total - s.count[validatorOpExit]
+	return total < s.slots && others < s.slots-s.exits
+}
+
+// add starts tracking the validator operation.
+func (s *validatorOpSet) add(tx *types.Transaction, from common.Address, kind validatorOpKind) {
+	if s.has(tx.Hash()) {
+		return
+	}
+	s.ops[tx.Hash()] = &pooledValidatorOp{tx: tx, from: from, kind: kind}
+	s.count[kind]++
+	s.updateGauges()
+}
+
+// remove stops tracking the validator operation.
+func (s *validatorOpSet) remove(hash common.Hash) {
+	op, ok := s.ops[hash]
+	if !ok {
+		return
+	}
+	delete(s.ops, hash)
+	s.count[op.kind]--
+	s.updateGauges()
+}
+
+// prune stops tracking the operations which left the pool.
+func (s *validatorOpSet) prune(all *txLookup) {
+	for hash := range s.ops {
+		if all.Get(hash) == nil {
+			s.remove(hash)
+		}
+	}
+}
+
+// hashes returns the hashes of the tracked operations split by priority:
+// the exits and the others.
+func (s *validatorOpSet) hashes() (exits map[common.Hash]struct{}, others map[common.Hash]struct{}) {
+	exits = make(map[common.Hash]struct{})
+	others = make(map[common.Hash]struct{})
+	for hash, op := range s.ops {
+		if op.kind == validatorOpExit {
+			exits[hash] = struct{}{}
+		} else {
+			others[hash] = struct{}{}
+		}
+	}
+	return exits, others
+}
+
+// transactions returns the tracked operations grouped by sender and sorted by nonce.
+func (s *validatorOpSet) transactions() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for _, op := range s.ops {
+		txs[op.from] = append(txs[op.from], op.tx)
+	}
+	for _, list := range txs {
+		sort.Sort(types.TxByNonce(list))
+	}
+	return txs
+}
+
+func (s *validatorOpSet) updateGauges() {
+	validatorOpGauge.Update(int64(len(s.ops)))
+	validatorOpExitGauge.Update(int64(s.count[validatorOpExit]))
+}
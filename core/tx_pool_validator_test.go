@@ -0,0 +1,144 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
+	valOperation "gitlab.waterfall.network/waterfall/protocol/gwat/validator/operation"
+)
+
+var testValidatorsAddress = common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+func validatorOpTransaction(t *testing.T, nonce uint64, op valOperation.Operation, key *ecdsa.PrivateKey) *types.Transaction {
+	data, err := valOperation.EncodeToBytes(op)
+	if err != nil {
+		t.Fatalf("failed to encode validator operation: %v", err)
+	}
+	tx, _ := types.SignTx(types.NewTransaction(nonce, testValidatorsAddress, big.NewInt(0), 100000, big.NewInt(1), data), types.HomesteadSigner{}, key)
+	return tx
+}
+
+func TestValidatorOpKind(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	exit, err := valOperation.NewExitOperation(common.BlsPubKey{0x01}, common.Address{0x02}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withdrawal, err := valOperation.NewWithdrawalOperation(common.Address{0x02}, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if kind, ok := getValidatorOpKind(validatorOpTransaction(t, 0, exit, key), &testValidatorsAddress); !ok || kind != validatorOpExit {
+		t.Errorf("exit kind mismatch: have %v %v, want %v true", kind, ok, validatorOpExit)
+	}
+	if kind, ok := getValidatorOpKind(validatorOpTransaction(t, 0, withdrawal, key), &testValidatorsAddress); !ok || kind != validatorOpWithdrawal {
+		t.Errorf("withdrawal kind mismatch: have %v %v, want %v true", kind, ok, validatorOpWithdrawal)
+	}
+	if _, ok := getValidatorOpKind(transaction(0, 100000, key), &testValidatorsAddress); ok {
+		t.Errorf("regular transaction detected as validator operation")
+	}
+	if _, ok := getValidatorOpKind(validatorOpTransaction(t, 0, exit, key), nil); ok {
+		t.Errorf("validator operation detected without validators address")
+	}
+	empty, _ := types.SignTx(types.NewTransaction(0, testValidatorsAddress, big.NewInt(0), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if _, ok := getValidatorOpKind(empty, &testValidatorsAddress); ok {
+		t.Errorf("empty data transaction detected as validator operation")
+	}
+	junk, _ := types.SignTx(types.NewTransaction(0, testValidatorsAddress, big.NewInt(0), 100000, big.NewInt(1), []byte{0xf4, 0x01, 0x02}), types.HomesteadSigner{}, key)
+	if _, ok := getValidatorOpKind(junk, &testValidatorsAddress); ok {
+		t.Errorf("undecodable transaction detected as validator operation")
+	}
+}
+
+func TestValidatorOpSetExitReserve(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	set := newValidatorOpSet(4)
+
+	// Three slots are available for the operations other than exits
+	for i := uint64(0); i < 3; i++ {
+		if !set.hasRoom(validatorOpDeposit) {
+			t.Fatalf("no room for deposit %d", i)
+		}
+		set.add(transaction(i, 100000, key), from, validatorOpDeposit)
+	}
+	if set.hasRoom(validatorOpDeposit) {
+		t.Fatalf("deposit took the slot reserved for exits")
+	}
+	if !set.hasRoom(validatorOpExit) {
+		t.Fatalf("exit starved by deposits")
+	}
+	exit := transaction(3, 100000, key)
+	set.add(exit, from, validatorOpExit)
+	if set.hasRoom(validatorOpExit) {
+		t.Fatalf("room left in the full set")
+	}
+	// Exits are split from the others
+	exits, others := set.hashes()
+	if _, ok := exits[exit.Hash()]; !ok || len(exits) != 1 {
+		t.Errorf("exit is not prioritized")
+	}
+	if len(others) != 3 {
+		t.Errorf("others mismatch: have %d, want %d", len(others), 3)
+	}
+	// Operations which left the pool are released
+	all := newTxLookup()
+	all.Add(exit, true)
+	set.prune(all)
+	if set.len() != 1 || !set.has(exit.Hash()) {
+		t.Fatalf("prune mismatch: have %d operations", set.len())
+	}
+	if !set.hasRoom(validatorOpDeposit) {
+		t.Fatalf("no room for deposit after prune")
+	}
+}
+
+func TestValidatorOpQueueTruncation(t *testing.T) {
+	pool, key := setupTxPool()
+	defer pool.Stop()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	// Queue the regular transactions followed by a validator operation
+	pool.config.GlobalQueue = 1
+	var valOp *types.Transaction
+	for i := uint64(1); i <= 4; i++ {
+		tx := transaction(i, 100000, key)
+		if _, err := pool.enqueueTx(tx.Hash(), tx, false, true); err != nil {
+			t.Fatalf("failed to enqueue transaction %d: %v", i, err)
+		}
+		valOp = tx
+	}
+	pool.valOps.add(valOp, from, validatorOpDeposit)
+
+	// Only the regular transactions of the sender are dropped
+	pool.truncateQueue()
+	if have := pool.queue[from].Len(); have != 1 {
+		t.Fatalf("queued transactions mismatch: have %d, want %d", have, 1)
+	}
+	if pool.all.Get(valOp.Hash()) == nil {
+		t.Errorf("validator operation dropped")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"io"
+	"os"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rlp"
+)
+
+// txSnapshot is a dump of the whole transaction pool, including the remote
+// pending, queued and processing transactions, with the aim to warm up the
+// pool on node restarts.
+type txSnapshot struct {
+	path string // Filesystem path to store the transactions at
+}
+
+// newTxSnapshot creates a new transaction pool snapshot.
+func newTxSnapshot(path string) *txSnapshot {
+	return &txSnapshot{
+		path: path,
+	}
+}
+
+// load parses a transaction pool snapshot from disk, injecting its contents
+// into the specified pool. The transactions are revalidated by the pool, so
+// the stale ones are dropped.
+func (snap *txSnapshot) load(add func([]*types.Transaction) []error) error {
+	// Skip the parsing if the snapshot file doesn't exist at all
+	if _, err := os.Stat(snap.path); os.IsNotExist(err) {
+		return nil
+	}
+	input, err := os.Open(snap.path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	stream := rlp.NewStream(input, 0)
+	total, dropped := 0, 0
+
+	loadBatch := func(txs types.Transactions) {
+		for _, err := range add(txs) {
+			if err != nil {
+				log.Debug("Failed to add snapshot transaction", "err", err)
+				dropped++
+			}
+		}
+	}
+	var (
+		failure error
+		batch   types.Transactions
+	)
+	for {
+		tx := new(types.Transaction)
+		if err = stream.Decode(tx); err != nil {
+			if err != io.EOF {
+				failure = err
+			}
+			if batch.Len() > 0 {
+				loadBatch(batch)
+			}
+			break
+		}
+		total++
+
+		if batch = append(batch, tx); batch.Len() > 1024 {
+			loadBatch(batch)
+			batch = batch[:0]
+		}
+	}
+	log.Info("Loaded transaction pool snapshot", "transactions", total, "dropped", dropped)
+
+	return failure
+}
+
+// write regenerates the transaction pool snapshot based on the specified
+// transactions, replacing the previous one atomically.
+func (snap *txSnapshot) write(all map[common.Address]types.Transactions) error {
+	replacement, err := os.OpenFile(snap.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	written := 0
+	for _, txs := range all {
+		for _, tx := range txs {
+			if err = rlp.Encode(replacement, tx); err != nil {
+				replacement.Close()
+				return err
+			}
+		}
+		written += len(txs)
+	}
+	if err = replacement.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(snap.path+".new", snap.path); err != nil {
+		return err
+	}
+	log.Info("Regenerated transaction pool snapshot", "transactions", written, "accounts", len(all))
+
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
 )
 
 // Checkpoint represents a coordinated checkpoint
@@ -200,12 +201,19 @@ func (vs *ValidatorSync) Print() string {
 	)
 }
 
+// Key returns the key of the validator sync operation.
 func (vs *ValidatorSync) Key() common.Hash {
 	var key common.Hash
 	if vs == nil {
 		return key
 	}
-	return vs.InitTxHash
+	return ValidatorSyncKey(vs.InitTxHash, vs.Creator)
+}
+
+// ValidatorSyncKey returns the key of the validator sync operation.
+// The operations initiated by the same tx (e.g. batch deposit) differ by creator.
+func ValidatorSyncKey(initTxHash common.Hash, creator common.Address) common.Hash {
+	return crypto.Keccak256Hash(initTxHash.Bytes(), creator.Bytes())
 }
 
 func (vs *ValidatorSync) MarshalJSON() ([]byte, error) {
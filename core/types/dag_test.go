@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
 )
 
 func TestValidatorSync_Copy(t *testing.T) {
@@ -67,8 +68,11 @@ func TestValidatorSync_Key(t *testing.T) {
 	}
 	src_1.Amount.SetString("32789456000000", 10)
 
-	want := common.Hash{0x01, 0x02, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	want := crypto.Keccak256Hash(src_1.InitTxHash.Bytes(), src_1.Creator.Bytes())
+
+	// the operation of another creator initiated by the same tx
+	src_2 := src_1.Copy()
+	src_2.Creator = common.Address{0x11}
 
 	tests := []struct {
 		name string
@@ -80,6 +84,11 @@ func TestValidatorSync_Key(t *testing.T) {
 			src:  src_1,
 			want: want,
 		},
+		{
+			name: "same init tx",
+			src:  src_2,
+			want: crypto.Keccak256Hash(src_1.InitTxHash.Bytes(), common.Address{0x11}.Bytes()),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
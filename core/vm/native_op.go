@@ -0,0 +1,252 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vm
+
+import (
+	"math/big"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+)
+
+// Native operation types.
+const (
+	NativeOpToken     = "TOKEN"
+	NativeOpValidator = "VALIDATOR"
+)
+
+// NativeOpLogger is implemented by the EVMLoggers which capture the native
+// token and validator operations. These operations are executed by the token
+// and validator processors outside of the EVM, so none of the EVMLogger hooks
+// are called for them.
+type NativeOpLogger interface {
+	CaptureNativeOp(env *EVM, frame *NativeOpFrame)
+}
+
+// NativeStorageAccess is a read or a write of a field of the native storage.
+type NativeStorageAccess struct {
+	Field string      `json:"field"`
+	Key   interface{} `json:"key,omitempty"`
+	Value interface{} `json:"value"`
+	Write bool        `json:"write"`
+}
+
+// NativeStateChange is a change of the native state, e.g. of the validator info.
+type NativeStateChange struct {
+	Kind    string         `json:"kind"`
+	Address common.Address `json:"address"`
+	Before  interface{}    `json:"before,omitempty"`
+	After   interface{}    `json:"after,omitempty"`
+}
+
+// NativeBalanceChange is a movement of the balance of the account.
+type NativeBalanceChange struct {
+	Address common.Address `json:"address"`
+	Delta   *hexutil.Big   `json:"delta"`
+}
+
+// NativeAccountState is the state of the account before it was touched by
+// the native operation for the first time.
+type NativeAccountState struct {
+	Exist   bool
+	Balance *big.Int
+	Nonce   uint64
+	Storage map[common.Hash]common.Hash
+}
+
+// NativeOpFrame is the trace frame of a native token or validator operation.
+type NativeOpFrame struct {
+	Type         string                 `json:"type"`
+	Op           string                 `json:"op"`
+	Args         map[string]interface{} `json:"args,omitempty"`
+	From         common.Address         `json:"from"`
+	To           common.Address         `json:"to"`
+	Value        *hexutil.Big           `json:"value"`
+	Gas          hexutil.Uint64         `json:"gas"`
+	GasUsed      hexutil.Uint64         `json:"gasUsed"`
+	Input        hexutil.Bytes          `json:"input"`
+	Output       hexutil.Bytes          `json:"output,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+	Storage      []*NativeStorageAccess `json:"storage,omitempty"`
+	StateChanges []*NativeStateChange   `json:"stateChanges,omitempty"`
+	Balances     []*NativeBalanceChange `json:"balances,omitempty"`
+	Logs         []*types.Log           `json:"logs,omitempty"`
+
+	prestate map[common.Address]*NativeAccountState
+}
+
+// NewNativeOpFrame creates the trace frame of the native operation.
+func NewNativeOpFrame(typ string, from, to common.Address, input []byte, gas uint64, value *big.Int) *NativeOpFrame {
+	frame := &NativeOpFrame{
+		Type:     typ,
+		From:     from,
+		To:       to,
+		Input:    common.CopyBytes(input),
+		Gas:      hexutil.Uint64(gas),
+		prestate: make(map[common.Address]*NativeAccountState),
+	}
+	if value != nil {
+		frame.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	return frame
+}
+
+// SetOp sets the decoded operation of the frame.
+func (f *NativeOpFrame) SetOp(op string, args map[string]interface{}) {
+	f.Op = op
+	f.Args = args
+}
+
+// CaptureStorage records a read or a write of a field of the native storage.
+func (f *NativeOpFrame) CaptureStorage(field string, key, value interface{}, write bool) {
+	f.Storage = append(f.Storage, &NativeStorageAccess{Field: field, Key: key, Value: value, Write: write})
+}
+
+// CaptureStateChange records a change of the native state.
+func (f *NativeOpFrame) CaptureStateChange(kind string, address common.Address, before, after interface{}) {
+	f.StateChanges = append(f.StateChanges, &NativeStateChange{Kind: kind, Address: address, Before: before, After: after})
+}
+
+// End records the result of the native operation.
+func (f *NativeOpFrame) End(output []byte, gasUsed uint64, err error) {
+	f.Output = common.CopyBytes(output)
+	f.GasUsed = hexutil.Uint64(gasUsed)
+	if err != nil {
+		f.Error = err.Error()
+	}
+}
+
+// Prestate returns the state of the accounts touched by the native operation
+// as it was before the operation.
+func (f *NativeOpFrame) Prestate() map[common.Address]*NativeAccountState {
+	return f.prestate
+}
+
+// nativeOpMark is the length of the frame journals at a state snapshot.
+type nativeOpMark struct {
+	storage, stateChanges, balances, logs int
+}
+
+// nativeOpStateDB is a StateDB which records the balance movements, the
+// emitted logs and the prestate of the touched accounts into the frame.
+type nativeOpStateDB struct {
+	StateDB
+	frame *NativeOpFrame
+	marks map[int]nativeOpMark
+}
+
+// NewNativeOpStateDB wraps the StateDB to record the effects of the native
+// operation into the frame.
+func NewNativeOpStateDB(db StateDB, frame *NativeOpFrame) StateDB {
+	return &nativeOpStateDB{
+		StateDB: db,
+		frame:   frame,
+		marks:   make(map[int]nativeOpMark),
+	}
+}
+
+func (s *nativeOpStateDB) touch(addr common.Address) *NativeAccountState {
+	if acc, ok := s.frame.prestate[addr]; ok {
+		return acc
+	}
+	acc := &NativeAccountState{
+		Exist:   s.StateDB.Exist(addr),
+		Balance: new(big.Int).Set(s.StateDB.GetBalance(addr)),
+		Nonce:   s.StateDB.GetNonce(addr),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+	s.frame.prestate[addr] = acc
+	return acc
+}
+
+func (s *nativeOpStateDB) touchSlot(addr common.Address, key common.Hash) {
+	acc := s.touch(addr)
+	if _, ok := acc.Storage[key]; !ok {
+		acc.Storage[key] = s.StateDB.GetState(addr, key)
+	}
+}
+
+func (s *nativeOpStateDB) CreateAccount(addr common.Address) {
+	s.touch(addr)
+	s.StateDB.CreateAccount(addr)
+}
+
+func (s *nativeOpStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	s.touch(addr)
+	s.frame.Balances = append(s.frame.Balances, &NativeBalanceChange{
+		Address: addr,
+		Delta:   (*hexutil.Big)(new(big.Int).Neg(amount)),
+	})
+	s.StateDB.SubBalance(addr, amount)
+}
+
+func (s *nativeOpStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	s.touch(addr)
+	s.frame.Balances = append(s.frame.Balances, &NativeBalanceChange{
+		Address: addr,
+		Delta:   (*hexutil.Big)(new(big.Int).Set(amount)),
+	})
+	s.StateDB.AddBalance(addr, amount)
+}
+
+func (s *nativeOpStateDB) SetNonce(addr common.Address, nonce uint64) {
+	s.touch(addr)
+	s.StateDB.SetNonce(addr, nonce)
+}
+
+func (s *nativeOpStateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	s.touchSlot(addr, key)
+	return s.StateDB.GetState(addr, key)
+}
+
+func (s *nativeOpStateDB) SetState(addr common.Address, key, value common.Hash) {
+	s.touchSlot(addr, key)
+	s.StateDB.SetState(addr, key, value)
+}
+
+func (s *nativeOpStateDB) AddLog(log *types.Log) {
+	s.StateDB.AddLog(log)
+	s.frame.Logs = append(s.frame.Logs, log)
+}
+
+func (s *nativeOpStateDB) Snapshot() int {
+	id := s.StateDB.Snapshot()
+	s.marks[id] = nativeOpMark{
+		storage:      len(s.frame.Storage),
+		stateChanges: len(s.frame.StateChanges),
+		balances:     len(s.frame.Balances),
+		logs:         len(s.frame.Logs),
+	}
+	return id
+}
+
+// RevertToSnapshot also drops the effects recorded after the snapshot,
+// the reads of the storage are kept as they happened anyway.
+func (s *nativeOpStateDB) RevertToSnapshot(id int) {
+	s.StateDB.RevertToSnapshot(id)
+	if mark, ok := s.marks[id]; ok {
+		reads := s.frame.Storage[:mark.storage]
+		for _, access := range s.frame.Storage[mark.storage:] {
+			if !access.Write {
+				reads = append(reads, access)
+			}
+		}
+		s.frame.Storage = reads
+		s.frame.StateChanges = s.frame.StateChanges[:mark.stateChanges]
+		s.frame.Balances = s.frame.Balances[:mark.balances]
+		s.frame.Logs = s.frame.Logs[:mark.logs]
+	}
+}
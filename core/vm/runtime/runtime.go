@@ -55,19 +55,21 @@ type Config struct {
 func setDefaults(cfg *Config) {
 	if cfg.ChainConfig == nil {
 		cfg.ChainConfig = &params.ChainConfig{
-			ChainID:                big.NewInt(1),
-			SecondsPerSlot:         4,
-			SlotsPerEpoch:          32,
-			EffectiveBalance:       big.NewInt(3200),
-			ValidatorOpExpireSlots: 14400,
-			ForkSlotSubNet1:        math.MaxUint64,
-			ForkSlotDelegate:       0,
-			ForkSlotPrefixFin:      0,
-			ForkSlotShanghai:       0,
-			ForkSlotValOpTracking:  0,
-			ForkSlotReduceBaseFee:  0,
-			ForkSlotValSyncProc:    0,
-			StartEpochsPerEra:      0,
+			ChainID:                     big.NewInt(1),
+			SecondsPerSlot:              4,
+			SlotsPerEpoch:               32,
+			EffectiveBalance:            big.NewInt(3200),
+			ValidatorOpExpireSlots:      14400,
+			ForkSlotSubNet1:             math.MaxUint64,
+			ForkSlotDelegate:            0,
+			ForkSlotPrefixFin:           0,
+			ForkSlotShanghai:            0,
+			ForkSlotValOpTracking:       0,
+			ForkSlotReduceBaseFee:       0,
+			ForkSlotValSyncProc:         0,
+			ForkSlotBatchDeposit:        0,
+			ForkSlotDelegatorWithdrawal: 0,
+			StartEpochsPerEra:           0,
 		}
 	}
 
@@ -0,0 +1,61 @@
+//go:build ((linux && amd64) || (linux && arm64) || (darwin && amd64) || (darwin && arm64) || (windows && amd64)) && !blst_disabled
+// +build linux,amd64 linux,arm64 darwin,amd64 darwin,arm64 windows,amd64
+// +build !blst_disabled
+
+package bls_sig
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// SecretKeyLength is the length of a serialized BLS secret key.
+const SecretKeyLength = 32
+
+// SecretKey used in the BLS signature scheme.
+type SecretKey struct {
+	p *blst.SecretKey
+}
+
+// RandKey creates a new BLS secret key from a random seed.
+func RandKey() (*SecretKey, error) {
+	var ikm [32]byte
+	if _, err := rand.Read(ikm[:]); err != nil {
+		return nil, err
+	}
+	sk := blst.KeyGen(ikm[:])
+	if sk == nil {
+		return nil, errors.New("could not generate secret key")
+	}
+	return &SecretKey{p: sk}, nil
+}
+
+// SecretKeyFromBytes creates a BLS secret key from a BigEndian byte slice.
+func SecretKeyFromBytes(privKey []byte) (*SecretKey, error) {
+	if len(privKey) != SecretKeyLength {
+		return nil, fmt.Errorf("secret key must be %d bytes", SecretKeyLength)
+	}
+	sk := new(blst.SecretKey).Deserialize(privKey)
+	if sk == nil {
+		return nil, errors.New("could not unmarshal bytes into secret key")
+	}
+	return &SecretKey{p: sk}, nil
+}
+
+// PublicKey obtains the public key corresponding to the secret key.
+func (s *SecretKey) PublicKey() *PublicKey {
+	return &PublicKey{p: new(blstPublicKey).From(s.p)}
+}
+
+// Sign a message using the secret key.
+func (s *SecretKey) Sign(msg []byte) *Signature {
+	return &Signature{s: new(blstSignature).Sign(s.p, msg, dst)}
+}
+
+// Marshal a secret key into a BigEndian byte slice.
+func (s *SecretKey) Marshal() []byte {
+	return s.p.Serialize()
+}
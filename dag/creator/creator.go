@@ -619,6 +619,12 @@ func (c *Creator) createNewBlock(coinbase common.Address, creators []common.Addr
 		return
 	}
 
+	// Validator operations go ahead of the regular transactions, exits first.
+	for _, valOpTxs := range c.splitValidatorOps(pendingTxs) {
+		if len(valOpTxs) > 0 {
+			c.appendTransactions(types.NewTransactionsByPriceAndNonce(c.current.signer, valOpTxs, header.BaseFee), header)
+		}
+	}
 	txs := types.NewTransactionsByPriceAndNonce(c.current.signer, pendingTxs, header.BaseFee)
 	if c.appendTransactions(txs, header) {
 		if len(syncData) > 0 && c.isAddressAssigned(coinbase, *c.bc.Config().ValidatorsStateAddress, creators) {
@@ -911,6 +917,44 @@ func (c *Creator) getPending(coinbase common.Address, creators []common.Address)
 	return pending
 }
 
+// splitValidatorOps moves the pending validator operations out of the pending
+// transactions and returns them grouped by priority: the senders of exits first,
+// then the others. Only the leading validator operations of each sender are
+// moved to keep the nonce order, the rest stays with the regular transactions.
+func (c *Creator) splitValidatorOps(pending map[common.Address]types.Transactions) [2]map[common.Address]types.Transactions {
+	exits, others := c.backend.TxPool().ValidatorOps()
+	tiers := [2]map[common.Address]types.Transactions{
+		make(map[common.Address]types.Transactions),
+		make(map[common.Address]types.Transactions),
+	}
+	for addr, txs := range pending {
+		n, hasExit := 0, false
+		for ; n < len(txs); n++ {
+			if _, ok := exits[txs[n].Hash()]; ok {
+				hasExit = true
+				continue
+			}
+			if _, ok := others[txs[n].Hash()]; !ok {
+				break
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		if hasExit {
+			tiers[0][addr] = txs[:n]
+		} else {
+			tiers[1][addr] = txs[:n]
+		}
+		if n == len(txs) {
+			delete(pending, addr)
+		} else {
+			pending[addr] = txs[n:]
+		}
+	}
+	return tiers
+}
+
 // isAddressAssigned checks if miner is allowed to add transaction from that address
 func (c *Creator) isAddressAssigned(coinbase common.Address, address common.Address, creators []common.Address) bool {
 	var creatorNr = int64(-1)
@@ -109,6 +109,8 @@ type ethDownloader interface {
 	OptimisticSpineSync(spines common.HashArray) error
 	MainSync(baseSpine common.Hash, spines common.HashArray) error
 	DagSync(baseSpine common.Hash, spines common.HashArray) error
+	CheckpointSync(cp *types.Checkpoint, cpEra *era.Era) error
+	SetTargetCheckpoint(cp *types.Checkpoint)
 	Terminate()
 }
 
@@ -245,6 +247,9 @@ func (d *Dag) HandleFinalize(data *types.FinalizationParams) *types.Finalization
 		return res
 	}
 
+	// the checkpoint is the sync target while the finalization is handled
+	d.downloader.SetTargetCheckpoint(data.Checkpoint)
+	defer d.downloader.SetTargetCheckpoint(nil)
 	switch data.SyncMode {
 	case types.NoSync:
 		if err = d.handleSyncUnloadedBlocks(baseSpine, spines, data.Checkpoint); err != nil {
@@ -553,6 +558,27 @@ func (d *Dag) HandleSyncSlotInfo(slotInfo types.SlotInfo) (bool, error) {
 	return false, nil
 }
 
+// HandleSyncCheckpoint starts the chain synchronization from the trusted checkpoint
+// given by coordinator instead of the replay of the finalized history.
+func (d *Dag) HandleSyncCheckpoint(cp *types.Checkpoint, cpEra *era.Era) (bool, error) {
+	log.Info("Handle sync checkpoint", "cp", cp, "era", cpEra)
+	if d.bc.GetSlotInfo() == nil {
+		return false, errors.New("no slot info")
+	}
+	if d.downloader.Synchronising() {
+		return false, errSynchronization
+	}
+
+	d.bc.DagMuLock()
+	defer d.bc.DagMuUnlock()
+
+	if err := d.downloader.CheckpointSync(cp, cpEra); err != nil {
+		log.Error("Handle sync checkpoint: failed", "err", err)
+		return false, err
+	}
+	return true, nil
+}
+
 // HandleValidateFinalization validate given spines sequence of finalization.
 // Checks existence and order by slot of finalization sequence.
 func (d *Dag) HandleValidateFinalization(spines common.HashArray) (bool, error) {
@@ -719,6 +719,20 @@ func (m *MockethDownloader) EXPECT() *MockethDownloaderMockRecorder {
 	return m.recorder
 }
 
+// CheckpointSync mocks base method.
+func (m *MockethDownloader) CheckpointSync(cp *types.Checkpoint, cpEra *era.Era) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckpointSync", cp, cpEra)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckpointSync indicates an expected call of CheckpointSync.
+func (mr *MockethDownloaderMockRecorder) CheckpointSync(cp, cpEra interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckpointSync", reflect.TypeOf((*MockethDownloader)(nil).CheckpointSync), cp, cpEra)
+}
+
 // DagSync mocks base method.
 func (m *MockethDownloader) DagSync(baseSpine common.Hash, spines common.HashArray) error {
 	m.ctrl.T.Helper()
@@ -761,6 +775,18 @@ func (mr *MockethDownloaderMockRecorder) OptimisticSpineSync(spines interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OptimisticSpineSync", reflect.TypeOf((*MockethDownloader)(nil).OptimisticSpineSync), spines)
 }
 
+// SetTargetCheckpoint mocks base method.
+func (m *MockethDownloader) SetTargetCheckpoint(cp *types.Checkpoint) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTargetCheckpoint", cp)
+}
+
+// SetTargetCheckpoint indicates an expected call of SetTargetCheckpoint.
+func (mr *MockethDownloaderMockRecorder) SetTargetCheckpoint(cp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTargetCheckpoint", reflect.TypeOf((*MockethDownloader)(nil).SetTargetCheckpoint), cp)
+}
+
 // Synchronising mocks base method.
 func (m *MockethDownloader) Synchronising() bool {
 	m.ctrl.T.Helper()
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"bufio"
 	"compress/gzip"
 	"context"
 	"errors"
@@ -176,12 +177,12 @@ func (api *PrivateAdminAPI) ExportChain(file string, first *uint64, last *uint64
 		defer writer.(*gzip.Writer).Close()
 	}
 
-	// Export the blockchain
+	// Export the finalized blockchain with the dag metadata
+	from, to := uint64(0), api.eth.BlockChain().GetLastFinalizedBlock().Nr()
 	if first != nil {
-		if err := api.eth.BlockChain().ExportN(writer, *first, *last); err != nil {
-			return false, err
-		}
-	} else if err := api.eth.BlockChain().Export(writer); err != nil {
+		from, to = *first, *last
+	}
+	if err := api.eth.BlockChain().ExportDag(writer, from, to); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -213,8 +214,16 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 		}
 	}
 
-	// Run actual the import in pre-configured batches
-	stream := rlp.NewStream(reader, 0)
+	// Import the dag export with its metadata
+	br := bufio.NewReader(reader)
+	if core.IsDagExport(br) {
+		if n, err := api.eth.BlockChain().ImportDag(br, nil); err != nil {
+			return false, fmt.Errorf("block %d: failed to import: %v", n, err)
+		}
+		return true, nil
+	}
+	// Run actual the import of the legacy export in pre-configured batches
+	stream := rlp.NewStream(br, 0)
 
 	blocks, index := make([]*types.Block, 0, 2500), 0
 	for batch := 0; ; batch++ {
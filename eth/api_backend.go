@@ -99,10 +99,17 @@ func (b *EthAPIBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumb
 		return block.Header(), nil
 	}
 	// Otherwise resolve and return the block
-	if number == rpc.LatestBlockNumber {
+	if number == rpc.LatestBlockNumber || number == rpc.FinalizedBlockNumber {
 		bl := b.eth.blockchain.GetLastFinalizedBlock()
 		return bl.Header(), nil
 	}
+	if number == rpc.CheckpointBlockNumber {
+		cp := b.eth.blockchain.GetLastCoordinatedCheckpoint()
+		if cp == nil {
+			return nil, errors.New("current cp not found")
+		}
+		return b.eth.blockchain.GetHeaderByHash(cp.Spine), nil
+	}
 	return b.eth.blockchain.GetHeaderByNumber(uint64(number)), nil
 }
 
@@ -137,10 +144,17 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 		return block, nil
 	}
 	// Otherwise resolve and return the block
-	if number == rpc.LatestBlockNumber {
+	if number == rpc.LatestBlockNumber || number == rpc.FinalizedBlockNumber {
 		bl := b.eth.blockchain.GetLastFinalizedBlock()
 		return bl, nil
 	}
+	if number == rpc.CheckpointBlockNumber {
+		cp := b.eth.blockchain.GetLastCoordinatedCheckpoint()
+		if cp == nil {
+			return nil, errors.New("current cp not found")
+		}
+		return b.eth.blockchain.GetBlockByHash(cp.Spine), nil
+	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
 }
 
@@ -176,22 +190,6 @@ func (b *EthAPIBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts)
 }
 
 func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
-	// Pending state is only known by the miner
-	if number == rpc.CheckpointBlockNumber {
-		cp := b.eth.blockchain.GetLastCoordinatedCheckpoint()
-		if cp == nil {
-			return nil, nil, errors.New("current cp not found")
-		}
-		header, err := b.HeaderByHash(ctx, cp.Spine)
-		if err != nil {
-			return nil, nil, err
-		}
-		if header == nil {
-			return nil, nil, errors.New("header not found")
-		}
-		stateDb, err := b.eth.BlockChain().StateAt(header.Root)
-		return stateDb, header, err
-	}
 	// Pending state is only known by the miner
 	if number == rpc.PendingBlockNumber {
 		block, state := b.eth.dag.Creator().Pending()
@@ -332,6 +330,15 @@ func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactio
 	return b.eth.TxPool().ContentFrom(addr)
 }
 
+func (b *EthAPIBackend) TxPoolStatus(txHash common.Hash) (core.TxStatus, common.HashArray) {
+	pool := b.eth.TxPool()
+	status := pool.Status([]common.Hash{txHash})[0]
+	if status == core.TxStatusProcessing {
+		return status, pool.ProcessingBlocks(txHash)
+	}
+	return status, nil
+}
+
 func (b *EthAPIBackend) TxPool() *core.TxPool {
 	return b.eth.TxPool()
 }
@@ -348,10 +355,14 @@ func (b *EthAPIBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error)
 	return b.gpo.SuggestTipCap(ctx)
 }
 
-func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (firstBlock *big.Int, reward [][]*big.Int, baseFee []*big.Int, gasUsedRatio []float64, err error) {
+func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (firstBlock *big.Int, reward [][]*big.Int, baseFee []*big.Int, gasUsedRatio []float64, congestion []float64, err error) {
 	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
 }
 
+func (b *EthAPIBackend) FeeHistoryBySlot(ctx context.Context, slotCount int, lastSlot *uint64, rewardPercentiles []float64) ([]*gasprice.SlotFees, *big.Int, error) {
+	return b.gpo.FeeHistoryBySlot(ctx, slotCount, lastSlot, rewardPercentiles)
+}
+
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
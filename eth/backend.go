@@ -38,6 +38,7 @@ import (
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/ethconfig"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/filters"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/gasprice"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/lightclient"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/snap"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
@@ -52,6 +53,7 @@ import (
 	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/token"
 	val "gitlab.waterfall.network/waterfall/protocol/gwat/validator"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/validatorsync"
 )
 
 // Config contains the configuration options of the ETH protocol.
@@ -98,9 +100,6 @@ type Ethereum struct {
 // initialisation of the common Ethereum object)
 func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	// Ensure configuration values are compatible and sane
-	if config.SyncMode == downloader.LightSync {
-		return nil, errors.New("can't run eth.Ethereum in light sync mode, use les.LightEthereum")
-	}
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
@@ -124,6 +123,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	rawdb.SetFreezerEraThreshold(chainDb, config.AncientEras)
 	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlockWithOverride(chainDb, config.Genesis, config.OverrideDelegatingStake, config.OverridePrefixFin, config.IsTestnet5, config.IsTestnet9)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -178,6 +178,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
+			DagPruneInterval:    config.DagPruneInterval,
 		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, vmConfig, &config.TxLookupLimit)
@@ -205,6 +206,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.ValidatorJournal != "" {
+		config.TxPool.ValidatorJournal = stack.ResolvePath(config.TxPool.ValidatorJournal)
+	}
+	if config.TxPool.Snapshot != "" {
+		config.TxPool.Snapshot = stack.ResolvePath(config.TxPool.Snapshot)
+	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
@@ -223,6 +230,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		EventMux:   eth.eventMux,
 		Checkpoint: checkpoint,
 		Whitelist:  config.Whitelist,
+
+		SyncCheckpoint:      config.SyncCheckpoint,
+		TxCreatorsBroadcast: config.TxCreatorsBroadcast,
+		BanPeer:             eth.p2pServer.BanPeer,
 	}); err != nil {
 		return nil, err
 	}
@@ -334,6 +345,14 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append validator APIs
 	apis = append(apis, val.GetAPIs(s.APIBackend, s.blockchain)...)
 
+	// Append validator sync APIs
+	apis = append(apis, validatorsync.GetAPIs(s)...)
+
+	// Append light client APIs
+	if s.handler.lightClient != nil {
+		apis = append(apis, lightclient.GetAPIs(s.handler.lightClient)...)
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -351,6 +370,11 @@ func (s *Ethereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   downloader.NewPublicDownloaderAPI(s.handler.downloader, s.eventMux),
 			Public:    true,
+		}, {
+			Namespace: "wat",
+			Version:   "1.0",
+			Service:   downloader.NewPublicSyncAPI(s.handler.downloader, s.eventMux),
+			Public:    true,
 		}, {
 			Namespace: "miner",
 			Version:   "1.0",
@@ -421,6 +445,10 @@ func (s *Ethereum) SetEtherbase(etherbase common.Address) {
 // is already running, this method adjust the number of threads allowed to use
 // and updates the minimum price required by the transaction pool.
 func (s *Ethereum) StartMining(threads int) error {
+	// The light client has no full state to create blocks
+	if s.handler.lightClient != nil {
+		return errors.New("can't create blocks in light sync mode")
+	}
 	// If the creator was not running, initialize it
 	if !s.dag.Creator().IsRunning() && len(s.AccountManager().Accounts()) > 0 {
 		// Propagate the initial price point to the transaction pool
@@ -0,0 +1,119 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"sync"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+)
+
+// creatorBroadcastSlots is the number of upcoming slots whose assigned
+// creators receive the transactions directly.
+const creatorBroadcastSlots = 2
+
+// creatorPeer is the peer which delivered the most recent block of a creator.
+type creatorPeer struct {
+	id   string
+	slot uint64
+}
+
+// creatorPeerSet tracks the peers known to be the nodes of creators (or the
+// closest hop to them). The peer which first delivers the block of a creator
+// for a new slot is assumed to be the closest one.
+type creatorPeerSet struct {
+	peers map[common.Address]creatorPeer
+	lock  sync.RWMutex
+}
+
+// newCreatorPeerSet creates a new set to track the peers of creators.
+func newCreatorPeerSet() *creatorPeerSet {
+	return &creatorPeerSet{
+		peers: make(map[common.Address]creatorPeer),
+	}
+}
+
+// observe records the peer which delivered the block of the creator.
+func (s *creatorPeerSet) observe(creator common.Address, id string, slot uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if known, ok := s.peers[creator]; ok && known.slot >= slot {
+		return
+	}
+	s.peers[creator] = creatorPeer{id: id, slot: slot}
+}
+
+// peer returns the id of the peer of the creator.
+func (s *creatorPeerSet) peer(creator common.Address) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	known, ok := s.peers[creator]
+	return known.id, ok
+}
+
+// assignedCreatorPeers returns the peers of the creators assigned to include
+// the transactions in the upcoming slots, mapped to the transaction hashes.
+func (h *handler) assignedCreatorPeers(txs types.Transactions) map[*ethPeer][]common.Hash {
+	slotInfo := h.chain.GetSlotInfo()
+	if slotInfo == nil {
+		return nil
+	}
+	currentSlot := slotInfo.CurrentSlot()
+	lastEpoch := h.chain.GetEraInfo().ToEpoch()
+	slotsCreators := make([][]common.Address, 0, creatorBroadcastSlots)
+	for slot := currentSlot + 1; slot <= currentSlot+creatorBroadcastSlots; slot++ {
+		// creators of the next era are not known yet
+		if slotInfo.SlotToEpoch(slot) > lastEpoch {
+			break
+		}
+		creators, err := h.chain.ValidatorStorage().GetCreatorsBySlot(h.chain, slot)
+		if err != nil {
+			log.Debug("Failed to get creators of upcoming slot", "slot", slot, "err", err)
+			continue
+		}
+		slotsCreators = append(slotsCreators, creators)
+	}
+	signer := types.LatestSigner(h.chain.Config())
+	assigned := make(map[*ethPeer][]common.Hash)
+	for _, tx := range txs {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		sent := make(map[*ethPeer]bool)
+		for _, creators := range slotsCreators {
+			creator, ok := core.AssignedCreator(from, creators)
+			if !ok {
+				continue
+			}
+			id, ok := h.creatorPeers.peer(creator)
+			if !ok {
+				continue
+			}
+			peer := h.peers.peer(id)
+			if peer == nil || sent[peer] || peer.KnownTransaction(tx.Hash()) {
+				continue
+			}
+			sent[peer] = true
+			assigned[peer] = append(assigned[peer], tx.Hash())
+		}
+	}
+	return assigned
+}
@@ -0,0 +1,165 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"sync/atomic"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+)
+
+// syncWithPeerMissingAncestors retrieves the unknown blocks with all missing
+// ancestors down to the last coordinated checkpoint.
+// Unlike syncWithPeerUnknownBlocksWithParents, which walks the dag one level
+// per round-trip, the remote peer collects all ancestors in a single response,
+// so the next request is performed only if the response limit was reached.
+func (d *Downloader) syncWithPeerMissingAncestors(p *peerConnection, hashes common.HashArray) (err error) {
+	// Make sure only one goroutine is ever allowed past this point at once
+	if !atomic.CompareAndSwapInt32(&d.dagSyncing, 0, 1) {
+		return errBusy
+	}
+
+	iterCount := 0
+	defer func(start time.Time) {
+		atomic.StoreInt32(&d.dagSyncing, 0)
+		log.Info("Sync missing ancestors: end",
+			"elapsed", time.Since(start),
+			"iterCount", iterCount,
+		)
+	}(time.Now())
+
+	checkpoint := d.blockchain.GetLastCoordinatedCheckpoint()
+	if checkpoint == nil {
+		return errInvalidAncestor
+	}
+
+	hashes.Deduplicate()
+	delayed := d.blockchain.GetInsertDelayedHashes()
+	loaded := make(map[common.Hash]bool, len(delayed))
+	for _, h := range delayed {
+		loaded[h] = true
+	}
+	reqHashes := d.filterUnloaded(hashes, loaded)
+	if len(reqHashes) == 0 {
+		return nil
+	}
+
+	blocks := make(types.Blocks, 0, len(reqHashes))
+	for len(reqHashes) > 0 {
+		fetched, err := d.fetchMissingAncestors(p, reqHashes, checkpoint.Spine)
+		if err != nil {
+			log.Error("Sync missing ancestors: fetch failed", "i", iterCount, "err", err, "reqHashes", reqHashes)
+			return err
+		}
+		if len(fetched) == 0 {
+			log.Warn("Sync missing ancestors: no blocks fetched", "i", iterCount, "reqHashes", reqHashes)
+			break
+		}
+		parents := make(common.HashArray, 0, len(fetched))
+		for _, block := range fetched {
+			// quick body validation
+			if block.BodyHash() != block.Body().CalculateHash() {
+				log.Error("Sync missing ancestors: bad block bodyHash",
+					"i", iterCount,
+					"hash", block.Hash().Hex(),
+					"bodyHash", block.BodyHash().Hex(),
+					"calcBodyHash", block.Body().CalculateHash().Hex(),
+					"err", errInvalidBody,
+				)
+				return errInvalidBody
+			}
+			hash := block.Hash()
+			if loaded[hash] {
+				continue
+			}
+			loaded[hash] = true
+			blocks = append(blocks, block)
+			parents = append(parents, block.ParentHashes()...)
+		}
+		log.Info("Sync missing ancestors: blocks fetched", "i", iterCount, "fetched", len(fetched), "blocks", len(blocks))
+
+		// request the rest, if the response was truncated by the limits of the remote peer
+		reqHashes = d.filterUnloaded(parents.Uniq(), loaded)
+		iterCount++
+	}
+	log.Info("Sync missing ancestors: all blocks fetched", "blocks", len(blocks), "checkpoint", checkpoint.Spine.Hex())
+
+	return d.insertUnknownBlocks(blocks)
+}
+
+// filterUnloaded returns the hashes which are neither loaded nor stored in the chain.
+func (d *Downloader) filterUnloaded(hashes common.HashArray, loaded map[common.Hash]bool) common.HashArray {
+	unloaded := make(common.HashArray, 0, len(hashes))
+	for _, h := range hashes {
+		if h == (common.Hash{}) || loaded[h] {
+			continue
+		}
+		if d.blockchain.GetHeaderByHash(h) != nil {
+			loaded[h] = true
+			continue
+		}
+		unloaded = append(unloaded, h)
+	}
+	return unloaded
+}
+
+// fetchMissingAncestors retrieves the blocks with missing ancestors from a remote peer.
+func (d *Downloader) fetchMissingAncestors(p *peerConnection, hashes common.HashArray, checkpoint common.Hash) (types.Blocks, error) {
+	p.log.Info("Retrieving missing ancestors: start", "hashes", len(hashes), "checkpoint", checkpoint.Hex())
+
+	//multi peers sync support
+	d.setPeerSync(p.id)
+	defer d.resetPeerSync(p.id)
+
+	if err := p.peer.RequestMissingAncestors(hashes, checkpoint); err != nil {
+		return nil, err
+	}
+
+	ttl := d.peers.rates.TargetTimeout()
+	timeout := time.After(ttl)
+
+	var err error
+	for {
+		if err != nil {
+			log.Error("Sync: error while handling peer", "err", err, "peer", p.id, "fn", "fetchMissingAncestors")
+		}
+		err = nil
+		select {
+		case <-d.cancelCh:
+			return nil, errCanceled
+		case packet := <-d.dagCh:
+			err = d.redirectPacketToSyncPeerChan(packet, dagCh)
+		case packet := <-d.headerCh:
+			err = d.redirectPacketToSyncPeerChan(packet, headerCh)
+		case packet := <-d.bodyCh:
+			err = d.redirectPacketToSyncPeerChan(packet, bodyCh)
+		case packet := <-d.receiptCh:
+			err = d.redirectPacketToSyncPeerChan(packet, receiptCh)
+		case packet := <-d.ancestorsCh:
+			// Discard anything not from the origin peer
+			if packet.PeerId() != p.id {
+				log.Error("Sync: missing ancestors: received from incorrect peer", "packet.peer", packet.PeerId(), "peer", p.id)
+				break
+			}
+			return packet.(*ancestorsPack).blocks, nil
+		case <-timeout:
+			p.log.Debug("Waiting for missing ancestors timed out", "elapsed", ttl)
+			return nil, errTimeout
+		}
+	}
+}
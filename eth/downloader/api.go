@@ -164,3 +164,56 @@ func (api *PublicDownloaderAPI) SubscribeSyncStatus(status chan interface{}) *Sy
 	api.installSyncSubscription <- status
 	return &SyncStatusSubscription{api: api, c: status}
 }
+
+// PublicSyncAPI provides the dag-aware information about the synchronisation status.
+type PublicSyncAPI struct {
+	d   *Downloader
+	mux *event.TypeMux
+}
+
+// NewPublicSyncAPI creates a new PublicSyncAPI.
+func NewPublicSyncAPI(d *Downloader, m *event.TypeMux) *PublicSyncAPI {
+	return &PublicSyncAPI{d: d, mux: m}
+}
+
+// Syncing returns false in case the node is synchronised with the network,
+// otherwise it returns the current phase of the dag synchronisation, the current
+// and target slots, the number of spines remaining, the checkpoint being synced to
+// and the estimated time to finish the phase.
+func (api *PublicSyncAPI) Syncing() (interface{}, error) {
+	if api.d.blockchain.IsSynced() && !api.d.Synchronising() {
+		return false, nil
+	}
+	return api.d.SyncStatus(), nil
+}
+
+// SyncPhase provides notifications when the dag synchronisation enters or leaves a phase.
+func (api *PublicSyncAPI) SyncPhase(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		sub := api.mux.Subscribe(SyncPhaseEvent{})
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-sub.Chan():
+				if ev == nil {
+					return
+				}
+				notifier.Notify(rpcSub.ID, ev.Data.(SyncPhaseEvent).Status)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
+)
+
+// ErrInvalidSyncCheckpoint is returned if the checkpoint sync can't be started from the given checkpoint.
+var ErrInvalidSyncCheckpoint = errors.New("invalid sync checkpoint")
+
+// SyncCheckpoint is the trusted checkpoint with its era to start the checkpoint sync from.
+type SyncCheckpoint struct {
+	Checkpoint *types.Checkpoint `json:"checkpoint"`
+	Era        *era.Era          `json:"era"`
+}
+
+// CheckpointSync starts the chain from the trusted checkpoint instead of
+// the replay of the finalized history. It retrieves the states of the checkpoint
+// root and of its era root over the snap protocol, anchors the chain at the checkpoint spine
+// and then downloads the dag blocks after the checkpoint only.
+func (d *Downloader) CheckpointSync(cp *types.Checkpoint, cpEra *era.Era) error {
+	if err := d.validateSyncCheckpoint(cp, cpEra); err != nil {
+		return err
+	}
+	if d.blockchain.HasBlock(cp.Spine) && d.blockchain.GetBlockFinalizedNumber(cp.Spine) != nil {
+		log.Info("Checkpoint sync: checkpoint already reached", "spine", cp.Spine.Hex())
+		return nil
+	}
+	if d.peers.Len() == 0 {
+		return errNoPeers
+	}
+
+	d.SetTargetCheckpoint(cp)
+
+	if err := d.checkpointStateSync(cp, cpEra); err != nil {
+		return err
+	}
+	return d.DagSync(cp.Spine, nil)
+}
+
+// validateSyncCheckpoint checks the trusted checkpoint is consistent
+// and ahead of the local chain.
+func (d *Downloader) validateSyncCheckpoint(cp *types.Checkpoint, cpEra *era.Era) error {
+	if cp == nil || cpEra == nil {
+		return fmt.Errorf("%w: checkpoint and era required", ErrInvalidSyncCheckpoint)
+	}
+	if cp.Spine == (common.Hash{}) || cp.Root == (common.Hash{}) || cpEra.Root == (common.Hash{}) {
+		return fmt.Errorf("%w: empty spine or root", ErrInvalidSyncCheckpoint)
+	}
+	if !cpEra.IsContainsEpoch(cp.FinEpoch) {
+		return fmt.Errorf("%w: era %d does not contain epoch %d", ErrInvalidSyncCheckpoint, cpEra.Number, cp.FinEpoch)
+	}
+	if lcp := d.blockchain.GetLastCoordinatedCheckpoint(); lcp != nil && lcp.FinEpoch > cp.FinEpoch {
+		return fmt.Errorf("%w: behind the local chain: local=%d checkpoint=%d", ErrInvalidSyncCheckpoint, lcp.FinEpoch, cp.FinEpoch)
+	}
+	return nil
+}
+
+// checkpointStateSync retrieves the checkpoint spine, its state and
+// the state of the era root and anchors the local chain at it.
+func (d *Downloader) checkpointStateSync(cp *types.Checkpoint, cpEra *era.Era) (err error) {
+	if d.Synchronising() {
+		log.Warn("Checkpoint sync canceled (synchronise process busy)")
+		return errBusy
+	}
+	if !atomic.CompareAndSwapInt32(&d.finSyncing, 0, 1) {
+		return errBusy
+	}
+	defer func(start time.Time) {
+		atomic.StoreInt32(&d.finSyncing, 0)
+		log.Info("Checkpoint sync terminated", "elapsed", common.PrettyDuration(time.Since(start)), "err", err)
+	}(time.Now())
+
+	d.cancelLock.Lock()
+	d.cancelCh = make(chan struct{})
+	d.cancelLock.Unlock()
+	defer d.Cancel()
+
+	d.mux.Post(StartEvent{})
+	defer func() {
+		if err != nil {
+			d.mux.Post(FailedEvent{err})
+		} else {
+			d.mux.Post(DoneEvent{d.lightchain.GetLastFinalizedHeader()})
+		}
+	}()
+
+	spine, err := d.fetchCheckpointSpine(cp)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Checkpoint sync: state sync started", "spine", cp.Spine.Hex(), "root", cp.Root.Hex(), "finEpoch", cp.FinEpoch)
+	d.syncStatsLock.Lock()
+	d.syncStatsChainOrigin = d.blockchain.GetLastFinalizedNumber()
+	d.syncStatsChainHeight = spine.Nr()
+	d.syncStatsLock.Unlock()
+
+	// the state of the checkpoint is retrieved over the snap protocol only.
+	d.snapSync = true
+	if err = d.syncState(cp.Root).Wait(); err != nil {
+		log.Error("Checkpoint sync: state sync failed", "root", cp.Root.Hex(), "err", err)
+		return err
+	}
+	// the validators of the era are built from the state of the era root.
+	if cpEra.Root != cp.Root {
+		log.Info("Checkpoint sync: era state sync started", "era", cpEra.Number, "root", cpEra.Root.Hex())
+		if err = d.syncState(cpEra.Root).Wait(); err != nil {
+			log.Error("Checkpoint sync: era state sync failed", "era", cpEra.Number, "root", cpEra.Root.Hex(), "err", err)
+			return err
+		}
+	}
+
+	return d.blockchain.InsertCheckpointAnchor(cp, cpEra, spine)
+}
+
+// fetchCheckpointSpine retrieves the checkpoint spine block from the peers.
+func (d *Downloader) fetchCheckpointSpine(cp *types.Checkpoint) (*types.Block, error) {
+	for _, p := range d.peers.SyncPeers() {
+		header, err := d.fetchHeaderByHash(p, cp.Spine)
+		if err != nil {
+			p.log.Warn("Checkpoint sync: spine header retrieving failed", "spine", cp.Spine.Hex(), "err", err)
+			continue
+		}
+		if header.Hash() != cp.Spine || header.Nr() == 0 {
+			p.log.Warn("Checkpoint sync: bad spine header", "spine", cp.Spine.Hex(), "hash", header.Hash().Hex(), "nr", header.Nr())
+			continue
+		}
+		if header.Root != (common.Hash{}) && header.Root != cp.Root {
+			p.log.Warn("Checkpoint sync: spine root mismatch", "spine", cp.Spine.Hex(), "root", header.Root.Hex(), "cpRoot", cp.Root.Hex())
+			continue
+		}
+		txsMap, err := d.fetchDagTxs(p, common.HashArray{cp.Spine})
+		if err != nil {
+			p.log.Warn("Checkpoint sync: spine body retrieving failed", "spine", cp.Spine.Hex(), "err", err)
+			continue
+		}
+		block := types.NewBlockWithHeader(header).WithBody(txsMap[cp.Spine])
+		if block.BodyHash() != block.Body().CalculateHash() {
+			p.log.Warn("Checkpoint sync: bad spine body", "spine", cp.Spine.Hex(), "err", errInvalidBody)
+			continue
+		}
+		return block, nil
+	}
+	return nil, errPeersUnavailable
+}
@@ -0,0 +1,412 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	ethereum "gitlab.waterfall.network/waterfall/protocol/gwat"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+)
+
+// dagFetchKind represents the kind of dag data retrieved by a request.
+type dagFetchKind int
+
+const (
+	dagHeadersFetch dagFetchKind = iota
+	dagBodiesFetch
+)
+
+// dagFetchRequest is a currently running dag data retrieval operation.
+type dagFetchRequest struct {
+	kind   dagFetchKind
+	peer   *peerConnection
+	hashes common.HashArray // Requested hashes, sorted by request order
+	time   time.Time        // Time when the request was made
+}
+
+// dagFetchResponse is a result of the dag data retrieval operation.
+type dagFetchResponse struct {
+	req     *dagFetchRequest
+	headers []*types.Header
+	bodies  [][]*types.Transaction
+	err     error
+}
+
+// dagFetcher schedules the retrieval of the dag headers and bodies across
+// all suitable peers. Each peer serves one request at a time, the size of
+// requests is adjusted to the peer throughput and the items failed by a peer
+// are retried at the other peers.
+type dagFetcher struct {
+	d *Downloader
+
+	headerTasks common.HashArray // Hashes of the blocks to fetch headers for
+	bodyTasks   common.HashArray // Hashes of the blocks to fetch bodies for
+
+	results map[common.Hash]*fetchResult // Partial and completed retrieval results
+	origin  map[common.Hash]string       // Peers delivered the headers
+	pending map[string]*dagFetchRequest  // Currently pending requests by peers
+	deliver chan *dagFetchResponse       // Channel receiving finished requests
+}
+
+// newDagFetcher creates a scheduler to retrieve the blocks of the given hashes.
+// The receipts are not retrieved: they are produced by the blocks insertion.
+func newDagFetcher(d *Downloader, hashes common.HashArray) *dagFetcher {
+	return &dagFetcher{
+		d:           d,
+		headerTasks: hashes.Copy(),
+		results:     make(map[common.Hash]*fetchResult, len(hashes)),
+		origin:      make(map[common.Hash]string, len(hashes)),
+		pending:     make(map[string]*dagFetchRequest),
+	}
+}
+
+// run retrieves the dag data from the given peers until all items are
+// completed or no peer is able to serve the rest of them.
+func (f *dagFetcher) run(peers []*peerConnection) error {
+	f.deliver = make(chan *dagFetchResponse, len(peers))
+	for {
+		for _, p := range peers {
+			if _, busy := f.pending[p.id]; busy {
+				continue
+			}
+			req := f.reserve(p)
+			if req == nil {
+				continue
+			}
+			f.pending[p.id] = req
+			f.d.updateFetchProgress(p.id, func(progress *ethereum.PeerSyncProgress) {
+				progress.Pending = uint64(len(req.hashes))
+			})
+			go f.fetch(req)
+		}
+		if len(f.pending) == 0 {
+			if f.done() {
+				return nil
+			}
+			return errPeersUnavailable
+		}
+		select {
+		case <-f.d.cancelCh:
+			return errCanceled
+		case res := <-f.deliver:
+			delete(f.pending, res.req.peer.id)
+			f.process(res)
+		}
+	}
+}
+
+// done returns true if there are no more tasks to fetch.
+func (f *dagFetcher) done() bool {
+	return len(f.headerTasks) == 0 && len(f.bodyTasks) == 0
+}
+
+// reserve takes the next chunk of tasks the peer is able to serve,
+// the chunk size is limited by the peer capacity.
+func (f *dagFetcher) reserve(p *peerConnection) *dagFetchRequest {
+	targetRTT := f.d.peers.rates.TargetRoundTrip()
+
+	var (
+		kind  dagFetchKind
+		tasks *common.HashArray
+		limit int
+	)
+	switch {
+	case len(f.bodyTasks) > 0 && f.available(p, f.bodyTasks):
+		kind, tasks, limit = dagBodiesFetch, &f.bodyTasks, p.BlockCapacity(targetRTT)
+	case len(f.headerTasks) > 0 && f.available(p, f.headerTasks):
+		kind, tasks, limit = dagHeadersFetch, &f.headerTasks, p.HeaderCapacity(targetRTT)
+	default:
+		return nil
+	}
+	var (
+		hashes = make(common.HashArray, 0, limit)
+		rest   = make(common.HashArray, 0, len(*tasks))
+	)
+	for _, hash := range *tasks {
+		if len(hashes) < limit && !p.Lacks(hash) {
+			hashes = append(hashes, hash)
+			continue
+		}
+		rest = append(rest, hash)
+	}
+	*tasks = rest
+	return &dagFetchRequest{
+		kind:   kind,
+		peer:   p,
+		hashes: hashes,
+		time:   time.Now(),
+	}
+}
+
+// available returns true if the peer is not known to lack any of the tasks.
+func (f *dagFetcher) available(p *peerConnection, tasks common.HashArray) bool {
+	for _, hash := range tasks {
+		if !p.Lacks(hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch executes the request and delivers the response to the scheduler.
+func (f *dagFetcher) fetch(req *dagFetchRequest) {
+	res := &dagFetchResponse{req: req}
+	switch req.kind {
+	case dagHeadersFetch:
+		res.headers, res.err = f.d.fetchDagHeaders(req.peer, req.hashes)
+	case dagBodiesFetch:
+		res.bodies, res.err = f.d.requestDagTxs(req.peer, req.hashes)
+	}
+	f.deliver <- res
+}
+
+// process validates the response, stores the delivered data
+// and reschedules the undelivered items.
+func (f *dagFetcher) process(res *dagFetchResponse) {
+	var (
+		req       = res.req
+		p         = req.peer
+		delivered = make(map[common.Hash]bool, len(req.hashes))
+		msgCode   uint64
+	)
+	if res.err != nil {
+		log.Warn("Sync dag: request failed", "peer", p.id, "kind", req.kind, "items", len(req.hashes), "err", res.err)
+		f.d.scorePeerErr(p.id, res.err)
+	}
+	switch req.kind {
+	case dagHeadersFetch:
+		msgCode = eth.BlockHeadersMsg
+		requested := make(map[common.Hash]bool, len(req.hashes))
+		for _, hash := range req.hashes {
+			requested[hash] = true
+		}
+		for _, header := range res.headers {
+			if header == nil {
+				continue
+			}
+			hash := header.Hash()
+			if !requested[hash] {
+				log.Warn("Sync dag: unrequested header delivered", "peer", p.id, "hash", hash.Hex())
+				f.d.penalizePeer(p.id, MisbehaviourInvalidBlock)
+				continue
+			}
+			if delivered[hash] {
+				continue
+			}
+			delivered[hash] = true
+			result := newFetchResult(header, false)
+			f.results[hash] = result
+			f.origin[hash] = p.id
+			if !result.Done(bodyType) {
+				f.bodyTasks = append(f.bodyTasks, hash)
+			}
+		}
+	case dagBodiesFetch:
+		msgCode = eth.BlockBodiesMsg
+		matched, invalid := alignDelivery(req.hashes, len(res.bodies), func(i int, hash common.Hash) bool {
+			block := types.NewBlockWithHeader(f.results[hash].Header).WithBody(res.bodies[i])
+			return block.BodyHash() == block.Body().CalculateHash()
+		})
+		if invalid > 0 {
+			log.Warn("Sync dag: invalid bodies delivered", "peer", p.id, "invalid", invalid)
+			f.d.penalizePeer(p.id, MisbehaviourInvalidBlock)
+		}
+		for i, hash := range matched {
+			delivered[hash] = true
+			f.results[hash].Transactions = res.bodies[i]
+			f.results[hash].SetBodyDone()
+		}
+	}
+	// adjust the peer throughput
+	p.rates.Update(msgCode, time.Since(req.time), len(delivered))
+
+	// reschedule the undelivered items to be retried by other peers
+	var undelivered common.HashArray
+	for _, hash := range req.hashes {
+		if !delivered[hash] {
+			p.MarkLacking(hash)
+			undelivered = append(undelivered, hash)
+		}
+	}
+	switch req.kind {
+	case dagHeadersFetch:
+		f.headerTasks = append(f.headerTasks, undelivered...)
+	case dagBodiesFetch:
+		f.bodyTasks = append(f.bodyTasks, undelivered...)
+	}
+	f.d.updateFetchProgress(p.id, func(progress *ethereum.PeerSyncProgress) {
+		progress.Pending = 0
+		if res.err != nil {
+			progress.Failures++
+		}
+		switch req.kind {
+		case dagHeadersFetch:
+			progress.Headers += uint64(len(delivered))
+		case dagBodiesFetch:
+			progress.Bodies += uint64(len(delivered))
+		}
+	})
+}
+
+// alignDelivery matches the delivered items to the requested hashes in request order,
+// skipping the hashes the peer has no data for. Returns the hashes of the matched
+// items by the indexes of delivery and the number of items matched to nothing.
+func alignDelivery(hashes common.HashArray, items int, match func(item int, hash common.Hash) bool) (map[int]common.Hash, int) {
+	var (
+		matched = make(map[int]common.Hash, items)
+		invalid int
+		next    int
+	)
+	for i := 0; i < items; i++ {
+		found := false
+		for j := next; j < len(hashes); j++ {
+			if match(i, hashes[j]) {
+				matched[i] = hashes[j]
+				next = j + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			invalid++
+		}
+	}
+	return matched, invalid
+}
+
+// completed retrieves the completed results.
+func (f *dagFetcher) completed() []*fetchResult {
+	results := make([]*fetchResult, 0, len(f.results))
+	for _, result := range f.results {
+		if result.AllDone() {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// fetchDagBlocks retrieves the blocks of the given hashes from all suitable peers.
+// Returns the completed blocks even if some of them failed to be retrieved.
+func (d *Downloader) fetchDagBlocks(hashes common.HashArray) (types.Blocks, error) {
+	peers := make([]*peerConnection, 0, d.peers.Len())
+	for _, p := range d.headSyncPeers() {
+		if p.version >= eth.ETH66 {
+			peers = append(peers, p)
+		}
+	}
+	if len(peers) == 0 {
+		return nil, errNoPeers
+	}
+	d.resetFetchProgress()
+
+	fetcher := newDagFetcher(d, hashes)
+	err := fetcher.run(peers)
+
+	completed := fetcher.completed()
+	headers := make(types.Headers, len(completed))
+	bodies := make(map[common.Hash]types.Transactions, len(completed))
+	for i, result := range completed {
+		headers[i] = result.Header
+		bodies[result.Header.Hash()] = result.Transactions
+	}
+	headers, failed, _ := d.filterConsistentParentsChains(headers)
+	for _, header := range failed {
+		d.penalizePeer(fetcher.origin[header.Hash()], MisbehaviourInconsistentParents)
+	}
+	blocks := make(types.Blocks, len(headers))
+	for i, header := range headers {
+		blocks[i] = types.NewBlockWithHeader(header).WithBody(bodies[header.Hash()])
+	}
+	log.Info("Sync dag: blocks retrieved", "requested", len(hashes), "completed", len(completed), "blocks", len(blocks), "peers", len(peers), "err", err)
+	return blocks, err
+}
+
+// syncDagBlocks retrieves the unknown blocks of the given hashes
+// from all suitable peers and inserts them to the chain.
+func (d *Downloader) syncDagBlocks(hashes common.HashArray) error {
+	// Make sure only one goroutine is ever allowed past this point at once
+	if !atomic.CompareAndSwapInt32(&d.dagSyncing, 0, 1) {
+		return errBusy
+	}
+	defer atomic.StoreInt32(&d.dagSyncing, 0)
+
+	// filter existed blocks
+	hashes = hashes.Copy()
+	hashes.Deduplicate()
+	hashes = hashes.Difference(d.blockchain.GetInsertDelayedHashes())
+	dagBlocks := d.blockchain.GetBlocksByHashes(hashes)
+	unknown := make(common.HashArray, 0, len(hashes))
+	for _, h := range hashes {
+		if dagBlocks[h] == nil && h != (common.Hash{}) {
+			unknown = append(unknown, h)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	blocks, err := d.fetchDagBlocks(unknown)
+	if len(blocks) > 0 {
+		if insErr := d.insertUnknownBlocks(blocks); insErr != nil {
+			return insErr
+		}
+	}
+	return err
+}
+
+// resetFetchProgress clears the per peer progress of the dag data retrieval.
+func (d *Downloader) resetFetchProgress() {
+	d.syncStatsLock.Lock()
+	defer d.syncStatsLock.Unlock()
+
+	d.syncStatsPeers = make(map[string]*ethereum.PeerSyncProgress)
+}
+
+// updateFetchProgress updates the progress of the dag data retrieval from the peer.
+func (d *Downloader) updateFetchProgress(id string, update func(progress *ethereum.PeerSyncProgress)) {
+	d.syncStatsLock.Lock()
+	defer d.syncStatsLock.Unlock()
+
+	if d.syncStatsPeers == nil {
+		d.syncStatsPeers = make(map[string]*ethereum.PeerSyncProgress)
+	}
+	progress, ok := d.syncStatsPeers[id]
+	if !ok {
+		progress = &ethereum.PeerSyncProgress{Peer: id}
+		d.syncStatsPeers[id] = progress
+	}
+	update(progress)
+}
+
+// fetchProgress retrieves the per peer progress of the dag data retrieval sorted by peers.
+// Must be called under syncStatsLock.
+func (d *Downloader) fetchProgress() []ethereum.PeerSyncProgress {
+	if len(d.syncStatsPeers) == 0 {
+		return nil
+	}
+	progress := make([]ethereum.PeerSyncProgress, 0, len(d.syncStatsPeers))
+	for _, p := range d.syncStatsPeers {
+		progress = append(progress, *p)
+	}
+	sort.Slice(progress, func(i, j int) bool {
+		return progress[i].Peer < progress[j].Peer
+	})
+	return progress
+}
@@ -0,0 +1,174 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/trie"
+)
+
+// dagTestPeer is a peer serving the dag data from the given blocks.
+type dagTestPeer struct {
+	id      string
+	d       *Downloader
+	blocks  map[common.Hash]*types.Block
+	corrupt bool // Whether the peer delivers invalid bodies
+}
+
+func (p *dagTestPeer) GetDagInfo() uint64                                  { return 0 }
+func (p *dagTestPeer) GetDagStatus() *eth.DagStatus                        { return nil }
+func (p *dagTestPeer) RequestHeadersByNumber(uint64, int, int, bool) error { return nil }
+func (p *dagTestPeer) RequestNodeData([]common.Hash) error                 { return nil }
+func (p *dagTestPeer) RequestDag(common.Hash, common.Hash) error           { return nil }
+func (p *dagTestPeer) RequestHashesBySlots(uint64, uint64) error           { return nil }
+func (p *dagTestPeer) RequestReceipts([]common.Hash) error                 { return nil }
+func (p *dagTestPeer) RequestMissingAncestors(common.HashArray, common.Hash) error {
+	return nil
+}
+
+func (p *dagTestPeer) RequestHeadersByHashes(hashes common.HashArray) error {
+	headers := make([]*types.Header, 0, len(hashes))
+	for _, hash := range hashes {
+		if block, ok := p.blocks[hash]; ok {
+			headers = append(headers, block.Header())
+		}
+	}
+	go p.d.DeliverHeaders(p.id, headers)
+	return nil
+}
+
+func (p *dagTestPeer) RequestBodies(hashes []common.Hash) error {
+	bodies := make([][]*types.Transaction, 0, len(hashes))
+	for _, hash := range hashes {
+		if block, ok := p.blocks[hash]; ok {
+			txs := block.Transactions()
+			if p.corrupt {
+				txs = txs[1:]
+			}
+			bodies = append(bodies, txs)
+		}
+	}
+	go p.d.DeliverBodies(p.id, bodies)
+	return nil
+}
+
+// newDagTestDownloader creates a downloader able to retrieve the dag data from the peers.
+func newDagTestDownloader() *Downloader {
+	return &Downloader{
+		peers:          newPeerSet(),
+		scores:         newPeerScores(),
+		dagCh:          make(chan dataPack, 1),
+		headerCh:       make(chan dataPack, 1),
+		bodyCh:         make(chan dataPack, 1),
+		receiptCh:      make(chan dataPack, 1),
+		cancelCh:       make(chan struct{}),
+		syncPeers:      make(map[string]*syncPeerChans),
+		syncPeersMutex: new(sync.Mutex),
+	}
+}
+
+func makeDagTestBlocks(n int) types.Blocks {
+	blocks := make(types.Blocks, n)
+	for i := range blocks {
+		txs := []*types.Transaction{
+			types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil),
+			types.NewTransaction(uint64(i), common.Address{0x02}, big.NewInt(1), 21000, big.NewInt(1), nil),
+		}
+		header := &types.Header{Slot: uint64(i), Height: uint64(i), Extra: []byte{byte(i)}}
+		blocks[i] = types.NewBlock(header, txs, nil, trie.NewStackTrie(nil))
+	}
+	return blocks
+}
+
+func TestDagFetcher_MultiPeer(t *testing.T) {
+	d := newDagTestDownloader()
+	blocks := makeDagTestBlocks(20)
+
+	full := make(map[common.Hash]*types.Block, len(blocks))
+	partial := make(map[common.Hash]*types.Block, len(blocks))
+	for i, block := range blocks {
+		full[block.Hash()] = block
+		if i%2 == 0 {
+			partial[block.Hash()] = block
+		}
+	}
+	peers := []*dagTestPeer{
+		{id: "full", d: d, blocks: full},
+		{id: "partial", d: d, blocks: partial},
+		{id: "corrupt", d: d, blocks: full, corrupt: true},
+	}
+	for _, p := range peers {
+		testutils.AssertNoError(t, d.peers.Register(newPeerConnection(p.id, eth.ETH68, p, log.New("peer", p.id))))
+	}
+
+	hashes := make(common.HashArray, len(blocks))
+	for i, block := range blocks {
+		hashes[i] = block.Hash()
+	}
+	fetcher := newDagFetcher(d, hashes)
+	testutils.AssertNoError(t, fetcher.run(d.peers.AllPeers()))
+
+	completed := fetcher.completed()
+	testutils.AssertEqual(t, len(blocks), len(completed))
+	for _, result := range completed {
+		block := full[result.Header.Hash()]
+		testutils.AssertEqual(t, block.Transactions().Len(), result.Transactions.Len())
+	}
+	if d.PeerScore("corrupt").InvalidBlocks == 0 {
+		t.Fatal("corrupt peer not penalized")
+	}
+	testutils.AssertEqual(t, uint64(0), d.PeerScore("full").InvalidBlocks)
+	testutils.AssertEqual(t, uint64(0), d.PeerScore("partial").InvalidBlocks)
+
+	var delivered uint64
+	for _, progress := range d.fetchProgress() {
+		delivered += progress.Bodies
+		testutils.AssertEqual(t, uint64(0), progress.Pending)
+	}
+	testutils.AssertEqual(t, uint64(len(blocks)), delivered)
+}
+
+func TestDagFetcher_Unavailable(t *testing.T) {
+	d := newDagTestDownloader()
+	blocks := makeDagTestBlocks(4)
+
+	known := map[common.Hash]*types.Block{blocks[0].Hash(): blocks[0]}
+	p := &dagTestPeer{id: "peer", d: d, blocks: known}
+	testutils.AssertNoError(t, d.peers.Register(newPeerConnection(p.id, eth.ETH68, p, log.New("peer", p.id))))
+
+	hashes := common.HashArray{blocks[0].Hash(), blocks[1].Hash()}
+	fetcher := newDagFetcher(d, hashes)
+	testutils.AssertError(t, fetcher.run(d.peers.AllPeers()), errPeersUnavailable)
+	testutils.AssertEqual(t, 1, len(fetcher.completed()))
+}
+
+func TestAlignDelivery(t *testing.T) {
+	hashes := common.HashArray{{0x01}, {0x02}, {0x03}, {0x04}}
+	items := common.HashArray{{0x02}, {0x04}, {0x05}}
+
+	matched, invalid := alignDelivery(hashes, len(items), func(i int, hash common.Hash) bool {
+		return items[i] == hash
+	})
+	testutils.AssertEqual(t, map[int]common.Hash{0: {0x02}, 1: {0x04}}, matched)
+	testutils.AssertEqual(t, 1, invalid)
+}
@@ -93,13 +93,19 @@ type Downloader struct {
 	syncStatsChainOrigin uint64 // Origin block number where syncing started at
 	syncStatsChainHeight uint64 // Highest block number known when syncing started
 	syncStatsState       stateSyncStats
-	syncStatsLock        sync.RWMutex // Lock protecting the sync stats fields
+	syncStatsPeers       map[string]*ethereum.PeerSyncProgress // Progress of the dag data retrieval per peer
+	syncPhase            *syncPhaseState                       // Current phase of the dag synchronisation
+	syncTargetCp         *types.Checkpoint                     // Checkpoint the synchronisation targets
+	syncStatsLock        sync.RWMutex                          // Lock protecting the sync stats fields
 
 	lightchain LightChain
 	blockchain BlockChain
 
 	// Callbacks
 	dropPeer peerDropFn // Drops a peer for misbehaving
+	banPeer  peerBanFn  // Temporarily bans a peer for misbehaving
+
+	scores *peerScores // Sync reputation of the peers
 
 	// Status
 	finSyncing int32
@@ -107,6 +113,7 @@ type Downloader struct {
 
 	// Channels
 	dagCh         chan dataPack        // Channel receiving inbound dag hashes
+	ancestorsCh   chan dataPack        // Channel receiving inbound missing ancestors
 	headerCh      chan dataPack        // Channel receiving inbound block headers
 	bodyCh        chan dataPack        // Channel receiving inbound block bodies
 	receiptCh     chan dataPack        // Channel receiving inbound receipts
@@ -202,6 +209,9 @@ type BlockChain interface {
 	// FastSyncCommitHead directly commits the head block to a certain entity.
 	FastSyncCommitHead(common.Hash) error
 
+	// InsertCheckpointAnchor anchors the chain at the trusted checkpoint retrieved by the checkpoint sync.
+	InsertCheckpointAnchor(cp *types.Checkpoint, cpEra *era.Era, block *types.Block) error
+
 	// InsertChain inserts a batch of blocks into the local chain.
 	InsertChain(types.Blocks) (int, error)
 
@@ -228,7 +238,7 @@ type BlockChain interface {
 }
 
 // New creates a new downloader to fetch hashes and blocks from remote peers.
-func New(checkpoint uint64, stateDb ethdb.Database, stateBloom *trie.SyncBloom, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn) *Downloader {
+func New(checkpoint uint64, stateDb ethdb.Database, stateBloom *trie.SyncBloom, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn, banPeer peerBanFn) *Downloader {
 	if lightchain == nil {
 		lightchain = chain
 	}
@@ -242,7 +252,10 @@ func New(checkpoint uint64, stateDb ethdb.Database, stateBloom *trie.SyncBloom,
 		blockchain:     chain,
 		lightchain:     lightchain,
 		dropPeer:       dropPeer,
+		banPeer:        banPeer,
+		scores:         newPeerScores(),
 		dagCh:          make(chan dataPack, 1),
+		ancestorsCh:    make(chan dataPack, 1),
 		headerCh:       make(chan dataPack, 1),
 		bodyCh:         make(chan dataPack, 1),
 		receiptCh:      make(chan dataPack, 1),
@@ -307,6 +320,7 @@ func (d *Downloader) Progress() ethereum.SyncProgress {
 		FinalizedSlot: d.blockchain.GetLastFinalizedHeader().Slot,
 		MaxDagSlot:    d.blockchain.GetTips().GetMaxSlot(),
 		CurrentSlot:   currSlot,
+		Peers:         d.fetchProgress(),
 	}
 }
 
@@ -371,6 +385,7 @@ func (d *Downloader) UnregisterPeer(id string) error {
 		return err
 	}
 	d.queue.Revoke(id)
+	d.scores.prune(func(id string) bool { return d.peers.Peer(id) != nil })
 
 	return nil
 }
@@ -397,6 +412,7 @@ func (d *Downloader) SyncUnloadedParents(id string, hashes common.HashArray) err
 		log.Info("Sync unknown parents: already inserted", "peer", id, "parents", hashes)
 		return nil
 	}
+	defer d.enterSyncPhase(SyncPhaseUnloadedParents, nil)()
 
 	//multi peers sync support
 	if !d.isPeerSync(id) {
@@ -419,7 +435,7 @@ func (d *Downloader) SyncUnloadedParents(id string, hashes common.HashArray) err
 		default:
 		}
 	}
-	for _, ch := range []chan dataPack{d.headerCh, d.bodyCh, d.receiptCh, d.dagCh} {
+	for _, ch := range []chan dataPack{d.headerCh, d.bodyCh, d.receiptCh, d.dagCh, d.ancestorsCh} {
 		for empty := false; !empty; {
 			select {
 			case <-ch:
@@ -447,7 +463,11 @@ func (d *Downloader) SyncUnloadedParents(id string, hashes common.HashArray) err
 	if p == nil {
 		return errUnknownPeer
 	}
-	err = d.syncWithPeerUnknownBlocksWithParents(p, unloaded)
+	if p.version >= eth.ETH67 {
+		err = d.syncWithPeerMissingAncestors(p, unloaded)
+	} else {
+		err = d.syncWithPeerUnknownBlocksWithParents(p, unloaded)
+	}
 	if err != nil {
 		log.Error("Sync unknown parents: failed", "err", err, "peer", id, "parents", unloaded)
 		return err
@@ -599,7 +619,7 @@ func (d *Downloader) syncWithPeerDagOnly(p *peerConnection) (err error) {
 
 	log.Info("Synchronising unloaded dag: unloaded  222", "peer", p.id, "baseSpine", baseSpine.Hex(), "unloaded", unloaded)
 
-	if err = d.syncWithPeerUnknownDagBlocks(p, unloaded); err != nil {
+	if err = d.syncDagBlocks(unloaded); err != nil {
 		log.Error("Synchronising unloaded dag failed", "err", err)
 		return err
 	}
@@ -610,113 +630,6 @@ func (d *Downloader) getMode() SyncMode {
 	return SyncMode(atomic.LoadUint32(&d.mode))
 }
 
-// syncWithPeerUnknownDagBlocks if remote peer has unknown dag blocks only sync such blocks only
-// nolint:unused
-func (d *Downloader) syncWithPeerUnknownDagBlocks(p *peerConnection, dag common.HashArray) (err error) {
-	// Make sure only one goroutine is ever allowed past this point at once
-	if !atomic.CompareAndSwapInt32(&d.dagSyncing, 0, 1) {
-		return errBusy
-	}
-
-	defer func(start time.Time) {
-		atomic.StoreInt32(&d.dagSyncing, 0)
-		log.Info("Sync of dag chain terminated", "elapsed", common.PrettyDuration(time.Since(start)))
-	}(time.Now())
-
-	// filter existed blocks
-	remoteHashes := dag.Copy()
-	remoteHashes.Deduplicate()
-	delayed := d.blockchain.GetInsertDelayedHashes()
-	remoteHashes = remoteHashes.Difference(delayed)
-	dagBlocks := d.blockchain.GetBlocksByHashes(remoteHashes)
-	dag = make(common.HashArray, 0, len(remoteHashes))
-	for _, h := range remoteHashes {
-		if dagBlocks[h] == nil && h != (common.Hash{}) {
-			dag = append(dag, h)
-		}
-	}
-	if len(dag) == 0 {
-		return nil
-	}
-
-	log.Info("Sync of unknown dag blocks", "count", len(dag), "dag", dag)
-
-	rawHdrs, err := d.fetchDagHeaders(p, dag)
-	log.Info("Sync of unknown dag blocks: dag headers retrieved", "count", len(rawHdrs), "headers", rawHdrs, "err", err)
-	if err != nil {
-		return err
-	}
-
-	headers, _, _ := d.filterConsistentParentsChains(rawHdrs)
-	if len(headers) == 0 {
-		return nil
-	}
-
-	dag = *headers.GetHashes()
-
-	txsMap, err := d.fetchDagTxs(p, dag)
-	log.Info("Sync of unknown dag blocks: dag transactions retrieved", "count", len(txsMap), "err", err)
-	if err != nil {
-		return err
-	}
-
-	blocks := make(types.Blocks, 0, len(headers))
-	for _, header := range headers {
-		txs := txsMap[header.Hash()]
-		block := types.NewBlockWithHeader(header).WithBody(txs)
-		// quick body validation
-		if block.BodyHash() != block.Body().CalculateHash() {
-			return errInvalidBody
-		}
-		blocks = append(blocks, block)
-	}
-	log.Info("Sync of unknown dag blocks: NewBlockWithHeader 111", "blocks", len(blocks), "err", err)
-
-	blocksBySlot, err := (&blocks).GroupBySlot()
-	log.Info("Sync of unknown dag blocks: GroupBySlot 222", "blocks", len(blocks), "err", err)
-	if err != nil {
-		return err
-	}
-	//sort by slots
-	slots := common.SorterAscU64{}
-	for sl := range blocksBySlot {
-		slots = append(slots, sl)
-	}
-	sort.Sort(slots)
-	log.Info("Sync of unknown dag blocks: sort slots 333", "blocks", len(blocks), "err", err, "slots", slots)
-
-	insBlocks := make(types.Blocks, 0, len(headers))
-	for _, slot := range slots {
-		slotBlocks := types.SpineSortBlocks(blocksBySlot[slot])
-		insBlocks = append(insBlocks, slotBlocks...)
-	}
-	log.Info("Sync of unknown dag blocks: SpineSortBlocks 444", "blocks", len(blocks), "err", err)
-
-	//try to insert all blocks
-	for {
-		var bl *types.Block
-		if bl, err = d.blockchain.WriteSyncBlocks(insBlocks, true); err != nil {
-			log.Error("Sync of unknown dag blocks: Failed writing blocks to chain  (sync unl)", "err", err, "bl.Slot", bl.Slot(), "hash", bl.Hash().Hex())
-			//if insertion failed - trying to insert after failed block
-			if bl != nil {
-				failedIx := 0
-				for i, b := range insBlocks {
-					failedIx = i
-					if bl.Hash() == b.Hash() {
-						break
-					}
-				}
-				if failedIx+1 < len(insBlocks) {
-					insBlocks = insBlocks[failedIx+1:]
-					continue
-				}
-				return nil
-			}
-		}
-		return nil
-	}
-}
-
 // syncWithPeerUnknownBlocksWithParents fetching unloaded blocks by hashes from remote peer.
 func (d *Downloader) syncWithPeerUnknownBlocksWithParents(p *peerConnection, hashes common.HashArray) (err error) {
 	// Make sure only one goroutine is ever allowed past this point at once
@@ -848,12 +761,14 @@ func (d *Downloader) syncWithPeerUnknownBlocksWithParents(p *peerConnection, has
 	}
 	log.Info("Sync unknown blocks: all blocks fetched", "blocks", len(blocks))
 
+	return d.insertUnknownBlocks(blocks)
+}
+
+// insertUnknownBlocks sorts the fetched unknown blocks by slots and writes them to the chain.
+func (d *Downloader) insertUnknownBlocks(blocks types.Blocks) (err error) {
 	blocksBySlot, err := (&blocks).GroupBySlot()
 	if err != nil {
-		log.Error("Sync unknown blocks: group by slot failed",
-			"i", iterCount,
-			"err", err,
-		)
+		log.Error("Sync unknown blocks: group by slot failed", "err", err)
 		return err
 	}
 	//sort by slots
@@ -945,6 +860,11 @@ func (d *Downloader) DeliverDag(id string, dag common.HashArray) error {
 	return d.deliver(d.dagCh, &dagPack{id, dag}, dagInMeter, dagDropMeter)
 }
 
+// DeliverAncestors injects a batch of missing ancestors received from a remote node.
+func (d *Downloader) DeliverAncestors(id string, blocks types.Blocks) error {
+	return d.deliver(d.ancestorsCh, &ancestorsPack{id, blocks}, ancestorsInMeter, ancestorsDropMeter)
+}
+
 // DeliverHeaders injects a new batch of block headers received from a remote
 // node into the download schedule.
 func (d *Downloader) DeliverHeaders(id string, headers []*types.Header) error {
@@ -1125,6 +1045,7 @@ func (d *Downloader) fetchHashesBySpines(p *peerConnection, baseSpine common.Has
 			}
 			dag = packet.(*dagPack).dag
 			if len(dag) == 0 {
+				d.penalizePeer(p.id, MisbehaviourEmptyDag)
 				err = errInvalidDag
 			}
 			return dag, err
@@ -1272,13 +1193,14 @@ func (d *Downloader) OptimisticSpineSync(spines common.HashArray) error {
 	if len(spines) == 0 {
 		return nil
 	}
+	defer d.enterSyncPhase(SyncPhaseOptimisticSpines, spines)()
 	if d.peers.Len() == 0 {
 		log.Error("Sync opt spines", "spines", len(spines), "peers.Len", d.peers.Len(), "err", errNoPeers, "spines", spines)
 		return errNoPeers
 	}
 
 	//select peer
-	for _, con := range d.peers.AllPeers() {
+	for _, con := range d.peers.SyncPeers() {
 		err := d.SyncUnloadedParents(con.id, spines)
 		switch err {
 		case nil:
@@ -1305,6 +1227,7 @@ func (d *Downloader) OptimisticSpineSync(spines common.HashArray) error {
 			errors.Is(err, errStallingPeer) || errors.Is(err, errEmptyHeaderSet) ||
 			errors.Is(err, errPeersUnavailable) || errors.Is(err, errTooOld) || errors.Is(err, errInvalidAncestor) ||
 			errors.Is(err, errInvalidBody) {
+			d.scorePeerErr(con.id, err)
 			log.Warn("Sync opt spines failed, dropping peer", "peer", con.id, "err", err)
 			if d.dropPeer == nil {
 				// The dropPeer method is nil when `--copydb` is used for a local copy.
@@ -1324,13 +1247,14 @@ func (d *Downloader) MainSync(baseSpine common.Hash, spines common.HashArray) er
 	if len(spines) == 0 {
 		return nil
 	}
+	defer d.enterSyncPhase(SyncPhaseFinalized, spines)()
 	if d.peers.Len() == 0 {
 		log.Error("Sync chain by spines", "baseSpine", baseSpine.Hex(), "spines", spines, "peers.Len", d.peers.Len(), "err", errNoPeers)
 		return errNoPeers
 	}
 
 	//select peer
-	for _, con := range d.peers.AllPeers() {
+	for _, con := range d.peers.SyncPeers() {
 		err := d.peerSyncBySpines(con, baseSpine, spines)
 		switch err {
 		case nil:
@@ -1348,6 +1272,7 @@ func (d *Downloader) MainSync(baseSpine common.Hash, spines common.HashArray) er
 			errors.Is(err, errStallingPeer) || errors.Is(err, errEmptyHeaderSet) ||
 			errors.Is(err, errPeersUnavailable) || errors.Is(err, errTooOld) || errors.Is(err, errInvalidAncestor) ||
 			errors.Is(err, errInvalidBody) {
+			d.scorePeerErr(con.id, err)
 			log.Warn("Sync failed, dropping peer", "peer", con.id, "err", err)
 			if d.dropPeer == nil {
 				// The dropPeer method is nil when `--copydb` is used for a local copy.
@@ -1371,6 +1296,8 @@ func (d *Downloader) DagSync(baseSpine common.Hash, spines common.HashArray) err
 		return errNoPeers
 	}
 
+	defer d.enterSyncPhase(SyncPhaseDag, spines)()
+
 	// set param to load full dag
 	log.Info("Sync of chain head detected", "baseSpine", baseSpine.Hex(), "spines", spines)
 	return d.multiPeersHeadSync(baseSpine, spines)
@@ -1977,7 +1904,7 @@ func (d *Downloader) multiPeersHeadSync(baseSpine common.Hash, spines common.Has
 	ctx, cancel := context.WithCancel(context.Background())
 	var count int
 Loop:
-	for i, con := range d.peers.AllPeers() {
+	for i, con := range d.headSyncPeers() {
 		select {
 		case <-ctx.Done():
 			close(sem)
@@ -2002,6 +1929,7 @@ Loop:
 				defer d.resetPeerSync(con.id)
 				defer wg.Done()
 				if err = d.multiPeerGetHashes(con, baseSpine, spines); err != nil {
+					d.scorePeerErr(con.id, err)
 					if errors.Is(err, errTimeout) {
 						log.Warn("Sync head failed: timed out", "i", i, "peer", con.id, "err", err)
 					}
@@ -2026,6 +1954,7 @@ Loop:
 					sem <- struct{}{}
 					return
 				} else {
+					d.rewardPeer(con.id)
 					count++
 					if count == MaxPeerCon {
 						cancel()
@@ -2037,18 +1966,13 @@ Loop:
 	wg.Wait()
 	log.Info("Sync head: hashes retrieved", "dag", d.syncPeerHashes)
 
-	// single peer sync
-
-	for pid, dag := range d.syncPeerHashes {
-		con := d.peers.Peer(pid)
-		if con == nil {
-			continue
-		}
-		err = d.syncWithPeerUnknownDagBlocks(con, dag)
-		//err = d.syncWithPeerUnknownBlocksWithParents(con, dag)
-		if err != nil {
-			log.Error("Sync head: block fetching failed", "err", err, "peer", pid, "blocks", dag)
-		}
+	// multi peers blocks fetching
+	var dag common.HashArray
+	for _, hashes := range d.syncPeerHashes {
+		dag = append(dag, hashes...)
+	}
+	if err = d.syncDagBlocks(dag); err != nil {
+		log.Error("Sync head: block fetching failed", "err", err, "blocks", len(dag))
 	}
 	log.Info("Sync head:", "dag", d.syncPeerHashes)
 	cancel()
@@ -2190,6 +2114,10 @@ func (d *Downloader) multiFetchDagHashesBySlots(p *peerConnection, from, to uint
 			dag = packet.(*dagPack).dag
 			if len(dag) == 0 {
 				log.Info("No block hashes found for provided slots", "from:", from, "to:", to)
+				// the peer has no blocks after its advertised head
+				if !p.isBeyondHead(from) {
+					d.penalizePeer(p.id, MisbehaviourEmptyHashesBySlots)
+				}
 			}
 			return dag, err
 		case <-timeout:
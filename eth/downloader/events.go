@@ -23,3 +23,6 @@ type DoneEvent struct {
 }
 type StartEvent struct{}
 type FailedEvent struct{ Err error }
+
+// SyncPhaseEvent is posted when the dag synchronisation enters or leaves a phase.
+type SyncPhaseEvent struct{ Status SyncStatus }
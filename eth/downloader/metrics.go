@@ -41,6 +41,9 @@ var (
 	dagInMeter   = metrics.NewRegisteredMeter("eth/downloader/dag/in", nil)
 	dagDropMeter = metrics.NewRegisteredMeter("eth/downloader/dag/drop", nil)
 
+	ancestorsInMeter   = metrics.NewRegisteredMeter("eth/downloader/ancestors/in", nil)
+	ancestorsDropMeter = metrics.NewRegisteredMeter("eth/downloader/ancestors/drop", nil)
+
 	stateInMeter   = metrics.NewRegisteredMeter("eth/downloader/states/in", nil)
 	stateDropMeter = metrics.NewRegisteredMeter("eth/downloader/states/drop", nil)
 )
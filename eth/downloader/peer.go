@@ -72,6 +72,7 @@ type peerConnection struct {
 // LightPeer encapsulates the methods required to synchronise with a remote light peer.
 type LightPeer interface {
 	GetDagInfo() uint64
+	GetDagStatus() *eth.DagStatus
 	RequestHeadersByHashes(common.HashArray) error
 	RequestHeadersByNumber(uint64, int, int, bool) error
 }
@@ -84,6 +85,7 @@ type Peer interface {
 	RequestNodeData([]common.Hash) error
 	RequestDag(baseSpine common.Hash, terminalSpine common.Hash) error
 	RequestHashesBySlots(from, to uint64) error
+	RequestMissingAncestors(hashes common.HashArray, checkpoint common.Hash) error
 }
 
 // lightPeerWrapper wraps a LightPeer struct, stubbing out the Peer-only methods.
@@ -96,12 +98,19 @@ func (w *lightPeerWrapper) RequestHashesBySlots(from, to uint64) error {
 	panic("implement me")
 }
 
+func (w *lightPeerWrapper) RequestMissingAncestors(common.HashArray, common.Hash) error {
+	panic("RequestMissingAncestors not supported in light client mode sync")
+}
+
 func (w *lightPeerWrapper) RequestDag(baseSpine common.Hash, terminalSpine common.Hash) error {
 	panic("RequestReceipts not supported in light client mode sync")
 }
 func (w *lightPeerWrapper) GetDagInfo() uint64 {
 	return w.peer.GetDagInfo()
 }
+func (w *lightPeerWrapper) GetDagStatus() *eth.DagStatus {
+	return w.peer.GetDagStatus()
+}
 func (w *lightPeerWrapper) RequestHeadersByHashes(h common.HashArray) error {
 	return w.peer.RequestHeadersByHashes(h)
 }
@@ -328,6 +337,13 @@ func (p *peerConnection) Lacks(hash common.Hash) bool {
 	return ok
 }
 
+// isBeyondHead returns true if the slot follows the max slot of the dag
+// advertised by the peer.
+func (p *peerConnection) isBeyondHead(slot uint64) bool {
+	status := p.peer.GetDagStatus()
+	return status != nil && slot > status.MaxSlot
+}
+
 // Id returns peerconnection id.
 func (p *peerConnection) Id() string {
 	return p.id
@@ -443,6 +459,19 @@ func (ps *peerSet) AllPeers() []*peerConnection {
 	return list
 }
 
+// SyncPeers retrieves a flat list of all the peers within the set,
+// ordered by the progress of their dag advertised in the handshake.
+func (ps *peerSet) SyncPeers() []*peerConnection {
+	peers := ps.AllPeers()
+	progress := make([]peerProgress, len(peers))
+	for i, p := range peers {
+		progress[i] = newPeerProgress(p.peer)
+	}
+	sortPeers := &peerProgressSort{peers, progress}
+	sort.Stable(sortPeers)
+	return sortPeers.p
+}
+
 // HeaderIdlePeers retrieves a flat list of all the currently header-idle peers
 // within the active peer set, ordered by their reputation.
 func (ps *peerSet) HeaderIdlePeers() ([]*peerConnection, int) {
@@ -452,7 +481,7 @@ func (ps *peerSet) HeaderIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.BlockHeadersMsg, time.Second)
 	}
-	return ps.idlePeers(eth.ETH66, eth.ETH66, idle, throughput)
+	return ps.idlePeers(eth.ETH66, eth.ETHLatest, idle, throughput)
 }
 
 // BodyIdlePeers retrieves a flat list of all the currently body-idle peers within
@@ -464,7 +493,7 @@ func (ps *peerSet) BodyIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.BlockBodiesMsg, time.Second)
 	}
-	return ps.idlePeers(eth.ETH66, eth.ETH66, idle, throughput)
+	return ps.idlePeers(eth.ETH66, eth.ETHLatest, idle, throughput)
 }
 
 // ReceiptIdlePeers retrieves a flat list of all the currently receipt-idle peers
@@ -476,7 +505,7 @@ func (ps *peerSet) ReceiptIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.ReceiptsMsg, time.Second)
 	}
-	return ps.idlePeers(eth.ETH66, eth.ETH66, idle, throughput)
+	return ps.idlePeers(eth.ETH66, eth.ETHLatest, idle, throughput)
 }
 
 // NodeDataIdlePeers retrieves a flat list of all the currently node-data-idle
@@ -488,7 +517,7 @@ func (ps *peerSet) NodeDataIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.NodeDataMsg, time.Second)
 	}
-	return ps.idlePeers(eth.ETH66, eth.ETH66, idle, throughput)
+	return ps.idlePeers(eth.ETH66, eth.ETHLatest, idle, throughput)
 }
 
 // DagIdlePeers retrieves a flat list of all the currently dag-idle
@@ -500,7 +529,7 @@ func (ps *peerSet) DagIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.DagMsg, time.Second)
 	}
-	return ps.idlePeers(eth.ETH66, eth.ETH66, idle, throughput)
+	return ps.idlePeers(eth.ETH66, eth.ETHLatest, idle, throughput)
 }
 
 // idlePeers retrieves a flat list of all currently idle peers satisfying the
@@ -550,3 +579,54 @@ func (ps *peerCapacitySort) Swap(i, j int) {
 	ps.p[i], ps.p[j] = ps.p[j], ps.p[i]
 	ps.tp[i], ps.tp[j] = ps.tp[j], ps.tp[i]
 }
+
+// peerProgress represents the dag progress of a peer.
+type peerProgress struct {
+	cpEpoch   uint64
+	lastFinNr uint64
+	maxSlot   uint64
+}
+
+// newPeerProgress collects the dag progress of a peer.
+func newPeerProgress(peer LightPeer) peerProgress {
+	progress := peerProgress{lastFinNr: peer.GetDagInfo()}
+	if status := peer.GetDagStatus(); status != nil {
+		if status.Checkpoint != nil {
+			progress.cpEpoch = status.Checkpoint.FinEpoch
+		}
+		progress.maxSlot = status.MaxSlot
+	}
+	return progress
+}
+
+// ahead returns true if the progress is ahead of the given one.
+// The checkpoint is prioritized over the last finalized number and the max slot.
+func (pp peerProgress) ahead(other peerProgress) bool {
+	if pp.cpEpoch != other.cpEpoch {
+		return pp.cpEpoch > other.cpEpoch
+	}
+	if pp.lastFinNr != other.lastFinNr {
+		return pp.lastFinNr > other.lastFinNr
+	}
+	return pp.maxSlot > other.maxSlot
+}
+
+// peerProgressSort implements sort.Interface.
+// It sorts peer connections by dag progress (descending).
+type peerProgressSort struct {
+	p  []*peerConnection
+	pr []peerProgress
+}
+
+func (ps *peerProgressSort) Len() int {
+	return len(ps.p)
+}
+
+func (ps *peerProgressSort) Less(i, j int) bool {
+	return ps.pr[i].ahead(ps.pr[j])
+}
+
+func (ps *peerProgressSort) Swap(i, j int) {
+	ps.p[i], ps.p[j] = ps.p[j], ps.p[i]
+	ps.pr[i], ps.pr[j] = ps.pr[j], ps.pr[i]
+}
@@ -0,0 +1,57 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+// statusTestPeer is a light peer advertising the given dag status.
+type statusTestPeer struct {
+	lastFinNr uint64
+	status    *eth.DagStatus
+}
+
+func (p *statusTestPeer) GetDagInfo() uint64                                  { return p.lastFinNr }
+func (p *statusTestPeer) GetDagStatus() *eth.DagStatus                        { return p.status }
+func (p *statusTestPeer) RequestHeadersByHashes(common.HashArray) error       { return nil }
+func (p *statusTestPeer) RequestHeadersByNumber(uint64, int, int, bool) error { return nil }
+
+func TestPeerSet_SyncPeers(t *testing.T) {
+	peers := map[string]*statusTestPeer{
+		"legacy": {lastFinNr: 500},
+		"behind": {lastFinNr: 100, status: &eth.DagStatus{Checkpoint: &types.Checkpoint{FinEpoch: 2}, MaxSlot: 90}},
+		"ahead":  {lastFinNr: 300, status: &eth.DagStatus{Checkpoint: &types.Checkpoint{FinEpoch: 5}, MaxSlot: 200}},
+		"tips":   {lastFinNr: 300, status: &eth.DagStatus{Checkpoint: &types.Checkpoint{FinEpoch: 5}, MaxSlot: 250}},
+		"no-cp":  {lastFinNr: 600, status: &eth.DagStatus{MaxSlot: 700}},
+	}
+	ps := newPeerSet()
+	for id, p := range peers {
+		testutils.AssertNoError(t, ps.Register(newPeerConnection(id, eth.ETH68, &lightPeerWrapper{p}, log.New("peer", id))))
+	}
+
+	ordered := ps.SyncPeers()
+	ids := make([]string, len(ordered))
+	for i, p := range ordered {
+		ids[i] = p.id
+	}
+	testutils.AssertEqual(t, []string{"tips", "ahead", "behind", "no-cp", "legacy"}, ids)
+}
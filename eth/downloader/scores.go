@@ -0,0 +1,245 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/metrics"
+)
+
+const (
+	scoreMax          = 100  // Maximum score a peer can collect by useful answers
+	scoreBanThreshold = -100 // Score at which the peer is banned
+
+	peerBanDuration = 30 * time.Minute // Duration of the temporary ban of a misbehaving peer
+)
+
+// Misbehaviour represents a kind of bad data or behaviour of a sync peer.
+type Misbehaviour int
+
+const (
+	MisbehaviourTimeout             Misbehaviour = iota // request timed out
+	MisbehaviourInvalidBlock                            // invalid block or body delivered
+	MisbehaviourInconsistentParents                     // blocks with unknown parents chains delivered
+	MisbehaviourEmptyDag                                // empty answer to GetDagMsg
+	MisbehaviourEmptyHashesBySlots                      // empty answer to GetHashesBySlotsMsg
+)
+
+func (m Misbehaviour) String() string {
+	switch m {
+	case MisbehaviourTimeout:
+		return "timeout"
+	case MisbehaviourInvalidBlock:
+		return "invalid block"
+	case MisbehaviourInconsistentParents:
+		return "inconsistent parents"
+	case MisbehaviourEmptyDag:
+		return "empty dag"
+	case MisbehaviourEmptyHashesBySlots:
+		return "empty hashes by slots"
+	default:
+		return "unknown"
+	}
+}
+
+// penalty returns the score penalty of the misbehaviour.
+func (m Misbehaviour) penalty() int {
+	switch m {
+	case MisbehaviourTimeout:
+		return 10
+	case MisbehaviourInvalidBlock:
+		return 50
+	case MisbehaviourInconsistentParents:
+		return 20
+	case MisbehaviourEmptyDag:
+		return 5
+	case MisbehaviourEmptyHashesBySlots:
+		// empty slots are legal, so the penalty is low
+		return 1
+	default:
+		return 0
+	}
+}
+
+var scoreBanMeter = metrics.NewRegisteredMeter("eth/downloader/peers/ban", nil)
+
+// PeerScore represents the sync reputation of a peer.
+type PeerScore struct {
+	Score               int       `json:"score"`
+	Useful              uint64    `json:"useful"`
+	Timeouts            uint64    `json:"timeouts"`
+	InvalidBlocks       uint64    `json:"invalidBlocks"`
+	InconsistentParents uint64    `json:"inconsistentParents"`
+	EmptyDag            uint64    `json:"emptyDag"`
+	EmptyHashesBySlots  uint64    `json:"emptyHashesBySlots"`
+	Bans                uint64    `json:"bans"`
+	BannedUntil         time.Time `json:"bannedUntil"`
+}
+
+// peerScores tracks the scores of the sync peers.
+// The scores of the banned peers are kept until the ban expires
+// to survive reconnects, the others are dropped with the peers.
+type peerScores struct {
+	scores map[string]*PeerScore
+	lock   sync.RWMutex
+}
+
+// newPeerScores creates an empty peer scores set.
+func newPeerScores() *peerScores {
+	return &peerScores{
+		scores: make(map[string]*PeerScore),
+	}
+}
+
+// get retrieves a copy of the peer score.
+func (ps *peerScores) get(id string) PeerScore {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	if score, ok := ps.scores[id]; ok {
+		return *score
+	}
+	return PeerScore{}
+}
+
+// reward increases the peer score for a useful answer.
+func (ps *peerScores) reward(id string) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	score := ps.peer(id)
+	score.Useful++
+	if score.Score < scoreMax {
+		score.Score++
+	}
+}
+
+// penalize decreases the peer score for the misbehaviour,
+// returns true if the peer must be banned.
+func (ps *peerScores) penalize(id string, m Misbehaviour) bool {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	score := ps.peer(id)
+	switch m {
+	case MisbehaviourTimeout:
+		score.Timeouts++
+	case MisbehaviourInvalidBlock:
+		score.InvalidBlocks++
+	case MisbehaviourInconsistentParents:
+		score.InconsistentParents++
+	case MisbehaviourEmptyDag:
+		score.EmptyDag++
+	case MisbehaviourEmptyHashesBySlots:
+		score.EmptyHashesBySlots++
+	}
+	score.Score -= m.penalty()
+	if score.Score > scoreBanThreshold {
+		return false
+	}
+	// reset the score to give the peer a chance after the ban
+	score.Score = 0
+	score.Bans++
+	score.BannedUntil = time.Now().Add(peerBanDuration)
+	return true
+}
+
+// prune drops the scores of the inactive peers which are not banned.
+func (ps *peerScores) prune(active func(id string) bool) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	now := time.Now()
+	for id, score := range ps.scores {
+		if !active(id) && !score.BannedUntil.After(now) {
+			delete(ps.scores, id)
+		}
+	}
+}
+
+// peer retrieves the peer score creating it if missed.
+// Must be called under lock.
+func (ps *peerScores) peer(id string) *PeerScore {
+	score, ok := ps.scores[id]
+	if !ok {
+		score = new(PeerScore)
+		ps.scores[id] = score
+	}
+	return score
+}
+
+// PeerScore retrieves the sync reputation of the peer.
+func (d *Downloader) PeerScore(id string) PeerScore {
+	return d.scores.get(id)
+}
+
+// ReportInvalidBlock penalizes the peer which delivered the invalid block.
+func (d *Downloader) ReportInvalidBlock(id string) {
+	d.penalizePeer(id, MisbehaviourInvalidBlock)
+}
+
+// rewardPeer increases the peer score for a useful answer.
+func (d *Downloader) rewardPeer(id string) {
+	d.scores.reward(id)
+}
+
+// penalizePeer decreases the peer score for the misbehaviour
+// and bans the peer if the score dropped below the threshold.
+func (d *Downloader) penalizePeer(id string, m Misbehaviour) {
+	if !d.scores.penalize(id, m) {
+		return
+	}
+	scoreBanMeter.Mark(1)
+	log.Warn("Sync peer banned", "peer", id, "misbehaviour", m, "duration", peerBanDuration)
+	if d.banPeer == nil {
+		// The banPeer method is nil when `--copydb` is used for a local copy.
+		log.Warn("Downloader wants to ban peer, but peerban-function is not set", "peer", id)
+		return
+	}
+	d.banPeer(id, peerBanDuration)
+}
+
+// scorePeerErr penalizes the peer for the error of data retrieval, if it is caused by the peer.
+func (d *Downloader) scorePeerErr(id string, err error) {
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, errTimeout):
+		d.penalizePeer(id, MisbehaviourTimeout)
+	case errors.Is(err, errInvalidBody) || errors.Is(err, errInvalidChain) || errors.Is(err, errBadPeer):
+		d.penalizePeer(id, MisbehaviourInvalidBlock)
+	case errors.Is(err, errInvalidAncestor):
+		d.penalizePeer(id, MisbehaviourInconsistentParents)
+	}
+}
+
+// headSyncPeers retrieves the sync peers ordered by sync progress
+// where the peers with negative score are moved to the end of the list.
+func (d *Downloader) headSyncPeers() []*peerConnection {
+	peers := d.peers.SyncPeers()
+	scores := make(map[string]int, len(peers))
+	for _, p := range peers {
+		scores[p.id] = d.scores.get(p.id).Score
+	}
+	sort.SliceStable(peers, func(i, j int) bool {
+		return scores[peers[i].id] >= 0 && scores[peers[j].id] < 0
+	})
+	return peers
+}
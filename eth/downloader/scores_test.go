@@ -0,0 +1,106 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+func TestDownloader_PenalizePeer(t *testing.T) {
+	var banned []string
+	d := &Downloader{
+		scores: newPeerScores(),
+		banPeer: func(id string, duration time.Duration) {
+			testutils.AssertEqual(t, peerBanDuration, duration)
+			banned = append(banned, id)
+		},
+	}
+
+	d.rewardPeer("good")
+	d.penalizePeer("good", MisbehaviourEmptyHashesBySlots)
+	testutils.AssertEqual(t, 0, d.PeerScore("good").Score)
+	testutils.AssertEqual(t, uint64(1), d.PeerScore("good").EmptyHashesBySlots)
+
+	d.scorePeerErr("bad", fmt.Errorf("%w: test", errTimeout))
+	d.scorePeerErr("bad", errCanceled)
+	testutils.AssertEqual(t, -MisbehaviourTimeout.penalty(), d.PeerScore("bad").Score)
+	testutils.AssertEqual(t, uint64(1), d.PeerScore("bad").Timeouts)
+
+	d.ReportInvalidBlock("bad")
+	testutils.AssertEqual(t, 0, len(banned))
+	d.ReportInvalidBlock("bad")
+	testutils.AssertEqual(t, []string{"bad"}, banned)
+
+	score := d.PeerScore("bad")
+	testutils.AssertEqual(t, 0, score.Score)
+	testutils.AssertEqual(t, uint64(2), score.InvalidBlocks)
+	testutils.AssertEqual(t, uint64(1), score.Bans)
+	if !score.BannedUntil.After(time.Now()) {
+		t.Fatal("ban expiration not set")
+	}
+}
+
+func TestDownloader_HeadSyncPeers(t *testing.T) {
+	d := &Downloader{
+		scores: newPeerScores(),
+		peers:  newPeerSet(),
+	}
+	for id, lastFinNr := range map[string]uint64{"first": 300, "second": 200, "third": 100} {
+		p := &statusTestPeer{lastFinNr: lastFinNr}
+		testutils.AssertNoError(t, d.peers.Register(newPeerConnection(id, eth.ETH68, &lightPeerWrapper{p}, log.New("peer", id))))
+	}
+	d.penalizePeer("first", MisbehaviourEmptyDag)
+	d.rewardPeer("third")
+
+	ordered := d.headSyncPeers()
+	ids := make([]string, len(ordered))
+	for i, p := range ordered {
+		ids[i] = p.id
+	}
+	testutils.AssertEqual(t, []string{"second", "third", "first"}, ids)
+}
+
+func TestDownloader_PruneScores(t *testing.T) {
+	d := &Downloader{
+		scores:  newPeerScores(),
+		peers:   newPeerSet(),
+		banPeer: func(string, time.Duration) {},
+	}
+	p := &statusTestPeer{status: &eth.DagStatus{MaxSlot: 100}}
+	testutils.AssertNoError(t, d.peers.Register(newPeerConnection("active", eth.ETH68, &lightPeerWrapper{p}, log.New("peer", "active"))))
+
+	d.rewardPeer("active")
+	d.rewardPeer("dropped")
+	d.ReportInvalidBlock("banned")
+	d.ReportInvalidBlock("banned")
+
+	// the scores of dropped peers are removed while the bans are kept
+	d.scores.prune(func(id string) bool { return d.peers.Peer(id) != nil })
+	testutils.AssertEqual(t, 1, d.PeerScore("active").Score)
+	testutils.AssertEqual(t, PeerScore{}, d.PeerScore("dropped"))
+	testutils.AssertEqual(t, uint64(1), d.PeerScore("banned").Bans)
+	testutils.AssertEqual(t, 2, len(d.scores.scores))
+
+	// empty ranges after the advertised head are consistent
+	con := d.peers.Peer("active")
+	testutils.AssertEqual(t, false, con.isBeyondHead(100))
+	testutils.AssertEqual(t, true, con.isBeyondHead(101))
+}
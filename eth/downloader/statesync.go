@@ -582,8 +582,6 @@ func (s *stateSync) updateStats(written, duplicate, unexpected int, duration tim
 }
 
 // syncState starts downloading state with the given root hash.
-//
-// nolint:unused // todo: state sync
 func (d *Downloader) syncState(root common.Hash) *stateSync {
 	// Create the state sync
 	s := newStateSync(d, root)
@@ -602,8 +600,6 @@ func (d *Downloader) syncState(root common.Hash) *stateSync {
 
 // newStateSync creates a new state trie download scheduler. This method does not
 // yet start the sync. The user needs to call run to initiate.
-//
-// nolint:unused // todo: state sync
 func newStateSync(d *Downloader, root common.Hash) *stateSync {
 	return &stateSync{
 		d:         d,
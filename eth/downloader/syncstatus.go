@@ -0,0 +1,179 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+)
+
+// SyncPhase represents the current phase of the dag synchronisation.
+type SyncPhase string
+
+const (
+	SyncPhaseIdle             SyncPhase = "idle"             // no synchronisation is running
+	SyncPhaseFinalized        SyncPhase = "finalized"        // sync of the finalized chain by spines
+	SyncPhaseDag              SyncPhase = "dag"              // sync of the not finalized dag head
+	SyncPhaseOptimisticSpines SyncPhase = "optimisticSpines" // sync of the optimistic spines
+	SyncPhaseUnloadedParents  SyncPhase = "unloadedParents"  // fetch of the unloaded parents of a block
+)
+
+// SyncStatus represents the dag-aware status of the synchronisation.
+type SyncStatus struct {
+	Phase           SyncPhase         `json:"phase"`
+	StartedAt       uint64            `json:"startedAt"`       // unix time the phase started
+	StartingSlot    uint64            `json:"startingSlot"`    // slot the phase started from
+	CurrentSlot     uint64            `json:"currentSlot"`     // slot the sync is currently at
+	TargetSlot      uint64            `json:"targetSlot"`      // slot the phase targets
+	Spines          uint64            `json:"spines"`          // number of spines to sync by the phase
+	SpinesRemaining uint64            `json:"spinesRemaining"` // number of spines not loaded yet
+	Checkpoint      *types.Checkpoint `json:"checkpoint"`      // checkpoint the sync targets
+	ETA             uint64            `json:"eta"`             // estimated seconds to reach the target slot (0 if unknown)
+}
+
+// syncPhaseState is the internal state of the current sync phase.
+type syncPhaseState struct {
+	phase     SyncPhase
+	started   time.Time
+	startSlot uint64
+	spines    common.HashArray
+	prev      *syncPhaseState // phase to restore on the exit
+	exited    bool
+}
+
+// SetTargetCheckpoint sets the checkpoint the synchronisation targets.
+func (d *Downloader) SetTargetCheckpoint(cp *types.Checkpoint) {
+	d.syncStatsLock.Lock()
+	defer d.syncStatsLock.Unlock()
+
+	d.syncTargetCp = cp
+}
+
+// enterSyncPhase switches the sync to the given phase and notifies the subscribers.
+// Returns the function to restore the previous phase on the phase exit.
+// The previous phase is restored only if the current phase is still the one
+// set by this call, the phases exited meanwhile are skipped.
+func (d *Downloader) enterSyncPhase(phase SyncPhase, spines common.HashArray) func() {
+	d.syncStatsLock.Lock()
+	state := &syncPhaseState{
+		phase:     phase,
+		started:   time.Now(),
+		startSlot: d.syncCurrentSlot(phase),
+		spines:    spines.Copy(),
+		prev:      d.syncPhase,
+	}
+	d.syncPhase = state
+	d.syncStatsLock.Unlock()
+	d.postSyncPhase()
+
+	return func() {
+		d.syncStatsLock.Lock()
+		state.exited = true
+		if d.syncPhase != state {
+			d.syncStatsLock.Unlock()
+			return
+		}
+		// keep the time and slot the previous phase started from to estimate its progress
+		prev := state.prev
+		for prev != nil && prev.exited {
+			prev = prev.prev
+		}
+		d.syncPhase = prev
+		if prev == nil {
+			d.syncTargetCp = nil
+		}
+		d.syncStatsLock.Unlock()
+		d.postSyncPhase()
+	}
+}
+
+// postSyncPhase notifies the subscribers about the change of the sync phase.
+func (d *Downloader) postSyncPhase() {
+	if d.mux == nil {
+		return
+	}
+	d.mux.Post(SyncPhaseEvent{Status: d.SyncStatus()})
+}
+
+// SyncStatus retrieves the dag-aware status of the synchronisation.
+func (d *Downloader) SyncStatus() SyncStatus {
+	d.syncStatsLock.RLock()
+	state := d.syncPhase
+	cp := d.syncTargetCp
+	d.syncStatsLock.RUnlock()
+
+	if state == nil {
+		return SyncStatus{
+			Phase:       SyncPhaseIdle,
+			CurrentSlot: d.syncCurrentSlot(SyncPhaseIdle),
+			TargetSlot:  d.syncTargetSlot(SyncPhaseIdle, nil),
+		}
+	}
+	status := SyncStatus{
+		Phase:        state.phase,
+		StartedAt:    uint64(state.started.Unix()),
+		StartingSlot: state.startSlot,
+		CurrentSlot:  d.syncCurrentSlot(state.phase),
+		TargetSlot:   d.syncTargetSlot(state.phase, cp),
+		Spines:       uint64(len(state.spines)),
+		Checkpoint:   cp,
+	}
+	for _, spine := range state.spines {
+		if d.blockchain.GetHeaderByHash(spine) == nil {
+			status.SpinesRemaining++
+		}
+	}
+	status.ETA = estimateSyncTime(state.started, time.Now(), state.startSlot, status.CurrentSlot, status.TargetSlot)
+	return status
+}
+
+// syncCurrentSlot returns the slot the sync phase is currently at.
+func (d *Downloader) syncCurrentSlot(phase SyncPhase) uint64 {
+	if phase == SyncPhaseFinalized {
+		if header := d.lightchain.GetLastFinalizedHeader(); header != nil {
+			return header.Slot
+		}
+		return 0
+	}
+	return d.blockchain.GetTips().GetMaxSlot()
+}
+
+// syncTargetSlot returns the slot the sync phase targets.
+func (d *Downloader) syncTargetSlot(phase SyncPhase, cp *types.Checkpoint) uint64 {
+	si := d.lightchain.GetSlotInfo()
+	if si == nil {
+		return 0
+	}
+	target := si.CurrentSlot()
+	if phase == SyncPhaseFinalized && cp != nil {
+		if cpSlot, err := si.SlotOfEpochEnd(cp.FinEpoch); err == nil && cpSlot < target {
+			target = cpSlot
+		}
+	}
+	return target
+}
+
+// estimateSyncTime estimates the seconds remaining to reach the target slot
+// by the average rate of the progress since the start. Returns 0 if unknown.
+func estimateSyncTime(started, now time.Time, startSlot, currentSlot, targetSlot uint64) uint64 {
+	elapsed := now.Sub(started)
+	if currentSlot <= startSlot || targetSlot <= currentSlot || elapsed <= 0 {
+		return 0
+	}
+	rate := float64(currentSlot-startSlot) / elapsed.Seconds()
+	return uint64(float64(targetSlot-currentSlot) / rate)
+}
@@ -0,0 +1,75 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+// syncStatusTestChain is a chain providing the last finalized header only.
+type syncStatusTestChain struct {
+	BlockChain
+}
+
+func (c *syncStatusTestChain) GetLastFinalizedHeader() *types.Header {
+	return &types.Header{Slot: 5}
+}
+
+func TestEstimateSyncTime(t *testing.T) {
+	started := time.Unix(1000, 0)
+	now := started.Add(100 * time.Second)
+
+	// 50 slots per 100 seconds, 200 slots remaining
+	testutils.AssertEqual(t, uint64(400), estimateSyncTime(started, now, 100, 150, 350))
+	// no progress yet
+	testutils.AssertEqual(t, uint64(0), estimateSyncTime(started, now, 100, 100, 350))
+	// target reached
+	testutils.AssertEqual(t, uint64(0), estimateSyncTime(started, now, 100, 350, 350))
+	// no time elapsed
+	testutils.AssertEqual(t, uint64(0), estimateSyncTime(started, started, 100, 150, 350))
+}
+
+func TestEnterSyncPhase(t *testing.T) {
+	chain := &syncStatusTestChain{}
+	d := &Downloader{lightchain: chain, blockchain: chain}
+	d.SetTargetCheckpoint(&types.Checkpoint{FinEpoch: 2})
+
+	// nested phases
+	exitOuter := d.enterSyncPhase(SyncPhaseFinalized, nil)
+	exitInner := d.enterSyncPhase(SyncPhaseFinalized, common.HashArray{{0x01}})
+	exitInner()
+	testutils.AssertEqual(t, 0, len(d.syncPhase.spines))
+	exitOuter()
+	testutils.AssertNil(t, d.syncPhase)
+	testutils.AssertNil(t, d.syncTargetCp)
+
+	// the phases exit out of order
+	d.SetTargetCheckpoint(&types.Checkpoint{FinEpoch: 3})
+	exitFirst := d.enterSyncPhase(SyncPhaseFinalized, nil)
+	exitSecond := d.enterSyncPhase(SyncPhaseFinalized, common.HashArray{{0x01}})
+	// the first phase does not reset the second one
+	exitFirst()
+	testutils.AssertEqual(t, 1, len(d.syncPhase.spines))
+	testutils.AssertEqual(t, uint64(3), d.syncTargetCp.FinEpoch)
+	// the exited first phase is not restored
+	exitSecond()
+	testutils.AssertNil(t, d.syncPhase)
+	testutils.AssertNil(t, d.syncTargetCp)
+}
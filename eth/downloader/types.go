@@ -18,6 +18,7 @@ package downloader
 
 import (
 	"fmt"
+	"time"
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
@@ -26,6 +27,9 @@ import (
 // peerDropFn is a callback type for dropping a peer detected as malicious.
 type peerDropFn func(id string)
 
+// peerBanFn is a callback type for temporarily banning a peer detected as malicious.
+type peerBanFn func(id string, duration time.Duration)
+
 // dataPack is a data message returned by a peer for some query.
 type dataPack interface {
 	PeerId() string
@@ -83,6 +87,16 @@ func (p *dagPack) PeerId() string { return p.peerID }
 func (p *dagPack) Items() int     { return len(p.dag) }
 func (p *dagPack) Stats() string  { return fmt.Sprintf("%d", len(p.dag)) }
 
+// ancestorsPack is a batch of missing ancestors returned by a peer.
+type ancestorsPack struct {
+	peerID string
+	blocks types.Blocks
+}
+
+func (p *ancestorsPack) PeerId() string { return p.peerID }
+func (p *ancestorsPack) Items() int     { return len(p.blocks) }
+func (p *ancestorsPack) Stats() string  { return fmt.Sprintf("%d", len(p.blocks)) }
+
 type syncPeerChanType int8
 
 const (
@@ -60,6 +60,7 @@ var Defaults = Config{
 	LightPeers:              100,
 	UltraLightFraction:      75,
 	DatabaseCache:           512,
+	AncientEras:             2,
 	TrieCleanCache:          154,
 	TrieCleanCacheJournal:   "triecache",
 	TrieCleanCacheRejournal: 60 * time.Minute,
@@ -105,6 +106,10 @@ type Config struct {
 	EthDiscoveryURLs  []string
 	SnapDiscoveryURLs []string
 
+	// Whether to send the transactions directly to the peers of the creators
+	// assigned to include them in the upcoming slots.
+	TxCreatorsBroadcast bool `toml:",omitempty"`
+
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
@@ -113,6 +118,9 @@ type Config struct {
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
+	// Trusted checkpoint to start the chain from by the checkpoint sync
+	SyncCheckpoint *downloader.SyncCheckpoint `toml:"-"`
+
 	// Light client options
 	LightServ          int  `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
 	LightIngress       int  `toml:",omitempty"` // Incoming bandwidth limit for light servers
@@ -132,6 +140,8 @@ type Config struct {
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
 	DatabaseFreezer    string
+	AncientEras        uint64        // Number of recent eras whose finalized blocks are not moved to the freezer (0 = disabled)
+	DagPruneInterval   time.Duration `toml:",omitempty"` // Time interval to prune the stale unfinalized dag data (0 = disabled)
 
 	TrieCleanCache          int
 	TrieCleanCacheJournal   string        `toml:",omitempty"` // Disk journal directory for trie cache to survive node restarts
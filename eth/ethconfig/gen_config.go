@@ -21,24 +21,28 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SyncMode                downloader.SyncMode
 		EthDiscoveryURLs        []string
 		SnapDiscoveryURLs       []string
+		TxCreatorsBroadcast     bool `toml:",omitempty"`
 		NoPruning               bool
 		NoPrefetch              bool
-		TxLookupLimit           uint64                 `toml:",omitempty"`
-		Whitelist               map[uint64]common.Hash `toml:"-"`
-		LightServ               int                    `toml:",omitempty"`
-		LightIngress            int                    `toml:",omitempty"`
-		LightEgress             int                    `toml:",omitempty"`
-		LightPeers              int                    `toml:",omitempty"`
-		LightNoPrune            bool                   `toml:",omitempty"`
-		LightNoSyncServe        bool                   `toml:",omitempty"`
-		SyncFromCheckpoint      bool                   `toml:",omitempty"`
-		UltraLightServers       []string               `toml:",omitempty"`
-		UltraLightFraction      int                    `toml:",omitempty"`
-		UltraLightOnlyAnnounce  bool                   `toml:",omitempty"`
-		SkipBcVersionCheck      bool                   `toml:"-"`
-		DatabaseHandles         int                    `toml:"-"`
+		TxLookupLimit           uint64                     `toml:",omitempty"`
+		Whitelist               map[uint64]common.Hash     `toml:"-"`
+		SyncCheckpoint          *downloader.SyncCheckpoint `toml:"-"`
+		LightServ               int                        `toml:",omitempty"`
+		LightIngress            int                        `toml:",omitempty"`
+		LightEgress             int                        `toml:",omitempty"`
+		LightPeers              int                        `toml:",omitempty"`
+		LightNoPrune            bool                       `toml:",omitempty"`
+		LightNoSyncServe        bool                       `toml:",omitempty"`
+		SyncFromCheckpoint      bool                       `toml:",omitempty"`
+		UltraLightServers       []string                   `toml:",omitempty"`
+		UltraLightFraction      int                        `toml:",omitempty"`
+		UltraLightOnlyAnnounce  bool                       `toml:",omitempty"`
+		SkipBcVersionCheck      bool                       `toml:"-"`
+		DatabaseHandles         int                        `toml:"-"`
 		DatabaseCache           int
 		DatabaseFreezer         string
+		AncientEras             uint64
+		DagPruneInterval        time.Duration `toml:",omitempty"`
 		TrieCleanCache          int
 		TrieCleanCacheJournal   string        `toml:",omitempty"`
 		TrieCleanCacheRejournal time.Duration `toml:",omitempty"`
@@ -65,10 +69,12 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SyncMode = c.SyncMode
 	enc.EthDiscoveryURLs = c.EthDiscoveryURLs
 	enc.SnapDiscoveryURLs = c.SnapDiscoveryURLs
+	enc.TxCreatorsBroadcast = c.TxCreatorsBroadcast
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
 	enc.Whitelist = c.Whitelist
+	enc.SyncCheckpoint = c.SyncCheckpoint
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
 	enc.LightEgress = c.LightEgress
@@ -83,6 +89,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
 	enc.DatabaseFreezer = c.DatabaseFreezer
+	enc.AncientEras = c.AncientEras
+	enc.DagPruneInterval = c.DagPruneInterval
 	enc.TrieCleanCache = c.TrieCleanCache
 	enc.TrieCleanCacheJournal = c.TrieCleanCacheJournal
 	enc.TrieCleanCacheRejournal = c.TrieCleanCacheRejournal
@@ -113,24 +121,28 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SyncMode                *downloader.SyncMode
 		EthDiscoveryURLs        []string
 		SnapDiscoveryURLs       []string
+		TxCreatorsBroadcast     *bool `toml:",omitempty"`
 		NoPruning               *bool
 		NoPrefetch              *bool
-		TxLookupLimit           *uint64                `toml:",omitempty"`
-		Whitelist               map[uint64]common.Hash `toml:"-"`
-		LightServ               *int                   `toml:",omitempty"`
-		LightIngress            *int                   `toml:",omitempty"`
-		LightEgress             *int                   `toml:",omitempty"`
-		LightPeers              *int                   `toml:",omitempty"`
-		LightNoPrune            *bool                  `toml:",omitempty"`
-		LightNoSyncServe        *bool                  `toml:",omitempty"`
-		SyncFromCheckpoint      *bool                  `toml:",omitempty"`
-		UltraLightServers       []string               `toml:",omitempty"`
-		UltraLightFraction      *int                   `toml:",omitempty"`
-		UltraLightOnlyAnnounce  *bool                  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool                  `toml:"-"`
-		DatabaseHandles         *int                   `toml:"-"`
+		TxLookupLimit           *uint64                    `toml:",omitempty"`
+		Whitelist               map[uint64]common.Hash     `toml:"-"`
+		SyncCheckpoint          *downloader.SyncCheckpoint `toml:"-"`
+		LightServ               *int                       `toml:",omitempty"`
+		LightIngress            *int                       `toml:",omitempty"`
+		LightEgress             *int                       `toml:",omitempty"`
+		LightPeers              *int                       `toml:",omitempty"`
+		LightNoPrune            *bool                      `toml:",omitempty"`
+		LightNoSyncServe        *bool                      `toml:",omitempty"`
+		SyncFromCheckpoint      *bool                      `toml:",omitempty"`
+		UltraLightServers       []string                   `toml:",omitempty"`
+		UltraLightFraction      *int                       `toml:",omitempty"`
+		UltraLightOnlyAnnounce  *bool                      `toml:",omitempty"`
+		SkipBcVersionCheck      *bool                      `toml:"-"`
+		DatabaseHandles         *int                       `toml:"-"`
 		DatabaseCache           *int
 		DatabaseFreezer         *string
+		AncientEras             *uint64
+		DagPruneInterval        *time.Duration `toml:",omitempty"`
 		TrieCleanCache          *int
 		TrieCleanCacheJournal   *string        `toml:",omitempty"`
 		TrieCleanCacheRejournal *time.Duration `toml:",omitempty"`
@@ -170,6 +182,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SnapDiscoveryURLs != nil {
 		c.SnapDiscoveryURLs = dec.SnapDiscoveryURLs
 	}
+	if dec.TxCreatorsBroadcast != nil {
+		c.TxCreatorsBroadcast = *dec.TxCreatorsBroadcast
+	}
 	if dec.NoPruning != nil {
 		c.NoPruning = *dec.NoPruning
 	}
@@ -182,6 +197,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.Whitelist != nil {
 		c.Whitelist = dec.Whitelist
 	}
+	if dec.SyncCheckpoint != nil {
+		c.SyncCheckpoint = dec.SyncCheckpoint
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
@@ -224,6 +242,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseFreezer != nil {
 		c.DatabaseFreezer = *dec.DatabaseFreezer
 	}
+	if dec.AncientEras != nil {
+		c.AncientEras = *dec.AncientEras
+	}
+	if dec.DagPruneInterval != nil {
+		c.DagPruneInterval = *dec.DagPruneInterval
+	}
 	if dec.TrieCleanCache != nil {
 		c.TrieCleanCache = *dec.TrieCleanCache
 	}
@@ -28,8 +28,10 @@ import (
 	ethereum "gitlab.waterfall.network/waterfall/protocol/gwat"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
 )
 
@@ -238,6 +240,10 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
+// The logs are sent at the finality level of the criteria:
+// "optimistic" at the earliest level available, "finalized" (default) once the
+// block is finalized and "checkpointed" once the block is covered by a coordinated checkpoint. Logs of rolled back blocks are sent
+// again with the removed property set to true.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -267,6 +273,9 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 			case <-notifier.Closed(): // connection dropped
 				logsSub.Unsubscribe()
 				return
+			case err := <-logsSub.Err(): // subscription dropped by the event system
+				log.Warn("Logs subscription dropped", "id", rpcSub.ID, "err", err)
+				return
 			}
 		}
 	}()
@@ -327,22 +336,12 @@ func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 //
 // https://eth.wiki/json-rpc/API#eth_getlogs
 func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
-	var filter *Filter
-	if crit.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.backend, *crit.BlockHash, crit.Addresses, crit.Topics)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if crit.FromBlock != nil {
-			begin = crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if crit.ToBlock != nil {
-			end = crit.ToBlock.Int64()
-		}
-		// Construct the range filter
-		filter = NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
+	filter, err := api.logsFilter(ctx, crit)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return returnLogs(nil), nil
 	}
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
@@ -352,6 +351,85 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	return returnLogs(logs), err
 }
 
+// logsFilter constructs the filter of the criteria bounded by its finality level.
+// Only finalized blocks have logs in the state, so the finalized level is not
+// limited, while the checkpointed level limits the range with the spine of
+// the last coordinated checkpoint.
+// It returns nil if no blocks of the criteria reach the finality level.
+func (api *PublicFilterAPI) logsFilter(ctx context.Context, crit FilterCriteria) (*Filter, error) {
+	var cpNr *uint64
+	if crit.Finality == ethereum.LogsCheckpointed {
+		nr, err := api.checkpointNr(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cpNr = &nr
+	}
+	if crit.BlockHash != nil {
+		if cpNr != nil {
+			nr := rawdb.ReadFinalizedNumberByHash(api.chainDb, *crit.BlockHash)
+			if nr == nil || *nr > *cpNr {
+				return nil, nil
+			}
+		}
+		// Block filter requested, construct a single-shot filter
+		return NewBlockFilter(api.backend, *crit.BlockHash, crit.Addresses, crit.Topics), nil
+	}
+	// Convert the RPC block numbers into internal representations
+	begin := rpc.LatestBlockNumber.Int64()
+	if crit.FromBlock != nil {
+		begin = crit.FromBlock.Int64()
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if crit.ToBlock != nil {
+		end = crit.ToBlock.Int64()
+	}
+	if begin == rpc.CheckpointBlockNumber.Int64() || end == rpc.CheckpointBlockNumber.Int64() {
+		nr, err := api.checkpointNr(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if begin == rpc.CheckpointBlockNumber.Int64() {
+			begin = int64(nr)
+		}
+		if end == rpc.CheckpointBlockNumber.Int64() {
+			end = int64(nr)
+		}
+	}
+	if begin == rpc.FinalizedBlockNumber.Int64() {
+		begin = rpc.LatestBlockNumber.Int64()
+	}
+	if end == rpc.FinalizedBlockNumber.Int64() {
+		end = rpc.LatestBlockNumber.Int64()
+	}
+	if cpNr != nil {
+		if begin == rpc.LatestBlockNumber.Int64() {
+			begin = int64(*cpNr)
+		}
+		if end == rpc.LatestBlockNumber.Int64() || end > int64(*cpNr) {
+			end = int64(*cpNr)
+		}
+	}
+	// Construct the range filter
+	return NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics), nil
+}
+
+// checkpointNr returns the finalized number of the spine of the last coordinated checkpoint.
+func (api *PublicFilterAPI) checkpointNr(ctx context.Context) (uint64, error) {
+	header, err := api.backend.HeaderByNumber(ctx, rpc.CheckpointBlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, errors.New("checkpoint not found")
+	}
+	nr := rawdb.ReadFinalizedNumberByHash(api.chainDb, header.Hash())
+	if nr == nil {
+		return 0, errors.New("checkpoint spine is not finalized")
+	}
+	return *nr, nil
+}
+
 // UninstallFilter removes the filter with the given filter id.
 //
 // https://eth.wiki/json-rpc/API#eth_uninstallfilter
@@ -382,22 +460,12 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 		return nil, fmt.Errorf("filter not found")
 	}
 
-	var filter *Filter
-	if f.crit.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.backend, *f.crit.BlockHash, f.crit.Addresses, f.crit.Topics)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if f.crit.FromBlock != nil {
-			begin = f.crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if f.crit.ToBlock != nil {
-			end = f.crit.ToBlock.Int64()
-		}
-		// Construct the range filter
-		filter = NewRangeFilter(api.backend, begin, end, f.crit.Addresses, f.crit.Topics)
+	filter, err := api.logsFilter(ctx, f.crit)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return returnLogs(nil), nil
 	}
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
@@ -467,6 +535,7 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 		ToBlock   *rpc.BlockNumber `json:"toBlock"`
 		Addresses interface{}      `json:"address"`
 		Topics    []interface{}    `json:"topics"`
+		Finality  string           `json:"finality"`
 	}
 
 	var raw input
@@ -490,6 +559,13 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 		}
 	}
 
+	switch finality := ethereum.LogFinality(raw.Finality); finality {
+	case "", ethereum.LogsOptimistic, ethereum.LogsFinalized, ethereum.LogsCheckpointed:
+		args.Finality = finality
+	default:
+		return fmt.Errorf("invalid finality %q, expected one of optimistic, finalized or checkpointed", raw.Finality)
+	}
+
 	args.Addresses = []common.Address{}
 
 	if raw.Addresses != nil {
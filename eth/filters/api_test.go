@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"testing"
 
+	ethereum "gitlab.waterfall.network/waterfall/protocol/gwat"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
 )
@@ -182,4 +183,24 @@ func TestUnmarshalJSONNewFilterArgs(t *testing.T) {
 	if len(test7.Topics[2]) != 0 {
 		t.Fatalf("expected 0 topics, got %d topics", len(test7.Topics[2]))
 	}
+
+	// finality level
+	var test8 FilterCriteria
+	if err := json.Unmarshal([]byte(`{"finality": "checkpointed"}`), &test8); err != nil {
+		t.Fatal(err)
+	}
+	if test8.Finality != ethereum.LogsCheckpointed {
+		t.Fatalf("expected %s finality, got %s", ethereum.LogsCheckpointed, test8.Finality)
+	}
+	var test9 FilterCriteria
+	if err := json.Unmarshal([]byte(`{"finality": "safe"}`), &test9); err == nil {
+		t.Fatal("expected error for unknown finality")
+	}
+	var test10 FilterCriteria
+	if err := json.Unmarshal([]byte(`{"finality": "optimistic"}`), &test10); err != nil {
+		t.Fatal(err)
+	}
+	if test10.Finality != ethereum.LogsOptimistic {
+		t.Fatalf("expected %s finality, got %s", ethereum.LogsOptimistic, test10.Finality)
+	}
 }
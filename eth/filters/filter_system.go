@@ -20,6 +20,7 @@ package filters
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -66,8 +67,17 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+	// maxHeldLogs is the maximum number of logs a subscription
+	// holds while waiting for the finality level of its criteria.
+	maxHeldLogs = 10000
 )
 
+// errTooManyHeldLogs is returned by a subscription which is dropped because
+// too many of its logs are waiting for the finality level of the criteria.
+var errTooManyHeldLogs = errors.New("too many logs waiting for finality, subscription dropped")
+
 type subscription struct {
 	id        rpc.ID
 	typ       Type
@@ -76,8 +86,10 @@ type subscription struct {
 	logs      chan []*types.Log
 	hashes    chan []common.Hash
 	headers   chan *types.Header
+	held      []*types.Log  // logs waiting for the finality level of the criteria
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
+	dropped   bool          // set when the filter is uninstalled by the event loop
 }
 
 // EventSystem creates subscriptions, processes events and broadcasts them to the
@@ -93,6 +105,7 @@ type EventSystem struct {
 	rmLogsSub      event.Subscription // Subscription for removed log event
 	pendingLogsSub event.Subscription // Subscription for pending log event
 	chainSub       event.Subscription // Subscription for new chain event
+	chainHeadSub   event.Subscription // Subscription for new finalized head event
 
 	// Channels
 	install       chan *subscription         // install filter for event notification
@@ -102,6 +115,7 @@ type EventSystem struct {
 	pendingLogsCh chan []*types.Log          // Channel to receive new log event
 	rmLogsCh      chan core.RemovedLogsEvent // Channel to receive removed log event
 	chainCh       chan core.ChainEvent       // Channel to receive new chain event
+	chainHeadCh   chan core.ChainHeadEvent   // Channel to receive new finalized head event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -121,6 +135,7 @@ func NewEventSystem(backend Backend, lightMode bool) *EventSystem {
 		rmLogsCh:      make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		pendingLogsCh: make(chan []*types.Log, logsChanSize),
 		chainCh:       make(chan core.ChainEvent, chainEvChanSize),
+		chainHeadCh:   make(chan core.ChainHeadEvent, chainHeadChanSize),
 	}
 
 	// Subscribe events
@@ -129,9 +144,10 @@ func NewEventSystem(backend Backend, lightMode bool) *EventSystem {
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 	m.pendingLogsSub = m.backend.SubscribePendingLogsEvent(m.pendingLogsCh)
+	m.chainHeadSub = m.backend.SubscribeChainHeadEvent(m.chainHeadCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.pendingLogsSub == nil || m.chainHeadSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -147,7 +163,8 @@ type Subscription struct {
 	unsubOnce sync.Once
 }
 
-// Err returns a channel that is closed when unsubscribed.
+// Err returns a channel that is closed when unsubscribed. A subscription
+// dropped by the event system receives the reason before the channel is closed.
 func (sub *Subscription) Err() <-chan error {
 	return sub.f.err
 }
@@ -187,7 +204,14 @@ func (es *EventSystem) subscribe(sub *subscription) *Subscription {
 // SubscribeLogs creates a subscription that will write all logs matching the
 // given criteria to the given logs channel. Default value for the from and to
 // block is "latest". If the fromBlock > toBlock an error is returned.
+// Logs are written at the finality level of the criteria, pending logs are
+// supported only at the default and optimistic levels.
 func (es *EventSystem) SubscribeLogs(crit ethereum.FilterQuery, logs chan []*types.Log) (*Subscription, error) {
+	switch crit.Finality {
+	case "", ethereum.LogsOptimistic, ethereum.LogsFinalized, ethereum.LogsCheckpointed:
+	default:
+		return nil, fmt.Errorf("invalid finality: %s", crit.Finality)
+	}
 	var from, to rpc.BlockNumber
 	if crit.FromBlock == nil {
 		from = rpc.LatestBlockNumber
@@ -200,6 +224,16 @@ func (es *EventSystem) SubscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 		to = rpc.BlockNumber(crit.ToBlock.Int64())
 	}
 
+	// the finalized and checkpoint tags follow the new logs as the latest one
+	if from == rpc.FinalizedBlockNumber || from == rpc.CheckpointBlockNumber {
+		from = rpc.LatestBlockNumber
+	}
+	if to == rpc.FinalizedBlockNumber || to == rpc.CheckpointBlockNumber {
+		to = rpc.LatestBlockNumber
+	}
+	if crit.Finality != "" && crit.Finality != ethereum.LogsOptimistic && (from == rpc.PendingBlockNumber || to == rpc.PendingBlockNumber) {
+		return nil, fmt.Errorf("pending logs are not supported at %s finality", crit.Finality)
+	}
 	// only interested in pending logs
 	if from == rpc.PendingBlockNumber && to == rpc.PendingBlockNumber {
 		return es.subscribePendingLogs(crit, logs), nil
@@ -252,7 +286,7 @@ func (es *EventSystem) subscribeLogs(crit ethereum.FilterQuery, logs chan []*typ
 		hashes:    make(chan []common.Hash),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
-		err:       make(chan error),
+		err:       make(chan error, 1), // buffered for the reason of a drop
 	}
 	return es.subscribe(sub)
 }
@@ -314,9 +348,19 @@ func (es *EventSystem) handleLogs(filters filterIndex, ev []*types.Log) {
 	}
 	for _, f := range filters[LogsSubscription] {
 		matchedLogs := filterLogs(ev, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics)
-		if len(matchedLogs) > 0 {
-			f.logs <- matchedLogs
+		if len(matchedLogs) == 0 {
+			continue
 		}
+		if isDeferred(f.logsCrit.Finality) {
+			// hold the logs until their blocks reach the required finality
+			if len(f.held)+len(matchedLogs) > maxHeldLogs {
+				es.drop(filters, f, errTooManyHeldLogs)
+				continue
+			}
+			f.held = append(f.held, matchedLogs...)
+			continue
+		}
+		f.logs <- matchedLogs
 	}
 }
 
@@ -335,9 +379,123 @@ func (es *EventSystem) handlePendingLogs(filters filterIndex, ev []*types.Log) {
 func (es *EventSystem) handleRemovedLogs(filters filterIndex, ev core.RemovedLogsEvent) {
 	for _, f := range filters[LogsSubscription] {
 		matchedLogs := filterLogs(ev.Logs, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics)
-		if len(matchedLogs) > 0 {
-			f.logs <- matchedLogs
+		if len(matchedLogs) == 0 {
+			continue
+		}
+		if isDeferred(f.logsCrit.Finality) {
+			// the held logs were never sent, so they are dropped silently
+			f.held, matchedLogs = dropHeldLogs(f.held, matchedLogs)
+			if len(matchedLogs) == 0 {
+				continue
+			}
 		}
+		f.logs <- matchedLogs
+	}
+}
+
+// handleChainHeadEvent sends the held logs which blocks reached
+// the finality level of the subscriptions.
+func (es *EventSystem) handleChainHeadEvent(filters filterIndex, ev core.ChainHeadEvent) {
+	var (
+		limit   *uint64
+		limited bool
+	)
+	for _, f := range filters[LogsSubscription] {
+		if len(f.held) == 0 {
+			continue
+		}
+		if !limited {
+			limit, limited = es.checkpointLimit(), true
+		}
+		if limit == nil {
+			continue
+		}
+		var released []*types.Log
+		released, f.held = es.releaseLogs(f.held, *limit)
+		if len(released) > 0 {
+			f.logs <- released
+		}
+	}
+}
+
+// checkpointLimit returns the highest finalized number covered by
+// the last coordinated checkpoint or nil if it is unknown.
+func (es *EventSystem) checkpointLimit() *uint64 {
+	header, _ := es.backend.HeaderByNumber(context.Background(), rpc.CheckpointBlockNumber)
+	if header == nil {
+		return nil
+	}
+	return rawdb.ReadFinalizedNumberByHash(es.backend.ChainDb(), header.Hash())
+}
+
+// releaseLogs splits the held logs into the ones of blocks finalized
+// up to the given limit and the ones which are still waiting.
+func (es *EventSystem) releaseLogs(held []*types.Log, limit uint64) (released, rest []*types.Log) {
+	finalized := make(map[common.Hash]bool)
+	for _, l := range held {
+		ok, known := finalized[l.BlockHash]
+		if !known {
+			nr := rawdb.ReadFinalizedNumberByHash(es.backend.ChainDb(), l.BlockHash)
+			ok = nr != nil && *nr <= limit
+			finalized[l.BlockHash] = ok
+		}
+		if ok {
+			released = append(released, l)
+		} else {
+			rest = append(rest, l)
+		}
+	}
+	return released, rest
+}
+
+// dropHeldLogs removes the held logs of the blocks of the removed logs.
+// It returns the rest of the held logs and the removed logs of
+// the blocks which logs were already sent.
+func dropHeldLogs(held, removed []*types.Log) (rest, sent []*types.Log) {
+	heldBlocks := make(map[common.Hash]bool)
+	for _, l := range held {
+		heldBlocks[l.BlockHash] = true
+	}
+	removedBlocks := make(map[common.Hash]bool)
+	for _, l := range removed {
+		removedBlocks[l.BlockHash] = true
+		if !heldBlocks[l.BlockHash] {
+			sent = append(sent, l)
+		}
+	}
+	for _, l := range held {
+		if !removedBlocks[l.BlockHash] {
+			rest = append(rest, l)
+		}
+	}
+	return rest, sent
+}
+
+// isDeferred reports whether logs of the finality level are held
+// after their blocks are finalized. The logs are emitted at finalization,
+// so only the checkpointed level is deferred.
+func isDeferred(finality ethereum.LogFinality) bool {
+	return finality == ethereum.LogsCheckpointed
+}
+
+// drop uninstalls the filter from the event loop and reports the reason
+// to the subscriber. A later Unsubscribe call of the dropped filter is a noop.
+func (es *EventSystem) drop(filters filterIndex, f *subscription, err error) {
+	uninstall(filters, f)
+	f.held = nil
+	f.dropped = true
+	f.err <- err
+	close(f.err)
+}
+
+// uninstall removes the filter from the index.
+func uninstall(filters filterIndex, f *subscription) {
+	if f.typ == MinedAndPendingLogsSubscription {
+		// the type are logs and pending logs subscriptions
+		delete(filters[LogsSubscription], f.id)
+		delete(filters[PendingLogsSubscription], f.id)
+	} else {
+		delete(filters[f.typ], f.id)
 	}
 }
 
@@ -454,6 +612,7 @@ func (es *EventSystem) eventLoop() {
 		es.rmLogsSub.Unsubscribe()
 		es.pendingLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.chainHeadSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -473,6 +632,8 @@ func (es *EventSystem) eventLoop() {
 			es.handlePendingLogs(index, ev)
 		case ev := <-es.chainCh:
 			es.handleChainEvent(index, ev)
+		case ev := <-es.chainHeadCh:
+			es.handleChainHeadEvent(index, ev)
 
 		case f := <-es.install:
 			if f.typ == MinedAndPendingLogsSubscription {
@@ -485,13 +646,11 @@ func (es *EventSystem) eventLoop() {
 			close(f.installed)
 
 		case f := <-es.uninstall:
-			if f.typ == MinedAndPendingLogsSubscription {
-				// the type are logs and pending logs subscriptions
-				delete(index[LogsSubscription], f.id)
-				delete(index[PendingLogsSubscription], f.id)
-			} else {
-				delete(index[f.typ], f.id)
+			if f.dropped {
+				// already uninstalled by the event loop
+				break
 			}
+			uninstall(index, f)
 			close(f.err)
 
 		// System stopped
@@ -503,6 +662,8 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.chainSub.Err():
 			return
+		case <-es.chainHeadSub.Err():
+			return
 		}
 	}
 }
@@ -53,6 +53,8 @@ type testBackend struct {
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
 	chainFeed       event.Feed
+	chainHeadFeed   event.Feed
+	cpHash          common.Hash
 }
 
 func (b *testBackend) ChainDb() ethdb.Database {
@@ -64,12 +66,14 @@ func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumbe
 		hash common.Hash
 		num  uint64
 	)
-	if blockNr == rpc.LatestBlockNumber {
+	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.FinalizedBlockNumber {
 		hash = rawdb.ReadLastCanonicalHash(b.db)
 		number := rawdb.ReadFinalizedNumberByHash(b.db, hash)
 		if number == nil {
 			return nil, nil
 		}
+	} else if blockNr == rpc.CheckpointBlockNumber {
+		hash = b.cpHash
 	} else {
 		num = uint64(blockNr)
 		hash = rawdb.ReadFinalizedHashByNumber(b.db, num)
@@ -126,6 +130,10 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.chainHeadFeed.Subscribe(ch)
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
@@ -488,6 +496,133 @@ func TestLogFilter(t *testing.T) {
 	}
 }
 
+// TestLogFinalitySubscription tests whether log subscriptions send logs
+// at the finality level of their criteria.
+func TestLogFinalitySubscription(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		api     = NewPublicFilterAPI(backend, false, deadline)
+
+		header1 = &types.Header{Slot: 1, Height: 1}
+		header2 = &types.Header{Slot: 2, Height: 2}
+		log1    = &types.Log{Address: common.HexToAddress("0x1111"), BlockHash: header1.Hash()}
+		log2    = &types.Log{Address: common.HexToAddress("0x2222"), BlockHash: header2.Hash()}
+	)
+	rawdb.WriteHeader(db, header1)
+	rawdb.WriteHeader(db, header2)
+
+	subscribe := func(finality ethereum.LogFinality) chan []*types.Log {
+		ch := make(chan []*types.Log, 8)
+		sub, err := api.events.SubscribeLogs(ethereum.FilterQuery{Finality: finality}, ch)
+		testutils.AssertNoError(t, err)
+		t.Cleanup(sub.Unsubscribe)
+		return ch
+	}
+	expect := func(name string, ch chan []*types.Log, want ...*types.Log) {
+		t.Helper()
+		select {
+		case logs := <-ch:
+			if !reflect.DeepEqual(logs, want) {
+				t.Fatalf("%s: unexpected logs: want %v, got %v", name, want, logs)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: logs not received", name)
+		}
+	}
+	expectNone := func(name string, ch chan []*types.Log) {
+		t.Helper()
+		select {
+		case logs := <-ch:
+			t.Fatalf("%s: unexpected logs %v", name, logs)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	if _, err := api.events.SubscribeLogs(ethereum.FilterQuery{Finality: "unknown"}, make(chan []*types.Log)); err == nil {
+		t.Fatal("expected error for unknown finality")
+	}
+	pendingCrit := ethereum.FilterQuery{
+		Finality:  ethereum.LogsFinalized,
+		FromBlock: big.NewInt(rpc.PendingBlockNumber.Int64()),
+		ToBlock:   big.NewInt(rpc.PendingBlockNumber.Int64()),
+	}
+	if _, err := api.events.SubscribeLogs(pendingCrit, make(chan []*types.Log)); err == nil {
+		t.Fatal("expected error for pending logs at finalized level")
+	}
+
+	optimisticPending := pendingCrit
+	optimisticPending.Finality = ethereum.LogsOptimistic
+	if _, err := api.events.SubscribeLogs(optimisticPending, make(chan []*types.Log)); err != nil {
+		t.Fatalf("unexpected error for pending logs at optimistic level: %v", err)
+	}
+
+	byDefault := subscribe("")
+	optimistic := subscribe(ethereum.LogsOptimistic)
+	finalized := subscribe(ethereum.LogsFinalized)
+	checkpointed := subscribe(ethereum.LogsCheckpointed)
+
+	// the logs are emitted at finalization of the blocks
+	rawdb.WriteFinalizedHashNumber(db, header1.Hash(), 1)
+	rawdb.WriteFinalizedHashNumber(db, header2.Hash(), 2)
+	rawdb.WriteLastCanonicalHash(db, header2.Hash())
+	backend.logsFeed.Send([]*types.Log{log1, log2})
+	expect("default", byDefault, log1, log2)
+	expect("optimistic", optimistic, log1, log2)
+	expect("finalized", finalized, log1, log2)
+	expectNone("checkpointed", checkpointed)
+
+	// the first block is covered by the checkpoint
+	backend.cpHash = header1.Hash()
+	backend.chainHeadFeed.Send(core.ChainHeadEvent{})
+	expect("checkpointed", checkpointed, log1)
+
+	// the not checkpointed block is rolled back
+	removed2 := &types.Log{Address: log2.Address, BlockHash: log2.BlockHash, Removed: true}
+	backend.rmLogsFeed.Send(core.RemovedLogsEvent{Logs: []*types.Log{removed2}})
+	expect("default", byDefault, removed2)
+	expect("optimistic", optimistic, removed2)
+	expect("finalized", finalized, removed2)
+	expectNone("checkpointed", checkpointed)
+
+	// the checkpointed block is rolled back
+	removed1 := &types.Log{Address: log1.Address, BlockHash: log1.BlockHash, Removed: true}
+	backend.rmLogsFeed.Send(core.RemovedLogsEvent{Logs: []*types.Log{removed1}})
+	expect("finalized", finalized, removed1)
+	expect("checkpointed", checkpointed, removed1)
+
+	// the rolled back block is covered by the next checkpoint
+	backend.cpHash = header2.Hash()
+	backend.chainHeadFeed.Send(core.ChainHeadEvent{})
+	expectNone("checkpointed", checkpointed)
+}
+
+// TestLogFinalitySubscriptionDropped tests whether a subscription which holds
+// too many logs waiting for the finality level is dropped with an error.
+func TestLogFinalitySubscriptionDropped(t *testing.T) {
+	var (
+		backend = &testBackend{db: rawdb.NewMemoryDatabase()}
+		api     = NewPublicFilterAPI(backend, false, deadline)
+		logs    = make([]*types.Log, maxHeldLogs/2+1)
+	)
+	for i := range logs {
+		logs[i] = &types.Log{Address: common.HexToAddress("0x1111"), BlockHash: common.Hash{0x01}}
+	}
+	sub, err := api.events.SubscribeLogs(ethereum.FilterQuery{Finality: ethereum.LogsCheckpointed}, make(chan []*types.Log))
+	testutils.AssertNoError(t, err)
+
+	backend.logsFeed.Send(logs)
+	backend.logsFeed.Send(logs)
+	select {
+	case err := <-sub.Err():
+		testutils.AssertEqual(t, errTooManyHeldLogs, err)
+	case <-time.After(time.Second):
+		t.Fatal("subscription not dropped")
+	}
+	// unsubscribing of the dropped subscription does not block
+	sub.Unsubscribe()
+}
+
 // TestPendingLogsSubscription tests if a subscription receives the correct pending logs that are posted to the event feed.
 func TestPendingLogsSubscription(t *testing.T) {
 
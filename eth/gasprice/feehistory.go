@@ -23,11 +23,9 @@ import (
 	"fmt"
 	"math"
 	"math/big"
-	"sort"
 	"sync/atomic"
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
-	"gitlab.waterfall.network/waterfall/protocol/gwat/consensus/misc"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
@@ -61,6 +59,7 @@ type processedFees struct {
 	reward               []*big.Int
 	baseFee, nextBaseFee *big.Int
 	gasUsedRatio         float64
+	slot                 uint64
 }
 
 // txGasAndReward is sorted in ascending order based on reward
@@ -84,28 +83,21 @@ func (s sortGasAndReward) Less(i, j int) bool {
 // the block field filled in, retrieves the block from the backend if not present yet and
 // fills in the rest of the fields.
 func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
-	chainconfig := oracle.backend.ChainConfig()
 	if bf.results.baseFee = bf.header.BaseFee; bf.results.baseFee == nil {
 		bf.results.baseFee = new(big.Int)
 	}
-	// Get active validators number
-	bc := oracle.backend.BlockChain()
-	genesisGasLimit := oracle.backend.Genesis().GasLimit()
-	validatorsCount, err := bc.ValidatorStorage().GetActiveValidatorsCount(bc, bf.block.Slot())
+	bf.results.slot = bf.header.Slot
+	nextBaseFee, err := oracle.slotBaseFee(bf.header.Slot)
 	if err != nil {
 		log.Error(
 			"Block processing error, can`t calculate base fee",
-			"blockHash", bf.block.Hash().Hex(),
-			"blockSlot", bf.block.Slot(),
+			"blockHash", bf.header.Hash().Hex(),
+			"blockSlot", bf.header.Slot,
 			"error", err,
 		)
 		return
 	}
-	creatorsPerSlotCount := oracle.backend.ChainConfig().ValidatorsPerSlot
-	if creatorsPerSlot, err := bc.ValidatorStorage().GetCreatorsBySlot(bc, bf.header.Slot); err == nil {
-		creatorsPerSlotCount = uint64(len(creatorsPerSlot))
-	}
-	bf.results.nextBaseFee = misc.CalcSlotBaseFee(chainconfig, creatorsPerSlotCount, validatorsCount, genesisGasLimit, bf.header.Slot)
+	bf.results.nextBaseFee = nextBaseFee
 	bf.results.gasUsedRatio = float64(bf.header.GasUsed) / float64(bf.header.GasLimit)
 	if len(percentiles) == 0 {
 		// rewards were not requested, return null
@@ -116,33 +108,25 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 		return
 	}
 
-	bf.results.reward = make([]*big.Int, len(percentiles))
-	if len(bf.block.Transactions()) == 0 {
-		// return an all zero row if there are no transactions to gather data from
-		for i := range bf.results.reward {
-			bf.results.reward[i] = new(big.Int)
-		}
-		return
-	}
-
 	sorter := make(sortGasAndReward, len(bf.block.Transactions()))
 	for i, tx := range bf.block.Transactions() {
 		reward, _ := tx.EffectiveGasTip(bf.block.BaseFee())
 		sorter[i] = txGasAndReward{gasUsed: bf.receipts[i].GasUsed, reward: reward}
 	}
-	sort.Sort(sorter)
-
-	var txIndex int
-	sumGasUsed := sorter[0].gasUsed
+	bf.results.reward = calcRewards(sorter, bf.block.GasUsed(), percentiles)
+}
 
-	for i, p := range percentiles {
-		thresholdGasUsed := uint64(float64(bf.block.GasUsed()) * p / 100)
-		for sumGasUsed < thresholdGasUsed && txIndex < len(bf.block.Transactions())-1 {
-			txIndex++
-			sumGasUsed += sorter[txIndex].gasUsed
+// checkPercentiles checks that the reward percentiles are in range and sorted.
+func checkPercentiles(rewardPercentiles []float64) error {
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 {
+			return fmt.Errorf("%w: %f", errInvalidPercentile, p)
+		}
+		if i > 0 && p < rewardPercentiles[i-1] {
+			return fmt.Errorf("%w: #%d:%f > #%d:%f", errInvalidPercentile, i-1, rewardPercentiles[i-1], i, p)
 		}
-		bf.results.reward[i] = sorter[txIndex].reward
 	}
+	return nil
 }
 
 // resolveBlockRange resolves the specified block range to absolute block numbers while also
@@ -201,12 +185,13 @@ func (oracle *Oracle) resolveBlockRange(ctx context.Context, lastBlock rpc.Block
 //     block, sorted in ascending order and weighted by gas used.
 //   - baseFee: base fee per gas in the given block
 //   - gasUsedRatio: gasUsed/gasLimit in the given block
+//   - congestion: gasUsed/capacity of the slot of the given block aggregated over all its blocks
 //
 // Note: baseFee includes the next block after the newest of the returned range, because this
-// value can be derived from the newest block.
-func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
+// value can be derived from the slot following the newest block.
+func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []float64, error) {
 	if blocks < 1 {
-		return common.Big0, nil, nil, nil, nil // returning with no data and no error means there are no retrievable blocks
+		return common.Big0, nil, nil, nil, nil, nil // returning with no data and no error means there are no retrievable blocks
 	}
 	maxFeeHistory := oracle.maxHeaderHistory
 	if len(rewardPercentiles) != 0 {
@@ -216,13 +201,8 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		log.Warn("Sanitizing fee history length", "requested", blocks, "truncated", maxFeeHistory)
 		blocks = maxFeeHistory
 	}
-	for i, p := range rewardPercentiles {
-		if p < 0 || p > 100 {
-			return common.Big0, nil, nil, nil, fmt.Errorf("%w: %f", errInvalidPercentile, p)
-		}
-		if i > 0 && p < rewardPercentiles[i-1] {
-			return common.Big0, nil, nil, nil, fmt.Errorf("%w: #%d:%f > #%d:%f", errInvalidPercentile, i-1, rewardPercentiles[i-1], i, p)
-		}
+	if err := checkPercentiles(rewardPercentiles); err != nil {
+		return common.Big0, nil, nil, nil, nil, err
 	}
 	var (
 		pendingBlock    *types.Block
@@ -231,7 +211,7 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 	)
 	pendingBlock, pendingReceipts, lastBlock, blocks, err := oracle.resolveBlockRange(ctx, unresolvedLastBlock, blocks)
 	if err != nil || blocks == 0 {
-		return common.Big0, nil, nil, nil, err
+		return common.Big0, nil, nil, nil, nil, err
 	}
 	oldestBlock := lastBlock + 1 - uint64(blocks)
 
@@ -294,16 +274,19 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		reward       = make([][]*big.Int, blocks)
 		baseFee      = make([]*big.Int, blocks+1)
 		gasUsedRatio = make([]float64, blocks)
+		congestion   = make([]float64, blocks)
+		slots        = make([]uint64, blocks)
 		firstMissing = blocks
 	)
 	for ; blocks > 0; blocks-- {
 		fees := <-results
 		if fees.err != nil {
-			return common.Big0, nil, nil, nil, fees.err
+			return common.Big0, nil, nil, nil, nil, fees.err
 		}
 		i := int(fees.blockNumber - oldestBlock)
 		if fees.results.baseFee != nil {
 			reward[i], baseFee[i], baseFee[i+1], gasUsedRatio[i] = fees.results.reward, fees.results.baseFee, fees.results.nextBaseFee, fees.results.gasUsedRatio
+			slots[i] = fees.results.slot
 		} else {
 			// getting no block and no error means we are requesting into the future (might happen because of a reorg)
 			if i < firstMissing {
@@ -312,13 +295,29 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 		}
 	}
 	if firstMissing == 0 {
-		return common.Big0, nil, nil, nil, nil
+		return common.Big0, nil, nil, nil, nil, nil
 	}
 	if len(rewardPercentiles) != 0 {
 		reward = reward[:firstMissing]
 	} else {
 		reward = nil
 	}
-	baseFee, gasUsedRatio = baseFee[:firstMissing+1], gasUsedRatio[:firstMissing]
-	return new(big.Int).SetUint64(oldestBlock), reward, baseFee, gasUsedRatio, nil
+	baseFee, gasUsedRatio, congestion = baseFee[:firstMissing+1], gasUsedRatio[:firstMissing], congestion[:firstMissing]
+	// the congestion is calculated once per slot, as the blocks of the range share slots
+	slotCongestion := make(map[uint64]float64)
+	for i := range congestion {
+		c, ok := slotCongestion[slots[i]]
+		if !ok {
+			if slotFees, err := oracle.slotFees(ctx, slots[i], nil, nil); err == nil {
+				c = slotFees.Congestion
+			}
+			slotCongestion[slots[i]] = c
+		}
+		congestion[i] = c
+	}
+	// the next block is created in one of the following slots
+	if baseFee[firstMissing] != nil {
+		baseFee[firstMissing] = oracle.nextSlotBaseFee(slots[firstMissing-1], baseFee[firstMissing])
+	}
+	return new(big.Int).SetUint64(oldestBlock), reward, baseFee, gasUsedRatio, congestion, nil
 }
@@ -33,7 +33,7 @@ import (
 	valStore "gitlab.waterfall.network/waterfall/protocol/gwat/validator/storage"
 )
 
-const sampleNumber = 3 // Number of transactions sampled in a block
+const sampleNumber = 3 // Number of transactions sampled in a slot
 
 var (
 	DefaultMaxPrice    = big.NewInt(500 * params.GWei)
@@ -136,7 +136,8 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 }
 
 // SuggestTipCap returns a tip cap so that newly created transaction can have a
-// very high chance to be included in the following blocks.
+// very high chance to be included in the following blocks. The tips are sampled
+// from the recent slots, all blocks of each slot are aggregated.
 //
 // Note, for legacy transactions and the legacy eth_gasPrice RPC call, it will be
 // necessary to add the basefee to the returned number to fall back to the legacy
@@ -164,16 +165,16 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	}
 	var (
 		sent, exp int
-		number    = head.Nr()
+		slot      = head.Slot
 		result    = make(chan results, oracle.checkBlocks)
 		quit      = make(chan struct{})
 		results   []*big.Int
 	)
-	for sent < oracle.checkBlocks && number > 0 {
-		go oracle.getBlockValues(ctx, types.MakeSigner(oracle.backend.ChainConfig()), number, sampleNumber, oracle.ignorePrice, result, quit)
+	for sent < oracle.checkBlocks && slot > 0 {
+		go oracle.getSlotValues(ctx, types.MakeSigner(oracle.backend.ChainConfig()), slot, sampleNumber, oracle.ignorePrice, result, quit)
 		sent++
 		exp++
-		number--
+		slot--
 	}
 	for exp > 0 {
 		res := <-result
@@ -183,20 +184,20 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 		}
 		exp--
 		// Nothing returned. There are two special cases here:
-		// - The block is empty
-		// - All the transactions included are sent by the miner itself.
+		// - The slot has no transactions
+		// - All the transactions included are sent by the creators of the blocks.
 		// In these cases, use the latest calculated price for sampling.
 		if len(res.values) == 0 {
 			res.values = []*big.Int{lastPrice}
 		}
 		// Besides, in order to collect enough data for sampling, if nothing
-		// meaningful returned, try to query more blocks. But the maximum
+		// meaningful returned, try to query more slots. But the maximum
 		// is 2*checkBlocks.
-		if len(res.values) == 1 && len(results)+1+exp < oracle.checkBlocks*2 && number > 0 {
-			go oracle.getBlockValues(ctx, types.MakeSigner(oracle.backend.ChainConfig()), number, sampleNumber, oracle.ignorePrice, result, quit)
+		if len(res.values) == 1 && len(results)+1+exp < oracle.checkBlocks*2 && slot > 0 {
+			go oracle.getSlotValues(ctx, types.MakeSigner(oracle.backend.ChainConfig()), slot, sampleNumber, oracle.ignorePrice, result, quit)
 			sent++
 			exp++
-			number--
+			slot--
 		}
 		results = append(results, res.values...)
 	}
@@ -221,67 +222,53 @@ type results struct {
 	err    error
 }
 
-type txSorter struct {
-	txs     []*types.Transaction
-	baseFee *big.Int
-}
-
-func newSorter(txs []*types.Transaction, baseFee *big.Int) *txSorter {
-	return &txSorter{
-		txs:     txs,
-		baseFee: baseFee,
-	}
-}
-
-func (s *txSorter) Len() int { return len(s.txs) }
-func (s *txSorter) Swap(i, j int) {
-	s.txs[i], s.txs[j] = s.txs[j], s.txs[i]
-}
-func (s *txSorter) Less(i, j int) bool {
-	// It's okay to discard the error because a tx would never be
-	// accepted into a block with an invalid effective tip.
-	tip1, _ := s.txs[i].EffectiveGasTip(s.baseFee)
-	tip2, _ := s.txs[j].EffectiveGasTip(s.baseFee)
-	return tip1.Cmp(tip2) < 0
-}
-
-// getBlockPrices calculates the lowest transaction gas price in a given block
-// and sends it to the result channel. If the block is empty or all transactions
-// are sent by the miner itself(it doesn't make any sense to include this kind of
-// transaction prices for sampling), nil gasprice is returned.
-func (oracle *Oracle) getBlockValues(ctx context.Context, signer types.Signer, blockNum uint64, limit int, ignoreUnder *big.Int, result chan results, quit chan struct{}) {
-	block, err := oracle.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNum))
-	if block == nil {
-		select {
-		case result <- results{nil, err}:
-		case <-quit:
+// getSlotValues calculates the lowest transaction tips of all blocks of the slot
+// and sends them to the result channel. If the slot has no transactions or all
+// transactions are sent by the creators of the blocks(it doesn't make any sense
+// to include this kind of transaction prices for sampling), nil gasprice is returned.
+func (oracle *Oracle) getSlotValues(ctx context.Context, signer types.Signer, slot uint64, limit int, ignoreUnder *big.Int, result chan results, quit chan struct{}) {
+	bc := oracle.backend.BlockChain()
+	var blocks []*types.Block
+	for _, hash := range bc.GetBlockHashesBySlot(slot) {
+		if block := bc.GetBlockByHash(hash); block != nil {
+			blocks = append(blocks, block)
 		}
-		return
 	}
-	// Sort the transaction by effective tip in ascending sort.
-	txs := make([]*types.Transaction, len(block.Transactions()))
-	copy(txs, block.Transactions())
-	sorter := newSorter(txs, block.BaseFee())
-	sort.Sort(sorter)
+	select {
+	case result <- results{slotTipValues(signer, blocks, limit, ignoreUnder), nil}:
+	case <-quit:
+	}
+}
 
-	var prices []*big.Int
-	for _, tx := range sorter.txs {
-		tip, _ := tx.EffectiveGasTip(block.BaseFee())
-		if ignoreUnder != nil && tip.Cmp(ignoreUnder) == -1 {
-			continue
-		}
-		sender, err := types.Sender(signer, tx)
-		if err == nil && sender != block.Coinbase() {
-			prices = append(prices, tip)
-			if len(prices) >= limit {
-				break
+// slotTipValues returns the lowest effective tips, up to the limit, of the
+// transactions of the slot blocks. The transactions included to several
+// blocks of the slot are counted once.
+func slotTipValues(signer types.Signer, blocks []*types.Block, limit int, ignoreUnder *big.Int) []*big.Int {
+	var (
+		prices []*big.Int
+		seen   = make(map[common.Hash]struct{})
+	)
+	for _, block := range blocks {
+		for _, tx := range block.Transactions() {
+			if _, ok := seen[tx.Hash()]; ok {
+				continue
+			}
+			seen[tx.Hash()] = struct{}{}
+			tip, _ := tx.EffectiveGasTip(block.BaseFee())
+			if ignoreUnder != nil && tip.Cmp(ignoreUnder) == -1 {
+				continue
+			}
+			sender, err := types.Sender(signer, tx)
+			if err == nil && sender != block.Coinbase() {
+				prices = append(prices, tip)
 			}
 		}
 	}
-	select {
-	case result <- results{prices, nil}:
-	case <-quit:
+	sort.Sort(bigIntArray(prices))
+	if len(prices) > limit {
+		prices = prices[:limit]
 	}
+	return prices
 }
 
 type bigIntArray []*big.Int
@@ -18,6 +18,7 @@ package gasprice
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"math"
 	"math/big"
@@ -243,3 +244,42 @@ func TestSuggestTipCap(t *testing.T) {
 	//30000000000
 	//1000000000
 }
+
+func TestSlotTipValues(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		coinbase = crypto.PubkeyToAddress(key.PublicKey)
+		signer   = types.LatestSigner(params.TestChainConfig)
+		baseFee  = big.NewInt(100)
+	)
+	newTx := func(key *ecdsa.PrivateKey, nonce uint64, tip int64) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     nonce,
+			To:        &common.Address{},
+			Gas:       21000,
+			GasFeeCap: big.NewInt(1000),
+			GasTipCap: big.NewInt(tip),
+		})
+	}
+	newBlock := func(coinbase common.Address, txs ...*types.Transaction) *types.Block {
+		header := &types.Header{Slot: 10, Coinbase: coinbase, BaseFee: baseFee}
+		return types.NewBlockWithHeader(header).WithBody(txs)
+	}
+	senderKey, _ := crypto.GenerateKey()
+	shared := newTx(senderKey, 0, 3)
+	blocks := []*types.Block{
+		newBlock(coinbase, newTx(senderKey, 1, 7), shared, newTx(key, 0, 4)),
+		newBlock(common.Address{0x01}, shared, newTx(senderKey, 2, 5), newTx(senderKey, 3, 1)),
+	}
+
+	// the tips of all blocks of the slot are sampled, the shared tx is counted once,
+	// the tx of the block creator and the tip under the threshold are skipped
+	values := slotTipValues(signer, blocks, 3, big.NewInt(2))
+	testutils.AssertEqual(t, []*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)}, values)
+
+	values = slotTipValues(signer, blocks, 2, big.NewInt(2))
+	testutils.AssertEqual(t, []*big.Int{big.NewInt(3), big.NewInt(5)}, values)
+
+	testutils.AssertEqual(t, 0, len(slotTipValues(signer, nil, 3, nil)))
+}
@@ -0,0 +1,246 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gasprice
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/consensus/misc"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+)
+
+// SlotFees is the fee data of the slot aggregated over all its blocks.
+type SlotFees struct {
+	Slot         uint64
+	Blocks       int        // Number of the blocks created in the slot
+	Creators     uint64     // Number of the creators assigned to the slot
+	BaseFee      *big.Int   // Base fee of the slot, the same for all its blocks
+	CpBaseFee    *big.Int   // Highest base fee of the checkpoints the blocks refer to
+	GasUsed      uint64     // Gas used by all blocks of the slot
+	GasLimit     uint64     // Gas limit of all blocks of the slot
+	GasUsedRatio float64    // gasUsed/gasLimit of the blocks of the slot
+	Congestion   float64    // gasUsed/capacity of the slot, the capacity counts the blocks of all assigned creators
+	Reward       []*big.Int // Requested percentiles of the effective tips weighted by gas used
+}
+
+// slotCacheKey is the key of the slot fees in the history cache.
+type slotCacheKey struct {
+	slot        uint64
+	percentiles string
+}
+
+// calcRewards returns the effective tips at the percentiles of the gas used by
+// the transactions. The sorter is sorted in place.
+func calcRewards(sorter sortGasAndReward, gasUsed uint64, percentiles []float64) []*big.Int {
+	reward := make([]*big.Int, len(percentiles))
+	if len(sorter) == 0 {
+		// return an all zero row if there are no transactions to gather data from
+		for i := range reward {
+			reward[i] = new(big.Int)
+		}
+		return reward
+	}
+	sort.Sort(sorter)
+
+	var txIndex int
+	sumGasUsed := sorter[0].gasUsed
+
+	for i, p := range percentiles {
+		thresholdGasUsed := uint64(float64(gasUsed) * p / 100)
+		for sumGasUsed < thresholdGasUsed && txIndex < len(sorter)-1 {
+			txIndex++
+			sumGasUsed += sorter[txIndex].gasUsed
+		}
+		reward[i] = sorter[txIndex].reward
+	}
+	return reward
+}
+
+// aggregateSlotFees aggregates the fee data of the blocks of the slot. The
+// receipts of the blocks are required only if reward percentiles are requested,
+// the blocks without receipts are skipped from the rewards.
+func aggregateSlotFees(fees *SlotFees, blocks []*types.Block, receipts []types.Receipts, maxGasPerBlock uint64, percentiles []float64) {
+	var (
+		sorter        sortGasAndReward
+		rewardGasUsed uint64
+	)
+	for i, block := range blocks {
+		fees.Blocks++
+		fees.GasUsed += block.GasUsed()
+		fees.GasLimit += block.GasLimit()
+		if fees.BaseFee == nil && block.BaseFee() != nil {
+			fees.BaseFee = new(big.Int).Set(block.BaseFee())
+		}
+		if cpBaseFee := block.CpBaseFee(); cpBaseFee != nil && (fees.CpBaseFee == nil || cpBaseFee.Cmp(fees.CpBaseFee) > 0) {
+			fees.CpBaseFee = new(big.Int).Set(cpBaseFee)
+		}
+		if len(percentiles) == 0 || i >= len(receipts) || len(receipts[i]) != len(block.Transactions()) {
+			continue
+		}
+		for j, tx := range block.Transactions() {
+			reward, _ := tx.EffectiveGasTip(block.BaseFee())
+			sorter = append(sorter, txGasAndReward{gasUsed: receipts[i][j].GasUsed, reward: reward})
+			rewardGasUsed += receipts[i][j].GasUsed
+		}
+	}
+	if fees.GasLimit > 0 {
+		fees.GasUsedRatio = float64(fees.GasUsed) / float64(fees.GasLimit)
+	}
+	if capacity := fees.Creators * maxGasPerBlock; capacity > 0 {
+		fees.Congestion = float64(fees.GasUsed) / float64(capacity)
+	}
+	if len(percentiles) != 0 {
+		fees.Reward = calcRewards(sorter, rewardGasUsed, percentiles)
+	}
+}
+
+// slotCreatorsCount returns the number of the creators assigned to the slot.
+func (oracle *Oracle) slotCreatorsCount(slot uint64) uint64 {
+	bc := oracle.backend.BlockChain()
+	if creators, err := bc.ValidatorStorage().GetCreatorsBySlot(bc, slot); err == nil {
+		return uint64(len(creators))
+	}
+	return oracle.backend.ChainConfig().ValidatorsPerSlot
+}
+
+// slotBaseFee calculates the base fee of the slot, it is the same for all
+// blocks of the slot.
+func (oracle *Oracle) slotBaseFee(slot uint64) (*big.Int, error) {
+	bc := oracle.backend.BlockChain()
+	validatorsCount, err := bc.ValidatorStorage().GetActiveValidatorsCount(bc, slot)
+	if err != nil {
+		return nil, err
+	}
+	genesisGasLimit := oracle.backend.Genesis().GasLimit()
+	return misc.CalcSlotBaseFee(oracle.backend.ChainConfig(), oracle.slotCreatorsCount(slot), validatorsCount, genesisGasLimit, slot), nil
+}
+
+// nextSlotBaseFee calculates the base fee of the slot following the given one.
+// The validators of the next era are not known until the era starts, so the
+// base fee of the given slot is returned at the end of the current era.
+func (oracle *Oracle) nextSlotBaseFee(slot uint64, baseFee *big.Int) *big.Int {
+	bc := oracle.backend.BlockChain()
+	if slotInfo := bc.GetSlotInfo(); slotInfo == nil || slotInfo.SlotToEpoch(slot+1) > bc.GetEraInfo().ToEpoch() {
+		return baseFee
+	}
+	next, err := oracle.slotBaseFee(slot + 1)
+	if err != nil {
+		log.Debug("Failed to calculate base fee of the next slot", "slot", slot+1, "err", err)
+		return baseFee
+	}
+	return next
+}
+
+// slotFees retrieves the blocks of the slot and aggregates their fee data.
+// The fees of the slots preceding the last finalized slot are cached, as no
+// blocks are expected to be added to them.
+func (oracle *Oracle) slotFees(ctx context.Context, slot uint64, percentiles []float64, percentileKey []byte) (*SlotFees, error) {
+	cacheKey := slotCacheKey{slot: slot, percentiles: string(percentileKey)}
+	if p, ok := oracle.historyCache.Get(cacheKey); ok {
+		return p.(*SlotFees), nil
+	}
+	bc := oracle.backend.BlockChain()
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+	)
+	for _, hash := range bc.GetBlockHashesBySlot(slot) {
+		block := bc.GetBlockByHash(hash)
+		if block == nil {
+			continue
+		}
+		blocks = append(blocks, block)
+		if len(percentiles) != 0 {
+			blockReceipts, err := oracle.backend.GetReceipts(ctx, hash)
+			if err != nil {
+				return nil, err
+			}
+			receipts = append(receipts, blockReceipts)
+		}
+	}
+	fees := &SlotFees{Slot: slot, Creators: oracle.slotCreatorsCount(slot)}
+	aggregateSlotFees(fees, blocks, receipts, oracle.backend.Genesis().GasLimit(), percentiles)
+	if fees.BaseFee == nil {
+		// no blocks in the slot
+		baseFee, err := oracle.slotBaseFee(slot)
+		if err != nil {
+			return nil, err
+		}
+		fees.BaseFee = baseFee
+	}
+	if slot < bc.GetLastFinalizedHeader().Slot {
+		oracle.historyCache.Add(cacheKey, fees)
+	}
+	return fees, nil
+}
+
+// FeeHistoryBySlot returns the fee data of the range of slots, all blocks of
+// each slot are aggregated. The range ends with the last slot, which is the
+// slot of the last finalized block if not specified. The base fee of the
+// slot following the range is returned as well.
+func (oracle *Oracle) FeeHistoryBySlot(ctx context.Context, slots int, lastSlot *uint64, rewardPercentiles []float64) ([]*SlotFees, *big.Int, error) {
+	if slots < 1 {
+		return nil, nil, nil
+	}
+	maxFeeHistory := oracle.maxHeaderHistory
+	if len(rewardPercentiles) != 0 {
+		maxFeeHistory = oracle.maxBlockHistory
+	}
+	if slots > maxFeeHistory {
+		log.Warn("Sanitizing fee history length", "requested", slots, "truncated", maxFeeHistory)
+		slots = maxFeeHistory
+	}
+	if err := checkPercentiles(rewardPercentiles); err != nil {
+		return nil, nil, err
+	}
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := head.Slot
+	if lastSlot != nil {
+		if *lastSlot > head.Slot {
+			return nil, nil, fmt.Errorf("%w: requested slot %d, head slot %d", errRequestBeyondHead, *lastSlot, head.Slot)
+		}
+		last = *lastSlot
+	}
+	// ensure not trying to retrieve before genesis
+	if uint64(slots) > last+1 {
+		slots = int(last + 1)
+	}
+	if slots < 1 {
+		return nil, nil, nil
+	}
+	percentileKey := make([]byte, 8*len(rewardPercentiles))
+	for i, p := range rewardPercentiles {
+		binary.LittleEndian.PutUint64(percentileKey[i*8:(i+1)*8], math.Float64bits(p))
+	}
+	history := make([]*SlotFees, 0, slots)
+	for slot := last + 1 - uint64(slots); slot <= last; slot++ {
+		fees, err := oracle.slotFees(ctx, slot, rewardPercentiles, percentileKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		history = append(history, fees)
+	}
+	return history, oracle.nextSlotBaseFee(last, history[len(history)-1].BaseFee), nil
+}
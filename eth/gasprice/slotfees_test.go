@@ -0,0 +1,72 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gasprice
+
+import (
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+func TestAggregateSlotFees(t *testing.T) {
+	newBlock := func(cpBaseFee int64, tips ...int64) (*types.Block, types.Receipts) {
+		var (
+			txs      []*types.Transaction
+			receipts types.Receipts
+		)
+		for i, tip := range tips {
+			txs = append(txs, types.NewTx(&types.DynamicFeeTx{
+				Nonce:     uint64(i),
+				To:        &common.Address{},
+				Gas:       21000,
+				GasFeeCap: big.NewInt(1000),
+				GasTipCap: big.NewInt(tip),
+			}))
+			receipts = append(receipts, &types.Receipt{GasUsed: 21000})
+		}
+		header := &types.Header{
+			Slot:      10,
+			GasLimit:  100000,
+			GasUsed:   uint64(len(tips)) * 21000,
+			BaseFee:   big.NewInt(100),
+			CpBaseFee: big.NewInt(cpBaseFee),
+		}
+		return types.NewBlockWithHeader(header).WithBody(txs), receipts
+	}
+	block1, receipts1 := newBlock(90, 1, 3)
+	block2, receipts2 := newBlock(95, 2)
+
+	fees := &SlotFees{Slot: 10, Creators: 4}
+	aggregateSlotFees(fees, []*types.Block{block1, block2}, []types.Receipts{receipts1, receipts2}, 100000, []float64{0, 50, 100})
+
+	testutils.AssertEqual(t, 2, fees.Blocks)
+	testutils.AssertEqual(t, uint64(63000), fees.GasUsed)
+	testutils.AssertEqual(t, uint64(200000), fees.GasLimit)
+	testutils.AssertEqual(t, big.NewInt(100), fees.BaseFee)
+	testutils.AssertEqual(t, big.NewInt(95), fees.CpBaseFee)
+	testutils.AssertEqual(t, 0.315, fees.GasUsedRatio)
+	testutils.AssertEqual(t, 0.1575, fees.Congestion)
+	testutils.AssertEqual(t, []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}, fees.Reward)
+
+	// no blocks in the slot
+	empty := &SlotFees{Slot: 11, Creators: 4}
+	aggregateSlotFees(empty, nil, nil, 100000, []float64{50})
+	testutils.AssertEqual(t, 0, empty.Blocks)
+	testutils.AssertEqual(t, 0.0, empty.Congestion)
+	testutils.AssertEqual(t, []*big.Int{new(big.Int)}, empty.Reward)
+}
@@ -30,12 +30,14 @@ import (
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/downloader"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/fetcher"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/lightclient"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/snap"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/event"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/p2p"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/p2p/enode"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/trie"
 )
@@ -85,11 +87,18 @@ type handlerConfig struct {
 	EventMux   *event.TypeMux            // Legacy event mux, deprecate for `feed`
 	Checkpoint *params.TrustedCheckpoint // Hard coded checkpoint for sync challenges
 	Whitelist  map[uint64]common.Hash    // Hard coded whitelist for sync challenged
+
+	SyncCheckpoint *downloader.SyncCheckpoint // Trusted checkpoint to start the chain from
+
+	TxCreatorsBroadcast bool // Whether to send the transactions directly to the peers of the assigned creators
+
+	BanPeer func(id enode.ID, duration time.Duration) // Temporarily bans a misbehaving peer at the p2p level
 }
 
 type handler struct {
 	networkID  uint64
-	forkFilter forkid.Filter // Fork ID filter, constant across the lifetime of the node
+	forkFilter forkid.Filter        // Fork ID filter, constant across the lifetime of the node
+	cpFilter   eth.CheckpointFilter // Checkpoint filter rejecting peers with conflicting checkpoints
 
 	fastSync  uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
 	snapSync  uint32 // Flag whether fast sync should operate on top of the snap protocol
@@ -98,12 +107,15 @@ type handler struct {
 	checkpointNumber uint64      // Block number for the sync progress validator to cross reference
 	checkpointHash   common.Hash // Block hash for the sync progress validator to cross reference
 
+	syncCheckpoint *downloader.SyncCheckpoint // Trusted checkpoint to start the chain from (nil if not required)
+
 	database ethdb.Database
 	txpool   txPool
 	chain    *core.BlockChain
 	maxPeers int
 
 	downloader   *downloader.Downloader
+	lightClient  *lightclient.Client // Checkpoint based light client (nil if not in light mode)
 	stateBloom   *trie.SyncBloom
 	blockFetcher *fetcher.BlockFetcher
 	txFetcher    *fetcher.TxFetcher
@@ -115,6 +127,10 @@ type handler struct {
 	minedBlockSub *event.TypeMuxSubscription
 
 	whitelist map[uint64]common.Hash
+	banNode   func(id enode.ID, duration time.Duration) // Bans a node at the p2p level (nil if unavailable)
+
+	txCreatorsBroadcast bool            // Whether to send the transactions directly to the peers of the assigned creators
+	creatorPeers        *creatorPeerSet // Peers known to be the nodes of creators
 
 	// channels for fetcher, syncer, txsyncLoop
 	quitSync chan struct{}
@@ -133,13 +149,18 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	h := &handler{
 		networkID:  config.Network,
 		forkFilter: forkid.NewFilter(config.Chain),
+		cpFilter:   eth.NewCheckpointFilter(config.Chain),
 		eventMux:   config.EventMux,
 		database:   config.Database,
 		txpool:     config.TxPool,
 		chain:      config.Chain,
 		peers:      newPeerSet(),
 		whitelist:  config.Whitelist,
+		banNode:    config.BanPeer,
 		quitSync:   make(chan struct{}),
+
+		txCreatorsBroadcast: config.TxCreatorsBroadcast,
+		creatorPeers:        newCreatorPeerSet(),
 	}
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the fast
@@ -155,7 +176,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 			h.fastSync = uint32(1)
 			log.Warn("Switch sync mode from full sync to fast sync")
 		}
-	} else {
+	} else if config.Sync != downloader.LightSync {
 		fullBlock := h.chain.GetLastFinalizedBlock()
 		if fullBlock.Height() > 0 {
 			// Print warning log if database is not empty to run fast sync.
@@ -168,6 +189,16 @@ func newHandler(config *handlerConfig) (*handler, error) {
 			}
 		}
 	}
+	// The checkpoint sync is only possible for an empty chain
+	// and retrieves the state over the snap protocol.
+	if config.SyncCheckpoint != nil && config.Sync != downloader.LightSync {
+		if h.chain.GetLastFinalizedBlock().Height() == 0 {
+			h.syncCheckpoint = config.SyncCheckpoint
+			h.snapSync = uint32(1)
+		} else {
+			log.Warn("Skip checkpoint sync: chain is not empty")
+		}
+	}
 	// If we have trusted checkpoints, enforce them on the chain
 	if config.Checkpoint != nil {
 		//h.checkpointNumber = (config.Checkpoint.SectionIndex+1)*params.CHTFrequency - 1
@@ -184,7 +215,19 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	if atomic.LoadUint32(&h.fastSync) == 1 && atomic.LoadUint32(&h.snapSync) == 0 {
 		h.stateBloom = trie.NewSyncBloom(config.BloomCache, config.Database)
 	}
-	h.downloader = downloader.New(h.checkpointNumber, config.Database, h.stateBloom, h.eventMux, h.chain, nil, h.removePeer)
+	// The light client follows the checkpoints instead of the chain sync,
+	// starting from the trusted checkpoint if provided.
+	if config.Sync == downloader.LightSync {
+		trusted := h.chain.GetLastCoordinatedCheckpoint()
+		if config.SyncCheckpoint != nil {
+			trusted = config.SyncCheckpoint.Checkpoint
+		}
+		if trusted == nil {
+			return nil, errors.New("light client requires a trusted checkpoint")
+		}
+		h.lightClient = lightclient.New(config.Database, h.chain.Config(), h.chain.Genesis().Header(), trusted, h.lightServers)
+	}
+	h.downloader = downloader.New(h.checkpointNumber, config.Database, h.stateBloom, h.eventMux, h.chain, nil, h.removePeer, h.banPeer)
 
 	// Construct the fetcher (short sync)
 	validateFn := func(header *types.Header) error {
@@ -262,6 +305,9 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		log.Info("Propagate block:", "slot", block.Slot(), "hash", block.Hash().Hex(), "txs", len(block.Transactions()), "parents", block.ParentHashes())
 
 		_, err := h.chain.InsertPropagatedBlocks(types.Blocks{block})
+		if h.chain.IsInvalidBlock(block.Hash()) {
+			h.downloader.ReportInvalidBlock(peerId)
+		}
 		if err == core.ErrInsertUncompletedDag {
 			log.Warn("Insert propagated blocks: unknown ancestors detected. start sync",
 				"err", err,
@@ -317,7 +363,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		lastFinNr = h.chain.GetLastFinalizedNumber()
 	)
 	forkID := forkid.NewID(h.chain.Config(), h.chain.Genesis().Hash(), h.chain.GetLastFinalizedBlock().Nr())
-	if err := peer.Handshake(h.networkID, lastFinNr, genesis.Hash(), forkID, h.forkFilter); err != nil {
+	if err := peer.Handshake(h.networkID, lastFinNr, h.dagStatus(), genesis.Hash(), forkID, h.forkFilter, h.cpFilter); err != nil {
 		peer.Log().Error("Gwat handshake failed", "err", err)
 		return err
 	}
@@ -351,12 +397,15 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 	if p == nil {
 		return errors.New("peer dropped during handling")
 	}
-	// Register the peer in the downloader. If the downloader considers it banned, we disconnect
-	if err := h.downloader.RegisterPeer(peer.ID(), peer.Version(), peer); err != nil {
-		peer.Log().Error("Failed to register peer in eth syncer", "err", err)
-		return err
+	// Register the peer in the downloader. If the downloader considers it banned, we disconnect.
+	// The light client doesn't run the chain sync.
+	if h.lightClient == nil {
+		if err := h.downloader.RegisterPeer(peer.ID(), peer.Version(), peer); err != nil {
+			peer.Log().Error("Failed to register peer in eth syncer", "err", err)
+			return err
+		}
 	}
-	if snap != nil {
+	if snap != nil && h.lightClient == nil {
 		if err := h.downloader.SnapSyncer.Register(snap); err != nil {
 			peer.Log().Error("Failed to register peer in snap syncer", "err", err)
 			return err
@@ -422,6 +471,28 @@ func (h *handler) removePeer(id string) {
 	}
 }
 
+// banPeer requests disconnection of a peer and rejects its connections for the given duration.
+func (h *handler) banPeer(id string, duration time.Duration) {
+	if h.banNode != nil {
+		if nodeID, err := enode.ParseID(id); err == nil {
+			h.banNode(nodeID, duration)
+		} else {
+			log.Warn("Failed to ban peer", "peer", id, "err", err)
+		}
+	}
+	h.removePeer(id)
+}
+
+// lightServers returns the peers capable to serve the light client requests.
+func (h *handler) lightServers() []lightclient.Peer {
+	peers := h.peers.peersWithMinVersion(eth.ETH69)
+	list := make([]lightclient.Peer, 0, len(peers))
+	for _, p := range peers {
+		list = append(list, p.Peer)
+	}
+	return list
+}
+
 // unregisterPeer removes a peer from the downloader, fetchers and main peer set.
 func (h *handler) unregisterPeer(id string) {
 	// Create a custom logger to avoid printing the entire id
@@ -441,12 +512,15 @@ func (h *handler) unregisterPeer(id string) {
 	// Remove the `eth` peer if it exists
 	logger.Debug("Removing Gwat peer", "snap", peer.snapExt != nil)
 
-	// Remove the `snap` extension if it exists
-	if peer.snapExt != nil {
-		h.downloader.SnapSyncer.Unregister(id)
+	// Remove the peer from the syncers if they are running
+	if h.lightClient == nil {
+		// Remove the `snap` extension if it exists
+		if peer.snapExt != nil {
+			h.downloader.SnapSyncer.Unregister(id)
+		}
+		h.downloader.UnregisterPeer(id)
+		h.txFetcher.Drop(id)
 	}
-	h.downloader.UnregisterPeer(id)
-	h.txFetcher.Drop(id)
 
 	if err := h.peers.unregisterPeer(id); err != nil {
 		logger.Error("Gwat peer removal failed", "err", err)
@@ -467,9 +541,20 @@ func (h *handler) Start(maxPeers int) {
 	h.minedBlockSub = h.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go h.minedBroadcastLoop()
 
+	// the light client follows the checkpoints instead of the chain sync
+	if h.lightClient != nil {
+		h.lightClient.Start()
+		return
+	}
+
 	// start sync handlers
 	h.wg.Add(1)
 	go h.chainSync.loop()
+
+	if h.syncCheckpoint != nil {
+		h.wg.Add(1)
+		go h.checkpointSyncLoop()
+	}
 }
 
 func (h *handler) Stop() {
@@ -481,6 +566,10 @@ func (h *handler) Stop() {
 	close(h.quitSync)
 	h.wg.Wait()
 
+	if h.lightClient != nil {
+		h.lightClient.Stop()
+	}
+
 	// Disconnect existing sessions.
 	// This also closes the gate for any new registrations on the peer set.
 	// sessions which are already established but not added to h.peers yet
@@ -491,6 +580,20 @@ func (h *handler) Stop() {
 	log.Info("Gwat protocol stopped")
 }
 
+// dagStatus collects the local dag status advertised in the handshake.
+func (h *handler) dagStatus() *eth.DagStatus {
+	tips := h.chain.GetTips()
+	status := &eth.DagStatus{
+		Checkpoint: h.chain.GetLastCoordinatedCheckpoint(),
+		Tips:       tips.GetHashes(),
+		MaxSlot:    tips.GetMaxSlot(),
+	}
+	if ei := h.chain.GetEraInfo(); ei.GetEra() != nil {
+		status.Era = ei.Number()
+	}
+	return status
+}
+
 // BroadcastBlock will either propagate a block to a subset of its peers, or
 // will only announce its availability (depending what's requested).
 func (h *handler) BroadcastBlock(block *types.Block, propagate bool) {
@@ -540,17 +643,36 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		txset = make(map[*ethPeer][]common.Hash) // Set peer->hash to transfer directly
 		annos = make(map[*ethPeer][]common.Hash) // Set peer->hash to announce
 
+		assigned = make(map[common.Hash]map[*ethPeer]bool) // Set hash->peers of the assigned creators
 	)
+	// Send transactions directly to the peers of the creators assigned to include them
+	if h.txCreatorsBroadcast {
+		for peer, hashes := range h.assignedCreatorPeers(txs) {
+			txset[peer] = append(txset[peer], hashes...)
+			for _, hash := range hashes {
+				if assigned[hash] == nil {
+					assigned[hash] = make(map[*ethPeer]bool)
+				}
+				assigned[hash][peer] = true
+			}
+		}
+	}
 	// Broadcast transactions to a batch of peers not knowing about it
 	for _, tx := range txs {
 		peers := h.peers.peersWithoutTransaction(tx.Hash())
 		// Send the tx unconditionally to a subset of our peers
 		numDirect := int(math.Sqrt(float64(len(peers))))
 		for _, peer := range peers[:numDirect] {
+			if assigned[tx.Hash()][peer] {
+				continue
+			}
 			txset[peer] = append(txset[peer], tx.Hash())
 		}
 		// For the remaining peers, send announcement only
 		for _, peer := range peers[numDirect:] {
+			if assigned[tx.Hash()][peer] {
+				continue
+			}
 			annos[peer] = append(annos[peer], tx.Hash())
 		}
 	}
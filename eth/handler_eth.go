@@ -47,7 +47,12 @@ func (h *ethHandler) RunPeer(peer *eth.Peer, hand eth.Handler) error {
 // PeerInfo retrieves all known `eth` information about a peer.
 func (h *ethHandler) PeerInfo(id enode.ID) interface{} {
 	if p := h.peers.peer(id.String()); p != nil {
-		return p.info()
+		info := p.info()
+		if h.downloader != nil {
+			score := h.downloader.PeerScore(p.ID())
+			info.Score = &score
+		}
+		return info
 	}
 	return nil
 }
@@ -61,6 +66,10 @@ func (h *ethHandler) AcceptTxs() bool {
 // Handle is invoked from a peer's message handler when it receives a new remote
 // message that the handler couldn't consume and serve itself.
 func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
+	// The light client doesn't run the chain sync and the fetchers
+	if h.lightClient != nil {
+		return h.handleLight(peer, packet)
+	}
 	// Consume any broadcasts and announces, forwarding the rest to the downloader
 	switch packet := packet.(type) {
 	case *eth.BlockHeadersPacket:
@@ -97,6 +106,23 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 		}
 		return h.handleBlockBroadcast(peer, packet.Block)
 
+	case *eth.CompactBlockPacket:
+		// skip if synchronizing
+		if h.downloader.FinSynchronising() {
+			return nil
+		}
+		return h.handleCompactBlock(peer, packet)
+
+	case *eth.AncestorsPacket:
+		if err := h.downloader.DeliverAncestors(peer.ID(), types.Blocks(*packet)); err != nil {
+			log.Debug("Failed to deliver ancestors", "err", err)
+		}
+		return nil
+
+	case *eth.CheckpointHeadersPacket, *eth.TrieProofsPacket:
+		// served to the light clients only
+		return nil
+
 	case *eth.NewPooledTransactionHashesPacket:
 		return h.txFetcher.Notify(peer.ID(), *packet)
 
@@ -115,6 +141,27 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 	}
 }
 
+// handleLight is invoked instead of Handle in the light mode: it delivers
+// the light client responses and drops the chain sync packets.
+func (h *ethHandler) handleLight(peer *eth.Peer, packet eth.Packet) error {
+	switch packet := packet.(type) {
+	case *eth.CheckpointHeadersPacket:
+		if err := h.lightClient.DeliverCheckpointHeaders(peer.ID(), *packet); err != nil {
+			log.Debug("Failed to deliver checkpoint headers", "err", err)
+		}
+		return nil
+
+	case *eth.TrieProofsPacket:
+		if err := h.lightClient.DeliverTrieProofs(peer.ID(), *packet); err != nil {
+			log.Debug("Failed to deliver trie proofs", "err", err)
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
+
 // handleHeaders is invoked from a peer's message handler when it transmits a batch
 // of headers for the local node to process.
 func (h *ethHandler) handleHeaders(peer *eth.Peer, headers []*types.Header) error {
@@ -231,6 +278,12 @@ func (h *ethHandler) handleBlockBroadcast(peer *eth.Peer, block *types.Block) er
 		log.Debug("skip handle: handleBlockBroadcast", "IsSynced()", h.chain.IsSynced())
 		return nil
 	}
+	// The first peer delivering the new block is the closest one to its creator
+	if !h.chain.HasBlock(block.Hash()) {
+		if slotInfo := h.chain.GetSlotInfo(); slotInfo != nil && block.Slot()+1 >= slotInfo.CurrentSlot() {
+			h.creatorPeers.observe(block.Coinbase(), peer.ID(), block.Slot())
+		}
+	}
 
 	// Schedule the block for import
 	h.blockFetcher.Enqueue(peer.ID(), block, peer.RequestOneHeader, peer.RequestBodies)
@@ -240,6 +293,29 @@ func (h *ethHandler) handleBlockBroadcast(peer *eth.Peer, block *types.Block) er
 		lastFinNr = block.CpNumber()
 	}
 	peer.SetDagInfo(lastFinNr)
+	peer.SetMaxSlot(block.Slot())
 	//h.chainSync.handlePeerEvent(peer, evtBroadcast)
 	return nil
 }
+
+// handleCompactBlock is invoked from a peer's message handler when it transmits a
+// compact block announcement for the local node to process. The block body is
+// reconstructed from the local tx pool, if it fails the block is fetched by hash.
+func (h *ethHandler) handleCompactBlock(peer *eth.Peer, packet *eth.CompactBlockPacket) error {
+	if !h.chain.IsSynced() {
+		log.Debug("skip handle: handleCompactBlock", "IsSynced()", h.chain.IsSynced())
+		return nil
+	}
+	hash := packet.Header.Hash()
+	if h.chain.HasBlock(hash) {
+		return nil
+	}
+	block, err := packet.Reconstruct(h.txpool.Get)
+	if err != nil {
+		log.Debug("Compact block reconstruction failed, fetching by hash", "hash", hash.Hex(), "txs", len(packet.TxHashes), "err", err)
+		return h.handleBlockAnnounces(peer, []common.Hash{hash}, []uint64{packet.Header.Nr()})
+	}
+	block.ReceivedAt = time.Now()
+	block.ReceivedFrom = peer
+	return h.handleBlockBroadcast(peer, block)
+}
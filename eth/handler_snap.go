@@ -46,5 +46,9 @@ func (h *snapHandler) PeerInfo(id enode.ID) interface{} {
 // Handle is invoked from a peer's message handler when it receives a new remote
 // message that the handler couldn't consume and serve itself.
 func (h *snapHandler) Handle(peer *snap.Peer, packet snap.Packet) error {
+	// the light client doesn't run the snap sync
+	if h.lightClient != nil {
+		return nil
+	}
 	return h.downloader.DeliverSnapPacket(peer, packet)
 }
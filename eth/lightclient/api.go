@@ -0,0 +1,132 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightclient
+
+import (
+	"context"
+	"math/big"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/vm"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token/operation"
+	valStore "gitlab.waterfall.network/waterfall/protocol/gwat/validator/storage"
+)
+
+// GetAPIs returns the light client RPC services.
+func GetAPIs(c *Client) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "wat",
+			Version:   "1.0",
+			Service:   NewPublicLightAPI(c),
+			Public:    true,
+		},
+	}
+}
+
+// PublicLightAPI provides an API to query the state of the last verified
+// checkpoint of the light client.
+type PublicLightAPI struct {
+	c *Client
+}
+
+// NewPublicLightAPI creates a new light client API.
+func NewPublicLightAPI(c *Client) *PublicLightAPI {
+	return &PublicLightAPI{c}
+}
+
+// CheckpointInfo represents the last verified checkpoint with its state commitments.
+type CheckpointInfo struct {
+	Checkpoint  *types.Checkpoint `json:"checkpoint"`
+	CpHash      common.Hash       `json:"cpHash"`
+	CpNumber    hexutil.Uint64    `json:"cpNumber"`
+	StateRoot   common.Hash       `json:"stateRoot"`
+	ReceiptHash common.Hash       `json:"receiptsRoot"`
+}
+
+// Light_Checkpoint returns the last verified checkpoint.
+func (api *PublicLightAPI) Light_Checkpoint() (*CheckpointInfo, error) {
+	head := api.c.Head()
+	header, err := api.c.StateHeader()
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointInfo{
+		Checkpoint:  head.Checkpoint,
+		CpHash:      header.Hash(),
+		CpNumber:    hexutil.Uint64(header.Nr()),
+		StateRoot:   header.Root,
+		ReceiptHash: header.ReceiptHash,
+	}, nil
+}
+
+// Light_GetBalance returns the balance of the account at the last verified checkpoint.
+func (api *PublicLightAPI) Light_GetBalance(ctx context.Context, address common.Address) (*hexutil.Big, error) {
+	stateDb, _, err := api.c.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balance := stateDb.GetBalance(address)
+	if err := stateDb.Error(); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+// Light_TokenBalanceOf returns the balance of the owner for a WRC-20 token
+// or the number of NFTs of the owner for a WRC-721 token at the last
+// verified checkpoint.
+func (api *PublicLightAPI) Light_TokenBalanceOf(ctx context.Context, tokenAddr common.Address, ownerAddr common.Address) (*hexutil.Big, error) {
+	stateDb, header, err := api.c.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+	op, err := operation.NewBalanceOfOperation(tokenAddr, ownerAddr)
+	if err != nil {
+		return nil, err
+	}
+	blockCtx := vm.BlockContext{
+		BlockNumber: new(big.Int).SetUint64(header.Nr()),
+		Time:        new(big.Int).SetUint64(header.Time),
+	}
+	res, err := token.NewProcessor(blockCtx, stateDb).BalanceOf(op)
+	if err != nil {
+		return nil, err
+	}
+	if err := stateDb.Error(); err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(res), nil
+}
+
+// Light_ValidatorInfo returns the validator info at the last verified checkpoint.
+func (api *PublicLightAPI) Light_ValidatorInfo(ctx context.Context, address common.Address) (*valStore.Validator, error) {
+	stateDb, _, err := api.c.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := valStore.NewStorage(api.c.config).GetValidator(stateDb, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := stateDb.Error(); err != nil {
+		return nil, err
+	}
+	return validator, nil
+}
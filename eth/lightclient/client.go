@@ -0,0 +1,330 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/state"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/light"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+)
+
+const (
+	// checkpointPollInterval is the interval of the checkpoint requests to peers.
+	checkpointPollInterval = 10 * time.Second
+
+	// requestTimeout is the time to wait for a peer response.
+	requestTimeout = 5 * time.Second
+
+	// defaultQuorum is the minimal number of peers which must serve the same
+	// checkpoint to accept it.
+	defaultQuorum = 2
+)
+
+var (
+	errNoCheckpoint   = errors.New("no verified checkpoint")
+	errNoRequest      = errors.New("no pending request")
+	errClientStopped  = errors.New("light client stopped")
+	errRequestTimeout = errors.New("request timeout")
+)
+
+// Peer is a full node serving the light client requests.
+type Peer interface {
+	ID() string
+	RequestCheckpointHeaders(fromEpoch, amount uint64) error
+	RequestTrieProofs(reqs []eth.TrieProofRequest) error
+}
+
+// Client is a light client following the coordinated checkpoints.
+type Client struct {
+	db      ethdb.Database
+	config  *params.ChainConfig
+	genesis *types.Header
+	peers   func() []Peer
+	quorum  int
+
+	lock  sync.RWMutex
+	chain *checkpointChain // verified checkpoints starting from the trusted one
+
+	reqLock        sync.Mutex // serializes the proof retrievals
+	pendLock       sync.Mutex
+	pendingHeaders map[string]chan []*eth.CheckpointHeader
+	pendingProofs  map[string]chan [][]byte
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a light client starting from the trusted checkpoint. The peers
+// function provides the connected peers capable to serve the client requests.
+func New(db ethdb.Database, config *params.ChainConfig, genesis *types.Header, trusted *types.Checkpoint, peers func() []Peer) *Client {
+	return &Client{
+		db:             db,
+		config:         config,
+		genesis:        genesis,
+		peers:          peers,
+		quorum:         defaultQuorum,
+		chain:          newCheckpointChain(trusted.Copy()),
+		pendingHeaders: make(map[string]chan []*eth.CheckpointHeader),
+		pendingProofs:  make(map[string]chan [][]byte),
+		quit:           make(chan struct{}),
+	}
+}
+
+// Start starts following the checkpoints.
+func (c *Client) Start() {
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// Stop terminates the client.
+func (c *Client) Stop() {
+	close(c.quit)
+	c.wg.Wait()
+}
+
+func (c *Client) loop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(checkpointPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.syncCheckpoints(); err != nil {
+				log.Debug("Light client: checkpoint sync failed", "err", err)
+			}
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// Head returns the last verified checkpoint.
+func (c *Client) Head() *eth.CheckpointHeader {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.chain.head
+}
+
+// StateHeader returns the header committing to the state of the last
+// verified checkpoint: the checkpoint block with the state root
+// committed by the checkpoint spine.
+func (c *Client) StateHeader() (*types.Header, error) {
+	head := c.Head()
+	if head == nil {
+		return nil, errNoCheckpoint
+	}
+	if head.CpHeader == nil {
+		// the genesis checkpoint: the genesis state is known locally
+		if c.genesis == nil || head.Spine.Hash() != c.genesis.Hash() {
+			return nil, errNoCheckpoint
+		}
+		return types.CopyHeader(c.genesis), nil
+	}
+	header := types.CopyHeader(head.CpHeader)
+	header.Root = head.Spine.CpRoot
+	header.ReceiptHash = head.Spine.CpReceiptHash
+	return header, nil
+}
+
+// State returns the state of the last verified checkpoint. The state
+// items are retrieved from peers with Merkle proofs on demand.
+func (c *Client) State(ctx context.Context) (*state.StateDB, *types.Header, error) {
+	header, err := c.StateHeader()
+	if err != nil {
+		return nil, nil, err
+	}
+	return light.NewState(ctx, header, c), header, nil
+}
+
+// syncCheckpoints requests the checkpoints following the head from all peers
+// and accepts the ones served by the quorum, linked to the verified ones and
+// passing the verification. The checkpoints not linked to the verified ones
+// are skipped.
+func (c *Client) syncCheckpoints() error {
+	peers := c.peers()
+	if len(peers) == 0 {
+		return light.ErrNoPeers
+	}
+	head := c.Head()
+	from := c.chain.trusted.FinEpoch
+	if head != nil {
+		from = head.Checkpoint.FinEpoch + 1
+	}
+	votes := make(checkpointVotes)
+	responded := c.requestCheckpointHeaders(peers, from, eth.LimitCheckpointHeaders, votes)
+	if responded == 0 {
+		return errRequestTimeout
+	}
+	for _, epoch := range votes.epochs() {
+		if head == nil {
+			// the trusted checkpoint is verified by its hash
+			ch := c.trustedHeader(votes, epoch)
+			if ch == nil {
+				continue
+			}
+			if err := c.insertHead(ch); err != nil {
+				return err
+			}
+			head = ch
+			continue
+		}
+		ch, count := votes.best(epoch)
+		if ch == nil || count < c.quorum {
+			log.Debug("Light client: no quorum on checkpoint", "finEpoch", epoch, "votes", count, "quorum", c.quorum)
+			break
+		}
+		if err := c.insertHead(ch); err != nil {
+			if errors.Is(err, errUnlinkedCheckpoint) {
+				log.Debug("Light client: skip checkpoint", "finEpoch", epoch, "err", err)
+				continue
+			}
+			return err
+		}
+		head = ch
+	}
+	return nil
+}
+
+// trustedHeader returns the headers of the trusted checkpoint if served.
+func (c *Client) trustedHeader(votes checkpointVotes, epoch uint64) *eth.CheckpointHeader {
+	if epoch != c.chain.trusted.FinEpoch {
+		return nil
+	}
+	for _, vote := range votes[epoch] {
+		if vote.header.Checkpoint.Spine == c.chain.trusted.Spine {
+			return vote.header
+		}
+	}
+	return nil
+}
+
+// insertHead verifies the checkpoint and sets it as the head.
+func (c *Client) insertHead(ch *eth.CheckpointHeader) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.chain.verify(ch); err != nil {
+		return err
+	}
+	c.chain.insert(ch)
+
+	log.Info("Light client: checkpoint verified",
+		"epoch", ch.Checkpoint.Epoch,
+		"finEpoch", ch.Checkpoint.FinEpoch,
+		"spine", ch.Checkpoint.Spine.Hex(),
+		"cpNumber", ch.Spine.CpNumber,
+		"cpRoot", ch.Spine.CpRoot.Hex(),
+	)
+	return nil
+}
+
+// requestCheckpointHeaders requests the checkpoints from all peers in parallel,
+// collects the responses to the votes and returns the number of responded peers.
+func (c *Client) requestCheckpointHeaders(peers []Peer, from, amount uint64, votes checkpointVotes) int {
+	type response struct {
+		peer    string
+		headers []*eth.CheckpointHeader
+	}
+	var (
+		wg        sync.WaitGroup
+		responses = make(chan response, len(peers))
+	)
+	for _, p := range peers {
+		ch := make(chan []*eth.CheckpointHeader, 1)
+		if !c.trackHeaders(p.ID(), ch) {
+			continue
+		}
+		if err := p.RequestCheckpointHeaders(from, amount); err != nil {
+			c.untrackHeaders(p.ID())
+			continue
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer c.untrackHeaders(id)
+
+			timer := time.NewTimer(requestTimeout)
+			defer timer.Stop()
+			select {
+			case headers := <-ch:
+				responses <- response{peer: id, headers: headers}
+			case <-timer.C:
+				log.Debug("Light client: checkpoint headers timeout", "peer", id)
+			case <-c.quit:
+			}
+		}(p.ID())
+	}
+	wg.Wait()
+	close(responses)
+
+	responded := 0
+	for res := range responses {
+		votes.add(res.peer, res.headers)
+		responded++
+	}
+	return responded
+}
+
+func (c *Client) trackHeaders(peer string, ch chan []*eth.CheckpointHeader) bool {
+	c.pendLock.Lock()
+	defer c.pendLock.Unlock()
+	if _, ok := c.pendingHeaders[peer]; ok {
+		return false
+	}
+	c.pendingHeaders[peer] = ch
+	return true
+}
+
+func (c *Client) untrackHeaders(peer string) {
+	c.pendLock.Lock()
+	defer c.pendLock.Unlock()
+	delete(c.pendingHeaders, peer)
+}
+
+// DeliverCheckpointHeaders injects the checkpoint headers received from a peer.
+func (c *Client) DeliverCheckpointHeaders(peer string, headers []*eth.CheckpointHeader) error {
+	c.pendLock.Lock()
+	ch, ok := c.pendingHeaders[peer]
+	delete(c.pendingHeaders, peer)
+	c.pendLock.Unlock()
+	if !ok {
+		return errNoRequest
+	}
+	ch <- headers
+	return nil
+}
+
+// DeliverTrieProofs injects the trie proofs received from a peer.
+func (c *Client) DeliverTrieProofs(peer string, nodes [][]byte) error {
+	c.pendLock.Lock()
+	ch, ok := c.pendingProofs[peer]
+	delete(c.pendingProofs, peer)
+	c.pendLock.Unlock()
+	if !ok {
+		return errNoRequest
+	}
+	ch <- nodes
+	return nil
+}
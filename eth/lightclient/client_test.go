@@ -0,0 +1,186 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/state"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/light"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/trie"
+)
+
+// testPeer is a full peer serving the light client requests.
+type testPeer struct {
+	id          string
+	client      *Client
+	checkpoints []*eth.CheckpointHeader
+	triedb      *trie.Database
+	badProofs   bool
+}
+
+func (p *testPeer) ID() string { return p.id }
+
+func (p *testPeer) RequestCheckpointHeaders(fromEpoch, amount uint64) error {
+	var headers []*eth.CheckpointHeader
+	for _, ch := range p.checkpoints {
+		if ch.Checkpoint.FinEpoch >= fromEpoch && ch.Checkpoint.FinEpoch < fromEpoch+amount {
+			headers = append(headers, ch)
+		}
+	}
+	go p.client.DeliverCheckpointHeaders(p.id, headers)
+	return nil
+}
+
+func (p *testPeer) RequestTrieProofs(reqs []eth.TrieProofRequest) error {
+	proofs := light.NewNodeSet()
+	for _, req := range reqs {
+		tr, err := trie.New(req.Root, p.triedb)
+		if err != nil {
+			return err
+		}
+		if err := tr.Prove(req.Key, 0, proofs); err != nil {
+			return err
+		}
+	}
+	var nodes [][]byte
+	for _, node := range proofs.NodeList() {
+		nodes = append(nodes, node)
+	}
+	if p.badProofs {
+		nodes = nodes[:len(nodes)-1]
+	}
+	go p.client.DeliverTrieProofs(p.id, nodes)
+	return nil
+}
+
+func newTestClient(trusted *eth.CheckpointHeader, peers ...*testPeer) *Client {
+	c := New(rawdb.NewMemoryDatabase(), params.TestChainConfig, nil, trusted.Checkpoint, func() []Peer {
+		list := make([]Peer, 0, len(peers))
+		for _, p := range peers {
+			list = append(list, p)
+		}
+		return list
+	})
+	for _, p := range peers {
+		p.client = c
+	}
+	return c
+}
+
+func TestClient_SyncCheckpoints(t *testing.T) {
+	var (
+		trusted = makeCheckpointHeader(1, 10, common.Hash{0x01})
+		cp2     = linkCheckpointHeader(trusted, 2, common.Hash{0x02})
+		cp3     = linkCheckpointHeader(cp2, 4, common.Hash{0x03})
+		cp3Fork = linkCheckpointHeader(cp2, 4, common.Hash{0x04})
+		honest  = []*eth.CheckpointHeader{trusted, cp2, cp3}
+	)
+	c := newTestClient(trusted,
+		&testPeer{id: "a", checkpoints: honest},
+		&testPeer{id: "b", checkpoints: honest},
+		&testPeer{id: "c", checkpoints: []*eth.CheckpointHeader{trusted, cp2, cp3Fork}},
+	)
+
+	// the trusted checkpoint is retrieved first
+	testutils.AssertNoError(t, c.syncCheckpoints())
+	testutils.AssertEqual(t, cp3, c.Head())
+
+	header, err := c.StateHeader()
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, cp3.Spine.CpRoot, header.Root)
+	testutils.AssertEqual(t, cp3.Spine.CpHash, header.Hash())
+}
+
+func TestClient_SyncCheckpointsUnlinked(t *testing.T) {
+	var (
+		trusted  = makeCheckpointHeader(1, 10, common.Hash{0x01})
+		unlinked = makeCheckpointHeader(2, 20, common.Hash{0x02})
+		cp3      = linkCheckpointHeader(trusted, 3, common.Hash{0x03})
+		served   = []*eth.CheckpointHeader{trusted, unlinked, cp3}
+	)
+	c := newTestClient(trusted,
+		&testPeer{id: "a", checkpoints: served},
+		&testPeer{id: "b", checkpoints: served},
+	)
+	// the checkpoint not linked to the verified ones is skipped
+	testutils.AssertNoError(t, c.syncCheckpoints())
+	testutils.AssertEqual(t, cp3, c.Head())
+}
+
+func TestClient_SyncCheckpointsNoQuorum(t *testing.T) {
+	var (
+		trusted = makeCheckpointHeader(1, 10, common.Hash{0x01})
+		cp2     = linkCheckpointHeader(trusted, 2, common.Hash{0x02})
+		cp2Fork = linkCheckpointHeader(trusted, 2, common.Hash{0x03})
+	)
+	c := newTestClient(trusted,
+		&testPeer{id: "a", checkpoints: []*eth.CheckpointHeader{trusted, cp2}},
+		&testPeer{id: "b", checkpoints: []*eth.CheckpointHeader{trusted, cp2Fork}},
+	)
+	testutils.AssertNoError(t, c.syncCheckpoints())
+	testutils.AssertEqual(t, trusted, c.Head())
+
+	// the quorum is not reduced to the number of the responded peers
+	c = newTestClient(trusted, &testPeer{id: "a", checkpoints: []*eth.CheckpointHeader{trusted, cp2}})
+	testutils.AssertNoError(t, c.syncCheckpoints())
+	testutils.AssertEqual(t, trusted, c.Head())
+
+	// the state is not available before a checkpoint is verified
+	c = newTestClient(trusted)
+	_, err := c.StateHeader()
+	testutils.AssertError(t, err, errNoCheckpoint)
+	testutils.AssertError(t, c.syncCheckpoints(), light.ErrNoPeers)
+}
+
+func TestClient_StateProofs(t *testing.T) {
+	var (
+		addr    = common.Address{0x01}
+		balance = big.NewInt(1000)
+		db      = state.NewDatabase(rawdb.NewMemoryDatabase())
+	)
+	stateDb, err := state.New(common.Hash{}, db, nil)
+	testutils.AssertNoError(t, err)
+	stateDb.AddBalance(addr, balance)
+	root, err := stateDb.Commit(true)
+	testutils.AssertNoError(t, err)
+	testutils.AssertNoError(t, db.TrieDB().Commit(root, false, nil))
+
+	trusted := makeCheckpointHeader(1, 10, root)
+	api := NewPublicLightAPI(newTestClient(trusted, &testPeer{id: "a", checkpoints: []*eth.CheckpointHeader{trusted}, triedb: db.TrieDB()}))
+	testutils.AssertNoError(t, api.c.syncCheckpoints())
+
+	res, err := api.Light_GetBalance(context.Background(), addr)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, balance, res.ToInt())
+
+	res, err = api.Light_GetBalance(context.Background(), common.Address{0x02})
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, 0, res.ToInt().Sign())
+
+	// incomplete proofs are rejected
+	api = NewPublicLightAPI(newTestClient(trusted, &testPeer{id: "a", checkpoints: []*eth.CheckpointHeader{trusted}, triedb: db.TrieDB(), badProofs: true}))
+	testutils.AssertNoError(t, api.c.syncCheckpoints())
+	if _, err = api.Light_GetBalance(context.Background(), addr); err == nil {
+		t.Fatal("balance retrieved with invalid proof")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/light"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/trie"
+)
+
+var errUnsupportedRequest = errors.New("unsupported light request")
+
+// Database returns the database storing the retrieved state items.
+func (c *Client) Database() ethdb.Database { return c.db }
+
+// ChtIndexer is not supported by the checkpoint based light client.
+func (c *Client) ChtIndexer() *core.ChainIndexer { return nil }
+
+// BloomTrieIndexer is not supported by the checkpoint based light client.
+func (c *Client) BloomTrieIndexer() *core.ChainIndexer { return nil }
+
+// BloomIndexer is not supported by the checkpoint based light client.
+func (c *Client) BloomIndexer() *core.ChainIndexer { return nil }
+
+// IndexerConfig is not supported by the checkpoint based light client.
+func (c *Client) IndexerConfig() *light.IndexerConfig { return nil }
+
+// RetrieveTxStatus is not supported by the checkpoint based light client.
+func (c *Client) RetrieveTxStatus(ctx context.Context, req *light.TxStatusRequest) error {
+	return fmt.Errorf("%w: %T", errUnsupportedRequest, req)
+}
+
+// Retrieve retrieves the requested state item from peers. Only the trie
+// entries are supported: they are requested with Merkle proofs which are
+// verified against the requested trie root before storing to the database.
+func (c *Client) Retrieve(ctx context.Context, req light.OdrRequest) error {
+	switch req := req.(type) {
+	case *light.TrieRequest:
+		return c.retrieveTrieEntry(ctx, req)
+	default:
+		return fmt.Errorf("%w: %T", errUnsupportedRequest, req)
+	}
+}
+
+func (c *Client) retrieveTrieEntry(ctx context.Context, req *light.TrieRequest) error {
+	c.reqLock.Lock()
+	defer c.reqLock.Unlock()
+
+	peers := c.peers()
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	for _, p := range peers {
+		nodes, err := c.requestTrieProof(ctx, p, req.Id.Root, req.Key)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Debug("Light client: trie proof request failed", "peer", p.ID(), "err", err)
+			continue
+		}
+		proof, err := VerifyTrieProof(req.Id.Root, req.Key, nodes)
+		if err != nil {
+			log.Warn("Light client: invalid trie proof", "peer", p.ID(), "root", req.Id.Root, "err", err)
+			continue
+		}
+		req.Proof = proof
+		req.StoreResult(c.db)
+		return nil
+	}
+	return light.ErrNoPeers
+}
+
+// requestTrieProof requests a single Merkle proof from the peer.
+func (c *Client) requestTrieProof(ctx context.Context, p Peer, root common.Hash, key []byte) ([][]byte, error) {
+	ch := make(chan [][]byte, 1)
+	c.pendLock.Lock()
+	c.pendingProofs[p.ID()] = ch
+	c.pendLock.Unlock()
+	defer func() {
+		c.pendLock.Lock()
+		delete(c.pendingProofs, p.ID())
+		c.pendLock.Unlock()
+	}()
+
+	if err := p.RequestTrieProofs([]eth.TrieProofRequest{{Root: root, Key: key}}); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(requestTimeout)
+	defer timer.Stop()
+	select {
+	case nodes := <-ch:
+		return nodes, nil
+	case <-timer.C:
+		return nil, errRequestTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.quit:
+		return nil, errClientStopped
+	}
+}
+
+// VerifyTrieProof checks the proof of the key (or of its absence) in the trie
+// with the given root and returns the proof nodes.
+func VerifyTrieProof(root common.Hash, key []byte, nodes [][]byte) (*light.NodeSet, error) {
+	proof := light.NewNodeSet()
+	for _, node := range nodes {
+		light.NodeList{node}.Store(proof)
+	}
+	if _, err := trie.VerifyProof(root, key, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lightclient implements a light client of the Waterfall chain.
+// It follows the coordinated checkpoints and their spine headers served by
+// full peers, verifies the state commitments of the checkpoints and answers
+// state queries with Merkle proofs fetched on demand.
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+)
+
+// maxCheckpointLinks is the number of the recently verified block hashes
+// the following checkpoints can refer to.
+const maxCheckpointLinks = 64
+
+var (
+	errIncompleteCheckpoint = errors.New("incomplete checkpoint header")
+	errSpineMismatch        = errors.New("checkpoint spine mismatch")
+	errCpHeaderMismatch     = errors.New("checkpoint block mismatch")
+	errCpRootMismatch       = errors.New("checkpoint state root mismatch")
+	errCpReceiptMismatch    = errors.New("checkpoint receipts root mismatch")
+	errBadEpoch             = errors.New("bad checkpoint epoch")
+	errBadSpineNumber       = errors.New("bad checkpoint spine number")
+	errUnlinkedCheckpoint   = errors.New("checkpoint is not linked to the verified ones")
+)
+
+// VerifyCheckpointHeader checks the consistency of the checkpoint with its
+// headers: the spine has to be the checkpoint spine and has to commit to the
+// checkpoint block with its state and receipts roots. If parent is not nil,
+// the checkpoint must also succeed the parent one. The link of the checkpoint
+// to the verified ones is checked by the checkpoint chain.
+func VerifyCheckpointHeader(parent, ch *eth.CheckpointHeader) error {
+	if ch == nil || ch.Checkpoint == nil || ch.Spine == nil {
+		return errIncompleteCheckpoint
+	}
+	cp, spine := ch.Checkpoint, ch.Spine
+	if spine.Hash() != cp.Spine {
+		return fmt.Errorf("%w: have %#x, want %#x", errSpineMismatch, spine.Hash(), cp.Spine)
+	}
+	if cp.Epoch > cp.FinEpoch {
+		return fmt.Errorf("%w: epoch %d finalized in %d", errBadEpoch, cp.Epoch, cp.FinEpoch)
+	}
+	if spine.CpHash == (common.Hash{}) {
+		// only the genesis has no checkpoint block
+		if ch.CpHeader != nil || spine.Height > 0 {
+			return fmt.Errorf("%w: no checkpoint block of spine %#x", errCpHeaderMismatch, cp.Spine)
+		}
+	} else {
+		cpHeader := ch.CpHeader
+		if cpHeader == nil {
+			return errIncompleteCheckpoint
+		}
+		if cpHeader.Hash() != spine.CpHash {
+			return fmt.Errorf("%w: have %#x, want %#x", errCpHeaderMismatch, cpHeader.Hash(), spine.CpHash)
+		}
+		if cpHeader.Number != nil && cpHeader.Nr() != spine.CpNumber {
+			return fmt.Errorf("%w: number %d, want %d", errCpHeaderMismatch, cpHeader.Nr(), spine.CpNumber)
+		}
+		if cpHeader.Root != spine.CpRoot {
+			return fmt.Errorf("%w: have %#x, want %#x", errCpRootMismatch, cpHeader.Root, spine.CpRoot)
+		}
+		if cpHeader.ReceiptHash != spine.CpReceiptHash {
+			return fmt.Errorf("%w: have %#x, want %#x", errCpReceiptMismatch, cpHeader.ReceiptHash, spine.CpReceiptHash)
+		}
+	}
+	if parent == nil {
+		return nil
+	}
+	if cp.FinEpoch <= parent.Checkpoint.FinEpoch || cp.Epoch < parent.Checkpoint.Epoch {
+		return fmt.Errorf("%w: epoch %d (fin %d) after %d (fin %d)",
+			errBadEpoch, cp.Epoch, cp.FinEpoch, parent.Checkpoint.Epoch, parent.Checkpoint.FinEpoch)
+	}
+	if parent.Spine != nil && spine.Number != nil && spine.Nr() < parent.Spine.Nr() {
+		return fmt.Errorf("%w: %d after %d", errBadSpineNumber, spine.Nr(), parent.Spine.Nr())
+	}
+	if parent.Spine != nil && spine.CpNumber < parent.Spine.CpNumber {
+		return fmt.Errorf("%w: checkpoint block %d after %d", errBadSpineNumber, spine.CpNumber, parent.Spine.CpNumber)
+	}
+	return nil
+}
+
+// checkpointChain is the chain of the verified checkpoints linked by hash.
+// The spine of each following checkpoint refers to its checkpoint block by
+// hash, which must be the spine of a verified checkpoint or the block committed
+// by one of them. So every accepted checkpoint descends from the trusted one.
+type checkpointChain struct {
+	trusted *types.Checkpoint
+	head    *eth.CheckpointHeader
+	links   []common.Hash // hashes of the verified blocks, oldest first
+}
+
+func newCheckpointChain(trusted *types.Checkpoint) *checkpointChain {
+	return &checkpointChain{trusted: trusted}
+}
+
+// verify checks the checkpoint can be the next head of the chain.
+// The first one must be the trusted checkpoint.
+func (c *checkpointChain) verify(ch *eth.CheckpointHeader) error {
+	if err := VerifyCheckpointHeader(c.head, ch); err != nil {
+		return err
+	}
+	if c.head == nil {
+		if ch.Checkpoint.Spine != c.trusted.Spine {
+			return fmt.Errorf("%w: have %#x, want trusted %#x", errSpineMismatch, ch.Checkpoint.Spine, c.trusted.Spine)
+		}
+		return nil
+	}
+	if !c.linked(ch.Spine.CpHash) {
+		return fmt.Errorf("%w: checkpoint block %#x", errUnlinkedCheckpoint, ch.Spine.CpHash)
+	}
+	return nil
+}
+
+// insert sets the verified checkpoint as the head of the chain.
+func (c *checkpointChain) insert(ch *eth.CheckpointHeader) {
+	c.head = ch
+	c.links = append(c.links, ch.Spine.CpHash, ch.Checkpoint.Spine)
+	if len(c.links) > maxCheckpointLinks {
+		c.links = c.links[len(c.links)-maxCheckpointLinks:]
+	}
+}
+
+func (c *checkpointChain) linked(hash common.Hash) bool {
+	if hash == (common.Hash{}) {
+		return false
+	}
+	for _, h := range c.links {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// checkpointVotes counts the peers agreeing on the checkpoints of each epoch.
+type checkpointVotes map[uint64]map[common.Hash]*checkpointVote
+
+type checkpointVote struct {
+	header *eth.CheckpointHeader
+	peers  map[string]struct{}
+}
+
+// add registers the checkpoints served by the peer.
+func (v checkpointVotes) add(peer string, headers []*eth.CheckpointHeader) {
+	for _, ch := range headers {
+		if ch == nil || ch.Checkpoint == nil || ch.Spine == nil {
+			continue
+		}
+		epoch := ch.Checkpoint.FinEpoch
+		if v[epoch] == nil {
+			v[epoch] = make(map[common.Hash]*checkpointVote)
+		}
+		key := ch.Spine.Hash()
+		if v[epoch][key] == nil {
+			v[epoch][key] = &checkpointVote{header: ch, peers: make(map[string]struct{})}
+		}
+		v[epoch][key].peers[peer] = struct{}{}
+	}
+}
+
+// best returns the checkpoint of the epoch served by the most of peers
+// and the number of these peers. Ties are rejected.
+func (v checkpointVotes) best(epoch uint64) (*eth.CheckpointHeader, int) {
+	var (
+		best  *eth.CheckpointHeader
+		votes int
+		tie   bool
+	)
+	for _, vote := range v[epoch] {
+		switch {
+		case len(vote.peers) > votes:
+			best, votes, tie = vote.header, len(vote.peers), false
+		case len(vote.peers) == votes:
+			tie = true
+		}
+	}
+	if tie {
+		return nil, 0
+	}
+	return best, votes
+}
+
+// epochs returns the epochs with votes in ascending order.
+func (v checkpointVotes) epochs() []uint64 {
+	epochs := make([]uint64, 0, len(v))
+	for epoch := range v {
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] < epochs[j] })
+	return epochs
+}
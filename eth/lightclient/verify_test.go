@@ -0,0 +1,185 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightclient
+
+import (
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+// makeCheckpointHeader creates a consistent checkpoint header finalized
+// in the given epoch with the checkpoint block of the given number.
+func makeCheckpointHeader(finEpoch, cpNumber uint64, root common.Hash) *eth.CheckpointHeader {
+	nr := cpNumber
+	cpHeader := &types.Header{
+		Slot:        cpNumber,
+		Height:      cpNumber,
+		CpBaseFee:   big.NewInt(0),
+		Number:      &nr,
+		Root:        root,
+		ReceiptHash: common.BytesToHash([]byte{byte(cpNumber)}),
+	}
+	spineNr := cpNumber + 1
+	spine := &types.Header{
+		ParentHashes:  common.HashArray{cpHeader.Hash()},
+		Slot:          cpNumber + 1,
+		Height:        cpNumber + 1,
+		CpHash:        cpHeader.Hash(),
+		CpNumber:      cpNumber,
+		CpBaseFee:     big.NewInt(0),
+		CpRoot:        root,
+		CpReceiptHash: cpHeader.ReceiptHash,
+		Number:        &spineNr,
+		Root:          root,
+	}
+	return &eth.CheckpointHeader{
+		Checkpoint: &types.Checkpoint{
+			Epoch:    finEpoch - 1,
+			FinEpoch: finEpoch,
+			Spine:    spine.Hash(),
+		},
+		Spine:    spine,
+		CpHeader: cpHeader,
+	}
+}
+
+// linkCheckpointHeader creates a checkpoint header finalized in the given
+// epoch which checkpoint block is the spine of the parent checkpoint.
+func linkCheckpointHeader(parent *eth.CheckpointHeader, finEpoch uint64, root common.Hash) *eth.CheckpointHeader {
+	cpHeader := parent.Spine
+	spineNr := cpHeader.Nr() + 1
+	spine := &types.Header{
+		ParentHashes:  common.HashArray{cpHeader.Hash()},
+		Slot:          cpHeader.Slot + 1,
+		Height:        cpHeader.Height + 1,
+		CpHash:        cpHeader.Hash(),
+		CpNumber:      cpHeader.Nr(),
+		CpBaseFee:     big.NewInt(0),
+		CpRoot:        cpHeader.Root,
+		CpReceiptHash: cpHeader.ReceiptHash,
+		Number:        &spineNr,
+		Root:          root,
+		TxHash:        root,
+	}
+	return &eth.CheckpointHeader{
+		Checkpoint: &types.Checkpoint{
+			Epoch:    finEpoch - 1,
+			FinEpoch: finEpoch,
+			Spine:    spine.Hash(),
+		},
+		Spine:    spine,
+		CpHeader: cpHeader,
+	}
+}
+
+func TestVerifyCheckpointHeader(t *testing.T) {
+	parent := makeCheckpointHeader(2, 10, common.Hash{0x01})
+	testutils.AssertNoError(t, VerifyCheckpointHeader(nil, parent))
+
+	ch := makeCheckpointHeader(3, 20, common.Hash{0x02})
+	testutils.AssertNoError(t, VerifyCheckpointHeader(parent, ch))
+
+	// checkpoints must succeed the parent one
+	testutils.AssertError(t, VerifyCheckpointHeader(ch, parent), errBadEpoch)
+
+	// incomplete checkpoint
+	testutils.AssertError(t, VerifyCheckpointHeader(parent, &eth.CheckpointHeader{Checkpoint: ch.Checkpoint}), errIncompleteCheckpoint)
+
+	// spine of other checkpoint
+	bad := *ch
+	bad.Spine = parent.Spine
+	testutils.AssertError(t, VerifyCheckpointHeader(parent, &bad), errSpineMismatch)
+
+	// checkpoint block not committed by the spine
+	bad = *ch
+	bad.CpHeader = parent.CpHeader
+	testutils.AssertError(t, VerifyCheckpointHeader(parent, &bad), errCpHeaderMismatch)
+
+	// state root not committed by the spine
+	bad = *ch
+	bad.CpHeader = types.CopyHeader(ch.CpHeader)
+	bad.CpHeader.Root = common.Hash{0xff}
+	testutils.AssertError(t, VerifyCheckpointHeader(parent, &bad), errCpRootMismatch)
+
+	// receipts root not committed by the spine
+	bad = *ch
+	bad.CpHeader = types.CopyHeader(ch.CpHeader)
+	bad.CpHeader.ReceiptHash = common.Hash{0xff}
+	testutils.AssertError(t, VerifyCheckpointHeader(parent, &bad), errCpReceiptMismatch)
+}
+
+func TestCheckpointVotes(t *testing.T) {
+	var (
+		cp1      = makeCheckpointHeader(1, 10, common.Hash{0x01})
+		cp2      = makeCheckpointHeader(2, 20, common.Hash{0x02})
+		cp2Fork  = makeCheckpointHeader(2, 21, common.Hash{0x03})
+		votes    = make(checkpointVotes)
+		noHeader *eth.CheckpointHeader
+	)
+	votes.add("a", []*eth.CheckpointHeader{cp1, cp2})
+	votes.add("b", []*eth.CheckpointHeader{cp1, cp2Fork})
+	votes.add("c", []*eth.CheckpointHeader{cp2, nil})
+
+	testutils.AssertEqual(t, []uint64{1, 2}, votes.epochs())
+
+	best, count := votes.best(1)
+	testutils.AssertEqual(t, cp1, best)
+	testutils.AssertEqual(t, 2, count)
+
+	best, count = votes.best(2)
+	testutils.AssertEqual(t, cp2, best)
+	testutils.AssertEqual(t, 2, count)
+
+	// ties are rejected
+	votes.add("d", []*eth.CheckpointHeader{cp2Fork})
+	best, count = votes.best(2)
+	testutils.AssertEqual(t, noHeader, best)
+	testutils.AssertEqual(t, 0, count)
+}
+
+func TestCheckpointChain(t *testing.T) {
+	var (
+		trusted  = makeCheckpointHeader(1, 10, common.Hash{0x01})
+		cp2      = linkCheckpointHeader(trusted, 2, common.Hash{0x02})
+		unlinked = makeCheckpointHeader(3, 30, common.Hash{0x03})
+		chain    = newCheckpointChain(trusted.Checkpoint)
+	)
+	// the first checkpoint must be the trusted one
+	testutils.AssertError(t, chain.verify(cp2), errSpineMismatch)
+	testutils.AssertNoError(t, chain.verify(trusted))
+	chain.insert(trusted)
+
+	// the following ones must refer to the verified ones
+	testutils.AssertError(t, chain.verify(unlinked), errUnlinkedCheckpoint)
+	testutils.AssertNoError(t, chain.verify(cp2))
+	chain.insert(cp2)
+	testutils.AssertEqual(t, cp2, chain.head)
+
+	// the links are kept for the recent checkpoints only
+	head := cp2
+	for epoch := uint64(3); epoch < maxCheckpointLinks; epoch++ {
+		head = linkCheckpointHeader(head, epoch, common.Hash{byte(epoch)})
+		testutils.AssertNoError(t, chain.verify(head))
+		chain.insert(head)
+	}
+	testutils.AssertEqual(t, maxCheckpointLinks, len(chain.links))
+	testutils.AssertEqual(t, false, chain.linked(trusted.Checkpoint.Spine))
+	testutils.AssertEqual(t, true, chain.linked(head.Checkpoint.Spine))
+}
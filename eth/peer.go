@@ -21,6 +21,7 @@ import (
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/downloader"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/snap"
 )
@@ -32,6 +33,10 @@ type ethPeerInfo struct {
 	LastFinNr uint64            `json:"lastFinNr"` // dag max height of the peer
 	LastCp    *types.Checkpoint `json:"lastCp"`    // last coordinated checkpoint of the peer
 	Dag       *common.HashArray `json:"dag"`       // hashes of the peer dag
+	Era       uint64            `json:"era"`       // current era of the peer
+	MaxSlot   uint64            `json:"maxSlot"`   // max slot of the peer dag tips
+
+	Score *downloader.PeerScore `json:"score,omitempty"` // sync reputation of the peer
 }
 
 // ethPeer is a wrapper around eth.Peer to maintain a few extra metadata.
@@ -45,11 +50,17 @@ type ethPeer struct {
 // info gathers and returns some `eth` protocol metadata known about a peer.
 func (p *ethPeer) info() *ethPeerInfo {
 	lastFinNr := p.GetDagInfo()
-	return &ethPeerInfo{
+	info := &ethPeerInfo{
 		Version:   p.Version(),
 		LastFinNr: lastFinNr,
 		//Dag:       dag,
 	}
+	if status := p.GetDagStatus(); status != nil {
+		info.LastCp = status.Checkpoint
+		info.Era = status.Era
+		info.MaxSlot = status.MaxSlot
+	}
+	return info
 }
 
 // snapPeerInfo represents a short summary of the `snap` sub-protocol metadata known
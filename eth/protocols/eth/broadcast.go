@@ -41,7 +41,11 @@ func (p *Peer) broadcastBlocks() {
 	for {
 		select {
 		case prop := <-p.queuedBlocks:
-			if err := p.SendNewBlock(prop.block); err != nil {
+			send := p.SendNewBlock
+			if p.version >= ETH67 {
+				send = p.SendNewCompactBlock
+			}
+			if err := send(prop.block); err != nil {
 				return
 			}
 			p.Log().Trace("Propagated block", "number", prop.block.Nr(), "hash", prop.block.Hash().Hex())
@@ -0,0 +1,62 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"errors"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+)
+
+var (
+	// ErrCompactBlockIncomplete is returned if some of the compact block
+	// transactions are not known by the local tx pool.
+	ErrCompactBlockIncomplete = errors.New("compact block incomplete")
+	// ErrCompactBlockBadBody is returned if the reconstructed block body
+	// does not match the announced header.
+	ErrCompactBlockBadBody = errors.New("compact block bad body")
+)
+
+// NewCompactBlockPacket creates the compact announcement of the block.
+func NewCompactBlockPacket(block *types.Block) *CompactBlockPacket {
+	txs := block.Transactions()
+	hashes := make(common.HashArray, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	return &CompactBlockPacket{
+		Header:   block.Header(),
+		TxHashes: hashes,
+	}
+}
+
+// Reconstruct assembles the full block from the transactions retrieved
+// by the announced hashes (usually by the lookup of the local tx pool).
+func (p *CompactBlockPacket) Reconstruct(getTx func(hash common.Hash) *types.Transaction) (*types.Block, error) {
+	txs := make([]*types.Transaction, len(p.TxHashes))
+	for i, hash := range p.TxHashes {
+		tx := getTx(hash)
+		if tx == nil {
+			return nil, ErrCompactBlockIncomplete
+		}
+		txs[i] = tx
+	}
+	block := types.NewBlockWithHeader(p.Header).WithBody(txs)
+	if block.BodyHash() != block.Body().CalculateHash() {
+		return nil, ErrCompactBlockBadBody
+	}
+	return block, nil
+}
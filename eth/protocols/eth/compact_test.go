@@ -0,0 +1,107 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rlp"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/trie"
+)
+
+func newCompactTestBlock(t *testing.T, txCount int) (*types.Block, []*types.Transaction) {
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	txs := make([]*types.Transaction, txCount)
+	for i := range txs {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), common.Address{0x11}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testKey)
+		testutils.AssertNoError(t, err)
+		txs[i] = tx
+	}
+	header := &types.Header{
+		ParentHashes: common.HashArray{{0x01}},
+		Slot:         10,
+		Height:       3,
+		GasLimit:     1000000,
+	}
+	return types.NewBlock(header, txs, nil, trie.NewStackTrie(nil)), txs
+}
+
+func TestCompactBlockReconstruct(t *testing.T) {
+	block, txs := newCompactTestBlock(t, 4)
+	_, foreign := newCompactTestBlock(t, 6)
+
+	enc, err := rlp.EncodeToBytes(NewCompactBlockPacket(block))
+	testutils.AssertNoError(t, err)
+	packet := new(CompactBlockPacket)
+	testutils.AssertNoError(t, rlp.DecodeBytes(enc, packet))
+	testutils.AssertNoError(t, packet.sanityCheck())
+	testutils.AssertEqual(t, len(txs), len(packet.TxHashes))
+
+	// the pool contains the block txs with extra ones
+	pool := map[common.Hash]*types.Transaction{}
+	for _, tx := range append([]*types.Transaction{foreign[5], foreign[4]}, txs...) {
+		pool[tx.Hash()] = tx
+	}
+	getTx := func(hash common.Hash) *types.Transaction { return pool[hash] }
+	res, err := packet.Reconstruct(getTx)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, block.Hash(), res.Hash())
+	testutils.AssertEqual(t, block.BodyHash(), res.Body().CalculateHash())
+
+	// a tx is missing in the pool
+	delete(pool, txs[2].Hash())
+	_, err = packet.Reconstruct(getTx)
+	testutils.AssertError(t, err, ErrCompactBlockIncomplete)
+	pool[txs[2].Hash()] = txs[2]
+
+	// the header does not match the announced txs
+	bad := &CompactBlockPacket{Header: block.Header(), TxHashes: packet.TxHashes[:3]}
+	_, err = bad.Reconstruct(getTx)
+	testutils.AssertError(t, err, ErrCompactBlockBadBody)
+
+	// the header is required
+	if err = (&CompactBlockPacket{}).sanityCheck(); err == nil {
+		t.Fatal("expected error for compact block without header")
+	}
+}
+
+func TestMissingAncestorsPacketsEncodeDecode(t *testing.T) {
+	query := GetMissingAncestorsPacket66{
+		RequestId: 1111,
+		GetMissingAncestorsPacket: GetMissingAncestorsPacket{
+			Hashes:     common.HashArray{{0x01}, {0x02}},
+			Checkpoint: common.Hash{0x03},
+		},
+	}
+	enc, err := rlp.EncodeToBytes(&query)
+	testutils.AssertNoError(t, err)
+	decQuery := new(GetMissingAncestorsPacket66)
+	testutils.AssertNoError(t, rlp.DecodeBytes(enc, decQuery))
+	testutils.AssertEqual(t, query, *decQuery)
+
+	block, _ := newCompactTestBlock(t, 2)
+	res := AncestorsPacket66{RequestId: 1111, AncestorsPacket: AncestorsPacket{block}}
+	enc, err = rlp.EncodeToBytes(&res)
+	testutils.AssertNoError(t, err)
+	decRes := new(AncestorsPacket66)
+	testutils.AssertNoError(t, rlp.DecodeBytes(enc, decRes))
+	testutils.AssertEqual(t, 1, len(decRes.AncestorsPacket))
+	testutils.AssertEqual(t, block.Hash(), decRes.AncestorsPacket[0].Hash())
+	testutils.AssertNoError(t, decRes.AncestorsPacket[0].SanityCheck())
+}
@@ -219,6 +219,56 @@ var eth66 = map[uint64]msgHandler{
 	GetHashesBySlotsMsg:           handleGetHashesBySlots66,
 }
 
+var eth67 = map[uint64]msgHandler{
+	NewBlockHashesMsg:             handleNewBlockhashes,
+	NewBlockMsg:                   handleNewBlock,
+	TransactionsMsg:               handleTransactions,
+	NewPooledTransactionHashesMsg: handleNewPooledTransactionHashes,
+	GetBlockHeadersMsg:            handleGetBlockHeaders66,
+	BlockHeadersMsg:               handleBlockHeaders66,
+	GetBlockBodiesMsg:             handleGetBlockBodies66,
+	BlockBodiesMsg:                handleBlockBodies66,
+	GetNodeDataMsg:                handleGetNodeData66,
+	NodeDataMsg:                   handleNodeData66,
+	GetReceiptsMsg:                handleGetReceipts66,
+	ReceiptsMsg:                   handleReceipts66,
+	GetPooledTransactionsMsg:      handleGetPooledTransactions66,
+	PooledTransactionsMsg:         handlePooledTransactions66,
+	GetDagMsg:                     handleGetDag66,
+	DagMsg:                        handleDag66,
+	GetHashesBySlotsMsg:           handleGetHashesBySlots66,
+	NewCompactBlockMsg:            handleNewCompactBlock,
+	GetMissingAncestorsMsg:        handleGetMissingAncestors66,
+	AncestorsMsg:                  handleAncestors66,
+}
+
+var eth69 = map[uint64]msgHandler{
+	NewBlockHashesMsg:             handleNewBlockhashes,
+	NewBlockMsg:                   handleNewBlock,
+	TransactionsMsg:               handleTransactions,
+	NewPooledTransactionHashesMsg: handleNewPooledTransactionHashes,
+	GetBlockHeadersMsg:            handleGetBlockHeaders66,
+	BlockHeadersMsg:               handleBlockHeaders66,
+	GetBlockBodiesMsg:             handleGetBlockBodies66,
+	BlockBodiesMsg:                handleBlockBodies66,
+	GetNodeDataMsg:                handleGetNodeData66,
+	NodeDataMsg:                   handleNodeData66,
+	GetReceiptsMsg:                handleGetReceipts66,
+	ReceiptsMsg:                   handleReceipts66,
+	GetPooledTransactionsMsg:      handleGetPooledTransactions66,
+	PooledTransactionsMsg:         handlePooledTransactions66,
+	GetDagMsg:                     handleGetDag66,
+	DagMsg:                        handleDag66,
+	GetHashesBySlotsMsg:           handleGetHashesBySlots66,
+	NewCompactBlockMsg:            handleNewCompactBlock,
+	GetMissingAncestorsMsg:        handleGetMissingAncestors66,
+	AncestorsMsg:                  handleAncestors66,
+	GetCheckpointHeadersMsg:       handleGetCheckpointHeaders66,
+	CheckpointHeadersMsg:          handleCheckpointHeaders66,
+	GetTrieProofsMsg:              handleGetTrieProofs66,
+	TrieProofsMsg:                 handleTrieProofs66,
+}
+
 // handleMessage is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
 func handleMessage(backend Backend, peer *Peer) error {
@@ -233,9 +283,11 @@ func handleMessage(backend Backend, peer *Peer) error {
 	defer msg.Discard()
 
 	var handlers = eth66
-	//if peer.Version() >= ETH67 { // Left in as a sample when new protocol is added
-	//	handlers = eth67
-	//}
+	if peer.Version() >= ETH69 {
+		handlers = eth69
+	} else if peer.Version() >= ETH67 {
+		handlers = eth67
+	}
 
 	// Track the amount of time it takes to serve the request and run the handler
 	if metrics.Enabled {
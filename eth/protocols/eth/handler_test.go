@@ -92,6 +92,7 @@ func newTestBackendWithGenerator(blocks int, generator func(int, *core.BlockGen)
 	}
 	txconfig := core.DefaultTxPoolConfig
 	txconfig.Journal = "" // Don't litter the disk with test journals
+	txconfig.ValidatorJournal = ""
 
 	return &testBackend{
 		db:     db,
@@ -21,8 +21,10 @@ import (
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/light"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/rlp"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/trie"
 )
 
 // handleGetBlockHeaders66 is the eth/66 version of handleGetBlockHeaders
@@ -288,6 +290,23 @@ func handleNewBlock(backend Backend, msg Decoder, peer *Peer) error {
 	return backend.Handle(peer, ann)
 }
 
+func handleNewCompactBlock(backend Backend, msg Decoder, peer *Peer) error {
+	// Retrieve and decode the propagated compact block
+	ann := new(CompactBlockPacket)
+	if err := msg.Decode(ann); err != nil {
+		peer.Log().Error("Handle request: decode failed", "err", err, "fn", "handleNewCompactBlock")
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if err := ann.sanityCheck(); err != nil {
+		peer.Log().Error("Handle request: sanityCheck failed", "err", err, "fn", "handleNewCompactBlock")
+		return err
+	}
+	// Mark the peer as owning the block
+	peer.markBlock(ann.Header.Hash())
+
+	return backend.Handle(peer, ann)
+}
+
 func handleBlockHeaders66(backend Backend, msg Decoder, peer *Peer) error {
 	// A batch of headers arrived to one of our previous requests
 	res := new(BlockHeadersPacket66)
@@ -713,3 +732,198 @@ func handleGetHashesBySlots66(backend Backend, msg Decoder, peer *Peer) error {
 	}
 	return peer.ReplyDagData(query.RequestId, hashes.Uniq())
 }
+
+func handleGetMissingAncestors66(backend Backend, msg Decoder, peer *Peer) error {
+	// Decode retrieval message
+	var query GetMissingAncestorsPacket66
+	if err := msg.Decode(&query); err != nil {
+		peer.Log().Error("Handle request: decode failed", "err", err, "fn", "handleGetMissingAncestors66")
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if len(query.Hashes) > LimitAncestors {
+		peer.Log().Error("Handle request: failed", "err", "too many hashes", "fn", "handleGetMissingAncestors66", "hashes", len(query.Hashes))
+		return fmt.Errorf("%w: %v > %v (get missing ancestors)", errMsgTooLarge, len(query.Hashes), LimitAncestors)
+	}
+	blocks, err := answerGetMissingAncestorsQuery(backend, query.GetMissingAncestorsPacket)
+	if err != nil {
+		peer.Log().Error("Handle request: failed", "err", err, "fn", "handleGetMissingAncestors66")
+		return peer.ReplyAncestors(query.RequestId, types.Blocks{})
+	}
+	return peer.ReplyAncestors(query.RequestId, blocks)
+}
+
+// answerGetMissingAncestorsQuery collects the requested blocks and all their
+// ancestors which are not finalized by the requester's checkpoint.
+func answerGetMissingAncestorsQuery(backend Backend, query GetMissingAncestorsPacket) (types.Blocks, error) {
+	cpHeader := backend.Chain().GetHeaderByHash(query.Checkpoint)
+	if cpHeader == nil {
+		return nil, fmt.Errorf("%w: unknown checkpoint %#x", errBadRequestParam, query.Checkpoint)
+	}
+	genesis := backend.Chain().Genesis().Hash()
+	if cpHeader.Nr() == 0 && cpHeader.Hash() != genesis {
+		return nil, fmt.Errorf("%w: checkpoint %#x is not finalized", errBadRequestParam, query.Checkpoint)
+	}
+	var (
+		bytes   int
+		blocks  = make(types.Blocks, 0, len(query.Hashes))
+		visited = make(map[common.Hash]bool, len(query.Hashes))
+		queue   = query.Hashes.Copy()
+	)
+	for len(queue) > 0 && len(blocks) < LimitAncestors && bytes < softResponseLimit {
+		hash := queue[0]
+		queue = queue[1:]
+		if visited[hash] || hash == genesis {
+			continue
+		}
+		visited[hash] = true
+
+		block := backend.Chain().GetBlockByHash(hash)
+		if block == nil {
+			continue
+		}
+		// skip the blocks finalized by the requester's checkpoint
+		if block.Height() > 0 && block.Nr() > 0 && block.Nr() <= cpHeader.Nr() {
+			continue
+		}
+		blocks = append(blocks, block)
+		bytes += int(block.Size())
+		queue = append(queue, block.ParentHashes()...)
+	}
+	return blocks, nil
+}
+
+func handleAncestors66(backend Backend, msg Decoder, peer *Peer) error {
+	// A batch of ancestors arrived to one of our previous requests
+	res := new(AncestorsPacket66)
+	if err := msg.Decode(res); err != nil {
+		peer.Log().Error("Handle request: decode failed", "err", err, "fn", "handleAncestors66")
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	for _, block := range res.AncestorsPacket {
+		if err := block.SanityCheck(); err != nil {
+			peer.Log().Error("Handle request: sanityCheck failed", "err", err, "fn", "handleAncestors66")
+			return err
+		}
+	}
+	requestTracker.Fulfil(peer.id, peer.version, AncestorsMsg, res.RequestId)
+	return backend.Handle(peer, &res.AncestorsPacket)
+}
+
+func handleGetCheckpointHeaders66(backend Backend, msg Decoder, peer *Peer) error {
+	// Decode retrieval message
+	var query GetCheckpointHeadersPacket66
+	if err := msg.Decode(&query); err != nil {
+		peer.Log().Error("Handle request: decode failed", "err", err, "fn", "handleGetCheckpointHeaders66")
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	response := answerGetCheckpointHeadersQuery(backend, query.GetCheckpointHeadersPacket)
+	return peer.ReplyCheckpointHeaders(query.RequestId, response)
+}
+
+// answerGetCheckpointHeadersQuery collects the coordinated checkpoints
+// finalized in the requested epochs together with their state commitments.
+// Epochs without checkpoint are skipped.
+func answerGetCheckpointHeadersQuery(backend Backend, query GetCheckpointHeadersPacket) []*CheckpointHeader {
+	var (
+		bytes   int
+		chain   = backend.Chain()
+		amount  = query.Amount
+		headers = make([]*CheckpointHeader, 0, LimitCheckpointHeaders)
+		lastCp  = chain.GetLastCoordinatedCheckpoint()
+	)
+	if amount > LimitCheckpointHeaders {
+		amount = LimitCheckpointHeaders
+	}
+	for epoch := query.FromEpoch; epoch < query.FromEpoch+amount && bytes < softResponseLimit; epoch++ {
+		if lastCp != nil && epoch > lastCp.FinEpoch {
+			break
+		}
+		spineHash := chain.GetEpoch(epoch)
+		if spineHash == (common.Hash{}) {
+			continue
+		}
+		cp := chain.GetCoordinatedCheckpoint(spineHash)
+		spine := chain.GetHeaderByHash(spineHash)
+		if cp == nil || spine == nil || cp.FinEpoch != epoch {
+			continue
+		}
+		item := &CheckpointHeader{
+			Checkpoint: cp,
+			Spine:      spine,
+		}
+		if spine.CpHash != (common.Hash{}) {
+			if item.CpHeader = chain.GetHeaderByHash(spine.CpHash); item.CpHeader == nil {
+				continue
+			}
+		}
+		headers = append(headers, item)
+		bytes += 3 * estHeaderSize
+	}
+	return headers
+}
+
+func handleCheckpointHeaders66(backend Backend, msg Decoder, peer *Peer) error {
+	// A batch of checkpoint headers arrived to one of our previous requests
+	res := new(CheckpointHeadersPacket66)
+	if err := msg.Decode(res); err != nil {
+		peer.Log().Error("Handle request: decode failed", "err", err, "fn", "handleCheckpointHeaders66")
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	for _, item := range res.CheckpointHeadersPacket {
+		if item == nil || item.Checkpoint == nil || item.Spine == nil {
+			return fmt.Errorf("%w: incomplete checkpoint header", errDecode)
+		}
+	}
+	requestTracker.Fulfil(peer.id, peer.version, CheckpointHeadersMsg, res.RequestId)
+	return backend.Handle(peer, &res.CheckpointHeadersPacket)
+}
+
+func handleGetTrieProofs66(backend Backend, msg Decoder, peer *Peer) error {
+	// Decode retrieval message
+	var query GetTrieProofsPacket66
+	if err := msg.Decode(&query); err != nil {
+		peer.Log().Error("Handle request: decode failed", "err", err, "fn", "handleGetTrieProofs66")
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if len(query.GetTrieProofsPacket) > LimitTrieProofs {
+		peer.Log().Error("Handle request: failed", "err", "too many proofs", "fn", "handleGetTrieProofs66", "proofs", len(query.GetTrieProofsPacket))
+		return fmt.Errorf("%w: %v > %v (get trie proofs)", errMsgTooLarge, len(query.GetTrieProofsPacket), LimitTrieProofs)
+	}
+	response := answerGetTrieProofsQuery(backend, query.GetTrieProofsPacket)
+	return peer.ReplyTrieProofs(query.RequestId, response)
+}
+
+// answerGetTrieProofsQuery collects the Merkle proofs of the requested keys.
+// The proofs of the tries missing locally are skipped.
+func answerGetTrieProofsQuery(backend Backend, query GetTrieProofsPacket) [][]byte {
+	proofs := light.NewNodeSet()
+	for _, req := range query {
+		if proofs.DataSize() >= softResponseLimit {
+			break
+		}
+		tr, err := trie.New(req.Root, backend.Chain().StateCache().TrieDB())
+		if err != nil {
+			log.Debug("Trie proof: trie unavailable", "root", req.Root, "err", err)
+			continue
+		}
+		if err := tr.Prove(req.Key, 0, proofs); err != nil {
+			log.Debug("Trie proof: failed", "root", req.Root, "key", fmt.Sprintf("%#x", req.Key), "err", err)
+		}
+	}
+	var nodes [][]byte
+	for _, blob := range proofs.NodeList() {
+		nodes = append(nodes, blob)
+	}
+	return nodes
+}
+
+func handleTrieProofs66(backend Backend, msg Decoder, peer *Peer) error {
+	// A batch of trie proofs arrived to one of our previous requests
+	res := new(TrieProofsPacket66)
+	if err := msg.Decode(res); err != nil {
+		peer.Log().Error("Handle request: decode failed", "err", err, "fn", "handleTrieProofs66")
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	requestTracker.Fulfil(peer.id, peer.version, TrieProofsMsg, res.RequestId)
+	return backend.Handle(peer, &res.TrieProofsPacket)
+}
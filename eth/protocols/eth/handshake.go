@@ -17,11 +17,14 @@
 package eth
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/forkid"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/p2p"
 )
 
@@ -31,26 +34,78 @@ const (
 	handshakeTimeout = 5 * time.Second
 )
 
+// CheckpointFilter is a method to check the last coordinated checkpoint of a
+// remote peer against the local chain.
+type CheckpointFilter func(cp *types.Checkpoint) error
+
+// NewCheckpointFilter creates a filter that rejects the checkpoints conflicting
+// with the checkpoints of the local chain.
+func NewCheckpointFilter(chain *core.BlockChain) CheckpointFilter {
+	return func(cp *types.Checkpoint) error {
+		if cp == nil {
+			return errors.New("no checkpoint")
+		}
+		if cp.Epoch > cp.FinEpoch {
+			return fmt.Errorf("bad checkpoint: epoch %d finalized in %d", cp.Epoch, cp.FinEpoch)
+		}
+		local := chain.GetLastCoordinatedCheckpoint()
+		if local == nil {
+			return nil
+		}
+		if cp.FinEpoch == local.FinEpoch && (cp.Spine != local.Spine || cp.Root != local.Root) {
+			return fmt.Errorf("checkpoint mismatch at epoch %d: spine %#x (!= %#x)", cp.FinEpoch, cp.Spine, local.Spine)
+		}
+		// the checkpoints prior to the local one must be known by the local chain,
+		// the epoch index is keyed by the finalization epoch.
+		if cp.FinEpoch < local.FinEpoch {
+			if spine := chain.GetEpoch(cp.FinEpoch); spine != (common.Hash{}) && spine != cp.Spine {
+				return fmt.Errorf("checkpoint mismatch at epoch %d: spine %#x (!= %#x)", cp.FinEpoch, cp.Spine, spine)
+			}
+		}
+		if known := chain.GetCoordinatedCheckpoint(cp.Spine); known != nil && known.Root != cp.Root {
+			return fmt.Errorf("checkpoint mismatch at spine %#x: root %#x (!= %#x)", cp.Spine, cp.Root, known.Root)
+		}
+		return nil
+	}
+}
+
 // Handshake executes the eth protocol handshake, negotiating version number,
-// network IDs, dag hashes and lastFinNr, and genesis block.
-func (p *Peer) Handshake(network uint64, lastFinNr uint64, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
+// network IDs, lastFinNr and dag status, and genesis block.
+func (p *Peer) Handshake(network uint64, lastFinNr uint64, dag *DagStatus, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, cpFilter CheckpointFilter) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 2)
 
-	var status StatusPacket // safe to read after two values have been received from errc
+	var status StatusPacket68 // safe to read after two values have been received from errc
 
 	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket{
+		if p.version < ETH68 {
+			errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket{
+				ProtocolVersion: uint32(p.version),
+				NetworkID:       network,
+				LastFinNr:       lastFinNr,
+				//Dag:             &common.HashArray{},
+				Genesis: genesis,
+				ForkID:  forkID,
+			})
+			return
+		}
+		if dag == nil {
+			dag = &DagStatus{}
+		}
+		errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket68{
 			ProtocolVersion: uint32(p.version),
 			NetworkID:       network,
 			LastFinNr:       lastFinNr,
-			//Dag:             &common.HashArray{},
-			Genesis: genesis,
-			ForkID:  forkID,
+			Genesis:         genesis,
+			ForkID:          forkID,
+			Checkpoint:      dag.Checkpoint,
+			Era:             dag.Era,
+			Tips:            dag.Tips,
+			MaxSlot:         dag.MaxSlot,
 		})
 	}()
 	go func() {
-		errc <- p.readStatus(network, &status, genesis, forkFilter)
+		errc <- p.readStatus(network, &status, genesis, forkFilter, cpFilter)
 	}()
 	timeout := time.NewTimer(handshakeTimeout)
 	defer timeout.Stop()
@@ -65,12 +120,20 @@ func (p *Peer) Handshake(network uint64, lastFinNr uint64, genesis common.Hash,
 		}
 	}
 	p.lastFinNr = status.LastFinNr
+	if p.version >= ETH68 {
+		p.dagStatus = &DagStatus{
+			Checkpoint: status.Checkpoint,
+			Era:        status.Era,
+			Tips:       status.Tips,
+			MaxSlot:    status.MaxSlot,
+		}
+	}
 	p.isNewCon = true
 	return nil
 }
 
 // readStatus reads the remote handshake message.
-func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.Hash, forkFilter forkid.Filter) error {
+func (p *Peer) readStatus(network uint64, status *StatusPacket68, genesis common.Hash, forkFilter forkid.Filter, cpFilter CheckpointFilter) error {
 	msg, err := p.rw.ReadMsg()
 	if err != nil {
 		return err
@@ -82,8 +145,20 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
 	}
 	// Decode the handshake and make sure everything matches
-	if err := msg.Decode(&status); err != nil {
-		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	if p.version >= ETH68 {
+		if err := msg.Decode(status); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+	} else {
+		var legacy StatusPacket
+		if err := msg.Decode(&legacy); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		status.ProtocolVersion = legacy.ProtocolVersion
+		status.NetworkID = legacy.NetworkID
+		status.LastFinNr = legacy.LastFinNr
+		status.Genesis = legacy.Genesis
+		status.ForkID = legacy.ForkID
 	}
 	if status.NetworkID != network {
 		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, status.NetworkID, network)
@@ -97,5 +172,15 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 	if err := forkFilter(status.ForkID); err != nil {
 		return fmt.Errorf("%w: %v", errForkIDRejected, err)
 	}
+	if p.version >= ETH68 {
+		if len(status.Tips) > LimitDagHashes {
+			return fmt.Errorf("%w: %v > %v (tips)", errMsgTooLarge, len(status.Tips), LimitDagHashes)
+		}
+		if cpFilter != nil {
+			if err := cpFilter(status.Checkpoint); err != nil {
+				return fmt.Errorf("%w: %v", errCheckpointRejected, err)
+			}
+		}
+	}
 	return nil
 }
@@ -22,6 +22,7 @@ import (
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/forkid"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/p2p"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/p2p/enode"
 )
@@ -78,7 +79,7 @@ func testHandshake(t *testing.T, protocol uint) {
 		// Send the junk test with one peer, check the handshake failure
 		go p2p.Send(app, test.code, test.data)
 
-		err := peer.Handshake(1, lfnr, genesis.Hash(), forkID, forkid.NewFilter(backend.chain))
+		err := peer.Handshake(1, lfnr, nil, genesis.Hash(), forkID, forkid.NewFilter(backend.chain), NewCheckpointFilter(backend.chain))
 		if err == nil {
 			t.Errorf("test %d: protocol returned nil error, want %q", i, test.want)
 		} else if !errors.Is(err, test.want) {
@@ -86,3 +87,100 @@ func testHandshake(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that handshake failures of the dag status exchange are detected and reported correctly.
+func TestHandshake68(t *testing.T) {
+	backend := newTestBackend(3)
+	defer backend.close()
+
+	var (
+		genesis = backend.chain.Genesis()
+		lfnr    = backend.chain.GetLastFinalizedNumber()
+		forkID  = forkid.NewID(backend.chain.Config(), backend.chain.Genesis().Hash(), backend.chain.GetLastFinalizedHeader().Nr())
+		cp      = backend.chain.GetLastCoordinatedCheckpoint()
+		status  = &DagStatus{Checkpoint: cp, Era: 1, Tips: common.HashArray{genesis.Hash()}, MaxSlot: 5}
+	)
+	if cp == nil {
+		t.Fatal("expected local checkpoint")
+	}
+	conflict := cp.Copy()
+	conflict.Spine = common.Hash{0x01}
+
+	// advance the local chain to the non-genesis checkpoints
+	prevCp := &types.Checkpoint{Epoch: 1, FinEpoch: 2, Root: genesis.Root(), Spine: common.Hash{0x11}}
+	lastCp := &types.Checkpoint{Epoch: 2, FinEpoch: 3, Root: genesis.Root(), Spine: common.Hash{0x12}}
+	backend.chain.SetLastCoordinatedCheckpoint(prevCp)
+	backend.chain.SetLastCoordinatedCheckpoint(lastCp)
+	defer backend.chain.SetLastCoordinatedCheckpoint(cp)
+	forked := prevCp.Copy()
+	forked.Spine = common.Hash{0x02}
+
+	tests := []struct {
+		data StatusPacket68
+		want error
+	}{
+		{
+			data: StatusPacket68{ETH68, 999, lfnr, genesis.Hash(), forkID, cp, 0, nil, 0},
+			want: errNetworkIDMismatch,
+		},
+		{
+			data: StatusPacket68{ETH68, 1, lfnr, genesis.Hash(), forkID, conflict, 0, nil, 0},
+			want: errCheckpointRejected,
+		},
+		{
+			data: StatusPacket68{ETH68, 1, lfnr, genesis.Hash(), forkID, cp, 0, make(common.HashArray, LimitDagHashes+1), 0},
+			want: errMsgTooLarge,
+		},
+		{
+			data: StatusPacket68{ETH68, 1, lfnr, genesis.Hash(), forkID, cp, 2, common.HashArray{{0x02}}, 7},
+			want: nil,
+		},
+		// the last local checkpoint
+		{
+			data: StatusPacket68{ETH68, 1, lfnr, genesis.Hash(), forkID, lastCp, 4, common.HashArray{{0x02}}, 12},
+			want: nil,
+		},
+		// a lagging peer on the local spine
+		{
+			data: StatusPacket68{ETH68, 1, lfnr, genesis.Hash(), forkID, prevCp, 3, common.HashArray{{0x02}}, 9},
+			want: nil,
+		},
+		// a lagging peer on a conflicting spine
+		{
+			data: StatusPacket68{ETH68, 1, lfnr, genesis.Hash(), forkID, forked, 3, common.HashArray{{0x02}}, 9},
+			want: errCheckpointRejected,
+		},
+	}
+	for i, test := range tests {
+		app, net := p2p.MsgPipe()
+		defer app.Close()
+		defer net.Close()
+
+		peer := NewPeer(ETH68, p2p.NewPeer(enode.ID{}, "peer", nil), net, nil)
+		defer peer.Close()
+
+		go p2p.Send(app, StatusMsg, test.data)
+		// drain the local status
+		go func() {
+			if msg, err := app.ReadMsg(); err == nil {
+				msg.Discard()
+			}
+		}()
+
+		err := peer.Handshake(1, lfnr, status, genesis.Hash(), forkID, forkid.NewFilter(backend.chain), NewCheckpointFilter(backend.chain))
+		if !errors.Is(err, test.want) {
+			t.Errorf("test %d: wrong error: got %v, want %v", i, err, test.want)
+		}
+		if test.want == nil {
+			remote := peer.GetDagStatus()
+			if remote == nil || remote.Era != test.data.Era || remote.MaxSlot != test.data.MaxSlot || remote.Checkpoint.Spine != test.data.Checkpoint.Spine {
+				t.Errorf("test %d: wrong dag status: %+v", i, remote)
+			}
+			peer.SetMaxSlot(test.data.MaxSlot - 1)
+			peer.SetMaxSlot(test.data.MaxSlot + 2)
+			if got := peer.GetDagStatus().MaxSlot; got != test.data.MaxSlot+2 {
+				t.Errorf("test %d: wrong max slot: got %d, want %d", i, got, test.data.MaxSlot+2)
+			}
+		}
+	}
+}
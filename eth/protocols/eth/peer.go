@@ -72,8 +72,9 @@ type Peer struct {
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
 
-	lastFinNr uint64 // Latest advertised dag lastFinNr
-	isNewCon  bool   // Is newly connected
+	lastFinNr uint64     // Latest advertised dag lastFinNr
+	dagStatus *DagStatus // Latest advertised dag status (wfdag/3 and later)
+	isNewCon  bool       // Is newly connected
 
 	knownBlocks     *knownCache            // Set of block hashes known to be known by this peer
 	queuedBlocks    chan *blockPropagation // Queue of blocks to broadcast to the peer
@@ -146,6 +147,25 @@ func (p *Peer) SetDagInfo(lastFinNr uint64) {
 	p.lastFinNr = lastFinNr
 }
 
+// GetDagStatus retrieves the latest dag status advertised by the peer,
+// nil if the peer does not support it.
+func (p *Peer) GetDagStatus() *DagStatus {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.dagStatus.Copy()
+}
+
+// SetMaxSlot updates the max slot of the peer dag status if the given slot is higher.
+func (p *Peer) SetMaxSlot(slot uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.dagStatus != nil && slot > p.dagStatus.MaxSlot {
+		p.dagStatus.MaxSlot = slot
+	}
+}
+
 // KnownBlock returns whether peer is known to already have a block.
 func (p *Peer) KnownBlock(hash common.Hash) bool {
 	return p.knownBlocks.Contains(hash)
@@ -284,6 +304,14 @@ func (p *Peer) AsyncSendNewBlock(block *types.Block) {
 	}
 }
 
+// SendNewCompactBlock propagates the block to a remote peer as a compact
+// block announcement.
+func (p *Peer) SendNewCompactBlock(block *types.Block) error {
+	// Mark all the block hash as known, but ensure we don't overflow our limits
+	p.knownBlocks.Add(block.Hash())
+	return p2p.Send(p.rw, NewCompactBlockMsg, NewCompactBlockPacket(block))
+}
+
 // ReplyBlockHeaders is the eth/66 version of SendBlockHeaders.
 func (p *Peer) ReplyBlockHeaders(id uint64, headers []*types.Header) error {
 	return p2p.Send(p.rw, BlockHeadersMsg, BlockHeadersPacket66{
@@ -325,6 +353,14 @@ func (p *Peer) ReplyDagData(id uint64, dag common.HashArray) error {
 	})
 }
 
+// ReplyAncestors is the response to GetMissingAncestors.
+func (p *Peer) ReplyAncestors(id uint64, blocks types.Blocks) error {
+	return p2p.Send(p.rw, AncestorsMsg, &AncestorsPacket66{
+		RequestId:       id,
+		AncestorsPacket: AncestorsPacket(blocks),
+	})
+}
+
 // RequestOneHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *Peer) RequestOneHeader(hash common.Hash) error {
@@ -459,6 +495,63 @@ func (p *Peer) RequestHashesBySlots(from, to uint64) error {
 	})
 }
 
+// RequestMissingAncestors fetches the given blocks with all the ancestors
+// unknown to the local node down to the checkpoint.
+func (p *Peer) RequestMissingAncestors(hashes common.HashArray, checkpoint common.Hash) error {
+	p.Log().Info("Fetching missing ancestors", "hashes", len(hashes), "checkpoint", fmt.Sprintf("%#x", checkpoint))
+	id := rand.Uint64()
+	requestTracker.Track(p.id, p.version, GetMissingAncestorsMsg, AncestorsMsg, id)
+	return p2p.Send(p.rw, GetMissingAncestorsMsg, &GetMissingAncestorsPacket66{
+		RequestId: id,
+		GetMissingAncestorsPacket: GetMissingAncestorsPacket{
+			Hashes:     hashes,
+			Checkpoint: checkpoint,
+		},
+	})
+}
+
+// ReplyCheckpointHeaders is the response to GetCheckpointHeaders.
+func (p *Peer) ReplyCheckpointHeaders(id uint64, headers []*CheckpointHeader) error {
+	return p2p.Send(p.rw, CheckpointHeadersMsg, &CheckpointHeadersPacket66{
+		RequestId:               id,
+		CheckpointHeadersPacket: headers,
+	})
+}
+
+// ReplyTrieProofs is the response to GetTrieProofs.
+func (p *Peer) ReplyTrieProofs(id uint64, nodes [][]byte) error {
+	return p2p.Send(p.rw, TrieProofsMsg, &TrieProofsPacket66{
+		RequestId:        id,
+		TrieProofsPacket: nodes,
+	})
+}
+
+// RequestCheckpointHeaders fetches a batch of coordinated checkpoints with
+// their state commitments starting from the given finalization epoch.
+func (p *Peer) RequestCheckpointHeaders(fromEpoch, amount uint64) error {
+	p.Log().Debug("Fetching checkpoint headers", "fromEpoch", fromEpoch, "amount", amount)
+	id := rand.Uint64()
+	requestTracker.Track(p.id, p.version, GetCheckpointHeadersMsg, CheckpointHeadersMsg, id)
+	return p2p.Send(p.rw, GetCheckpointHeadersMsg, &GetCheckpointHeadersPacket66{
+		RequestId: id,
+		GetCheckpointHeadersPacket: GetCheckpointHeadersPacket{
+			FromEpoch: fromEpoch,
+			Amount:    amount,
+		},
+	})
+}
+
+// RequestTrieProofs fetches a batch of Merkle proofs of state or storage tries.
+func (p *Peer) RequestTrieProofs(reqs []TrieProofRequest) error {
+	p.Log().Debug("Fetching trie proofs", "count", len(reqs))
+	id := rand.Uint64()
+	requestTracker.Track(p.id, p.version, GetTrieProofsMsg, TrieProofsMsg, id)
+	return p2p.Send(p.rw, GetTrieProofsMsg, &GetTrieProofsPacket66{
+		RequestId:           id,
+		GetTrieProofsPacket: reqs,
+	})
+}
+
 // knownCache is a cache for known hashes.
 type knownCache struct {
 	hashes mapset.Set
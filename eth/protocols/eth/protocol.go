@@ -30,6 +30,13 @@ import (
 // Constants to match up protocol versions and messages
 const (
 	ETH66 = 1
+	ETH67 = 2
+	ETH68 = 3
+	ETH69 = 4
+
+	// ETHLatest is the latest protocol version, it bounds the version
+	// constraints which must cover all the supported versions.
+	ETHLatest = ETH69
 )
 
 // ProtocolName is the official short name of the `wfdag` protocol used during
@@ -38,17 +45,26 @@ const ProtocolName = "wfdag"
 
 // ProtocolVersions are the supported versions of the `wfdag` protocol (first
 // is primary).
-var ProtocolVersions = []uint{ETH66}
+var ProtocolVersions = []uint{ETH69, ETH68, ETH67, ETH66}
 
 // protocolLengths are the number of implemented message corresponding to
 // different protocol versions.
-var protocolLengths = map[uint]uint64{ETH66: 20}
+var protocolLengths = map[uint]uint64{ETH69: 27, ETH68: 23, ETH67: 23, ETH66: 20}
 
 // maxMessageSize is the maximum cap on the size of a protocol message.
 const maxMessageSize = 10 * 1024 * 1024
 
 const LimitDagHashes = 32 * 8 * 4 //32slot * 8 blocks * 4 epochs = 1024
 
+// LimitAncestors is the maximum number of ancestors served in one response.
+const LimitAncestors = LimitDagHashes
+
+// LimitCheckpointHeaders is the maximum number of checkpoint headers served in one response.
+const LimitCheckpointHeaders = 64
+
+// LimitTrieProofs is the maximum number of trie proofs served in one response.
+const LimitTrieProofs = 64
+
 const (
 	StatusMsg                     = 0x00
 	NewBlockHashesMsg             = 0x01
@@ -68,6 +84,17 @@ const (
 	GetDagMsg                     = 0x11
 	DagMsg                        = 0x12
 	GetHashesBySlotsMsg           = 0x13
+
+	// Protocol messages introduced in wfdag/2
+	NewCompactBlockMsg     = 0x14
+	GetMissingAncestorsMsg = 0x15
+	AncestorsMsg           = 0x16
+
+	// Protocol messages introduced in wfdag/4
+	GetCheckpointHeadersMsg = 0x17
+	CheckpointHeadersMsg    = 0x18
+	GetTrieProofsMsg        = 0x19
+	TrieProofsMsg           = 0x1a
 )
 
 var (
@@ -81,6 +108,7 @@ var (
 	errForkIDRejected          = errors.New("fork ID rejected")
 	errInvalidDag              = errors.New("invalid dag")
 	errBadRequestParam         = errors.New("bad request params")
+	errCheckpointRejected      = errors.New("checkpoint rejected")
 )
 
 // Packet represents a p2p message in the `wfdag` protocol.
@@ -99,6 +127,44 @@ type StatusPacket struct {
 	ForkID          forkid.ID
 }
 
+// StatusPacket68 is the network packet for the status message for wfdag/3 and later.
+// Besides the basic status it carries the dag state of the peer.
+type StatusPacket68 struct {
+	ProtocolVersion uint32
+	NetworkID       uint64
+	LastFinNr       uint64
+	Genesis         common.Hash
+	ForkID          forkid.ID
+	Checkpoint      *types.Checkpoint // last coordinated checkpoint
+	Era             uint64            // current era
+	Tips            common.HashArray  // hashes of dag tips
+	MaxSlot         uint64            // max slot of dag tips
+}
+
+// DagStatus represents the dag state of a peer advertised in the handshake.
+type DagStatus struct {
+	Checkpoint *types.Checkpoint `json:"checkpoint"`
+	Era        uint64            `json:"era"`
+	Tips       common.HashArray  `json:"tips"`
+	MaxSlot    uint64            `json:"maxSlot"`
+}
+
+// Copy creates a deep copy of the dag status.
+func (s *DagStatus) Copy() *DagStatus {
+	if s == nil {
+		return nil
+	}
+	cpy := &DagStatus{
+		Era:     s.Era,
+		Tips:    s.Tips.Copy(),
+		MaxSlot: s.MaxSlot,
+	}
+	if s.Checkpoint != nil {
+		cpy.Checkpoint = s.Checkpoint.Copy()
+	}
+	return cpy
+}
+
 // NewBlockHashesPacket is the network packet for the block announcements.
 type NewBlockHashesPacket []struct {
 	Hash   common.Hash // Hash of one particular block being announced
@@ -349,9 +415,106 @@ type GetHashesBySlotsPacket66 struct {
 	GetHashesBySlotsPacket
 }
 
+// CompactBlockPacket is the network packet for the compact block propagation
+// message: the block header with the hashes of the block transactions,
+// the receiver reconstructs the block body from its own tx pool.
+type CompactBlockPacket struct {
+	Header   *types.Header
+	TxHashes common.HashArray
+}
+
+// sanityCheck verifies that the values are reasonable, as a DoS protection
+func (request *CompactBlockPacket) sanityCheck() error {
+	if request.Header == nil {
+		return errors.New("compact block without header")
+	}
+	return request.Header.SanityCheck()
+}
+
+// GetMissingAncestorsPacket represents a query of all ancestors of the given
+// blocks down to the checkpoint.
+type GetMissingAncestorsPacket struct {
+	Hashes     common.HashArray // blocks which ancestors are requested
+	Checkpoint common.Hash      // spine of the requester's checkpoint: ancestors finalized by it are skipped
+}
+
+// GetMissingAncestorsPacket66 represents a missing ancestors query over wfdag/2.
+type GetMissingAncestorsPacket66 struct {
+	RequestId uint64
+	GetMissingAncestorsPacket
+}
+
+// AncestorsPacket is the network packet for missing ancestors distribution.
+type AncestorsPacket []*types.Block
+
+// AncestorsPacket66 is the network packet for missing ancestors distribution over wfdag/2.
+type AncestorsPacket66 struct {
+	RequestId uint64
+	AncestorsPacket
+}
+
+// GetCheckpointHeadersPacket represents a query of the coordinated checkpoints
+// finalized starting from the given epoch.
+type GetCheckpointHeadersPacket struct {
+	FromEpoch uint64 // first finalization epoch to retrieve
+	Amount    uint64 // maximum number of checkpoints to retrieve
+}
+
+// GetCheckpointHeadersPacket66 represents a checkpoint headers query over wfdag/4.
+type GetCheckpointHeadersPacket66 struct {
+	RequestId uint64
+	GetCheckpointHeadersPacket
+}
+
+// CheckpointHeader is a coordinated checkpoint with the headers committing
+// to its state: the checkpoint spine and the block referenced by the spine
+// as its own checkpoint (CpHash), which holds the state and receipts roots.
+type CheckpointHeader struct {
+	Checkpoint *types.Checkpoint
+	Spine      *types.Header
+	CpHeader   *types.Header `rlp:"nil"` // nil for the genesis checkpoint
+}
+
+// CheckpointHeadersPacket is the network packet for checkpoint headers distribution.
+type CheckpointHeadersPacket []*CheckpointHeader
+
+// CheckpointHeadersPacket66 is the network packet for checkpoint headers distribution over wfdag/4.
+type CheckpointHeadersPacket66 struct {
+	RequestId uint64
+	CheckpointHeadersPacket
+}
+
+// TrieProofRequest represents a single Merkle proof query of the trie with the given root.
+type TrieProofRequest struct {
+	Root common.Hash // root of the state or storage trie
+	Key  []byte      // hashed key of the proved item
+}
+
+// GetTrieProofsPacket represents a query of Merkle proofs.
+type GetTrieProofsPacket []TrieProofRequest
+
+// GetTrieProofsPacket66 represents a Merkle proofs query over wfdag/4.
+type GetTrieProofsPacket66 struct {
+	RequestId uint64
+	GetTrieProofsPacket
+}
+
+// TrieProofsPacket is the network packet for Merkle proofs distribution:
+// the deduplicated set of trie nodes of all requested proofs.
+type TrieProofsPacket [][]byte
+
+// TrieProofsPacket66 is the network packet for Merkle proofs distribution over wfdag/4.
+type TrieProofsPacket66 struct {
+	RequestId uint64
+	TrieProofsPacket
+}
+
 func (*StatusPacket) Name() string { return "Status" }
 func (*StatusPacket) Kind() byte   { return StatusMsg }
 
+func (*StatusPacket68) Name() string { return "Status" }
+func (*StatusPacket68) Kind() byte   { return StatusMsg }
+
 func (*NewBlockHashesPacket) Name() string { return "NewBlockHashes" }
 func (*NewBlockHashesPacket) Kind() byte   { return NewBlockHashesMsg }
 
@@ -402,3 +565,24 @@ func (*DagPacket) Kind() byte   { return DagMsg }
 
 func (*GetHashesBySlotsPacket) Name() string { return "GetHashesBySlots" }
 func (*GetHashesBySlotsPacket) Kind() byte   { return GetHashesBySlotsMsg }
+
+func (*CompactBlockPacket) Name() string { return "NewCompactBlock" }
+func (*CompactBlockPacket) Kind() byte   { return NewCompactBlockMsg }
+
+func (*GetMissingAncestorsPacket) Name() string { return "GetMissingAncestors" }
+func (*GetMissingAncestorsPacket) Kind() byte   { return GetMissingAncestorsMsg }
+
+func (*AncestorsPacket) Name() string { return "Ancestors" }
+func (*AncestorsPacket) Kind() byte   { return AncestorsMsg }
+
+func (*GetCheckpointHeadersPacket) Name() string { return "GetCheckpointHeaders" }
+func (*GetCheckpointHeadersPacket) Kind() byte   { return GetCheckpointHeadersMsg }
+
+func (*CheckpointHeadersPacket) Name() string { return "CheckpointHeaders" }
+func (*CheckpointHeadersPacket) Kind() byte   { return CheckpointHeadersMsg }
+
+func (*GetTrieProofsPacket) Name() string { return "GetTrieProofs" }
+func (*GetTrieProofsPacket) Kind() byte   { return GetTrieProofsMsg }
+
+func (*TrieProofsPacket) Name() string { return "TrieProofs" }
+func (*TrieProofsPacket) Kind() byte   { return TrieProofsMsg }
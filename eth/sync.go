@@ -17,10 +17,12 @@
 package eth
 
 import (
+	"errors"
 	"time"
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/downloader"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/protocols/eth"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
 )
@@ -131,3 +133,36 @@ func (cs *chainSyncer) loop() {
 		}
 	}
 }
+
+// checkpointSyncLoop runs the checkpoint sync with the trusted checkpoint
+// as soon as the snap peers are available, retrying until it succeeds.
+func (h *handler) checkpointSyncLoop() {
+	defer h.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if h.chain.GetSlotInfo() == nil || h.peers.snapLen() < defaultMinSyncPeers {
+				timer.Reset(forceSyncCycle)
+				continue
+			}
+			cp := h.syncCheckpoint
+			err := h.downloader.CheckpointSync(cp.Checkpoint, cp.Era)
+			if errors.Is(err, downloader.ErrInvalidSyncCheckpoint) {
+				log.Error("Checkpoint sync rejected", "err", err)
+				return
+			}
+			if err != nil {
+				log.Warn("Checkpoint sync failed, retrying", "spine", cp.Checkpoint.Spine.Hex(), "err", err)
+				timer.Reset(forceSyncCycle)
+				continue
+			}
+			log.Info("Checkpoint sync completed", "spine", cp.Checkpoint.Spine.Hex(), "finEpoch", cp.Checkpoint.FinEpoch)
+			return
+		case <-h.quitSync:
+			return
+		}
+	}
+}
@@ -921,6 +921,7 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *Contex
 			Failed:      result.Failed(),
 			ReturnValue: returnVal,
 			StructLogs:  ethapi.FormatLogs(tracer.StructLogs()),
+			NativeOps:   tracer.NativeOps(),
 		}, nil
 
 	case Tracer:
@@ -18,6 +18,7 @@ package logger
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -108,10 +109,11 @@ type StructLogger struct {
 	cfg Config
 	env *vm.EVM
 
-	storage map[common.Address]Storage
-	logs    []StructLog
-	output  []byte
-	err     error
+	storage   map[common.Address]Storage
+	logs      []StructLog
+	nativeOps []*vm.NativeOpFrame
+	output    []byte
+	err       error
 }
 
 // NewStructLogger returns a new logger
@@ -130,6 +132,7 @@ func (l *StructLogger) Reset() {
 	l.storage = make(map[common.Address]Storage)
 	l.output = make([]byte, 0)
 	l.logs = l.logs[:0]
+	l.nativeOps = l.nativeOps[:0]
 	l.err = nil
 }
 
@@ -223,9 +226,23 @@ func (l *StructLogger) CaptureEnter(typ vm.OpCode, from common.Address, to commo
 
 func (l *StructLogger) CaptureExit(output []byte, gasUsed uint64, err error) {}
 
+// CaptureNativeOp implements the NativeOpLogger interface to trace a native
+// token or validator operation.
+func (l *StructLogger) CaptureNativeOp(env *vm.EVM, frame *vm.NativeOpFrame) {
+	l.env = env
+	l.nativeOps = append(l.nativeOps, frame)
+	l.output = frame.Output
+	if frame.Error != "" {
+		l.err = errors.New(frame.Error)
+	}
+}
+
 // StructLogs returns the captured log entries.
 func (l *StructLogger) StructLogs() []StructLog { return l.logs }
 
+// NativeOps returns the captured native operations.
+func (l *StructLogger) NativeOps() []*vm.NativeOpFrame { return l.nativeOps }
+
 // Error returns the VM error captured by the trace.
 func (l *StructLogger) Error() error { return l.err }
 
@@ -99,6 +99,11 @@ func (l *JSONLogger) CaptureEnter(typ vm.OpCode, from common.Address, to common.
 
 func (l *JSONLogger) CaptureExit(output []byte, gasUsed uint64, err error) {}
 
+// CaptureNativeOp outputs the frame of the native token or validator operation.
+func (l *JSONLogger) CaptureNativeOp(env *vm.EVM, frame *vm.NativeOpFrame) {
+	l.encoder.Encode(frame)
+}
+
 func (l *JSONLogger) CaptureTxStart(gasLimit uint64) {}
 
 func (l *JSONLogger) CaptureTxEnd(restGas uint64) {}
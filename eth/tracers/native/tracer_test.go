@@ -0,0 +1,106 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/state"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/vm"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token/operation"
+)
+
+// traceTokenCreate runs the WRC20 token creation with the given tracer.
+func traceTokenCreate(t *testing.T, name string) (common.Address, json.RawMessage) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	from := common.BytesToAddress(testutils.RandomData(20))
+	statedb.AddBalance(from, big.NewInt(1e18))
+
+	decimals := uint8(18)
+	op, err := operation.NewWrc20CreateOperation([]byte("Test"), []byte("TST"), &decimals, big.NewInt(1000))
+	testutils.AssertNoError(t, err)
+	data, err := operation.EncodeToBytes(op)
+	testutils.AssertNoError(t, err)
+
+	tracer, err := lookup(name, nil)
+	testutils.AssertNoError(t, err)
+
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(5),
+		Difficulty:  big.NewInt(0),
+		BaseFee:     big.NewInt(1),
+		GasLimit:    6000000,
+	}
+	msg := types.NewMessage(from, nil, 0, new(big.Int), 1000000, big.NewInt(1), big.NewInt(1), big.NewInt(1), data, nil, false)
+	evm := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, params.AllEthashProtocolChanges, vm.Config{Debug: true, Tracer: tracer, NoBaseFee: true})
+	tp := token.NewProcessor(blockCtx, statedb)
+
+	_, err = core.ApplyMessage(evm, tp, nil, msg, new(core.GasPool).AddGas(msg.Gas()))
+	testutils.AssertNoError(t, err)
+
+	res, err := tracer.GetResult()
+	testutils.AssertNoError(t, err)
+	return from, res
+}
+
+func TestCallTracerNativeOp(t *testing.T) {
+	from, res := traceTokenCreate(t, "callTracer")
+
+	call := new(callFrame)
+	testutils.AssertNoError(t, json.Unmarshal(res, call))
+	testutils.AssertEqual(t, vm.NativeOpToken, call.Type)
+	testutils.AssertEqual(t, addrToHex(from), call.From)
+	testutils.AssertEqual(t, "", call.Error)
+	if call.Native == nil {
+		t.Fatal("native operation frame is missing")
+	}
+	testutils.AssertEqual(t, "create", call.Native.Op)
+	testutils.AssertEqual(t, call.To, addrToHex(call.Native.To))
+
+	writes := 0
+	for _, access := range call.Native.Storage {
+		if access.Write {
+			writes++
+		}
+	}
+	if writes == 0 {
+		t.Fatal("storage writes are not traced")
+	}
+}
+
+func TestPrestateTracerNativeOp(t *testing.T) {
+	from, res := traceTokenCreate(t, "prestateTracer")
+
+	pre := make(map[common.Address]*account)
+	testutils.AssertNoError(t, json.Unmarshal(res, &pre))
+	acc, ok := pre[from]
+	if !ok {
+		t.Fatal("sender is missing in prestate")
+	}
+	testutils.AssertEqual(t, "0xde0b6b3a7640000", acc.Balance)
+	testutils.AssertEqual(t, uint64(0), acc.Nonce)
+}
@@ -69,6 +69,12 @@ func init() {
 	}
 }
 
+// RegisterLookup registers a method as a lookup for tracers, meaning that
+// users can invoke a named tracer through that lookup.
+func RegisterLookup(lookup func(string, *Context) (Tracer, error)) {
+	lookups = append(lookups, lookup)
+}
+
 // New returns a new instance of a tracer, by iterating through the
 // registered lookups.
 func New(code string, ctx *Context) (Tracer, error) {
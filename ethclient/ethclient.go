@@ -381,6 +381,9 @@ func toFilterArg(q ethereum.FilterQuery) (interface{}, error) {
 		}
 		arg["toBlock"] = toBlockNumArg(q.ToBlock)
 	}
+	if q.Finality != "" {
+		arg["finality"] = q.Finality
+	}
 	return arg, nil
 }
 
@@ -0,0 +1,300 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tokenclient provides an RPC client for the native token API.
+package tokenclient
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "gitlab.waterfall.network/waterfall/protocol/gwat"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token/operation"
+)
+
+// Client is a wrapper around rpc.Client that implements the native token functionality.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	return DialContext(context.Background(), rawurl)
+}
+
+// DialContext connects a client to the given URL with the given context.
+func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return New(c), nil
+}
+
+// New creates a client that uses the given RPC client.
+func New(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// Close closes the underlying RPC connection.
+func (tc *Client) Close() {
+	tc.c.Close()
+}
+
+type rpcTokenProperties struct {
+	Std         *hexutil.Uint  `json:"std"`
+	Name        *hexutil.Bytes `json:"name"`
+	Symbol      *hexutil.Bytes `json:"symbol"`
+	PercentFee  *hexutil.Uint8 `json:"percentFee,omitempty"`
+	Decimals    *hexutil.Uint8 `json:"decimals,omitempty"`
+	TotalSupply *hexutil.Big   `json:"totalSupply,omitempty"`
+	Cost        *hexutil.Big   `json:"cost,omitempty"`
+	BaseURI     *hexutil.Bytes `json:"baseURI,omitempty"`
+	ByTokenId   *struct {
+		TokenURI    *hexutil.Bytes  `json:"tokenURI"`
+		OwnerOf     *common.Address `json:"ownerOf"`
+		GetApproved *common.Address `json:"getApproved"`
+		Metadata    *hexutil.Bytes  `json:"metadata"`
+		Cost        *hexutil.Big    `json:"cost,omitempty"`
+	} `json:"byTokenId,omitempty"`
+}
+
+func (p *rpcTokenProperties) std() operation.Std {
+	if p.Std == nil {
+		return 0
+	}
+	return operation.Std(*p.Std)
+}
+
+func (p *rpcTokenProperties) toWRC20() *token.WRC20PropertiesResult {
+	props := &token.WRC20PropertiesResult{
+		Std:         p.std(),
+		Name:        bytesOf(p.Name),
+		Symbol:      bytesOf(p.Symbol),
+		TotalSupply: p.TotalSupply.ToInt(),
+		Cost:        p.Cost.ToInt(),
+	}
+	if p.Decimals != nil {
+		props.Decimals = uint8(*p.Decimals)
+	}
+	return props
+}
+
+func (p *rpcTokenProperties) toWRC721() *token.WRC721PropertiesResult {
+	props := &token.WRC721PropertiesResult{
+		Std:     p.std(),
+		Name:    bytesOf(p.Name),
+		Symbol:  bytesOf(p.Symbol),
+		BaseURI: bytesOf(p.BaseURI),
+	}
+	if p.PercentFee != nil {
+		props.PercentFee = uint8(*p.PercentFee)
+	}
+	if byId := p.ByTokenId; byId != nil {
+		props.TokenURI = bytesOf(byId.TokenURI)
+		props.Metadata = bytesOf(byId.Metadata)
+		props.Cost = byId.Cost.ToInt()
+		if byId.OwnerOf != nil {
+			props.OwnerOf = *byId.OwnerOf
+		}
+		if byId.GetApproved != nil {
+			props.GetApproved = *byId.GetApproved
+		}
+	}
+	return props
+}
+
+func bytesOf(b *hexutil.Bytes) []byte {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
+
+// Properties returns the properties of the token, which is either
+// a *token.WRC20PropertiesResult or a *token.WRC721PropertiesResult
+// according to the token standard. The properties of the NFT are only
+// returned for a WRC-721 token if tokenId is given.
+// The block number can be nil, in which case the properties are taken from the latest known block.
+func (tc *Client) Properties(ctx context.Context, tokenAddr common.Address, tokenId *big.Int, blockNumber *big.Int) (interface{}, error) {
+	var result *rpcTokenProperties
+	err := tc.c.CallContext(ctx, &result, "wat_tokenProperties", tokenAddr, toBlockNumArg(blockNumber), (*hexutil.Big)(tokenId))
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ethereum.NotFound
+	}
+	switch result.std() {
+	case operation.StdWRC20:
+		return result.toWRC20(), nil
+	case operation.StdWRC721:
+		return result.toWRC721(), nil
+	}
+	return nil, operation.ErrStandardNotValid
+}
+
+// WRC20Properties returns the properties of the WRC-20 token.
+func (tc *Client) WRC20Properties(ctx context.Context, tokenAddr common.Address, blockNumber *big.Int) (*token.WRC20PropertiesResult, error) {
+	props, err := tc.Properties(ctx, tokenAddr, nil, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if props, ok := props.(*token.WRC20PropertiesResult); ok {
+		return props, nil
+	}
+	return nil, operation.ErrStandardNotValid
+}
+
+// WRC721Properties returns the properties of the WRC-721 token
+// and of its NFT with the given id if the id is given.
+func (tc *Client) WRC721Properties(ctx context.Context, tokenAddr common.Address, tokenId *big.Int, blockNumber *big.Int) (*token.WRC721PropertiesResult, error) {
+	props, err := tc.Properties(ctx, tokenAddr, tokenId, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if props, ok := props.(*token.WRC721PropertiesResult); ok {
+		return props, nil
+	}
+	return nil, operation.ErrStandardNotValid
+}
+
+// BalanceOf returns the balance of the owner for a WRC-20 token
+// or the number of NFTs assigned to the owner for a WRC-721 token.
+// The block number can be nil, in which case the balance is taken from the latest known block.
+func (tc *Client) BalanceOf(ctx context.Context, tokenAddr common.Address, owner common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var result hexutil.Big
+	err := tc.c.CallContext(ctx, &result, "wat_tokenBalanceOf", tokenAddr, owner, toBlockNumArg(blockNumber))
+	return (*big.Int)(&result), err
+}
+
+// Allowance returns the amount of the WRC-20 token the spender is allowed to withdraw from the owner.
+// The block number can be nil, in which case the allowance is taken from the latest known block.
+func (tc *Client) Allowance(ctx context.Context, tokenAddr common.Address, owner common.Address, spender common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var result hexutil.Big
+	err := tc.c.CallContext(ctx, &result, "wat_wrc20Allowance", tokenAddr, owner, spender, toBlockNumArg(blockNumber))
+	return (*big.Int)(&result), err
+}
+
+// IsApprovedForAll reports whether the operator is allowed to manage all NFTs of the owner.
+// The block number can be nil, in which case the approval is taken from the latest known block.
+func (tc *Client) IsApprovedForAll(ctx context.Context, tokenAddr common.Address, owner common.Address, operator common.Address, blockNumber *big.Int) (bool, error) {
+	var result bool
+	err := tc.c.CallContext(ctx, &result, "wat_wrc721IsApprovedForAll", tokenAddr, owner, operator, toBlockNumArg(blockNumber))
+	return result, err
+}
+
+// Cost returns the cost of the token, the token id is required for a WRC-721 token.
+// The block number can be nil, in which case the cost is taken from the latest known block.
+func (tc *Client) Cost(ctx context.Context, tokenAddr common.Address, tokenId *big.Int, blockNumber *big.Int) (*big.Int, error) {
+	if tokenId == nil {
+		tokenId = new(big.Int)
+	}
+	var result hexutil.Big
+	err := tc.c.CallContext(ctx, &result, "wat_tokenCost", tokenAddr, (*hexutil.Big)(tokenId), toBlockNumArg(blockNumber))
+	return (*big.Int)(&result), err
+}
+
+// Transaction data
+
+// CreateData returns the data of a transaction creating the token.
+// A WRC-721 token is created if BaseURI is given in the args.
+func (tc *Client) CreateData(ctx context.Context, args token.TokenArgs) ([]byte, error) {
+	return tc.data(ctx, "wat_tokenCreate", args)
+}
+
+// WRC20TransferData returns the data of a transaction transferring WRC-20 tokens to the address.
+func (tc *Client) WRC20TransferData(ctx context.Context, to common.Address, value *big.Int) ([]byte, error) {
+	return tc.data(ctx, "wat_wrc20Transfer", to, (*hexutil.Big)(value))
+}
+
+// WRC20TransferFromData returns the data of a transaction transferring WRC-20 tokens between the addresses.
+func (tc *Client) WRC20TransferFromData(ctx context.Context, from common.Address, to common.Address, value *big.Int) ([]byte, error) {
+	return tc.data(ctx, "wat_wrc20TransferFrom", from, to, (*hexutil.Big)(value))
+}
+
+// WRC20ApproveData returns the data of a transaction allowing the spender to withdraw WRC-20 tokens.
+func (tc *Client) WRC20ApproveData(ctx context.Context, spender common.Address, value *big.Int) ([]byte, error) {
+	return tc.data(ctx, "wat_wrc20Approve", spender, (*hexutil.Big)(value))
+}
+
+// WRC721MintData returns the data of a transaction minting the NFT to the address.
+func (tc *Client) WRC721MintData(ctx context.Context, to common.Address, tokenId *big.Int, metadata []byte) ([]byte, error) {
+	var meta *hexutil.Bytes
+	if metadata != nil {
+		meta = (*hexutil.Bytes)(&metadata)
+	}
+	return tc.data(ctx, "wat_wrc721Mint", to, (*hexutil.Big)(tokenId), meta)
+}
+
+// WRC721BurnData returns the data of a transaction burning the NFT.
+func (tc *Client) WRC721BurnData(ctx context.Context, tokenId *big.Int) ([]byte, error) {
+	return tc.data(ctx, "wat_wrc721Burn", (*hexutil.Big)(tokenId))
+}
+
+// WRC721ApproveData returns the data of a transaction approving the address to manage the NFT.
+func (tc *Client) WRC721ApproveData(ctx context.Context, approved common.Address, tokenId *big.Int) ([]byte, error) {
+	return tc.data(ctx, "wat_wrc721Approve", approved, (*hexutil.Big)(tokenId))
+}
+
+// WRC721SetApprovalForAllData returns the data of a transaction allowing or
+// forbidding the operator to manage all NFTs of the sender.
+func (tc *Client) WRC721SetApprovalForAllData(ctx context.Context, operator common.Address, approved bool) ([]byte, error) {
+	return tc.data(ctx, "wat_wrc721SetApprovalForAll", operator, approved)
+}
+
+// WRC721TransferFromData returns the data of a transaction transferring the NFT between the addresses.
+func (tc *Client) WRC721TransferFromData(ctx context.Context, from common.Address, to common.Address, tokenId *big.Int) ([]byte, error) {
+	return tc.data(ctx, "wat_wrc721TransferFrom", from, to, (*hexutil.Big)(tokenId))
+}
+
+// SetPriceData returns the data of a transaction setting the price of the token.
+func (tc *Client) SetPriceData(ctx context.Context, value *big.Int, tokenId *big.Int) ([]byte, error) {
+	if tokenId == nil {
+		tokenId = new(big.Int)
+	}
+	return tc.data(ctx, "wat_setPrice", (*hexutil.Big)(value), (*hexutil.Big)(tokenId))
+}
+
+// BuyData returns the data of a transaction buying the token.
+func (tc *Client) BuyData(ctx context.Context, tokenId *big.Int, newValue *big.Int) ([]byte, error) {
+	if tokenId == nil {
+		tokenId = new(big.Int)
+	}
+	if newValue == nil {
+		newValue = new(big.Int)
+	}
+	return tc.data(ctx, "wat_buy", (*hexutil.Big)(tokenId), (*hexutil.Big)(newValue))
+}
+
+func (tc *Client) data(ctx context.Context, method string, args ...interface{}) ([]byte, error) {
+	var result hexutil.Bytes
+	err := tc.c.CallContext(ctx, &result, method, args...)
+	return result, err
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	pending := big.NewInt(-1)
+	if number.Cmp(pending) == 0 {
+		return "pending"
+	}
+	return hexutil.EncodeBig(number)
+}
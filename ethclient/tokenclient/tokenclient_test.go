@@ -0,0 +1,140 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/ethconfig"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/node"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token/operation"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+func newTestBackend(t *testing.T) *node.Node {
+	depositData := make(core.DepositData, 0)
+	for i := 0; i < 64; i++ {
+		depositData = append(depositData, &core.ValidatorData{
+			Pubkey:            common.BytesToBlsPubKey(testutils.RandomData(96)).String(),
+			CreatorAddress:    common.BytesToAddress(testutils.RandomData(20)).String(),
+			WithdrawalAddress: common.BytesToAddress(testutils.RandomData(20)).String(),
+			Amount:            3200,
+		})
+	}
+	genesis := &core.Genesis{
+		Config:     params.AllEthashProtocolChanges,
+		Alloc:      core.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000000000000000)}},
+		ExtraData:  []byte("test genesis"),
+		Timestamp:  9000,
+		Validators: depositData,
+	}
+	n, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("can't create new node: %v", err)
+	}
+	if _, err := eth.New(n, &ethconfig.Config{Genesis: genesis}); err != nil {
+		t.Fatalf("can't create new ethereum service: %v", err)
+	}
+	if err := n.Start(); err != nil {
+		t.Fatalf("can't start test node: %v", err)
+	}
+	return n
+}
+
+func TestTokenClient(t *testing.T) {
+	backend := newTestBackend(t)
+	client, err := backend.Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+	defer client.Close()
+
+	tc := New(client)
+	ctx := context.Background()
+
+	t.Run("CreateData", func(t *testing.T) {
+		var (
+			args        token.TokenArgs
+			name        = hexutil.Bytes("Test Token")
+			symbol      = hexutil.Bytes("TT")
+			decimals    = hexutil.Uint8(5)
+			totalSupply = (*hexutil.Big)(big.NewInt(1000))
+		)
+		args.Name = &name
+		args.Symbol = &symbol
+		args.Decimals = &decimals
+		args.TotalSupply = totalSupply
+
+		data, err := tc.CreateData(ctx, args)
+		testutils.AssertNoError(t, err)
+		op, err := operation.DecodeBytes(data)
+		testutils.AssertNoError(t, err)
+		createOp, ok := op.(operation.Create)
+		if !ok {
+			t.Fatalf("unexpected operation type: %T", op)
+		}
+		testutils.AssertEqual(t, operation.Std(operation.StdWRC20), createOp.Standard())
+		testutils.AssertEqual(t, []byte(name), createOp.Name())
+		testutils.AssertEqual(t, uint8(decimals), createOp.Decimals())
+	})
+	t.Run("WRC20TransferData", func(t *testing.T) {
+		data, err := tc.WRC20TransferData(ctx, testAddr, big.NewInt(10))
+		testutils.AssertNoError(t, err)
+		op, err := operation.DecodeBytes(data)
+		testutils.AssertNoError(t, err)
+		transferOp, ok := op.(operation.Transfer)
+		if !ok {
+			t.Fatalf("unexpected operation type: %T", op)
+		}
+		testutils.AssertEqual(t, testAddr, transferOp.To())
+		testutils.AssertEqual(t, big.NewInt(10), transferOp.Value())
+	})
+	t.Run("WRC721MintData", func(t *testing.T) {
+		data, err := tc.WRC721MintData(ctx, testAddr, big.NewInt(7), []byte("meta"))
+		testutils.AssertNoError(t, err)
+		op, err := operation.DecodeBytes(data)
+		testutils.AssertNoError(t, err)
+		mintOp, ok := op.(operation.Mint)
+		if !ok {
+			t.Fatalf("unexpected operation type: %T", op)
+		}
+		testutils.AssertEqual(t, big.NewInt(7), mintOp.TokenId())
+		metadata, _ := mintOp.Metadata()
+		testutils.AssertEqual(t, []byte("meta"), metadata)
+	})
+	t.Run("NotToken", func(t *testing.T) {
+		if _, err := tc.Properties(ctx, testAddr, nil, nil); err == nil {
+			t.Fatal("expected error for properties of non-token address")
+		}
+		if _, err := tc.BalanceOf(ctx, testAddr, testAddr, nil); err == nil {
+			t.Fatal("expected error for balance of non-token address")
+		}
+	})
+}
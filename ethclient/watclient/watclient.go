@@ -0,0 +1,576 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package watclient provides an RPC client for the wat and dag APIs.
+package watclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	ethereum "gitlab.waterfall.network/waterfall/protocol/gwat"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
+	valStore "gitlab.waterfall.network/waterfall/protocol/gwat/validator/storage"
+)
+
+// Client is a wrapper around rpc.Client that implements the wat and dag functionality.
+//
+// If you want to use the standardized Ethereum RPC functionality, use ethclient.Client instead.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	return DialContext(context.Background(), rawurl)
+}
+
+// DialContext connects a client to the given URL with the given context.
+func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return New(c), nil
+}
+
+// New creates a client that uses the given RPC client.
+func New(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// Close closes the underlying RPC connection.
+func (wc *Client) Close() {
+	wc.c.Close()
+}
+
+// Info
+
+// Era returns the era with the given number.
+// The number can be nil, in which case the current era is returned.
+func (wc *Client) Era(ctx context.Context, number *uint64) (*era.Era, error) {
+	var result *era.Era
+	if err := wc.c.CallContext(ctx, &result, "wat_getEra", number); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ethereum.NotFound
+	}
+	return result, nil
+}
+
+// DagHashes returns the hashes of the blocks of the current dag.
+func (wc *Client) DagHashes(ctx context.Context) (common.HashArray, error) {
+	var result common.HashArray
+	err := wc.c.CallContext(ctx, &result, "wat_getDagHashes")
+	return result, err
+}
+
+// SlotHashes returns the hashes of the blocks created in the given slot.
+func (wc *Client) SlotHashes(ctx context.Context, slot uint64) (common.HashArray, error) {
+	var result common.HashArray
+	err := wc.c.CallContext(ctx, &result, "wat_getSlotHashes", slot)
+	return result, err
+}
+
+// Transaction lifecycle statuses.
+const (
+	TxStatusUnknown    = "unknown"
+	TxStatusQueued     = "queued"
+	TxStatusPending    = "pending"
+	TxStatusProcessing = "processing"
+	TxStatusFinalized  = "finalized"
+	TxStatusDropped    = "dropped"
+)
+
+// TxStatusBlock is a dag block including the transaction.
+type TxStatusBlock struct {
+	Hash common.Hash `json:"hash"`
+	Slot uint64      `json:"slot"`
+}
+
+// TxStatusCheckpoint is the coordinated checkpoint covering the finalized transaction.
+type TxStatusCheckpoint struct {
+	Epoch    uint64      `json:"epoch"`
+	FinEpoch uint64      `json:"finEpoch"`
+	Spine    common.Hash `json:"spine"`
+}
+
+// TransactionStatus is the lifecycle status of a transaction.
+type TransactionStatus struct {
+	Hash            common.Hash         `json:"hash"`
+	Status          string              `json:"status"`
+	Blocks          []*TxStatusBlock    `json:"blocks"`
+	BlockHash       *common.Hash        `json:"blockHash,omitempty"`
+	FinalizedNumber *uint64             `json:"finalizedNumber,omitempty"`
+	Checkpoint      *TxStatusCheckpoint `json:"checkpoint,omitempty"`
+}
+
+type rpcTransactionStatus struct {
+	Hash   common.Hash `json:"hash"`
+	Status string      `json:"status"`
+	Blocks []*struct {
+		Hash common.Hash    `json:"hash"`
+		Slot hexutil.Uint64 `json:"slot"`
+	} `json:"blocks"`
+	BlockHash       *common.Hash    `json:"blockHash,omitempty"`
+	FinalizedNumber *hexutil.Uint64 `json:"finalizedNumber,omitempty"`
+	Checkpoint      *struct {
+		Epoch    hexutil.Uint64 `json:"epoch"`
+		FinEpoch hexutil.Uint64 `json:"finEpoch"`
+		Spine    common.Hash    `json:"spine"`
+	} `json:"checkpoint,omitempty"`
+}
+
+func (s *rpcTransactionStatus) toStatus() *TransactionStatus {
+	status := &TransactionStatus{
+		Hash:      s.Hash,
+		Status:    s.Status,
+		Blocks:    make([]*TxStatusBlock, 0, len(s.Blocks)),
+		BlockHash: s.BlockHash,
+	}
+	for _, b := range s.Blocks {
+		status.Blocks = append(status.Blocks, &TxStatusBlock{Hash: b.Hash, Slot: uint64(b.Slot)})
+	}
+	if s.FinalizedNumber != nil {
+		nr := uint64(*s.FinalizedNumber)
+		status.FinalizedNumber = &nr
+	}
+	if s.Checkpoint != nil {
+		status.Checkpoint = &TxStatusCheckpoint{
+			Epoch:    uint64(s.Checkpoint.Epoch),
+			FinEpoch: uint64(s.Checkpoint.FinEpoch),
+			Spine:    s.Checkpoint.Spine,
+		}
+	}
+	return status
+}
+
+// TransactionStatus returns the lifecycle status of the transaction with the given hash.
+func (wc *Client) TransactionStatus(ctx context.Context, hash common.Hash) (*TransactionStatus, error) {
+	var result *rpcTransactionStatus
+	if err := wc.c.CallContext(ctx, &result, "wat_getTransactionStatus", hash); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ethereum.NotFound
+	}
+	return result.toStatus(), nil
+}
+
+// SubscribeTransactionStatus subscribes to notifications about the changes of the
+// lifecycle status of the transaction with the given hash. The notifications stop
+// once the transaction is finalized and covered by a coordinated checkpoint.
+func (wc *Client) SubscribeTransactionStatus(ctx context.Context, hash common.Hash, ch chan<- *TransactionStatus) (ethereum.Subscription, error) {
+	rpcCh := make(chan *rpcTransactionStatus)
+	sub, err := wc.c.Subscribe(ctx, "wat", rpcCh, "transactionStatus", hash)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			select {
+			case status := <-rpcCh:
+				select {
+				case ch <- status.toStatus():
+				case <-sub.Err():
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// SenderAssignment is the creator assigned to include the transactions of the
+// sender in the slot.
+type SenderAssignment struct {
+	Slot      uint64
+	Creator   common.Address
+	IsCreator bool
+}
+
+// SenderAssignment returns the creators assigned to include the transactions of
+// the address in the given number of upcoming slots.
+func (wc *Client) SenderAssignment(ctx context.Context, address common.Address, slots uint64) ([]*SenderAssignment, error) {
+	var result []*struct {
+		Slot      hexutil.Uint64 `json:"slot"`
+		Creator   common.Address `json:"creator"`
+		IsCreator bool           `json:"isCreator"`
+	}
+	if err := wc.c.CallContext(ctx, &result, "wat_getSenderAssignment", address, hexutil.Uint64(slots)); err != nil {
+		return nil, err
+	}
+	assignments := make([]*SenderAssignment, 0, len(result))
+	for _, a := range result {
+		assignments = append(assignments, &SenderAssignment{Slot: uint64(a.Slot), Creator: a.Creator, IsCreator: a.IsCreator})
+	}
+	return assignments, nil
+}
+
+// AccountState is the state of the account changed by the simulated transaction,
+// only the changed fields are set.
+type AccountState struct {
+	Balance *big.Int
+	Nonce   *uint64
+	Code    []byte
+	Storage map[common.Hash]common.Hash
+}
+
+// SimulatedTx is the result of the simulated transaction of the bundle.
+type SimulatedTx struct {
+	GasUsed    uint64
+	ReturnData []byte
+	Error      string
+	Logs       []*types.Log
+	Pre        map[common.Address]*AccountState // state of the changed accounts before the transaction
+	Post       map[common.Address]*AccountState // state of the changed accounts after the transaction
+}
+
+// SimulatedBundle is the result of the simulated bundle of transactions.
+type SimulatedBundle struct {
+	Slot         uint64
+	Height       uint64
+	ParentHashes common.HashArray
+	GasUsed      uint64
+	Transactions []*SimulatedTx
+}
+
+type rpcAccountState struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type rpcSimulatedBundle struct {
+	Slot         hexutil.Uint64   `json:"slot"`
+	Height       hexutil.Uint64   `json:"height"`
+	ParentHashes common.HashArray `json:"parentHashes"`
+	GasUsed      hexutil.Uint64   `json:"gasUsed"`
+	Transactions []*struct {
+		GasUsed    hexutil.Uint64 `json:"gasUsed"`
+		ReturnData hexutil.Bytes  `json:"returnData"`
+		Error      string         `json:"error,omitempty"`
+		Logs       []*types.Log   `json:"logs"`
+		StateDiff  struct {
+			Pre  map[common.Address]*rpcAccountState `json:"pre"`
+			Post map[common.Address]*rpcAccountState `json:"post"`
+		} `json:"stateDiff"`
+	} `json:"transactions"`
+}
+
+func toAccountStates(states map[common.Address]*rpcAccountState) map[common.Address]*AccountState {
+	accounts := make(map[common.Address]*AccountState, len(states))
+	for addr, s := range states {
+		acc := &AccountState{Code: s.Code, Storage: s.Storage}
+		if s.Balance != nil {
+			acc.Balance = s.Balance.ToInt()
+		}
+		if s.Nonce != nil {
+			nonce := uint64(*s.Nonce)
+			acc.Nonce = &nonce
+		}
+		accounts[addr] = acc
+	}
+	return accounts
+}
+
+func (b *rpcSimulatedBundle) toBundle() *SimulatedBundle {
+	bundle := &SimulatedBundle{
+		Slot:         uint64(b.Slot),
+		Height:       uint64(b.Height),
+		ParentHashes: b.ParentHashes,
+		GasUsed:      uint64(b.GasUsed),
+		Transactions: make([]*SimulatedTx, 0, len(b.Transactions)),
+	}
+	for _, tx := range b.Transactions {
+		bundle.Transactions = append(bundle.Transactions, &SimulatedTx{
+			GasUsed:    uint64(tx.GasUsed),
+			ReturnData: tx.ReturnData,
+			Error:      tx.Error,
+			Logs:       tx.Logs,
+			Pre:        toAccountStates(tx.StateDiff.Pre),
+			Post:       toAccountStates(tx.StateDiff.Post),
+		})
+	}
+	return bundle
+}
+
+// SimulateBundle applies the ordered list of messages to the state derived from
+// the current tips without committing it and returns the result of each message.
+func (wc *Client) SimulateBundle(ctx context.Context, msgs []ethereum.CallMsg) (*SimulatedBundle, error) {
+	args := make([]interface{}, 0, len(msgs))
+	for _, msg := range msgs {
+		args = append(args, toCallArg(msg))
+	}
+	var result rpcSimulatedBundle
+	if err := wc.c.CallContext(ctx, &result, "wat_simulateBundle", args); err != nil {
+		return nil, err
+	}
+	return result.toBundle(), nil
+}
+
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	return arg
+}
+
+// SlotFees is the fee data of the slot aggregated over all its blocks.
+type SlotFees struct {
+	Slot         uint64
+	Blocks       int
+	Creators     uint64
+	BaseFee      *big.Int
+	CpBaseFee    *big.Int
+	GasUsed      uint64
+	GasLimit     uint64
+	GasUsedRatio float64
+	Congestion   float64
+	Reward       []*big.Int
+}
+
+// FeeHistoryBySlot is the fee history of the range of slots.
+type FeeHistoryBySlot struct {
+	OldestSlot  uint64
+	Slots       []*SlotFees
+	NextBaseFee *big.Int // base fee of the slot following the range
+}
+
+type rpcFeeHistoryBySlot struct {
+	OldestSlot hexutil.Uint64 `json:"oldestSlot"`
+	Slots      []*struct {
+		Slot         hexutil.Uint64 `json:"slot"`
+		Blocks       hexutil.Uint   `json:"blocks"`
+		Creators     hexutil.Uint64 `json:"creators"`
+		BaseFee      *hexutil.Big   `json:"baseFeePerGas"`
+		CpBaseFee    *hexutil.Big   `json:"cpBaseFeePerGas,omitempty"`
+		GasUsed      hexutil.Uint64 `json:"gasUsed"`
+		GasLimit     hexutil.Uint64 `json:"gasLimit"`
+		GasUsedRatio float64        `json:"gasUsedRatio"`
+		Congestion   float64        `json:"congestion"`
+		Reward       []*hexutil.Big `json:"reward,omitempty"`
+	} `json:"slots"`
+	NextBaseFee *hexutil.Big `json:"nextBaseFeePerGas,omitempty"`
+}
+
+// FeeHistoryBySlot retrieves the fee history of the range of slots ending with the
+// last slot, all blocks of each slot are aggregated. The last slot can be nil, in
+// which case the slot of the last finalized block is used.
+func (wc *Client) FeeHistoryBySlot(ctx context.Context, slotCount uint64, lastSlot *uint64, rewardPercentiles []float64) (*FeeHistoryBySlot, error) {
+	var last *hexutil.Uint64
+	if lastSlot != nil {
+		last = (*hexutil.Uint64)(lastSlot)
+	}
+	var res rpcFeeHistoryBySlot
+	if err := wc.c.CallContext(ctx, &res, "wat_feeHistoryBySlot", hexutil.Uint64(slotCount), last, rewardPercentiles); err != nil {
+		return nil, err
+	}
+	history := &FeeHistoryBySlot{
+		OldestSlot:  uint64(res.OldestSlot),
+		Slots:       make([]*SlotFees, 0, len(res.Slots)),
+		NextBaseFee: (*big.Int)(res.NextBaseFee),
+	}
+	for _, s := range res.Slots {
+		fees := &SlotFees{
+			Slot:         uint64(s.Slot),
+			Blocks:       int(s.Blocks),
+			Creators:     uint64(s.Creators),
+			BaseFee:      (*big.Int)(s.BaseFee),
+			CpBaseFee:    (*big.Int)(s.CpBaseFee),
+			GasUsed:      uint64(s.GasUsed),
+			GasLimit:     uint64(s.GasLimit),
+			GasUsedRatio: s.GasUsedRatio,
+			Congestion:   s.Congestion,
+		}
+		for _, r := range s.Reward {
+			fees.Reward = append(fees.Reward, (*big.Int)(r))
+		}
+		history.Slots = append(history.Slots, fees)
+	}
+	return history, nil
+}
+
+// Validators
+
+// Validators returns the addresses of the validators of the era with the given number.
+// The number can be nil, in which case the validators of the current era are returned.
+func (wc *Client) Validators(ctx context.Context, era *uint64) ([]common.Address, error) {
+	var result []common.Address
+	err := wc.c.CallContext(ctx, &result, "wat_getValidators", era)
+	return result, err
+}
+
+// ValidatorsBySlot returns the addresses of the block creators of the given slot.
+func (wc *Client) ValidatorsBySlot(ctx context.Context, slot uint64) ([]common.Address, error) {
+	var result []common.Address
+	err := wc.c.CallContext(ctx, &result, "wat_getValidatorsBySlot", slot)
+	return result, err
+}
+
+// ValidatorInfo returns the info of the validator with the given address.
+// The block number can be nil, in which case the info is taken from the last coordinated checkpoint.
+func (wc *Client) ValidatorInfo(ctx context.Context, address common.Address, blockNumber *big.Int) (*valStore.Validator, error) {
+	var result *valStore.Validator
+	if err := wc.c.CallContext(ctx, &result, "wat_validator_GetInfo", address, toOptBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ethereum.NotFound
+	}
+	return result, nil
+}
+
+// DepositData returns the data of a deposit transaction of the validator.
+func (wc *Client) DepositData(ctx context.Context, args validator.DepositArgs) ([]byte, error) {
+	var result hexutil.Bytes
+	err := wc.c.CallContext(ctx, &result, "wat_validator_DepositData", args)
+	return result, err
+}
+
+// BatchDepositData returns the data of a batch deposit transaction of several validators.
+// The value of the transaction must be equal to the sum of amounts of all deposits.
+func (wc *Client) BatchDepositData(ctx context.Context, args []validator.BatchDepositArgs) ([]byte, error) {
+	var result hexutil.Bytes
+	err := wc.c.CallContext(ctx, &result, "wat_validator_BatchDepositData", args)
+	return result, err
+}
+
+// ExitData returns the data of an exit transaction of the validator.
+func (wc *Client) ExitData(ctx context.Context, args validator.ExitRequestArgs) ([]byte, error) {
+	var result hexutil.Bytes
+	err := wc.c.CallContext(ctx, &result, "wat_validator_ExitData", args)
+	return result, err
+}
+
+// WithdrawalData returns the data of a withdrawal transaction of the validator.
+func (wc *Client) WithdrawalData(ctx context.Context, args validator.WithdrawalArgs) ([]byte, error) {
+	var result hexutil.Bytes
+	err := wc.c.CallContext(ctx, &result, "wat_validator_WithdrawalData", args)
+	return result, err
+}
+
+// DelegatorWithdrawalData returns the data of a withdrawal transaction of the delegator's stake.
+func (wc *Client) DelegatorWithdrawalData(ctx context.Context, args validator.DelegatorWithdrawalArgs) ([]byte, error) {
+	var result hexutil.Bytes
+	err := wc.c.CallContext(ctx, &result, "wat_validator_DelegatorWithdrawalData", args)
+	return result, err
+}
+
+// DepositCount returns the number of deposits.
+// The block number can be nil, in which case the count is taken from the latest known block.
+func (wc *Client) DepositCount(ctx context.Context, blockNumber *big.Int) (uint64, error) {
+	var result hexutil.Uint64
+	err := wc.c.CallContext(ctx, &result, "wat_validator_DepositCount", toBlockNumArg(blockNumber))
+	return uint64(result), err
+}
+
+// DepositAddress returns the address to send the validator transactions to.
+func (wc *Client) DepositAddress(ctx context.Context) (common.Address, error) {
+	var result hexutil.Bytes
+	if err := wc.c.CallContext(ctx, &result, "wat_validator_DepositAddress"); err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(result), nil
+}
+
+// Dag
+
+// CoordinatedState returns the state of the last coordinated finalization.
+func (wc *Client) CoordinatedState(ctx context.Context) (*types.FinalizationResult, error) {
+	var result *types.FinalizationResult
+	if err := wc.c.CallContext(ctx, &result, "dag_coordinatedState"); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ethereum.NotFound
+	}
+	if result.Error != nil {
+		return nil, errors.New(*result.Error)
+	}
+	return result, nil
+}
+
+// Candidates returns the spine candidates for finalization up to the given slot.
+func (wc *Client) Candidates(ctx context.Context, slot uint64) (common.HashArray, error) {
+	var result *types.CandidatesResult
+	if err := wc.c.CallContext(ctx, &result, "dag_getCandidates", slot); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ethereum.NotFound
+	}
+	if result.Error != nil {
+		return nil, errors.New(*result.Error)
+	}
+	return result.Candidates, nil
+}
+
+// OptimisticSpines returns the optimistic spines following the given last finalized spine.
+func (wc *Client) OptimisticSpines(ctx context.Context, lastFinSpine common.Hash) ([]common.HashArray, error) {
+	var result *types.OptimisticSpinesResult
+	if err := wc.c.CallContext(ctx, &result, "dag_getOptimisticSpines", lastFinSpine); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ethereum.NotFound
+	}
+	if result.Error != nil {
+		return nil, errors.New(*result.Error)
+	}
+	return result.Data, nil
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	pending := big.NewInt(-1)
+	if number.Cmp(pending) == 0 {
+		return "pending"
+	}
+	return hexutil.EncodeBig(number)
+}
+
+// toOptBlockNumArg is like toBlockNumArg but leaves the choice of the
+// default block to the server if no block number is given.
+func toOptBlockNumArg(number *big.Int) interface{} {
+	if number == nil {
+		return nil
+	}
+	return toBlockNumArg(number)
+}
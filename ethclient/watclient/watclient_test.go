@@ -0,0 +1,243 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethereum "gitlab.waterfall.network/waterfall/protocol/gwat"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/ethconfig"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/ethclient"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/node"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator"
+)
+
+var (
+	testKey, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr      = crypto.PubkeyToAddress(testKey.PublicKey)
+	testValidator = common.BytesToAddress(testutils.RandomData(20))
+)
+
+func newTestBackend(t *testing.T) (*node.Node, []*types.Block, *eth.Ethereum) {
+	// Generate test chain.
+	genesis, blocks := generateTestChain()
+	// Create node
+	n, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("can't create new node: %v", err)
+	}
+	// Create Ethereum Service
+	config := &ethconfig.Config{Genesis: genesis, GPO: ethconfig.Defaults.GPO}
+	ethservice, err := eth.New(n, config)
+	if err != nil {
+		t.Fatalf("can't create new ethereum service: %v", err)
+	}
+	// Import the test chain.
+	if err := n.Start(); err != nil {
+		t.Fatalf("can't start test node: %v", err)
+	}
+	ethservice.BlockChain().SetIsSynced(true)
+	if _, err := ethservice.BlockChain().SyncInsertChain(blocks[1:]); err != nil {
+		t.Fatalf("can't import test blocks: %v", err)
+	}
+	return n, blocks, ethservice
+}
+
+func generateTestChain() (*core.Genesis, []*types.Block) {
+	depositData := make(core.DepositData, 0)
+	for i := 0; i < 64; i++ {
+		creator := common.BytesToAddress(testutils.RandomData(20))
+		if i == 0 {
+			creator = testValidator
+		}
+		valData := &core.ValidatorData{
+			Pubkey:            common.BytesToBlsPubKey(testutils.RandomData(96)).String(),
+			CreatorAddress:    creator.String(),
+			WithdrawalAddress: common.BytesToAddress(testutils.RandomData(20)).String(),
+			Amount:            3200,
+		}
+
+		depositData = append(depositData, valData)
+	}
+	db := rawdb.NewMemoryDatabase()
+	config := params.AllEthashProtocolChanges
+	genesis := &core.Genesis{
+		Config:     config,
+		Alloc:      core.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000000000000000)}},
+		ExtraData:  []byte("test genesis"),
+		Timestamp:  9000,
+		Validators: depositData,
+	}
+	generate := func(i int, g *core.BlockGen) {
+		g.OffsetTime(5)
+		g.SetExtra([]byte("test"))
+	}
+	gblock := genesis.MustCommit(db)
+	blocks, _ := core.GenerateChain(config, gblock, db, 1, generate)
+	for _, bl := range blocks {
+		nr := bl.Height()
+		bl.SetNumber(&nr)
+	}
+	blocks = append([]*types.Block{gblock}, blocks...)
+	return genesis, blocks
+}
+
+func TestWatClient(t *testing.T) {
+	backend, blocks, ethservice := newTestBackend(t)
+	client, err := backend.Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+	defer client.Close()
+
+	wc := New(client)
+	ctx := context.Background()
+
+	t.Run("Era", func(t *testing.T) {
+		current, err := wc.Era(ctx, nil)
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, ethservice.BlockChain().GetEraInfo().GetEra().Number, current.Number)
+
+		number := uint64(0)
+		genesisEra, err := wc.Era(ctx, &number)
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, number, genesisEra.Number)
+
+		number = 1000
+		_, err = wc.Era(ctx, &number)
+		if err == nil {
+			t.Fatal("expected error for unknown era")
+		}
+	})
+	t.Run("SlotHashes", func(t *testing.T) {
+		hashes, err := wc.SlotHashes(ctx, blocks[0].Slot())
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, common.HashArray{blocks[0].Hash()}, hashes)
+	})
+	t.Run("DagHashes", func(t *testing.T) {
+		_, err := wc.DagHashes(ctx)
+		testutils.AssertNoError(t, err)
+	})
+	t.Run("TransactionStatus", func(t *testing.T) {
+		status, err := wc.TransactionStatus(ctx, common.Hash{0x01})
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, TxStatusUnknown, status.Status)
+		testutils.AssertEqual(t, 0, len(status.Blocks))
+	})
+	t.Run("SubscribeTransactionStatus", func(t *testing.T) {
+		ch := make(chan *TransactionStatus, 1)
+		sub, err := wc.SubscribeTransactionStatus(ctx, common.Hash{0x01}, ch)
+		testutils.AssertNoError(t, err)
+		defer sub.Unsubscribe()
+		status := <-ch
+		testutils.AssertEqual(t, common.Hash{0x01}, status.Hash)
+		testutils.AssertEqual(t, TxStatusUnknown, status.Status)
+	})
+	t.Run("SenderAssignment", func(t *testing.T) {
+		sender := common.BytesToAddress(testutils.RandomData(20))
+		assignments, err := wc.SenderAssignment(ctx, sender, 2)
+		testutils.AssertNoError(t, err)
+		if len(assignments) > 2 {
+			t.Fatalf("expected at most 2 assignments, got %d", len(assignments))
+		}
+		for _, a := range assignments {
+			creators, err := wc.ValidatorsBySlot(ctx, a.Slot)
+			testutils.AssertNoError(t, err)
+			creator, _ := core.AssignedCreator(sender, creators)
+			testutils.AssertEqual(t, creator, a.Creator)
+			testutils.AssertEqual(t, false, a.IsCreator)
+		}
+
+		_, err = wc.SenderAssignment(ctx, sender, 0)
+		if err == nil {
+			t.Fatal("expected error for zero slots")
+		}
+	})
+	t.Run("SimulateBundle", func(t *testing.T) {
+		recipient := common.BytesToAddress(testutils.RandomData(20))
+		other := common.BytesToAddress(testutils.RandomData(20))
+		// The second transfer spends the funds received by the first one
+		bundle, err := wc.SimulateBundle(ctx, []ethereum.CallMsg{
+			{From: testAddr, To: &recipient, Value: big.NewInt(1000)},
+			{From: recipient, To: &other, Value: big.NewInt(400)},
+		})
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, 2, len(bundle.Transactions))
+		for _, tx := range bundle.Transactions {
+			testutils.AssertEqual(t, "", tx.Error)
+		}
+		second := bundle.Transactions[1]
+		testutils.AssertEqual(t, big.NewInt(1000), second.Pre[recipient].Balance)
+		testutils.AssertEqual(t, big.NewInt(600), second.Post[recipient].Balance)
+		testutils.AssertEqual(t, big.NewInt(400), second.Post[other].Balance)
+
+		// Nothing is committed to the state
+		balance, err := ethclient.NewClient(client).BalanceAt(ctx, recipient, nil)
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, 0, balance.Sign())
+
+		_, err = wc.SimulateBundle(ctx, nil)
+		if err == nil {
+			t.Fatal("expected error for empty bundle")
+		}
+	})
+	t.Run("FeeHistoryBySlot", func(t *testing.T) {
+		lastSlot := blocks[1].Slot()
+		history, err := wc.FeeHistoryBySlot(ctx, 2, &lastSlot, []float64{50})
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, lastSlot-1, history.OldestSlot)
+		testutils.AssertEqual(t, 2, len(history.Slots))
+		last := history.Slots[1]
+		testutils.AssertEqual(t, lastSlot, last.Slot)
+		testutils.AssertEqual(t, len(ethservice.BlockChain().GetBlockHashesBySlot(lastSlot)), last.Blocks)
+		testutils.AssertEqual(t, 1, len(last.Reward))
+		if last.BaseFee == nil || history.NextBaseFee == nil {
+			t.Fatal("expected base fees of the slots")
+		}
+
+		beyond := lastSlot + 1000
+		_, err = wc.FeeHistoryBySlot(ctx, 2, &beyond, nil)
+		if err == nil {
+			t.Fatal("expected error for slot beyond head")
+		}
+	})
+	t.Run("ValidatorInfo", func(t *testing.T) {
+		info, err := wc.ValidatorInfo(ctx, testValidator, big.NewInt(0))
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, testValidator, info.Address)
+	})
+	t.Run("DepositAddress", func(t *testing.T) {
+		address, err := wc.DepositAddress(ctx)
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, ethservice.BlockChain().Config().ValidatorsStateAddress, &address)
+	})
+	t.Run("WithdrawalData", func(t *testing.T) {
+		data, err := wc.WithdrawalData(ctx, validator.WithdrawalArgs{CreatorAddress: &testValidator})
+		if err == nil {
+			t.Fatalf("expected error for missing amount, got data %x", data)
+		}
+	})
+}
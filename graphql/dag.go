@@ -0,0 +1,272 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/internal/ethapi"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
+)
+
+var (
+	errEraNotFound        = errors.New("era not found")
+	errCheckpointNotFound = errors.New("checkpoint not found")
+)
+
+// newBlockByHash creates the lazily fetched block of the given hash.
+func newBlockByHash(backend ethapi.Backend, hash common.Hash) *Block {
+	numberOrHash := rpc.BlockNumberOrHashWithHash(hash, false)
+	return &Block{
+		backend:      backend,
+		numberOrHash: &numberOrHash,
+		hash:         hash,
+	}
+}
+
+// newBlocksByHashes creates the lazily fetched blocks of the given hashes.
+func newBlocksByHashes(backend ethapi.Backend, hashes common.HashArray) []*Block {
+	ret := make([]*Block, 0, len(hashes))
+	for _, hash := range hashes {
+		ret = append(ret, newBlockByHash(backend, hash))
+	}
+	return ret
+}
+
+func (b *Block) Slot(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.Slot), nil
+}
+
+func (b *Block) Era(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.Era), nil
+}
+
+func (b *Block) Height(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.Height), nil
+}
+
+func (b *Block) Finalized(ctx context.Context) (bool, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return false, err
+	}
+	return b.backend.GetBlockFinalizedNumber(hash) != nil, nil
+}
+
+func (b *Block) ParentHashes(ctx context.Context) ([]common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return header.ParentHashes, nil
+}
+
+func (b *Block) Parents(ctx context.Context) ([]*Block, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newBlocksByHashes(b.backend, header.ParentHashes), nil
+}
+
+func (b *Block) Children(ctx context.Context) ([]*Block, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newBlocksByHashes(b.backend, b.backend.BlockChain().ReadChildren(hash)), nil
+}
+
+func (b *Block) CpHash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.CpHash, nil
+}
+
+func (b *Block) CpNumber(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.CpNumber), nil
+}
+
+func (b *Block) CpRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.CpRoot, nil
+}
+
+func (b *Block) Checkpoint(ctx context.Context) (*Block, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.CpHash == (common.Hash{}) {
+		return nil, nil
+	}
+	return newBlockByHash(b.backend, header.CpHash), nil
+}
+
+// Slot represents the blocks created in a slot.
+type Slot struct {
+	backend ethapi.Backend
+	number  uint64
+	headers []*types.Header
+}
+
+func (s *Slot) Number() Long {
+	return Long(s.number)
+}
+
+func (s *Slot) Blocks() []*Block {
+	ret := make([]*Block, 0, len(s.headers))
+	for _, header := range s.headers {
+		block := newBlockByHash(s.backend, header.Hash())
+		block.header = header
+		ret = append(ret, block)
+	}
+	return ret
+}
+
+// Spine returns the block of the slot chosen as spine
+// or nil if the slot has no blocks.
+func (s *Slot) Spine() (*Block, error) {
+	if len(s.headers) == 0 {
+		return nil, nil
+	}
+	spines, err := types.CalculateOptimisticSpines(s.headers)
+	if err != nil || len(spines) == 0 {
+		return nil, err
+	}
+	return newBlockByHash(s.backend, spines[0]), nil
+}
+
+// Era represents an era of the validators.
+type Era struct {
+	backend ethapi.Backend
+	era     *era.Era
+}
+
+func (e *Era) Number() Long {
+	return Long(e.era.Number)
+}
+
+func (e *Era) FromEpoch() Long {
+	return Long(e.era.From)
+}
+
+func (e *Era) ToEpoch() Long {
+	return Long(e.era.To)
+}
+
+func (e *Era) Root() common.Hash {
+	return e.era.Root
+}
+
+func (e *Era) Block() *Block {
+	if e.era.BlockHash == (common.Hash{}) {
+		return nil
+	}
+	return newBlockByHash(e.backend, e.era.BlockHash)
+}
+
+// Checkpoint represents a coordinated checkpoint.
+type Checkpoint struct {
+	backend ethapi.Backend
+	cp      *types.Checkpoint
+}
+
+func (c *Checkpoint) Epoch() Long {
+	return Long(c.cp.Epoch)
+}
+
+func (c *Checkpoint) FinEpoch() Long {
+	return Long(c.cp.FinEpoch)
+}
+
+func (c *Checkpoint) Root() common.Hash {
+	return c.cp.Root
+}
+
+func (c *Checkpoint) Spine() *Block {
+	return newBlockByHash(c.backend, c.cp.Spine)
+}
+
+func (r *Resolver) Slot(ctx context.Context, args struct{ Number Long }) (*Slot, error) {
+	if args.Number < 0 {
+		return nil, nil
+	}
+	slot := &Slot{backend: r.backend, number: uint64(args.Number)}
+	for _, hash := range r.backend.BlockHashesBySlot(ctx, slot.number) {
+		header, err := r.backend.HeaderByHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if header != nil {
+			slot.headers = append(slot.headers, header)
+		}
+	}
+	return slot, nil
+}
+
+func (r *Resolver) Tips(ctx context.Context) []*Block {
+	return newBlocksByHashes(r.backend, r.backend.BlockChain().GetTips().GetHashes())
+}
+
+func (r *Resolver) Era(ctx context.Context, args struct{ Number *Long }) (*Era, error) {
+	if args.Number == nil {
+		return &Era{r.backend, r.backend.BlockChain().GetEraInfo().GetEra()}, nil
+	}
+	dbEra := rawdb.ReadEra(r.backend.ChainDb(), uint64(*args.Number))
+	if dbEra == nil {
+		return nil, errEraNotFound
+	}
+	return &Era{r.backend, dbEra}, nil
+}
+
+func (r *Resolver) Checkpoint(ctx context.Context, args struct{ Spine *common.Hash }) (*Checkpoint, error) {
+	var cp *types.Checkpoint
+	if args.Spine == nil {
+		cp = r.backend.BlockChain().GetLastCoordinatedCheckpoint()
+	} else {
+		cp = r.backend.BlockChain().GetCoordinatedCheckpoint(*args.Spine)
+	}
+	if cp == nil {
+		return nil, errCheckpointNotFound
+	}
+	return &Checkpoint{r.backend, cp}, nil
+}
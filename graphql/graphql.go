@@ -614,7 +614,7 @@ func (b *Block) Parent(ctx context.Context) (*Block, error) {
 				break
 			}
 		}
-		if parent != nil {
+		if parent == nil {
 			return nil, nil
 		}
 		num := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(parent.Nr()))
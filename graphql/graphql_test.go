@@ -16,6 +16,63 @@
 
 package graphql
 
+import (
+	"context"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/internal/ethapi"
+)
+
+// parentTestBackend serves the headers by hash only.
+type parentTestBackend struct {
+	ethapi.Backend
+	headers map[common.Hash]*types.Header
+}
+
+func (b *parentTestBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.headers[hash], nil
+}
+
+// Tests that the parent of a block is the finalized one of its parents
+// and no parent is returned if none of them is finalized.
+func TestBlockParent(t *testing.T) {
+	var (
+		finNr     = uint64(5)
+		finalized = &types.Header{Slot: 1, Height: 5, Number: &finNr}
+		pending   = &types.Header{Slot: 2, Height: 6}
+		blockNr   = uint64(7)
+		backend   = &parentTestBackend{headers: map[common.Hash]*types.Header{
+			finalized.Hash(): finalized,
+			pending.Hash():   pending,
+		}}
+	)
+	block := &Block{
+		backend: backend,
+		header:  &types.Header{Slot: 3, Height: 7, Number: &blockNr, ParentHashes: common.HashArray{pending.Hash(), finalized.Hash()}},
+	}
+	parent, err := block.Parent(context.Background())
+	if err != nil {
+		t.Fatalf("failed to resolve parent: %v", err)
+	}
+	if parent == nil || parent.hash != finalized.Hash() || parent.numberOrHash.BlockNumber.Int64() != int64(finNr) {
+		t.Fatalf("unexpected parent: %v", parent)
+	}
+
+	block = &Block{
+		backend: backend,
+		header:  &types.Header{Slot: 3, Height: 7, Number: &blockNr, ParentHashes: common.HashArray{pending.Hash()}},
+	}
+	parent, err = block.Parent(context.Background())
+	if err != nil {
+		t.Fatalf("failed to resolve parent: %v", err)
+	}
+	if parent != nil {
+		t.Fatalf("unexpected parent: %v", parent.hash)
+	}
+}
+
 //func TestBuildSchema(t *testing.T) {
 //	ddir, err := os.MkdirTemp("", "graphql-buildschema")
 //	if err != nil {
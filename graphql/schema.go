@@ -168,8 +168,6 @@ const schema string = `
         hash: Bytes32!
         # Parent is the parent block of this block.
         parent: Block
-        # Nonce is the block nonce, an 8 byte sequence determined by the miner.
-        nonce: Bytes!
         # TransactionsRoot is the keccak256 hash of the root of the trie of transactions in this block.
         transactionsRoot: Bytes32!
         # TransactionCount is the number of transactions in this block. if
@@ -194,20 +192,9 @@ const schema string = `
         # LogsBloom is a bloom filter that can be used to check if a block may
         # contain log entries matching a filter.
         logsBloom: Bytes!
-        # TotalDifficulty is the sum of all difficulty values up to and including
-        # this block.
-        totalDifficulty: BigInt!
         # OmmerCount is the number of ommers (AKA uncles) associated with this
         # block. If ommers are unavailable, this field will be null.
         ommerCount: Int
-        # Ommers is a list of ommer (AKA uncle) blocks associated with this block.
-        # If ommers are unavailable, this field will be null. Depending on your
-        # node, the transactions, transactionAt, transactionCount, ommers,
-        # ommerCount and ommerAt fields may not be available on any ommer blocks.
-        ommers: [Block]
-        # OmmerAt returns the ommer (AKA uncle) at the specified index. If ommers
-        # are unavailable, or the index is out of bounds, this field will be null.
-        ommerAt(index: Int!): Block
         # OmmerHash is the keccak256 hash of all the ommers (AKA uncles)
         # associated with this block.
         ommerHash: Bytes32!
@@ -227,6 +214,28 @@ const schema string = `
         # EstimateGas estimates the amount of gas that will be required for
         # successful execution of a transaction at the current block's state.
         estimateGas(data: CallData!): Long!
+        # Slot is the slot in which the block was created.
+        slot: Long!
+        # Era is the era in which the block was created.
+        era: Long!
+        # Height is the height of the block in the dag.
+        height: Long!
+        # Finalized is true if the block has been finalized.
+        finalized: Boolean!
+        # ParentHashes are the hashes of the parent blocks.
+        parentHashes: [Bytes32!]!
+        # Parents are the parent blocks of this block in the dag.
+        parents: [Block!]!
+        # Children are the known child blocks of this block in the dag.
+        children: [Block!]!
+        # CpHash is the hash of the checkpoint block of this block.
+        cpHash: Bytes32!
+        # CpNumber is the number of the checkpoint block of this block.
+        cpNumber: Long!
+        # CpRoot is the state root of the checkpoint block of this block.
+        cpRoot: Bytes32!
+        # Checkpoint is the checkpoint block of this block.
+        checkpoint: Block
     }
 
     # CallData represents the data associated with a local contract call.
@@ -316,6 +325,96 @@ const schema string = `
       estimateGas(data: CallData!): Long!
     }
 
+    # Slot represents the blocks created in a particular slot.
+    type Slot {
+        # Number is the number of the slot.
+        number: Long!
+        # Blocks is the list of blocks created in the slot.
+        blocks: [Block!]!
+        # Spine is the spine block of the slot, or null if the slot is empty.
+        spine: Block
+    }
+
+    # Era represents an era of validators.
+    type Era {
+        # Number is the number of the era.
+        number: Long!
+        # FromEpoch is the first epoch of the era.
+        fromEpoch: Long!
+        # ToEpoch is the last epoch of the era.
+        toEpoch: Long!
+        # Root is the state root of the era.
+        root: Bytes32!
+        # Block is the block whose state root is the root of the era.
+        block: Block
+    }
+
+    # Checkpoint represents a coordinated checkpoint.
+    type Checkpoint {
+        # Epoch is the epoch of the checkpoint.
+        epoch: Long!
+        # FinEpoch is the epoch at which the checkpoint was finalized.
+        finEpoch: Long!
+        # Root is the state root of the checkpoint.
+        root: Bytes32!
+        # Spine is the spine block of the checkpoint.
+        spine: Block!
+    }
+
+    # Token represents a native token at a particular block.
+    type Token {
+        # Address is the address of the token.
+        address: Address!
+        # Standard is the token standard, e.g. 20 for WRC-20 or 721 for WRC-721.
+        standard: Int!
+        # Name is the name of the token.
+        name: Bytes!
+        # Symbol is the symbol of the token.
+        symbol: Bytes!
+        # Decimals is the decimals of a WRC-20 token.
+        decimals: Int
+        # TotalSupply is the total supply of a WRC-20 token.
+        totalSupply: BigInt
+        # PercentFee is the percent fee of a WRC-721 token.
+        percentFee: Int
+        # BaseURI is the base URI of a WRC-721 token.
+        baseURI: Bytes
+        # BalanceOf returns the token balance of the owner.
+        balanceOf(owner: Address!): BigInt!
+    }
+
+    # Stake is the stake of a validator deposited from an address.
+    type Stake {
+        # Address is the address the stake was deposited from.
+        address: Address!
+        # Sum is the amount of the stake, in wei.
+        sum: BigInt!
+    }
+
+    # Validator represents the validator info at a particular block.
+    type Validator {
+        # Address is the address of the validator.
+        address: Address!
+        # PubKey is the BLS public key of the validator.
+        pubKey: Bytes!
+        # WithdrawalAddress is the address to withdraw the stake to.
+        withdrawalAddress: Address
+        # Index is the index of the validator.
+        index: Long!
+        # ActivationEra is the era the validator is activated at.
+        activationEra: Long!
+        # ExitEra is the era the validator exits at.
+        exitEra: Long!
+        # Stake is the list of stakes of the validator.
+        stake: [Stake!]!
+        # DepositTxs are the hashes of the deposit transactions.
+        depositTxs: [Bytes32!]!
+        # WithdrawalTx is the hash of the last withdrawal transaction.
+        withdrawalTx: Bytes32
+        # ExitTx is the hash of the exit transaction.
+        exitTx: Bytes32
+    }
+
     type Query {
         # Block fetches an Ethereum block by number or by hash. If neither is
         # supplied, the most recent known block is returned.
@@ -339,6 +438,24 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # Slot returns the blocks of a slot.
+        slot(number: Long!): Slot
+        # Tips returns the current tips of the dag.
+        tips: [Block!]!
+        # Era returns an era by number, or the current era if no number is given.
+        era(number: Long): Era
+        # Checkpoint returns the checkpoint of a spine, or the last coordinated
+        # checkpoint if no spine is given.
+        checkpoint(spine: Bytes32): Checkpoint
+        # Token returns a native token at the given block, or the latest block
+        # if no block is given.
+        token(address: Address!, block: Long): Token
+        # Validator returns the validator info at the given block, or the last
+        # checkpoint if no block is given.
+        validator(address: Address!, block: Long): Validator
+        # Validators returns the validators of an era, or of the current era if
+        # no era is given.
+        validators(era: Long, block: Long): [Validator!]!
     }
 
     type Mutation {
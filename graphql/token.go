@@ -0,0 +1,175 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"math/big"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/internal/ethapi"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token/operation"
+)
+
+// Token represents a native token at a particular block.
+type Token struct {
+	backend       ethapi.Backend
+	address       common.Address
+	blockNrOrHash rpc.BlockNumberOrHash
+	props         interface{}
+}
+
+// runTokenProcessor executes the given function with the token processor
+// at the state of the given block.
+func runTokenProcessor(ctx context.Context, backend ethapi.Backend, blockNrOrHash rpc.BlockNumberOrHash, fn func(tp *token.Processor) error) error {
+	// Setup context so it may be cancelled the call has completed
+	// or, in case of unmetered gas, setup a context with a timeout.
+	var cancel context.CancelFunc
+	if timeout := backend.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	state, header, err := backend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return err
+	}
+	tp, tpError, err := backend.GetTP(ctx, state, header)
+	if err != nil {
+		return err
+	}
+	if err := fn(tp); err != nil {
+		return err
+	}
+	return tpError()
+}
+
+func (t *Token) Address() common.Address {
+	return t.address
+}
+
+func (t *Token) Standard() int32 {
+	switch props := t.props.(type) {
+	case *token.WRC20PropertiesResult:
+		return int32(props.Std)
+	case *token.WRC721PropertiesResult:
+		return int32(props.Std)
+	}
+	return 0
+}
+
+func (t *Token) Name() hexutil.Bytes {
+	switch props := t.props.(type) {
+	case *token.WRC20PropertiesResult:
+		return props.Name
+	case *token.WRC721PropertiesResult:
+		return props.Name
+	}
+	return hexutil.Bytes{}
+}
+
+func (t *Token) Symbol() hexutil.Bytes {
+	switch props := t.props.(type) {
+	case *token.WRC20PropertiesResult:
+		return props.Symbol
+	case *token.WRC721PropertiesResult:
+		return props.Symbol
+	}
+	return hexutil.Bytes{}
+}
+
+// Decimals returns the decimals of a WRC-20 token, or nil for other tokens.
+func (t *Token) Decimals() *int32 {
+	if props, ok := t.props.(*token.WRC20PropertiesResult); ok {
+		decimals := int32(props.Decimals)
+		return &decimals
+	}
+	return nil
+}
+
+// TotalSupply returns the total supply of a WRC-20 token, or nil for other tokens.
+func (t *Token) TotalSupply() *hexutil.Big {
+	if props, ok := t.props.(*token.WRC20PropertiesResult); ok && props.TotalSupply != nil {
+		return (*hexutil.Big)(props.TotalSupply)
+	}
+	return nil
+}
+
+// PercentFee returns the percent fee of a WRC-721 token, or nil for other tokens.
+func (t *Token) PercentFee() *int32 {
+	if props, ok := t.props.(*token.WRC721PropertiesResult); ok {
+		fee := int32(props.PercentFee)
+		return &fee
+	}
+	return nil
+}
+
+// BaseURI returns the base URI of a WRC-721 token, or nil for other tokens.
+func (t *Token) BaseURI() *hexutil.Bytes {
+	if props, ok := t.props.(*token.WRC721PropertiesResult); ok && len(props.BaseURI) > 0 {
+		baseURI := hexutil.Bytes(props.BaseURI)
+		return &baseURI
+	}
+	return nil
+}
+
+// BalanceOf returns the token balance of the owner. For a WRC-721 token
+// it is the number of NFTs assigned to the owner.
+func (t *Token) BalanceOf(ctx context.Context, args struct{ Owner common.Address }) (hexutil.Big, error) {
+	op, err := operation.NewBalanceOfOperation(t.address, args.Owner)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	var balance *big.Int
+	err = runTokenProcessor(ctx, t.backend, t.blockNrOrHash, func(tp *token.Processor) error {
+		balance, err = tp.BalanceOf(op)
+		return err
+	})
+	if err != nil || balance == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*balance), nil
+}
+
+func (r *Resolver) Token(ctx context.Context, args struct {
+	Address common.Address
+	Block   *hexutil.Uint64
+}) (*Token, error) {
+	t := &Token{
+		backend:       r.backend,
+		address:       args.Address,
+		blockNrOrHash: BlockNumberArgs{Block: args.Block}.NumberOrLatest(),
+	}
+	op, err := operation.NewPropertiesOperation(t.address, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = runTokenProcessor(ctx, r.backend, t.blockNrOrHash, func(tp *token.Processor) error {
+		t.props, err = tp.Properties(op)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if t.props == nil {
+		return nil, nil
+	}
+	return t, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+	valStore "gitlab.waterfall.network/waterfall/protocol/gwat/validator/storage"
+)
+
+var errNoSlotInfo = errors.New("no slot info")
+
+// Validator represents the validator info at a particular block.
+type Validator struct {
+	val *valStore.Validator
+}
+
+// Stake represents the stake of a validator deposited from an address.
+type Stake struct {
+	stake *valStore.StakeByAddress
+}
+
+func (s *Stake) Address() common.Address {
+	return s.stake.Address
+}
+
+func (s *Stake) Sum() hexutil.Big {
+	if s.stake.Sum == nil {
+		return hexutil.Big{}
+	}
+	return hexutil.Big(*s.stake.Sum)
+}
+
+func (v *Validator) Address() common.Address {
+	return v.val.Address
+}
+
+func (v *Validator) PubKey() hexutil.Bytes {
+	return v.val.PubKey.Bytes()
+}
+
+func (v *Validator) WithdrawalAddress() *common.Address {
+	return v.val.WithdrawalAddress
+}
+
+func (v *Validator) Index() Long {
+	return Long(v.val.Index)
+}
+
+func (v *Validator) ActivationEra() Long {
+	return Long(v.val.ActivationEra)
+}
+
+func (v *Validator) ExitEra() Long {
+	return Long(v.val.ExitEra)
+}
+
+func (v *Validator) Stake() []*Stake {
+	ret := make([]*Stake, 0, len(v.val.Stake))
+	for _, stake := range v.val.Stake {
+		ret = append(ret, &Stake{stake})
+	}
+	return ret
+}
+
+func (v *Validator) DepositTxs() []common.Hash {
+	return v.val.DepositTxs
+}
+
+func (v *Validator) WithdrawalTx() *common.Hash {
+	return v.val.WithdrawalTx
+}
+
+func (v *Validator) ExitTx() *common.Hash {
+	return v.val.ExitTx
+}
+
+// validatorsBlockOr returns the provided block number argument or
+// the spine of the last coordinated checkpoint if none was provided.
+func validatorsBlockOr(block *hexutil.Uint64) rpc.BlockNumberOrHash {
+	return BlockNumberArgs{Block: block}.NumberOr(rpc.BlockNumberOrHashWithNumber(rpc.CheckpointBlockNumber))
+}
+
+func (r *Resolver) Validator(ctx context.Context, args struct {
+	Address common.Address
+	Block   *hexutil.Uint64
+}) (*Validator, error) {
+	stateDb, _, err := r.backend.StateAndHeaderByNumberOrHash(ctx, validatorsBlockOr(args.Block))
+	if stateDb == nil || err != nil {
+		return nil, err
+	}
+	val, err := r.backend.ValidatorsStorage().GetValidator(stateDb, args.Address)
+	if errors.Is(err, valStore.ErrNoStateValidatorInfo) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{val}, nil
+}
+
+// Validators returns the validators of the era, the current era is used if none was provided.
+func (r *Resolver) Validators(ctx context.Context, args struct {
+	Era   *Long
+	Block *hexutil.Uint64
+}) ([]*Validator, error) {
+	bc := r.backend.BlockChain()
+	slotInfo := bc.GetSlotInfo()
+	if slotInfo == nil {
+		return nil, errNoSlotInfo
+	}
+	startEpoch := bc.GetEraInfo().FromEpoch()
+	if args.Era != nil {
+		dbEra := rawdb.ReadEra(r.backend.ChainDb(), uint64(*args.Era))
+		if dbEra == nil {
+			return nil, errEraNotFound
+		}
+		startEpoch = dbEra.From
+	}
+	slot, err := slotInfo.SlotOfEpochStart(startEpoch)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := r.backend.ValidatorsStorage().GetValidators(bc, slot, "graphql")
+	if err != nil {
+		return nil, err
+	}
+	stateDb, _, err := r.backend.StateAndHeaderByNumberOrHash(ctx, validatorsBlockOr(args.Block))
+	if stateDb == nil || err != nil {
+		return nil, err
+	}
+	ret := make([]*Validator, 0, len(addresses))
+	for _, address := range addresses {
+		val, err := r.backend.ValidatorsStorage().GetValidator(stateDb, address)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &Validator{val})
+	}
+	return ret, nil
+}
@@ -106,6 +106,17 @@ type SyncProgress struct {
 	FinalizedSlot uint64
 	MaxDagSlot    uint64
 	CurrentSlot   uint64
+
+	Peers []PeerSyncProgress // Progress of the dag data retrieval per peer
+}
+
+// PeerSyncProgress gives the progress of the dag data retrieval from a single peer.
+type PeerSyncProgress struct {
+	Peer     string // Identifier of the peer
+	Headers  uint64 // Number of headers retrieved from the peer
+	Bodies   uint64 // Number of block bodies retrieved from the peer
+	Pending  uint64 // Number of items currently requested from the peer
+	Failures uint64 // Number of failed requests to the peer
 }
 
 // ChainSyncReader wraps access to the node's current sync status. If there's no
@@ -137,12 +148,28 @@ type ContractCaller interface {
 	CallContract(ctx context.Context, call CallMsg, blockNumber *big.Int) ([]byte, error)
 }
 
+// LogFinality is the finality level the logs of a filter are emitted at.
+type LogFinality string
+
+const (
+	// LogsOptimistic emits logs at the earliest level available. The logs are
+	// computed with the state of finalized blocks, so they are emitted at
+	// finalization as well as the finalized ones.
+	LogsOptimistic LogFinality = "optimistic"
+	// LogsFinalized emits logs once their block is finalized,
+	// which is when the state of the block is computed.
+	LogsFinalized LogFinality = "finalized"
+	// LogsCheckpointed emits logs once their block is covered by a coordinated checkpoint.
+	LogsCheckpointed LogFinality = "checkpointed"
+)
+
 // FilterQuery contains options for contract log filtering.
 type FilterQuery struct {
 	BlockHash *common.Hash     // used by eth_getLogs, return logs only from block with this hash
 	FromBlock *big.Int         // beginning of the queried range, nil means genesis block
 	ToBlock   *big.Int         // end of the range, nil means latest block
 	Addresses []common.Address // restricts matches to events created by specific contracts
+	Finality  LogFinality      // finality level of the matched logs, empty means finalized
 
 	// The Topic list restricts matches to particular event topics. Each event has a list
 	// of topics. Topics matches a prefix of that list. An empty element slice matches any
@@ -87,6 +87,7 @@ type feeHistoryResult struct {
 	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
 	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
 	GasUsedRatio []float64        `json:"gasUsedRatio"`
+	Congestion   []float64        `json:"slotCongestion,omitempty"`
 }
 
 func (s *PublicEthereumAPI) FeeHistory(ctx context.Context, blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
@@ -94,13 +95,14 @@ func (s *PublicEthereumAPI) FeeHistory(ctx context.Context, blockCount rpc.Decim
 		return nil, fmt.Errorf("node is not synchronized")
 	}
 
-	oldest, reward, baseFee, gasUsed, err := s.b.FeeHistory(ctx, int(blockCount), lastBlock, rewardPercentiles)
+	oldest, reward, baseFee, gasUsed, congestion, err := s.b.FeeHistory(ctx, int(blockCount), lastBlock, rewardPercentiles)
 	if err != nil {
 		return nil, err
 	}
 	results := &feeHistoryResult{
 		OldestBlock:  (*hexutil.Big)(oldest),
 		GasUsedRatio: gasUsed,
+		Congestion:   congestion,
 	}
 	if reward != nil {
 		results.Reward = make([][]*hexutil.Big, len(reward))
@@ -140,7 +142,7 @@ func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 		"pulledStates":  hexutil.Uint64(progress.PulledStates),
 		"knownStates":   hexutil.Uint64(progress.KnownStates),
 		"finalizedSlot": hexutil.Uint64(progress.FinalizedSlot),
-		"maxDagSlot":    hexutil.Uint64(progress.FinalizedSlot),
+		"maxDagSlot":    hexutil.Uint64(progress.MaxDagSlot),
 		"currentSlot":   hexutil.Uint64(progress.CurrentSlot),
 	}, nil
 }
@@ -1282,10 +1284,11 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args TransactionA
 // while replaying a transaction in debug mode as well as transaction
 // execution status, the amount of gas used and the return value
 type ExecutionResult struct {
-	Gas         uint64         `json:"gas"`
-	Failed      bool           `json:"failed"`
-	ReturnValue string         `json:"returnValue"`
-	StructLogs  []StructLogRes `json:"structLogs"`
+	Gas         uint64              `json:"gas"`
+	Failed      bool                `json:"failed"`
+	ReturnValue string              `json:"returnValue"`
+	StructLogs  []StructLogRes      `json:"structLogs"`
+	NativeOps   []*vm.NativeOpFrame `json:"nativeOps,omitempty"`
 }
 
 // StructLogRes stores a structured log emitted by the EVM while replaying a
@@ -2349,6 +2352,11 @@ func (api *PublicDagAPI) SyncSlotInfo(ctx context.Context, data types.SlotInfo)
 	return api.b.Dag().HandleSyncSlotInfo(data)
 }
 
+// SyncCheckpoint starts the chain synchronization from the trusted checkpoint.
+func (api *PublicDagAPI) SyncCheckpoint(ctx context.Context, cp *types.Checkpoint, cpEra *era.Era) (bool, error) {
+	return api.b.Dag().HandleSyncCheckpoint(cp, cpEra)
+}
+
 // PublicWatAPI provides an API to access the gwat public consensus functionality.
 // It offers only methods that operate on public data that is freely available to anyone.
 type PublicWatAPI struct {
@@ -0,0 +1,77 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+)
+
+// defaultSenderAssignmentSlots is the number of upcoming slots reported
+// by wat_getSenderAssignment if not specified.
+const defaultSenderAssignmentSlots = 4
+
+// RPCSenderAssignment is the creator assigned to include the transactions
+// of the sender in the slot.
+type RPCSenderAssignment struct {
+	Slot      hexutil.Uint64 `json:"slot"`
+	Creator   common.Address `json:"creator"`
+	IsCreator bool           `json:"isCreator"` // the sender is the creator of the slot itself
+}
+
+// GetSenderAssignment returns the creators assigned to include the transactions
+// of the address in the upcoming slots. The number of slots is limited to the
+// two epochs, slots beyond the current era are not assigned yet and skipped.
+func (api *PublicWatAPI) GetSenderAssignment(ctx context.Context, address common.Address, slots *hexutil.Uint64) ([]*RPCSenderAssignment, error) {
+	bc := api.b.BlockChain()
+	slotInfo := bc.GetSlotInfo()
+	if slotInfo == nil {
+		return nil, errors.New("no slot info")
+	}
+	count := uint64(defaultSenderAssignmentSlots)
+	if slots != nil {
+		count = uint64(*slots)
+	}
+	if maxSlots := 2 * slotInfo.SlotsPerEpoch; count == 0 || count > maxSlots {
+		return nil, fmt.Errorf("invalid slots number %d, expected 1..%d", count, maxSlots)
+	}
+	currentSlot := slotInfo.CurrentSlot()
+	lastEpoch := bc.GetEraInfo().ToEpoch()
+	assignments := make([]*RPCSenderAssignment, 0, count)
+	for slot := currentSlot + 1; slot <= currentSlot+count; slot++ {
+		if slotInfo.SlotToEpoch(slot) > lastEpoch {
+			break
+		}
+		creators, err := bc.ValidatorStorage().GetCreatorsBySlot(bc, slot)
+		if err != nil {
+			return nil, err
+		}
+		creator, ok := core.AssignedCreator(address, creators)
+		if !ok {
+			continue
+		}
+		assignments = append(assignments, &RPCSenderAssignment{
+			Slot:      hexutil.Uint64(slot),
+			Creator:   creator,
+			IsCreator: creator == address,
+		})
+	}
+	return assignments, nil
+}
@@ -0,0 +1,287 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/state"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/vm"
+)
+
+// maxSimulateBundleTxs is the maximum number of transactions simulated
+// by wat_simulateBundle.
+const maxSimulateBundleTxs = 64
+
+// RPCAccountState is the state of the account changed by the simulated
+// transaction, only the changed fields are set.
+type RPCAccountState struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// RPCStateDiff is the state of the accounts changed by the simulated
+// transaction before and after its execution.
+type RPCStateDiff struct {
+	Pre  map[common.Address]*RPCAccountState `json:"pre"`
+	Post map[common.Address]*RPCAccountState `json:"post"`
+}
+
+// RPCSimulatedTx is the result of the simulated transaction of the bundle.
+type RPCSimulatedTx struct {
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	Error      string         `json:"error,omitempty"`
+	Logs       []*types.Log   `json:"logs"`
+	StateDiff  *RPCStateDiff  `json:"stateDiff"`
+}
+
+// RPCSimulatedBundle is the result of the simulated bundle of transactions.
+type RPCSimulatedBundle struct {
+	Slot         hexutil.Uint64    `json:"slot"`
+	Height       hexutil.Uint64    `json:"height"`
+	ParentHashes common.HashArray  `json:"parentHashes"`
+	GasUsed      hexutil.Uint64    `json:"gasUsed"`
+	Transactions []*RPCSimulatedTx `json:"transactions"`
+}
+
+// SimulateBundle applies the ordered list of transactions to the state derived
+// from the current tips and returns the result of each of them. The state is
+// changed by each transaction of the bundle, so the dependent transactions,
+// e.g. approve and transferFrom, can be simulated. Nothing is committed.
+func (api *PublicWatAPI) SimulateBundle(ctx context.Context, txs []TransactionArgs) (*RPCSimulatedBundle, error) {
+	if len(txs) == 0 || len(txs) > maxSimulateBundleTxs {
+		return nil, fmt.Errorf("invalid bundle size %d, expected 1..%d", len(txs), maxSimulateBundleTxs)
+	}
+	statedb, header, err := api.b.BlockChain().StateByTips()
+	if err != nil {
+		return nil, err
+	}
+	// Setup context so it may be cancelled when the bundle has been simulated.
+	var cancel context.CancelFunc
+	if timeout := api.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	bundle := &RPCSimulatedBundle{
+		Slot:         hexutil.Uint64(header.Slot),
+		Height:       hexutil.Uint64(header.Height),
+		ParentHashes: header.ParentHashes,
+		Transactions: make([]*RPCSimulatedTx, 0, len(txs)),
+	}
+	gp := new(core.GasPool).AddGas(math.MaxUint64)
+	for i, args := range txs {
+		res, err := simulateTx(ctx, api.b, statedb, header, args, i, gp)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		bundle.GasUsed += res.GasUsed
+		bundle.Transactions = append(bundle.Transactions, res)
+	}
+	return bundle, nil
+}
+
+// simulateTx applies the transaction of the bundle to the state.
+func simulateTx(ctx context.Context, b Backend, statedb *state.StateDB, header *types.Header, args TransactionArgs, index int, gp *core.GasPool) (*RPCSimulatedTx, error) {
+	msg, err := args.ToMessage(b.RPCGasCap(), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	// The logs of the simulated transactions are keyed by the bundle index
+	txHash := common.BigToHash(big.NewInt(int64(index)))
+	statedb.Prepare(txHash, index)
+
+	tracer := newTouchedTracer()
+	evm, vmError, err := b.GetEVM(ctx, msg, statedb, header, &vm.Config{NoBaseFee: true, Debug: true, Tracer: tracer})
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+	tp, tpError, err := b.GetTP(ctx, statedb, header)
+	if err != nil {
+		return nil, err
+	}
+	vp, vpError, err := b.GetVP(ctx, statedb, header)
+	if err != nil {
+		return nil, err
+	}
+
+	pre := statedb.Copy()
+	snapshot := statedb.Snapshot()
+	result, applyErr := core.ApplyMessage(evm, tp, vp, msg, gp)
+	if err := vmError(); err != nil {
+		return nil, err
+	}
+	if err := tpError(); err != nil {
+		return nil, err
+	}
+	if err := vpError(); err != nil {
+		return nil, err
+	}
+	if evm.Cancelled() {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", b.RPCEVMTimeout())
+	}
+
+	res := &RPCSimulatedTx{Logs: []*types.Log{}}
+	if applyErr != nil {
+		// The transaction is not applicable to the state, skip it
+		statedb.RevertToSnapshot(snapshot)
+		res.Error = applyErr.Error()
+		res.StateDiff = &RPCStateDiff{
+			Pre:  make(map[common.Address]*RPCAccountState),
+			Post: make(map[common.Address]*RPCAccountState),
+		}
+		return res, nil
+	}
+	statedb.Finalise(true)
+
+	res.GasUsed = hexutil.Uint64(result.UsedGas)
+	res.ReturnData = result.Return()
+	if len(result.Revert()) > 0 {
+		res.ReturnData = result.Revert()
+		res.Error = newRevertError(result).Error()
+	} else if result.Err != nil {
+		res.Error = result.Err.Error()
+	}
+	if logs := statedb.GetLogs(txHash, header.Hash()); logs != nil {
+		res.Logs = logs
+	}
+	res.StateDiff = tracer.diff(pre, statedb)
+	return res, nil
+}
+
+// touchedTracer collects the accounts and the storage slots touched by the
+// transaction, including the native token and validator operations. The reward
+// of the coinbase is not a part of the diff, as the creator of the block
+// including the transaction is not known in advance.
+type touchedTracer struct {
+	touched map[common.Address]map[common.Hash]struct{}
+}
+
+func newTouchedTracer() *touchedTracer {
+	return &touchedTracer{touched: make(map[common.Address]map[common.Hash]struct{})}
+}
+
+func (t *touchedTracer) touchAccount(addr common.Address) map[common.Hash]struct{} {
+	slots, ok := t.touched[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		t.touched[addr] = slots
+	}
+	return slots
+}
+
+func (t *touchedTracer) touchSlot(addr common.Address, slot common.Hash) {
+	t.touchAccount(addr)[slot] = struct{}{}
+}
+
+func (t *touchedTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.touchAccount(from)
+	t.touchAccount(to)
+}
+
+func (t *touchedTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	stackData := scope.Stack.Data()
+	stackLen := len(stackData)
+	switch {
+	case stackLen >= 1 && op == vm.SSTORE:
+		t.touchSlot(scope.Contract.Address(), common.Hash(stackData[stackLen-1].Bytes32()))
+	case stackLen >= 1 && op == vm.SELFDESTRUCT:
+		t.touchAccount(common.Address(stackData[stackLen-1].Bytes20()))
+	}
+}
+
+func (t *touchedTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.touchAccount(to)
+}
+
+func (t *touchedTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *touchedTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *touchedTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {}
+
+// CaptureNativeOp collects the accounts touched by the native operation.
+func (t *touchedTracer) CaptureNativeOp(env *vm.EVM, frame *vm.NativeOpFrame) {
+	t.touchAccount(frame.From)
+	t.touchAccount(frame.To)
+	for addr, acc := range frame.Prestate() {
+		t.touchAccount(addr)
+		for slot := range acc.Storage {
+			t.touchSlot(addr, slot)
+		}
+	}
+}
+
+// diff compares the touched accounts of the states before and after the
+// transaction.
+func (t *touchedTracer) diff(pre, post *state.StateDB) *RPCStateDiff {
+	diff := &RPCStateDiff{
+		Pre:  make(map[common.Address]*RPCAccountState),
+		Post: make(map[common.Address]*RPCAccountState),
+	}
+	for addr, slots := range t.touched {
+		var (
+			before, after = new(RPCAccountState), new(RPCAccountState)
+			changed       bool
+		)
+		if preBalance, postBalance := pre.GetBalance(addr), post.GetBalance(addr); preBalance.Cmp(postBalance) != 0 {
+			before.Balance, after.Balance = (*hexutil.Big)(preBalance), (*hexutil.Big)(postBalance)
+			changed = true
+		}
+		if preNonce, postNonce := pre.GetNonce(addr), post.GetNonce(addr); preNonce != postNonce {
+			before.Nonce, after.Nonce = (*hexutil.Uint64)(&preNonce), (*hexutil.Uint64)(&postNonce)
+			changed = true
+		}
+		if preCode, postCode := pre.GetCode(addr), post.GetCode(addr); !bytes.Equal(preCode, postCode) {
+			before.Code, after.Code = preCode, postCode
+			changed = true
+		}
+		for slot := range slots {
+			preValue, postValue := pre.GetState(addr, slot), post.GetState(addr, slot)
+			if preValue == postValue {
+				continue
+			}
+			if before.Storage == nil {
+				before.Storage = make(map[common.Hash]common.Hash)
+				after.Storage = make(map[common.Hash]common.Hash)
+			}
+			before.Storage[slot], after.Storage[slot] = preValue, postValue
+			changed = true
+		}
+		if changed {
+			diff.Pre[addr], diff.Post[addr] = before, after
+		}
+	}
+	return diff
+}
@@ -0,0 +1,145 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethapi_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/eth/ethconfig"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/internal/ethapi"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/node"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+// newSimulateBackend starts a node with the not finalized block, which
+// transfers the funds from the test account to the recipient, at the tips.
+func newSimulateBackend(t *testing.T, recipient common.Address, amount *big.Int) *eth.Ethereum {
+	depositData := make(core.DepositData, 0)
+	for i := 0; i < 64; i++ {
+		depositData = append(depositData, &core.ValidatorData{
+			Pubkey:            common.BytesToBlsPubKey(testutils.RandomData(96)).String(),
+			CreatorAddress:    common.BytesToAddress(testutils.RandomData(20)).String(),
+			WithdrawalAddress: common.BytesToAddress(testutils.RandomData(20)).String(),
+			Amount:            3200,
+		})
+	}
+	config := params.AllEthashProtocolChanges
+	genesis := &core.Genesis{
+		Config:     config,
+		Alloc:      core.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000000000000000)}},
+		ExtraData:  []byte("test genesis"),
+		Timestamp:  9000,
+		Validators: depositData,
+	}
+	db := rawdb.NewMemoryDatabase()
+	gblock := genesis.MustCommit(db)
+	signer := types.MakeSigner(config)
+	blocks, _ := core.GenerateChain(config, gblock, db, 1, func(i int, g *core.BlockGen) {
+		g.OffsetTime(5)
+		tx, err := types.SignTx(types.NewTransaction(0, recipient, amount, params.TxGas, g.BaseFee(), nil), signer, testKey)
+		testutils.AssertNoError(t, err)
+		g.AddTx(tx)
+	})
+
+	n, err := node.New(&node.Config{})
+	testutils.AssertNoError(t, err)
+	t.Cleanup(func() { n.Close() })
+	ethservice, err := eth.New(n, &ethconfig.Config{Genesis: genesis, GPO: ethconfig.Defaults.GPO})
+	testutils.AssertNoError(t, err)
+	testutils.AssertNoError(t, n.Start())
+	ethservice.BlockChain().SetIsSynced(true)
+	bc := ethservice.BlockChain()
+	_, err = bc.InsertChain(blocks)
+	testutils.AssertNoError(t, err)
+	bc.AddTips(&types.BlockDAG{
+		Hash:                   blocks[0].Hash(),
+		Height:                 blocks[0].Height(),
+		Slot:                   blocks[0].Slot(),
+		CpHash:                 gblock.Hash(),
+		CpHeight:               gblock.Height(),
+		OrderedAncestorsHashes: common.HashArray{},
+	})
+	bc.RemoveTips(common.HashArray{gblock.Hash()})
+	return ethservice
+}
+
+func TestSimulateBundle(t *testing.T) {
+	var (
+		recipient  = common.BytesToAddress(testutils.RandomData(20))
+		other      = common.BytesToAddress(testutils.RandomData(20))
+		ethservice = newSimulateBackend(t, recipient, big.NewInt(1000))
+		api        = ethapi.NewPublicWatAPI(ethservice.APIBackend)
+		ctx        = context.Background()
+	)
+	// the first transfer spends the funds received in the not finalized block,
+	// the second one spends the funds received by the first one
+	// and the third one exceeds the balance
+	bundle, err := api.SimulateBundle(ctx, []ethapi.TransactionArgs{
+		{From: &recipient, To: &other, Value: (*hexutil.Big)(big.NewInt(400))},
+		{From: &other, To: &recipient, Value: (*hexutil.Big)(big.NewInt(100))},
+		{From: &other, To: &recipient, Value: (*hexutil.Big)(big.NewInt(1000))},
+	})
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, ethservice.BlockChain().GetTips().GetHashes(), bundle.ParentHashes)
+	testutils.AssertEqual(t, 3, len(bundle.Transactions))
+	testutils.AssertEqual(t, hexutil.Uint64(2*params.TxGas), bundle.GasUsed)
+
+	first := bundle.Transactions[0]
+	testutils.AssertEqual(t, "", first.Error)
+	testutils.AssertEqual(t, big.NewInt(1000), first.StateDiff.Pre[recipient].Balance.ToInt())
+	testutils.AssertEqual(t, big.NewInt(600), first.StateDiff.Post[recipient].Balance.ToInt())
+	testutils.AssertEqual(t, big.NewInt(400), first.StateDiff.Post[other].Balance.ToInt())
+
+	second := bundle.Transactions[1]
+	testutils.AssertEqual(t, "", second.Error)
+	testutils.AssertEqual(t, big.NewInt(300), second.StateDiff.Post[other].Balance.ToInt())
+
+	third := bundle.Transactions[2]
+	if third.Error == "" {
+		t.Fatal("expected error for insufficient funds")
+	}
+	testutils.AssertEqual(t, 0, len(third.StateDiff.Post))
+
+	// nothing is committed to the state
+	statedb, _, err := ethservice.BlockChain().StateByTips()
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, big.NewInt(1000), statedb.GetBalance(recipient))
+	testutils.AssertEqual(t, 0, statedb.GetBalance(other).Sign())
+
+	// the bundle size is limited
+	_, err = api.SimulateBundle(ctx, nil)
+	if err == nil {
+		t.Fatal("expected error for empty bundle")
+	}
+	_, err = api.SimulateBundle(ctx, make([]ethapi.TransactionArgs, 65))
+	if err == nil {
+		t.Fatal("expected error for too large bundle")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethapi
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+)
+
+// RPCSlotFees is the fee data of the slot aggregated over all its blocks.
+type RPCSlotFees struct {
+	Slot         hexutil.Uint64 `json:"slot"`
+	Blocks       hexutil.Uint   `json:"blocks"`
+	Creators     hexutil.Uint64 `json:"creators"`
+	BaseFee      *hexutil.Big   `json:"baseFeePerGas"`
+	CpBaseFee    *hexutil.Big   `json:"cpBaseFeePerGas,omitempty"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	GasLimit     hexutil.Uint64 `json:"gasLimit"`
+	GasUsedRatio float64        `json:"gasUsedRatio"`
+	Congestion   float64        `json:"congestion"`
+	Reward       []*hexutil.Big `json:"reward,omitempty"`
+}
+
+// RPCFeeHistoryBySlot is the fee history of the range of slots.
+type RPCFeeHistoryBySlot struct {
+	OldestSlot  hexutil.Uint64 `json:"oldestSlot"`
+	Slots       []*RPCSlotFees `json:"slots"`
+	NextBaseFee *hexutil.Big   `json:"nextBaseFeePerGas,omitempty"` // base fee of the slot following the range
+}
+
+// FeeHistoryBySlot returns the fee history of the range of slots ending with
+// the last slot, all blocks of each slot are aggregated. The last slot is the
+// slot of the last finalized block if not specified.
+func (api *PublicWatAPI) FeeHistoryBySlot(ctx context.Context, slotCount rpc.DecimalOrHex, lastSlot *hexutil.Uint64, rewardPercentiles []float64) (*RPCFeeHistoryBySlot, error) {
+	if !api.b.BlockChain().IsSynced() {
+		return nil, fmt.Errorf("node is not synchronized")
+	}
+	var last *uint64
+	if lastSlot != nil {
+		slot := uint64(*lastSlot)
+		last = &slot
+	}
+	history, nextBaseFee, err := api.b.FeeHistoryBySlot(ctx, int(slotCount), last, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+	result := &RPCFeeHistoryBySlot{
+		Slots:       make([]*RPCSlotFees, 0, len(history)),
+		NextBaseFee: (*hexutil.Big)(nextBaseFee),
+	}
+	if len(history) > 0 {
+		result.OldestSlot = hexutil.Uint64(history[0].Slot)
+	}
+	for _, fees := range history {
+		slotFees := &RPCSlotFees{
+			Slot:         hexutil.Uint64(fees.Slot),
+			Blocks:       hexutil.Uint(fees.Blocks),
+			Creators:     hexutil.Uint64(fees.Creators),
+			BaseFee:      (*hexutil.Big)(fees.BaseFee),
+			CpBaseFee:    (*hexutil.Big)(fees.CpBaseFee),
+			GasUsed:      hexutil.Uint64(fees.GasUsed),
+			GasLimit:     hexutil.Uint64(fees.GasLimit),
+			GasUsedRatio: fees.GasUsedRatio,
+			Congestion:   fees.Congestion,
+		}
+		if fees.Reward != nil {
+			slotFees.Reward = make([]*hexutil.Big, len(fees.Reward))
+			for i, r := range fees.Reward {
+				slotFees.Reward[i] = (*hexutil.Big)(r)
+			}
+		}
+		result.Slots = append(result.Slots, slotFees)
+	}
+	return result, nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethapi
+
+import (
+	"context"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+)
+
+// Transaction lifecycle statuses.
+const (
+	TxStatusUnknown    = "unknown"
+	TxStatusQueued     = "queued"
+	TxStatusPending    = "pending"
+	TxStatusProcessing = "processing"
+	TxStatusFinalized  = "finalized"
+	TxStatusDropped    = "dropped"
+)
+
+// TxStatusBlock is a dag block including the transaction.
+type TxStatusBlock struct {
+	Hash common.Hash    `json:"hash"`
+	Slot hexutil.Uint64 `json:"slot"`
+}
+
+// TxStatusCheckpoint is the coordinated checkpoint covering the finalized transaction.
+type TxStatusCheckpoint struct {
+	Epoch    hexutil.Uint64 `json:"epoch"`
+	FinEpoch hexutil.Uint64 `json:"finEpoch"`
+	Spine    common.Hash    `json:"spine"`
+}
+
+// RPCTransactionStatus is the lifecycle status of the transaction.
+type RPCTransactionStatus struct {
+	Hash            common.Hash         `json:"hash"`
+	Status          string              `json:"status"`
+	Blocks          []*TxStatusBlock    `json:"blocks"`
+	BlockHash       *common.Hash        `json:"blockHash,omitempty"`
+	FinalizedNumber *hexutil.Uint64     `json:"finalizedNumber,omitempty"`
+	Checkpoint      *TxStatusCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// equal reports whether the statuses match.
+func (s *RPCTransactionStatus) equal(other *RPCTransactionStatus) bool {
+	if other == nil || s.Status != other.Status || len(s.Blocks) != len(other.Blocks) {
+		return false
+	}
+	for i, b := range s.Blocks {
+		if *b != *other.Blocks[i] {
+			return false
+		}
+	}
+	return (s.Checkpoint == nil) == (other.Checkpoint == nil)
+}
+
+// transactionStatus resolves the lifecycle status of the transaction:
+// finalized transactions are looked up in the chain, the others in the pool.
+func transactionStatus(ctx context.Context, b Backend, hash common.Hash) *RPCTransactionStatus {
+	res := &RPCTransactionStatus{
+		Hash:   hash,
+		Status: TxStatusUnknown,
+		Blocks: []*TxStatusBlock{},
+	}
+	if tx, blockHash, _, err := b.GetTransaction(ctx, hash); err == nil && tx != nil {
+		if nr := b.GetBlockFinalizedNumber(blockHash); nr != nil {
+			res.Status = TxStatusFinalized
+			res.BlockHash = &blockHash
+			res.FinalizedNumber = (*hexutil.Uint64)(nr)
+			if header, _ := b.HeaderByHash(ctx, blockHash); header != nil {
+				res.Blocks = append(res.Blocks, &TxStatusBlock{Hash: blockHash, Slot: hexutil.Uint64(header.Slot)})
+				res.Checkpoint = coveringCheckpoint(b.BlockChain(), header.Slot, *nr)
+			}
+			return res
+		}
+	}
+	status, blocks := b.TxPoolStatus(hash)
+	switch status {
+	case core.TxStatusQueued:
+		res.Status = TxStatusQueued
+	case core.TxStatusPending:
+		res.Status = TxStatusPending
+	case core.TxStatusProcessing:
+		res.Status = TxStatusProcessing
+	case core.TxStatusDropped:
+		res.Status = TxStatusDropped
+	}
+	for _, blockHash := range blocks {
+		if header, _ := b.HeaderByHash(ctx, blockHash); header != nil {
+			res.Blocks = append(res.Blocks, &TxStatusBlock{Hash: blockHash, Slot: hexutil.Uint64(header.Slot)})
+		}
+	}
+	return res
+}
+
+// coveringCheckpoint returns the first coordinated checkpoint, which spine
+// is finalized not before the block of the given slot and finalized number.
+func coveringCheckpoint(bc *core.BlockChain, slot uint64, nr uint64) *TxStatusCheckpoint {
+	lastCp := bc.GetLastCoordinatedCheckpoint()
+	if lastCp == nil || bc.GetSlotInfo() == nil {
+		return nil
+	}
+	for epoch := bc.GetSlotInfo().SlotToEpoch(slot); epoch <= lastCp.FinEpoch; epoch++ {
+		spine := bc.GetEpoch(epoch)
+		if spine == (common.Hash{}) {
+			continue
+		}
+		cp := bc.GetCoordinatedCheckpoint(spine)
+		if cp == nil {
+			continue
+		}
+		if spineNr := bc.ReadFinalizedNumberByHash(spine); spineNr != nil && *spineNr >= nr {
+			return &TxStatusCheckpoint{
+				Epoch:    hexutil.Uint64(cp.Epoch),
+				FinEpoch: hexutil.Uint64(cp.FinEpoch),
+				Spine:    cp.Spine,
+			}
+		}
+	}
+	return nil
+}
+
+// GetTransactionStatus returns the lifecycle status of the transaction:
+// queued, pending, processing, finalized or dropped, with the dag blocks
+// including the transaction, the finalized number and the checkpoint
+// once known.
+func (api *PublicWatAPI) GetTransactionStatus(ctx context.Context, hash common.Hash) *RPCTransactionStatus {
+	return transactionStatus(ctx, api.b, hash)
+}
+
+// TransactionStatus provides notifications on changes of the lifecycle status
+// of the transaction. The notifications stop once the transaction is finalized
+// and covered by a coordinated checkpoint.
+func (api *PublicWatAPI) TransactionStatus(ctx context.Context, hash common.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		var (
+			txsCh   = make(chan core.NewTxsEvent, 16)
+			headCh  = make(chan core.ChainHeadEvent, 16)
+			procCh  = make(chan *types.BlockTransactions, 16)
+			txsSub  = api.b.SubscribeNewTxsEvent(txsCh)
+			headSub = api.b.SubscribeChainHeadEvent(headCh)
+			procSub = api.b.BlockChain().SubscribeProcessing(procCh)
+			last    *RPCTransactionStatus
+		)
+		defer txsSub.Unsubscribe()
+		defer headSub.Unsubscribe()
+		defer procSub.Unsubscribe()
+
+		notify := func() bool {
+			status := transactionStatus(context.Background(), api.b, hash)
+			if !status.equal(last) {
+				notifier.Notify(rpcSub.ID, status)
+				last = status
+			}
+			return status.Status != TxStatusFinalized || status.Checkpoint == nil
+		}
+		if !notify() {
+			return
+		}
+		for {
+			select {
+			case ev := <-txsCh:
+				for _, tx := range ev.Txs {
+					if tx.Hash() == hash && !notify() {
+						return
+					}
+				}
+			case <-procCh:
+				if !notify() {
+					return
+				}
+			case <-headCh:
+				if !notify() {
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -96,6 +96,16 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'validatorSyncRequeue',
+			call: 'admin_validatorSyncRequeue',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'validatorSyncDrop',
+			call: 'admin_validatorSyncDrop',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -827,6 +837,11 @@ web3._extend({
 			call: 'dag_syncSlotInfo',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'syncCheckpoint',
+			call: 'dag_syncCheckpoint',
+			params: 2
+		}),
 	]
 });
 `
@@ -1031,6 +1046,33 @@ web3._extend({
 			}]
 		}),	
 
+		// LIGHT CLIENT API //
+		new web3._extend.Method({
+			name: 'light.checkpoint',
+			call: 'wat_light_Checkpoint',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'light.getBalance',
+			call: 'wat_light_GetBalance',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter],
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
+		new web3._extend.Method({
+			name: 'light.tokenBalanceOf',
+			call: 'wat_light_TokenBalanceOf',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter],
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
+		new web3._extend.Method({
+			name: 'light.validatorInfo',
+			call: 'wat_light_ValidatorInfo',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+
 		// INFO API //
 		new web3._extend.Method({
 			name: 'getEra',
@@ -1048,6 +1090,28 @@ web3._extend({
 			call: 'wat_getSlotHashes',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getTransactionStatus',
+			call: 'wat_getTransactionStatus',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getSenderAssignment',
+			call: 'wat_getSenderAssignment',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'simulateBundle',
+			call: 'wat_simulateBundle',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'feeHistoryBySlot',
+			call: 'wat_feeHistoryBySlot',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 
 		// VALIDATOR API //
 		new web3._extend.Method({
@@ -1122,6 +1186,26 @@ web3._extend({
 				return options;
 			}]
 		}),
+		new web3._extend.Method({
+			name: 'validator.batchDepositData',
+			call: 'wat_validator_BatchDepositData',
+			params: 1,
+			inputFormatter: [function(deposits) {
+				if (!Array.isArray(deposits)) throw new Error('deposits: array is required.');
+				for (var i = 0; i < deposits.length; i++) {
+					var options = deposits[i];
+					handleHexField(options, 'pubkey', BlsPubKeyLength)
+					handleHexField(options, 'creator_address', AddressLength)
+					handleHexField(options, 'withdrawal_address', AddressLength)
+					handleHexField(options, 'signature', BlsSigLength)
+					options.amount = web3._extend.utils.toHex(options.amount);
+					if (options.delegating_stake && options.delegating_stake.trial_period) {
+						options.delegating_stake.trial_period = web3._extend.utils.toDecimal(options.delegating_stake.trial_period);
+					}
+				}
+				return deposits;
+			}]
+		}),
 		new web3._extend.Method({
 			name: 'validator.exitData',
 			call: 'wat_validator_ExitData',
@@ -1145,6 +1229,17 @@ web3._extend({
 				return options;
 			}]
 		}),
+		new web3._extend.Method({
+			name: 'validator.delegatorWithdrawalData',
+			call: 'wat_validator_DelegatorWithdrawalData',
+			params: 1,
+			inputFormatter: [function(options) {
+				handleHexField(options, 'creator_address', AddressLength)
+				handleHexField(options, 'delegator_address', AddressLength)
+				options.amount = web3._extend.utils.toHex(options.amount);
+				return options;
+			}]
+		}),
 		new web3._extend.Method({
 			name: 'validator.depositCount',
 			call: 'wat_validator_DepositCount',
@@ -1156,6 +1251,16 @@ web3._extend({
 			name: 'validator.depositAddress',
 			call: 'wat_validator_DepositAddress',
 		}),
+		new web3._extend.Method({
+			name: 'validatorSync.pending',
+			call: 'wat_validatorSync_Pending',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'validatorSync.get',
+			call: 'wat_validatorSync_Get',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'validator.getTransactionReceipt',
 			call: 'wat_validator_GetTransactionReceipt',
@@ -1166,6 +1271,10 @@ web3._extend({
 		  name: 'info',
 		  getter: 'wat_info'
 		}),
+		new web3._extend.Property({
+		  name: 'syncing',
+		  getter: 'wat_syncing'
+		}),
 	]
 });
 `
@@ -95,7 +95,7 @@ func (lc *LightChain) EpochToEra(epoch uint64) *era.Era {
 	panic("implement me")
 }
 
-func (lc *LightChain) GetValidatorSyncData(InitTxHash common.Hash) *types.ValidatorSync {
+func (lc *LightChain) GetValidatorSyncData(InitTxHash common.Hash, creator common.Address) *types.ValidatorSync {
 	//TODO implement me
 	panic("implement me")
 }
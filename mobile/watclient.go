@@ -0,0 +1,308 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Contains a wrapper for the wat and native token clients.
+
+package geth
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/ethclient/tokenclient"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/ethclient/watclient"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
+	valStore "gitlab.waterfall.network/waterfall/protocol/gwat/validator/storage"
+)
+
+// Era represents an era of validators.
+type Era struct {
+	era *era.Era
+}
+
+func (e *Era) GetNumber() int64    { return int64(e.era.Number) }
+func (e *Era) GetFromEpoch() int64 { return int64(e.era.From) }
+func (e *Era) GetToEpoch() int64   { return int64(e.era.To) }
+func (e *Era) GetRoot() *Hash      { return &Hash{e.era.Root} }
+func (e *Era) GetBlockHash() *Hash { return &Hash{e.era.BlockHash} }
+func (e *Era) EncodeJSON() (string, error) {
+	data, err := json.Marshal(e.era)
+	return string(data), err
+}
+func (e *Era) String() string {
+	return encodeOrError(e)
+}
+
+// Validator represents the info of a validator.
+type Validator struct {
+	validator *valStore.Validator
+}
+
+func (v *Validator) GetAddress() *Address { return &Address{v.validator.Address} }
+func (v *Validator) GetPubKey() []byte    { return v.validator.PubKey.Bytes() }
+func (v *Validator) GetWithdrawalAddress() *Address {
+	if v.validator.WithdrawalAddress == nil {
+		return nil
+	}
+	return &Address{*v.validator.WithdrawalAddress}
+}
+func (v *Validator) GetIndex() int64         { return int64(v.validator.Index) }
+func (v *Validator) GetActivationEra() int64 { return int64(v.validator.ActivationEra) }
+func (v *Validator) GetExitEra() int64       { return int64(v.validator.ExitEra) }
+func (v *Validator) EncodeJSON() (string, error) {
+	data, err := json.Marshal(v.validator)
+	return string(data), err
+}
+func (v *Validator) String() string {
+	return encodeOrError(v)
+}
+
+// TransactionStatus is the lifecycle status of a transaction.
+type TransactionStatus struct {
+	status *watclient.TransactionStatus
+}
+
+func (s *TransactionStatus) GetHash() *Hash    { return &Hash{s.status.Hash} }
+func (s *TransactionStatus) GetStatus() string { return s.status.Status }
+
+// GetBlockHashes returns the hashes of the dag blocks including the transaction.
+func (s *TransactionStatus) GetBlockHashes() *Hashes {
+	hashes := NewHashesEmpty()
+	for _, b := range s.status.Blocks {
+		hashes.Append(&Hash{b.Hash})
+	}
+	return hashes
+}
+
+// GetBlockHash returns the hash of the finalized block including the transaction, if any.
+func (s *TransactionStatus) GetBlockHash() *Hash {
+	if s.status.BlockHash == nil {
+		return nil
+	}
+	return &Hash{*s.status.BlockHash}
+}
+
+// GetFinalizedNumber returns the finalized number of the block including the transaction, or -1.
+func (s *TransactionStatus) GetFinalizedNumber() int64 {
+	if s.status.FinalizedNumber == nil {
+		return -1
+	}
+	return int64(*s.status.FinalizedNumber)
+}
+
+// IsCheckpointed reports whether the transaction is covered by a coordinated checkpoint.
+func (s *TransactionStatus) IsCheckpointed() bool { return s.status.Checkpoint != nil }
+func (s *TransactionStatus) EncodeJSON() (string, error) {
+	data, err := json.Marshal(s.status)
+	return string(data), err
+}
+func (s *TransactionStatus) String() string {
+	return encodeOrError(s)
+}
+
+// WatClient provides access to the wat APIs.
+type WatClient struct {
+	client *watclient.Client
+}
+
+// NewWatClient connects a client to the given URL.
+func NewWatClient(rawurl string) (client *WatClient, _ error) {
+	rawClient, err := watclient.Dial(rawurl)
+	return &WatClient{rawClient}, err
+}
+
+// GetEra returns the era with the given number. If number is <0, the current era is returned.
+func (wc *WatClient) GetEra(ctx *Context, number int64) (era *Era, _ error) {
+	var nr *uint64
+	if number >= 0 {
+		n := uint64(number)
+		nr = &n
+	}
+	rawEra, err := wc.client.Era(ctx.context, nr)
+	return &Era{rawEra}, err
+}
+
+// GetSlotHashes returns the hashes of the blocks created in the given slot.
+func (wc *WatClient) GetSlotHashes(ctx *Context, slot int64) (hashes *Hashes, _ error) {
+	rawHashes, err := wc.client.SlotHashes(ctx.context, uint64(slot))
+	return &Hashes{rawHashes}, err
+}
+
+// GetDagHashes returns the hashes of the blocks of the current dag.
+func (wc *WatClient) GetDagHashes(ctx *Context) (hashes *Hashes, _ error) {
+	rawHashes, err := wc.client.DagHashes(ctx.context)
+	return &Hashes{rawHashes}, err
+}
+
+// GetTransactionStatus returns the lifecycle status of the transaction.
+func (wc *WatClient) GetTransactionStatus(ctx *Context, hash *Hash) (status *TransactionStatus, _ error) {
+	rawStatus, err := wc.client.TransactionStatus(ctx.context, hash.hash)
+	return &TransactionStatus{rawStatus}, err
+}
+
+// TransactionStatusHandler is a client-side subscription callback to invoke on
+// transaction status changes and subscription failure.
+type TransactionStatusHandler interface {
+	OnTransactionStatus(status *TransactionStatus)
+	OnError(failure string)
+}
+
+// SubscribeTransactionStatus subscribes to notifications about the changes of the
+// lifecycle status of the transaction.
+func (wc *WatClient) SubscribeTransactionStatus(ctx *Context, hash *Hash, handler TransactionStatusHandler, buffer int) (sub *Subscription, _ error) {
+	// Subscribe to the event internally
+	ch := make(chan *watclient.TransactionStatus, buffer)
+	rawSub, err := wc.client.SubscribeTransactionStatus(ctx.context, hash.hash, ch)
+	if err != nil {
+		return nil, err
+	}
+	// Start up a dispatcher to feed into the callback
+	go func() {
+		for {
+			select {
+			case status := <-ch:
+				handler.OnTransactionStatus(&TransactionStatus{status})
+
+			case err := <-rawSub.Err():
+				if err != nil {
+					handler.OnError(err.Error())
+				}
+				return
+			}
+		}
+	}()
+	return &Subscription{rawSub}, nil
+}
+
+// GetValidators returns the addresses of the validators of the era.
+// If era is <0, the validators of the current era are returned.
+func (wc *WatClient) GetValidators(ctx *Context, era int64) (validators *Addresses, _ error) {
+	var nr *uint64
+	if era >= 0 {
+		n := uint64(era)
+		nr = &n
+	}
+	rawAddresses, err := wc.client.Validators(ctx.context, nr)
+	return &Addresses{rawAddresses}, err
+}
+
+// GetValidatorInfo returns the info of the validator with the given address.
+// If number is <0, the info is taken from the last coordinated checkpoint.
+func (wc *WatClient) GetValidatorInfo(ctx *Context, address *Address, number int64) (validator *Validator, _ error) {
+	var nr *big.Int
+	if number >= 0 {
+		nr = big.NewInt(number)
+	}
+	rawValidator, err := wc.client.ValidatorInfo(ctx.context, address.address, nr)
+	return &Validator{rawValidator}, err
+}
+
+// GetDepositAddress returns the address to send the validator transactions to.
+func (wc *WatClient) GetDepositAddress(ctx *Context) (address *Address, _ error) {
+	rawAddress, err := wc.client.DepositAddress(ctx.context)
+	return &Address{rawAddress}, err
+}
+
+// WRC20Properties represents the properties of a WRC-20 token.
+type WRC20Properties struct {
+	props *token.WRC20PropertiesResult
+}
+
+func (p *WRC20Properties) GetStandard() int        { return int(p.props.Std) }
+func (p *WRC20Properties) GetName() []byte         { return p.props.Name }
+func (p *WRC20Properties) GetSymbol() []byte       { return p.props.Symbol }
+func (p *WRC20Properties) GetDecimals() int        { return int(p.props.Decimals) }
+func (p *WRC20Properties) GetTotalSupply() *BigInt { return &BigInt{p.props.TotalSupply} }
+
+// WRC721Properties represents the properties of a WRC-721 token.
+type WRC721Properties struct {
+	props *token.WRC721PropertiesResult
+}
+
+func (p *WRC721Properties) GetStandard() int      { return int(p.props.Std) }
+func (p *WRC721Properties) GetName() []byte       { return p.props.Name }
+func (p *WRC721Properties) GetSymbol() []byte     { return p.props.Symbol }
+func (p *WRC721Properties) GetBaseURI() []byte    { return p.props.BaseURI }
+func (p *WRC721Properties) GetPercentFee() int    { return int(p.props.PercentFee) }
+func (p *WRC721Properties) GetTokenURI() []byte   { return p.props.TokenURI }
+func (p *WRC721Properties) GetOwnerOf() *Address  { return &Address{p.props.OwnerOf} }
+func (p *WRC721Properties) GetApproved() *Address { return &Address{p.props.GetApproved} }
+func (p *WRC721Properties) GetMetadata() []byte   { return p.props.Metadata }
+
+// TokenClient provides access to the native token APIs.
+type TokenClient struct {
+	client *tokenclient.Client
+}
+
+// NewTokenClient connects a client to the given URL.
+func NewTokenClient(rawurl string) (client *TokenClient, _ error) {
+	rawClient, err := tokenclient.Dial(rawurl)
+	return &TokenClient{rawClient}, err
+}
+
+// GetWRC20Properties returns the properties of the WRC-20 token.
+// If number is <0, the properties are taken from the latest known block.
+func (tc *TokenClient) GetWRC20Properties(ctx *Context, tokenAddr *Address, number int64) (props *WRC20Properties, _ error) {
+	rawProps, err := tc.client.WRC20Properties(ctx.context, tokenAddr.address, toBlockNumber(number))
+	return &WRC20Properties{rawProps}, err
+}
+
+// GetWRC721Properties returns the properties of the WRC-721 token and of its NFT
+// if tokenId is not nil. If number is <0, the properties are taken from the latest known block.
+func (tc *TokenClient) GetWRC721Properties(ctx *Context, tokenAddr *Address, tokenId *BigInt, number int64) (props *WRC721Properties, _ error) {
+	var id *big.Int
+	if tokenId != nil {
+		id = tokenId.bigint
+	}
+	rawProps, err := tc.client.WRC721Properties(ctx.context, tokenAddr.address, id, toBlockNumber(number))
+	return &WRC721Properties{rawProps}, err
+}
+
+// GetBalanceOf returns the token balance of the owner.
+// If number is <0, the balance is taken from the latest known block.
+func (tc *TokenClient) GetBalanceOf(ctx *Context, tokenAddr *Address, owner *Address, number int64) (balance *BigInt, _ error) {
+	rawBalance, err := tc.client.BalanceOf(ctx.context, tokenAddr.address, owner.address, toBlockNumber(number))
+	return &BigInt{rawBalance}, err
+}
+
+// GetAllowance returns the amount of the WRC-20 token the spender is allowed to withdraw from the owner.
+// If number is <0, the allowance is taken from the latest known block.
+func (tc *TokenClient) GetAllowance(ctx *Context, tokenAddr *Address, owner *Address, spender *Address, number int64) (allowance *BigInt, _ error) {
+	rawAllowance, err := tc.client.Allowance(ctx.context, tokenAddr.address, owner.address, spender.address, toBlockNumber(number))
+	return &BigInt{rawAllowance}, err
+}
+
+// WRC20TransferData returns the data of a transaction transferring WRC-20 tokens to the address.
+func (tc *TokenClient) WRC20TransferData(ctx *Context, to *Address, value *BigInt) (data []byte, _ error) {
+	return tc.client.WRC20TransferData(ctx.context, to.address, value.bigint)
+}
+
+// WRC20ApproveData returns the data of a transaction allowing the spender to withdraw WRC-20 tokens.
+func (tc *TokenClient) WRC20ApproveData(ctx *Context, spender *Address, value *BigInt) (data []byte, _ error) {
+	return tc.client.WRC20ApproveData(ctx.context, spender.address, value.bigint)
+}
+
+// WRC721TransferFromData returns the data of a transaction transferring the NFT between the addresses.
+func (tc *TokenClient) WRC721TransferFromData(ctx *Context, from *Address, to *Address, tokenId *BigInt) (data []byte, _ error) {
+	return tc.client.WRC721TransferFromData(ctx.context, from.address, to.address, tokenId.bigint)
+}
+
+// toBlockNumber converts the mobile block number, which is <0 for the latest block.
+func toBlockNumber(number int64) *big.Int {
+	if number < 0 {
+		return nil
+	}
+	return big.NewInt(number)
+}
@@ -199,6 +199,10 @@ type Server struct {
 
 	// State of run loop and listenLoop.
 	inboundHistory expHeap
+
+	// Temporarily banned nodes.
+	bannedLock sync.Mutex
+	banned     map[enode.ID]mclock.AbsTime
 }
 
 type peerOpFunc func(map[enode.ID]*Peer)
@@ -355,6 +359,40 @@ func (srv *Server) RemovePeer(node *enode.Node) {
 	}
 }
 
+// BanPeer disconnects the given node if it is currently connected as a peer and
+// rejects its connections for the given duration. Unlike RemovePeer it doesn't wait
+// for the peer connection to end, so it is safe to use in protocol implementations.
+func (srv *Server) BanPeer(id enode.ID, duration time.Duration) {
+	srv.bannedLock.Lock()
+	if srv.banned == nil {
+		srv.banned = make(map[enode.ID]mclock.AbsTime)
+	}
+	srv.banned[id] = srv.clock.Now().Add(duration)
+	srv.bannedLock.Unlock()
+
+	srv.doPeerOp(func(peers map[enode.ID]*Peer) {
+		if peer := peers[id]; peer != nil {
+			peer.Disconnect(DiscUselessPeer)
+		}
+	})
+}
+
+// IsBanned returns true if the given node is temporarily banned.
+func (srv *Server) IsBanned(id enode.ID) bool {
+	srv.bannedLock.Lock()
+	defer srv.bannedLock.Unlock()
+
+	until, ok := srv.banned[id]
+	if !ok {
+		return false
+	}
+	if srv.clock.Now() >= until {
+		delete(srv.banned, id)
+		return false
+	}
+	return true
+}
+
 // AddTrustedPeer adds the given node to a reserved trusted list which allows the
 // node to always connect, even if the slot are full.
 func (srv *Server) AddTrustedPeer(node *enode.Node) {
@@ -814,6 +852,8 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case !c.is(trustedConn) && srv.IsBanned(c.node.ID()):
+		return DiscUselessPeer
 	default:
 		return nil
 	}
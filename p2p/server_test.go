@@ -233,6 +233,51 @@ func TestServerRemovePeerDisconnect(t *testing.T) {
 	}
 }
 
+// This test checks that BanPeer disconnects the peer and rejects it until the ban expires.
+func TestServerBanPeer(t *testing.T) {
+	srv1 := &Server{Config: Config{
+		PrivateKey:  newkey(),
+		MaxPeers:    1,
+		NoDiscovery: true,
+		Logger:      testlog.Logger(t, log.LvlTrace).New("server", "1"),
+	}}
+	srv2 := &Server{Config: Config{
+		PrivateKey:  newkey(),
+		MaxPeers:    1,
+		NoDiscovery: true,
+		NoDial:      true,
+		ListenAddr:  "127.0.0.1:0",
+		Logger:      testlog.Logger(t, log.LvlTrace).New("server", "2"),
+	}}
+	srv1.Start()
+	defer srv1.Stop()
+	srv2.Start()
+	defer srv2.Stop()
+
+	if !syncAddPeer(srv1, srv2.Self()) {
+		t.Fatal("peer not connected")
+	}
+	srv1.BanPeer(srv2.Self().ID(), time.Hour)
+	for start := time.Now(); srv1.PeerCount() > 0; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("banned peer still connected")
+		}
+	}
+	if !srv1.IsBanned(srv2.Self().ID()) {
+		t.Fatal("peer not banned")
+	}
+	c := &conn{node: srv2.Self(), flags: dynDialedConn}
+	if err := srv1.postHandshakeChecks(map[enode.ID]*Peer{}, 0, c); err != DiscUselessPeer {
+		t.Fatalf("wrong error for banned peer: %v", err)
+	}
+
+	// expired ban
+	srv1.BanPeer(srv2.Self().ID(), 0)
+	if srv1.IsBanned(srv2.Self().ID()) {
+		t.Fatal("expired ban still active")
+	}
+}
+
 // This test checks that connections are disconnected just after the encryption handshake
 // when the server is at capacity. Trusted connections should still be accepted.
 func TestServerAtCap(t *testing.T) {
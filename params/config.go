@@ -59,23 +59,25 @@ var (
 	validatorsStateAddress = common.HexToAddress("0x329c3A3d65Ab0bE08c6eff6695933391Cfc02cCA")
 	// MainnetChainConfig is the chain parameters to run a node on the main network.
 	MainnetChainConfig = &ChainConfig{
-		ChainID:                big.NewInt(181),
-		SecondsPerSlot:         6,
-		SlotsPerEpoch:          32,
-		EpochsPerEra:           16,
-		TransitionPeriod:       2,
-		ValidatorsStateAddress: &validatorsStateAddress,
-		ValidatorsPerSlot:      8,
-		EffectiveBalance:       big.NewInt(32000),
-		ValidatorOpExpireSlots: 14400,
-		ForkSlotSubNet1:        math.MaxUint64,
-		ForkSlotDelegate:       0,
-		ForkSlotPrefixFin:      0,
-		ForkSlotShanghai:       0,
-		ForkSlotValOpTracking:  216000,
-		ForkSlotReduceBaseFee:  216000,
-		ForkSlotValSyncProc:    math.MaxUint64,
-		StartEpochsPerEra:      0,
+		ChainID:                     big.NewInt(181),
+		SecondsPerSlot:              6,
+		SlotsPerEpoch:               32,
+		EpochsPerEra:                16,
+		TransitionPeriod:            2,
+		ValidatorsStateAddress:      &validatorsStateAddress,
+		ValidatorsPerSlot:           8,
+		EffectiveBalance:            big.NewInt(32000),
+		ValidatorOpExpireSlots:      14400,
+		ForkSlotSubNet1:             math.MaxUint64,
+		ForkSlotDelegate:            0,
+		ForkSlotPrefixFin:           0,
+		ForkSlotShanghai:            0,
+		ForkSlotValOpTracking:       216000,
+		ForkSlotReduceBaseFee:       216000,
+		ForkSlotValSyncProc:         math.MaxUint64,
+		ForkSlotBatchDeposit:        math.MaxUint64,
+		ForkSlotDelegatorWithdrawal: math.MaxUint64,
+		StartEpochsPerEra:           0,
 	}
 
 	// MainnetTrustedCheckpoint contains the light client trusted checkpoint for the main network.
@@ -101,24 +103,26 @@ var (
 
 	// Testnet8ChainConfig contains the chain parameters to run a node on the Testnet8.
 	Testnet8ChainConfig = &ChainConfig{
-		ChainID:                big.NewInt(8601152),
-		SecondsPerSlot:         4,
-		SlotsPerEpoch:          32,
-		EpochsPerEra:           16,
-		TransitionPeriod:       2,
-		ValidatorsStateAddress: nil,
-		ValidatorsPerSlot:      5,
-		EffectiveBalance:       big.NewInt(3200),
-		ValidatorOpExpireSlots: 21600,
-		ForkSlotSubNet1:        math.MaxUint64,
-		ForkSlotDelegate:       2729920,
-		ForkSlotPrefixFin:      4058240,
-		ForkSlotShanghai:       math.MaxUint64,
-		ForkSlotValOpTracking:  math.MaxUint64,
-		ForkSlotReduceBaseFee:  math.MaxUint64,
-		ForkSlotValSyncProc:    math.MaxUint64,
-		StartEpochsPerEra:      math.MaxUint64,
-		AcceptCpRootOnFinEpoch: testnet8AcceptCpRootOnFinEpoch,
+		ChainID:                     big.NewInt(8601152),
+		SecondsPerSlot:              4,
+		SlotsPerEpoch:               32,
+		EpochsPerEra:                16,
+		TransitionPeriod:            2,
+		ValidatorsStateAddress:      nil,
+		ValidatorsPerSlot:           5,
+		EffectiveBalance:            big.NewInt(3200),
+		ValidatorOpExpireSlots:      21600,
+		ForkSlotSubNet1:             math.MaxUint64,
+		ForkSlotDelegate:            2729920,
+		ForkSlotPrefixFin:           4058240,
+		ForkSlotShanghai:            math.MaxUint64,
+		ForkSlotValOpTracking:       math.MaxUint64,
+		ForkSlotReduceBaseFee:       math.MaxUint64,
+		ForkSlotValSyncProc:         math.MaxUint64,
+		ForkSlotBatchDeposit:        math.MaxUint64,
+		ForkSlotDelegatorWithdrawal: math.MaxUint64,
+		StartEpochsPerEra:           math.MaxUint64,
+		AcceptCpRootOnFinEpoch:      testnet8AcceptCpRootOnFinEpoch,
 	}
 
 	// TestNet8TrustedCheckpoint contains the light client trusted checkpoint for the Testnet8.
@@ -148,43 +152,47 @@ var (
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
 	AllEthashProtocolChanges = &ChainConfig{
-		ChainID:                big.NewInt(1337),
-		SecondsPerSlot:         4,
-		SlotsPerEpoch:          32,
-		EpochsPerEra:           8,
-		TransitionPeriod:       2,
-		ValidatorsStateAddress: nil,
-		ValidatorsPerSlot:      6,
-		EffectiveBalance:       big.NewInt(3200),
-		ValidatorOpExpireSlots: 14400,
-		ForkSlotSubNet1:        math.MaxUint64,
-		ForkSlotDelegate:       0,
-		ForkSlotPrefixFin:      0,
-		ForkSlotShanghai:       0,
-		ForkSlotValOpTracking:  0,
-		ForkSlotReduceBaseFee:  0,
-		ForkSlotValSyncProc:    math.MaxUint64,
-		StartEpochsPerEra:      0,
+		ChainID:                     big.NewInt(1337),
+		SecondsPerSlot:              4,
+		SlotsPerEpoch:               32,
+		EpochsPerEra:                8,
+		TransitionPeriod:            2,
+		ValidatorsStateAddress:      nil,
+		ValidatorsPerSlot:           6,
+		EffectiveBalance:            big.NewInt(3200),
+		ValidatorOpExpireSlots:      14400,
+		ForkSlotSubNet1:             math.MaxUint64,
+		ForkSlotDelegate:            0,
+		ForkSlotPrefixFin:           0,
+		ForkSlotShanghai:            0,
+		ForkSlotValOpTracking:       0,
+		ForkSlotReduceBaseFee:       0,
+		ForkSlotValSyncProc:         math.MaxUint64,
+		ForkSlotBatchDeposit:        0,
+		ForkSlotDelegatorWithdrawal: 0,
+		StartEpochsPerEra:           0,
 	}
 
 	TestChainConfig = &ChainConfig{
-		ChainID:                big.NewInt(1337),
-		SecondsPerSlot:         4,
-		SlotsPerEpoch:          32,
-		EpochsPerEra:           8,
-		TransitionPeriod:       2,
-		ValidatorsStateAddress: nil,
-		ValidatorsPerSlot:      6,
-		EffectiveBalance:       big.NewInt(3200),
-		ValidatorOpExpireSlots: 14400,
-		ForkSlotSubNet1:        math.MaxUint64,
-		ForkSlotDelegate:       0,
-		ForkSlotPrefixFin:      0,
-		ForkSlotShanghai:       0,
-		ForkSlotValOpTracking:  0,
-		ForkSlotReduceBaseFee:  0,
-		ForkSlotValSyncProc:    math.MaxUint64,
-		StartEpochsPerEra:      0,
+		ChainID:                     big.NewInt(1337),
+		SecondsPerSlot:              4,
+		SlotsPerEpoch:               32,
+		EpochsPerEra:                8,
+		TransitionPeriod:            2,
+		ValidatorsStateAddress:      nil,
+		ValidatorsPerSlot:           6,
+		EffectiveBalance:            big.NewInt(3200),
+		ValidatorOpExpireSlots:      14400,
+		ForkSlotSubNet1:             math.MaxUint64,
+		ForkSlotDelegate:            0,
+		ForkSlotPrefixFin:           0,
+		ForkSlotShanghai:            0,
+		ForkSlotValOpTracking:       0,
+		ForkSlotReduceBaseFee:       0,
+		ForkSlotValSyncProc:         math.MaxUint64,
+		ForkSlotBatchDeposit:        0,
+		ForkSlotDelegatorWithdrawal: 0,
+		StartEpochsPerEra:           0,
 	}
 )
 
@@ -253,13 +261,15 @@ type ChainConfig struct {
 	EffectiveBalance       *big.Int `json:"effectiveBalance"`
 	ValidatorOpExpireSlots uint64   `json:"validatorOpExpireSlots"`
 	// Fork slots
-	ForkSlotSubNet1       uint64 `json:"forkSlotSubNet1,omitempty"`
-	ForkSlotDelegate      uint64 `json:"forkSlotDelegate,omitempty"`
-	ForkSlotPrefixFin     uint64 `json:"forkSlotPrefixFin,omitempty"`
-	ForkSlotShanghai      uint64 `json:"forkSlotShanghai,omitempty"`
-	ForkSlotValOpTracking uint64 `json:"forkSlotValOpTracking,omitempty"`
-	ForkSlotReduceBaseFee uint64 `json:"forkSlotReduceBaseFee,omitempty"`
-	ForkSlotValSyncProc   uint64 `json:"forkSlotValSyncProc,omitempty"`
+	ForkSlotSubNet1             uint64 `json:"forkSlotSubNet1,omitempty"`
+	ForkSlotDelegate            uint64 `json:"forkSlotDelegate,omitempty"`
+	ForkSlotPrefixFin           uint64 `json:"forkSlotPrefixFin,omitempty"`
+	ForkSlotShanghai            uint64 `json:"forkSlotShanghai,omitempty"`
+	ForkSlotValOpTracking       uint64 `json:"forkSlotValOpTracking,omitempty"`
+	ForkSlotReduceBaseFee       uint64 `json:"forkSlotReduceBaseFee,omitempty"`
+	ForkSlotValSyncProc         uint64 `json:"forkSlotValSyncProc,omitempty"`
+	ForkSlotBatchDeposit        uint64 `json:"forkSlotBatchDeposit,omitempty"`
+	ForkSlotDelegatorWithdrawal uint64 `json:"forkSlotDelegatorWithdrawal,omitempty"`
 	// Fork eras
 	StartEpochsPerEra uint64 `json:"startEpochsPerEra"`
 
@@ -289,7 +299,7 @@ func (c *CliqueConfig) String() string {
 func (c *ChainConfig) String() string {
 	return fmt.Sprintf("{ChainID: %v, SecondsPerSlot: %v, SlotsPerEpoch: %v, EpochsPerEra: %v, TransitionPeriod: %v, "+
 		"ValidatorsPerSlot %v, ValidatorsStateAddress %v, EffectiveBalance: %v, ValidatorOpExpireSlots: %v, ForkSlotSubNet1: %v, ForkSlotDelegate: %v, "+
-		"ForkSlotPrefixFin: %v, ForkSlotShanghai: %v, ForkSlotValOpTracking: %v, ForkSlotReduceBaseFee: %v, ForkSlotValSyncProc: %v, StartEpochsPerEra: %v, AcceptCpRootOnFinEpoch: %v}",
+		"ForkSlotPrefixFin: %v, ForkSlotShanghai: %v, ForkSlotValOpTracking: %v, ForkSlotReduceBaseFee: %v, ForkSlotValSyncProc: %v, ForkSlotBatchDeposit: %v, ForkSlotDelegatorWithdrawal: %v, StartEpochsPerEra: %v, AcceptCpRootOnFinEpoch: %v}",
 		c.ChainID,
 		c.SecondsPerSlot,
 		c.SlotsPerEpoch,
@@ -306,6 +316,8 @@ func (c *ChainConfig) String() string {
 		c.ForkSlotValOpTracking,
 		c.ForkSlotReduceBaseFee,
 		c.ForkSlotValSyncProc,
+		c.ForkSlotBatchDeposit,
+		c.ForkSlotDelegatorWithdrawal,
 		c.StartEpochsPerEra,
 		c.AcceptCpRootOnFinEpoch,
 	)
@@ -341,6 +353,16 @@ func (c *ChainConfig) IsForkSlotValSyncProc(slot uint64) bool {
 	return slot >= c.ForkSlotValSyncProc
 }
 
+// IsForkSlotBatchDeposit returns true if provided slot greater or equal of the fork slot ForkSlotBatchDeposit.
+func (c *ChainConfig) IsForkSlotBatchDeposit(slot uint64) bool {
+	return slot >= c.ForkSlotBatchDeposit
+}
+
+// IsForkSlotDelegatorWithdrawal returns true if provided slot greater or equal of the fork slot ForkSlotDelegatorWithdrawal.
+func (c *ChainConfig) IsForkSlotDelegatorWithdrawal(slot uint64) bool {
+	return slot >= c.ForkSlotDelegatorWithdrawal
+}
+
 // CheckConfigForkOrder checks that we don't "skip" any forks, geth isn't pluggable enough
 // to guarantee that forks can be implemented in a different order than on official networks
 func (c *ChainConfig) CheckConfigForkOrder() error {
@@ -471,6 +493,8 @@ func OverrideTestnet5(conf *ChainConfig) *ChainConfig {
 	conf.ForkSlotValOpTracking = 0
 	conf.ForkSlotReduceBaseFee = 0
 	conf.ForkSlotValSyncProc = math.MaxUint64
+	conf.ForkSlotBatchDeposit = math.MaxUint64
+	conf.ForkSlotDelegatorWithdrawal = math.MaxUint64
 	//conf.StartEpochsPerEra = 0
 	//conf.AcceptCpRootOnFinEpoch = nil
 
@@ -496,6 +520,8 @@ func OverrideTestnet9(conf *ChainConfig) *ChainConfig {
 	conf.ForkSlotValOpTracking = 0
 	conf.ForkSlotReduceBaseFee = 0
 	conf.ForkSlotValSyncProc = math.MaxUint64
+	conf.ForkSlotBatchDeposit = math.MaxUint64
+	conf.ForkSlotDelegatorWithdrawal = math.MaxUint64
 	conf.StartEpochsPerEra = 0
 	//conf.AcceptCpRootOnFinEpoch = nil
 
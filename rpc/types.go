@@ -59,6 +59,7 @@ type jsonWriter interface {
 type BlockNumber int64
 
 const (
+	FinalizedBlockNumber  = BlockNumber(-4)
 	CheckpointBlockNumber = BlockNumber(-3)
 	PendingBlockNumber    = BlockNumber(-2)
 	LatestBlockNumber     = BlockNumber(-1)
@@ -67,6 +68,8 @@ const (
 
 // UnmarshalJSON parses the given JSON fragment into a BlockNumber. It supports:
 // - "latest", "earliest" or "pending" as string arguments
+// - "finalized" for the last finalized block
+// - "checkpoint" (or "final") for the spine of the last coordinated checkpoint
 // - the block number
 // Returned errors:
 // - an invalid block number error when the given argument isn't a known strings
@@ -87,7 +90,10 @@ func (bn *BlockNumber) UnmarshalJSON(data []byte) error {
 	case "pending":
 		*bn = PendingBlockNumber
 		return nil
-	case "final":
+	case "finalized":
+		*bn = FinalizedBlockNumber
+		return nil
+	case "checkpoint", "final":
 		*bn = CheckpointBlockNumber
 		return nil
 	}
@@ -104,7 +110,7 @@ func (bn *BlockNumber) UnmarshalJSON(data []byte) error {
 }
 
 // MarshalText implements encoding.TextMarshaler. It marshals:
-// - "latest", "earliest" or "pending" as strings
+// - "latest", "earliest", "pending", "finalized" or "checkpoint" as strings
 // - other numbers as hex
 func (bn BlockNumber) MarshalText() ([]byte, error) {
 	switch bn {
@@ -114,6 +120,10 @@ func (bn BlockNumber) MarshalText() ([]byte, error) {
 		return []byte("latest"), nil
 	case PendingBlockNumber:
 		return []byte("pending"), nil
+	case FinalizedBlockNumber:
+		return []byte("finalized"), nil
+	case CheckpointBlockNumber:
+		return []byte("checkpoint"), nil
 	default:
 		return hexutil.Uint64(bn).MarshalText()
 	}
@@ -160,7 +170,11 @@ func (bnh *BlockNumberOrHash) UnmarshalJSON(data []byte) error {
 		bn := PendingBlockNumber
 		bnh.BlockNumber = &bn
 		return nil
-	case "final":
+	case "finalized":
+		bn := FinalizedBlockNumber
+		bnh.BlockNumber = &bn
+		return nil
+	case "checkpoint", "final":
 		bn := CheckpointBlockNumber
 		bnh.BlockNumber = &bn
 		return nil
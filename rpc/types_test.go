@@ -48,6 +48,9 @@ func TestBlockNumberJSONUnmarshal(t *testing.T) {
 		14: {`someString`, true, BlockNumber(0)},
 		15: {`""`, true, BlockNumber(0)},
 		16: {``, true, BlockNumber(0)},
+		17: {`"finalized"`, false, FinalizedBlockNumber},
+		18: {`"checkpoint"`, false, CheckpointBlockNumber},
+		19: {`"final"`, false, CheckpointBlockNumber},
 	}
 
 	for i, test := range tests {
@@ -99,6 +102,10 @@ func TestBlockNumberOrHash_UnmarshalJSON(t *testing.T) {
 		23: {`{"blockNumber":"latest"}`, false, BlockNumberOrHashWithNumber(LatestBlockNumber)},
 		24: {`{"blockNumber":"earliest"}`, false, BlockNumberOrHashWithNumber(EarliestBlockNumber)},
 		25: {`{"blockNumber":"0x1", "blockHash":"0x0000000000000000000000000000000000000000000000000000000000000000"}`, true, BlockNumberOrHash{}},
+		26: {`"finalized"`, false, BlockNumberOrHashWithNumber(FinalizedBlockNumber)},
+		27: {`"checkpoint"`, false, BlockNumberOrHashWithNumber(CheckpointBlockNumber)},
+		28: {`{"blockNumber":"finalized"}`, false, BlockNumberOrHashWithNumber(FinalizedBlockNumber)},
+		29: {`{"blockNumber":"checkpoint"}`, false, BlockNumberOrHashWithNumber(CheckpointBlockNumber)},
 	}
 
 	for i, test := range tests {
@@ -133,6 +140,8 @@ func TestBlockNumberOrHash_WithNumber_MarshalAndUnmarshal(t *testing.T) {
 		{"pending", int64(PendingBlockNumber)},
 		{"latest", int64(LatestBlockNumber)},
 		{"earliest", int64(EarliestBlockNumber)},
+		{"finalized", int64(FinalizedBlockNumber)},
+		{"checkpoint", int64(CheckpointBlockNumber)},
 	}
 	for _, test := range tests {
 		test := test
@@ -122,6 +122,7 @@ type Processor struct {
 	state        vm.StateDB
 	ctx          vm.BlockContext
 	eventEmmiter *EventEmmiter
+	tracer       *vm.NativeOpFrame
 }
 
 // NewProcessor creates new token processor
@@ -148,6 +149,9 @@ func NewProcessor(blockCtx vm.BlockContext, stateDb vm.StateDB) *Processor {
 //
 // It returns byte representation of the return value of an operation.
 func (p *Processor) Call(caller Ref, token common.Address, value *big.Int, op operation.Operation) (ret []byte, err error) {
+	if p.tracer != nil {
+		p.tracer.SetOp(OperationArgs(op))
+	}
 	if _, isCreate := op.(operation.Create); isCreate {
 		if token != (common.Address{}) {
 			return nil, ErrNotNilTo
@@ -238,6 +242,7 @@ func (p *Processor) tokenCreate(caller Ref, tokenAddr common.Address, op operati
 	if err != nil {
 		return nil, err
 	}
+	storage = p.traceStorage(storage)
 
 	stdB, err := op.Standard().MarshalBinary()
 	if err != nil {
@@ -1182,6 +1187,7 @@ func (p *Processor) newStorageWithoutStdCheck(token common.Address) (tokenStorag
 	if err != nil {
 		return nil, operation.Std(0), err
 	}
+	storage = p.traceStorage(storage)
 
 	var stdB []byte
 	err = storage.ReadField(StandardField, &stdB)
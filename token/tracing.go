@@ -0,0 +1,165 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/holiman/uint256"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/vm"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/token/operation"
+	tokenStorage "gitlab.waterfall.network/waterfall/protocol/gwat/token/storage"
+)
+
+// WithTracer returns a copy of the processor which records the decoded
+// operations, the storage fields read and written, the balance movements
+// and the emitted logs into the trace frame.
+func (p *Processor) WithTracer(frame *vm.NativeOpFrame) *Processor {
+	state := vm.NewNativeOpStateDB(p.state, frame)
+	return &Processor{
+		ctx:          p.ctx,
+		state:        state,
+		eventEmmiter: NewEventEmmiter(state),
+		tracer:       frame,
+	}
+}
+
+// traceStorage wraps the storage to record the accessed fields if the processor is traced.
+func (p *Processor) traceStorage(storage tokenStorage.Storage) tokenStorage.Storage {
+	if p.tracer == nil {
+		return storage
+	}
+	return &tracingStorage{Storage: storage, frame: p.tracer}
+}
+
+// tracingStorage records the fields read and written into the trace frame.
+type tracingStorage struct {
+	tokenStorage.Storage
+	frame *vm.NativeOpFrame
+}
+
+func (s *tracingStorage) ReadField(name string, toPtr interface{}) error {
+	err := s.Storage.ReadField(name, toPtr)
+	if err == nil {
+		key, value := traceField(toPtr)
+		s.frame.CaptureStorage(name, key, value, false)
+	}
+	return err
+}
+
+func (s *tracingStorage) WriteField(name string, val interface{}) error {
+	err := s.Storage.WriteField(name, val)
+	if err == nil {
+		key, value := traceField(val)
+		s.frame.CaptureStorage(name, key, value, true)
+	}
+	return err
+}
+
+// traceField splits the field value into the map key, if any, and the value
+// formatted for the trace output.
+func traceField(v interface{}) (key, value interface{}) {
+	if kv, ok := v.(*tokenStorage.KeyValuePair); ok {
+		return traceValue(kv.Key()), traceValue(kv.Value())
+	}
+	return nil, traceValue(v)
+}
+
+func traceValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return hexutil.Bytes(v)
+	case *uint256.Int:
+		return (*hexutil.Big)(v.ToBig())
+	case *big.Int:
+		return (*hexutil.Big)(v)
+	case uint8:
+		return hexutil.Uint64(v)
+	case operation.Std:
+		return hexutil.Uint64(v)
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		return traceValue(rv.Elem().Interface())
+	}
+	return v
+}
+
+// OperationArgs returns the name and the arguments of the token operation.
+func OperationArgs(op operation.Operation) (string, map[string]interface{}) {
+	args := map[string]interface{}{"std": hexutil.Uint64(op.Standard())}
+	switch v := op.(type) {
+	case operation.Create:
+		args["name"] = hexutil.Bytes(v.Name())
+		args["symbol"] = hexutil.Bytes(v.Symbol())
+		switch op.Standard() {
+		case operation.StdWRC20:
+			args["decimals"] = hexutil.Uint64(v.Decimals())
+			if totalSupply, ok := v.TotalSupply(); ok {
+				args["totalSupply"] = (*hexutil.Big)(totalSupply)
+			}
+		case operation.StdWRC721:
+			if baseURI, ok := v.BaseURI(); ok {
+				args["baseURI"] = hexutil.Bytes(baseURI)
+			}
+			args["percentFee"] = hexutil.Uint64(v.PercentFee())
+		}
+		return "create", args
+	case operation.TransferFrom:
+		args["from"] = v.From()
+		args["to"] = v.To()
+		args["value"] = (*hexutil.Big)(v.Value())
+		return "transferFrom", args
+	case operation.Transfer:
+		args["to"] = v.To()
+		args["value"] = (*hexutil.Big)(v.Value())
+		return "transfer", args
+	case operation.Approve:
+		args["spender"] = v.Spender()
+		args["value"] = (*hexutil.Big)(v.Value())
+		return "approve", args
+	case operation.Mint:
+		args["to"] = v.To()
+		args["tokenId"] = (*hexutil.Big)(v.TokenId())
+		if metadata, ok := v.Metadata(); ok {
+			args["metadata"] = hexutil.Bytes(metadata)
+		}
+		return "mint", args
+	case operation.Buy:
+		if tokenId, ok := v.TokenId(); ok {
+			args["tokenId"] = (*hexutil.Big)(tokenId)
+		}
+		if newCost, ok := v.NewCost(); ok {
+			args["newCost"] = (*hexutil.Big)(newCost)
+		}
+		return "buy", args
+	case operation.SetPrice:
+		if tokenId, ok := v.TokenId(); ok {
+			args["tokenId"] = (*hexutil.Big)(tokenId)
+		}
+		args["value"] = (*hexutil.Big)(v.Value())
+		return "setPrice", args
+	case operation.Burn:
+		args["tokenId"] = (*hexutil.Big)(v.TokenId())
+		return "burn", args
+	case operation.SetApprovalForAll:
+		args["operator"] = v.Operator()
+		args["isApproved"] = v.IsApproved()
+		return "setApprovalForAll", args
+	}
+	return "unknown", args
+}
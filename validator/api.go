@@ -102,8 +102,71 @@ type DelegatingRulesArgs struct {
 	Withdrawal  *[]common.Address         `json:"withdrawal"`   // addresses of role  to init exit
 }
 
+// DelegatingStakeData converts the arguments to the delegating stake data of deposit operation.
+func (args *DelegatingStakeArgs) DelegatingStakeData() (*operation.DelegatingStakeData, error) {
+	if args.Rules == nil {
+		return nil, operation.ErrNoRules
+	}
+	trialPeriod := uint64(0)
+	if args.TrialPeriod != nil {
+		trialPeriod = *args.TrialPeriod
+	}
+	trialRulesArgs := args.TrialRules
+	if trialRulesArgs == nil {
+		trialRulesArgs = &DelegatingRulesArgs{}
+	}
+	rules, err := args.Rules.DelegatingStakeRules()
+	if err != nil {
+		return nil, err
+	}
+	trialRules, err := trialRulesArgs.DelegatingStakeRules()
+	if err != nil {
+		return nil, err
+	}
+	return operation.NewDelegatingStakeData(rules, trialPeriod, trialRules)
+}
+
+// DelegatingStakeRules converts the arguments to the delegating stake rules.
+// Omitted fields are treated as empty.
+func (args *DelegatingRulesArgs) DelegatingStakeRules() (*operation.DelegatingStakeRules, error) {
+	var (
+		profitShare map[common.Address]uint8
+		stakeShare  map[common.Address]uint8
+		exit        []common.Address
+		withdrawal  []common.Address
+	)
+	if args.ProfitShare != nil {
+		profitShare = *args.ProfitShare
+	}
+	if args.StakeShare != nil {
+		stakeShare = *args.StakeShare
+	}
+	if args.Exit != nil {
+		exit = *args.Exit
+	}
+	if args.Withdrawal != nil {
+		withdrawal = *args.Withdrawal
+	}
+	return operation.NewDelegatingStakeRules(profitShare, stakeShare, exit, withdrawal)
+}
+
 // Validator_DepositData creates a validators deposit data for deposit tx.
 func (s *PublicValidatorAPI) Validator_DepositData(_ context.Context, args DepositArgs) (hexutil.Bytes, error) {
+	op, err := args.depositOperation()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := operation.EncodeToBytes(op)
+	if err != nil {
+		log.Warn("Failed to encode validator deposit operation", "err", err)
+		return nil, err
+	}
+	return b, nil
+}
+
+// depositOperation validates the arguments and creates the deposit operation.
+func (args *DepositArgs) depositOperation() (operation.Deposit, error) {
 	if args.PubKey == nil {
 		return nil, operation.ErrNoPubKey
 	}
@@ -118,46 +181,51 @@ func (s *PublicValidatorAPI) Validator_DepositData(_ context.Context, args Depos
 	}
 
 	var (
-		op                operation.Operation
-		err               error
-		delegatingStake   *operation.DelegatingStakeData
-		rules, trialRules *operation.DelegatingStakeRules
+		err             error
+		delegatingStake *operation.DelegatingStakeData
 	)
 	if args.DelegatingStake != nil {
-		dlgStakeArg := args.DelegatingStake
-		if dlgStakeArg.Rules == nil {
-			return nil, operation.ErrNoRules
-		}
-		if dlgStakeArg.TrialPeriod == nil {
-			def := uint64(0)
-			dlgStakeArg.TrialPeriod = &def
-		}
-		if dlgStakeArg.TrialRules == nil {
-			dlgStakeArg.TrialRules = &DelegatingRulesArgs{}
-		}
-		ar := dlgStakeArg.Rules
-		rules, err = operation.NewDelegatingStakeRules(*ar.ProfitShare, *ar.StakeShare, *ar.Exit, *ar.Withdrawal)
-		if err != nil {
+		if delegatingStake, err = args.DelegatingStake.DelegatingStakeData(); err != nil {
 			return nil, err
 		}
-		atr := dlgStakeArg.TrialRules
-		trialRules, err = operation.NewDelegatingStakeRules(*atr.ProfitShare, *atr.StakeShare, *atr.Exit, *atr.Withdrawal)
+	}
+	return operation.NewDepositOperation(*args.PubKey, *args.CreatorAddress, *args.WithdrawalAddress, *args.Signature, delegatingStake)
+}
+
+type BatchDepositArgs struct {
+	DepositArgs
+	Amount *hexutil.Big `json:"amount"` // deposit value of the validator
+}
+
+// Validator_BatchDepositData creates a deposit data of several validators for batch deposit tx.
+// The value of the tx must be equal to the sum of amounts of all deposits.
+func (s *PublicValidatorAPI) Validator_BatchDepositData(_ context.Context, args []BatchDepositArgs) (hexutil.Bytes, error) {
+	entries := make([]*operation.BatchDepositEntry, len(args))
+	for i, arg := range args {
+		if arg.Amount == nil {
+			return nil, &BatchDepositError{Index: i, Err: operation.ErrNoAmount}
+		}
+		dop, err := arg.depositOperation()
 		if err != nil {
-			return nil, err
+			var creator common.Address
+			if arg.CreatorAddress != nil {
+				creator = *arg.CreatorAddress
+			}
+			return nil, &BatchDepositError{Index: i, Creator: creator, Err: err}
 		}
-
-		if delegatingStake, err = operation.NewDelegatingStakeData(rules, *dlgStakeArg.TrialPeriod, trialRules); err != nil {
-			return nil, err
+		entries[i] = &operation.BatchDepositEntry{
+			Deposit: dop,
+			Value:   (*big.Int)(arg.Amount),
 		}
 	}
-
-	if op, err = operation.NewDepositOperation(*args.PubKey, *args.CreatorAddress, *args.WithdrawalAddress, *args.Signature, delegatingStake); err != nil {
+	op, err := operation.NewBatchDepositOperation(entries)
+	if err != nil {
 		return nil, err
 	}
 
 	b, err := operation.EncodeToBytes(op)
 	if err != nil {
-		log.Warn("Failed to encode validator deposit operation", "err", err)
+		log.Warn("Failed to encode validator batch deposit operation", "err", err)
 		return nil, err
 	}
 	return b, nil
@@ -254,6 +322,45 @@ func (s *PublicValidatorAPI) Validator_WithdrawalData(args WithdrawalArgs) (hexu
 	return b, nil
 }
 
+type DelegatorWithdrawalArgs struct {
+	CreatorAddress   *common.Address `json:"creator_address"`
+	DelegatorAddress *common.Address `json:"delegator_address"`
+	Amount           *hexutil.Big    `json:"amount"`
+}
+
+// Validator_DelegatorWithdrawalData creates a data of withdrawal tx of the delegator's stake.
+// Zero amount means withdrawal of the whole stake of the delegator.
+func (s *PublicValidatorAPI) Validator_DelegatorWithdrawalData(args DelegatorWithdrawalArgs) (hexutil.Bytes, error) {
+	if args.CreatorAddress == nil {
+		return nil, operation.ErrNoCreatorAddress
+	}
+
+	if args.DelegatorAddress == nil {
+		return nil, operation.ErrNoDelegatorAddress
+	}
+
+	if args.Amount == nil {
+		return nil, operation.ErrNoAmount
+	}
+
+	op, err := operation.NewDelegatorWithdrawalOperation(
+		*args.CreatorAddress,
+		*args.DelegatorAddress,
+		(*big.Int)(args.Amount),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := operation.EncodeToBytes(op)
+	if err != nil {
+		log.Warn("Failed to encode validator delegator withdrawal operation", "err", err)
+		return nil, err
+	}
+
+	return b, nil
+}
+
 func (s *PublicValidatorAPI) Validator_DepositAddress() hexutil.Bytes {
 	return s.b.ChainConfig().ValidatorsStateAddress[:]
 }
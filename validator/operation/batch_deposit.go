@@ -0,0 +1,156 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+)
+
+// MaxBatchDeposits is the max number of deposits in one batch deposit operation.
+const MaxBatchDeposits = 512
+
+// BatchDepositEntry is a single deposit of the batch deposit operation.
+type BatchDepositEntry struct {
+	Deposit Deposit
+	Value   *big.Int
+}
+
+// Copy returns a copy of the entry.
+func (e *BatchDepositEntry) Copy() *BatchDepositEntry {
+	if e == nil {
+		return nil
+	}
+	cpy := &BatchDepositEntry{Deposit: e.Deposit}
+	if e.Value != nil {
+		cpy.Value = new(big.Int).Set(e.Value)
+	}
+	return cpy
+}
+
+type batchDepositOperation struct {
+	deposits []*BatchDepositEntry
+}
+
+func (op *batchDepositOperation) init(deposits []*BatchDepositEntry) error {
+	if len(deposits) == 0 {
+		return ErrNoDeposits
+	}
+	if len(deposits) > MaxBatchDeposits {
+		return ErrTooManyDeposits
+	}
+	entries := make([]*BatchDepositEntry, len(deposits))
+	for i, e := range deposits {
+		if e == nil || e.Deposit == nil {
+			return ErrNoDeposits
+		}
+		if e.Value == nil || e.Value.Sign() <= 0 {
+			return ErrNoDepositValue
+		}
+		entries[i] = e.Copy()
+	}
+	op.deposits = entries
+	return nil
+}
+
+// NewBatchDepositOperation creates an operation for creating deposits of several validators
+func NewBatchDepositOperation(deposits []*BatchDepositEntry) (BatchDeposit, error) {
+	op := batchDepositOperation{}
+	if err := op.init(deposits); err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// UnmarshalBinary unmarshals a batch deposit operation from byte encoding.
+//
+// The encoding is: count(uint32) followed by entries
+// of valueLen(uint32) value depositLen(uint32) deposit.
+func (op *batchDepositOperation) UnmarshalBinary(b []byte) error {
+	if len(b) < common.Uint32Size {
+		return ErrBadDataLen
+	}
+	count := int(binary.BigEndian.Uint32(b[:common.Uint32Size]))
+	if count > MaxBatchDeposits {
+		return ErrTooManyDeposits
+	}
+	b = b[common.Uint32Size:]
+
+	deposits := make([]*BatchDepositEntry, 0, count)
+	for i := 0; i < count; i++ {
+		valueBin, rest, err := readLenPrefixed(b)
+		if err != nil {
+			return err
+		}
+		depositBin, rest, err := readLenPrefixed(rest)
+		if err != nil {
+			return err
+		}
+		b = rest
+
+		deposit := &depositOperation{}
+		if err = deposit.UnmarshalBinary(depositBin); err != nil {
+			return err
+		}
+		deposits = append(deposits, &BatchDepositEntry{
+			Deposit: deposit,
+			Value:   new(big.Int).SetBytes(valueBin),
+		})
+	}
+	if len(b) > 0 {
+		return ErrBadDataLen
+	}
+	return op.init(deposits)
+}
+
+// MarshalBinary marshals a batch deposit operation to byte encoding
+func (op *batchDepositOperation) MarshalBinary() ([]byte, error) {
+	bin := make([]byte, common.Uint32Size)
+	binary.BigEndian.PutUint32(bin, uint32(len(op.deposits)))
+	for _, e := range op.deposits {
+		depositBin, err := e.Deposit.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		bin = appendLenPrefixed(bin, e.Value.Bytes())
+		bin = appendLenPrefixed(bin, depositBin)
+	}
+	return bin, nil
+}
+
+// OpCode returns op code of a batch deposit operation
+func (op *batchDepositOperation) OpCode() Code {
+	return BatchDepositCode
+}
+
+// Deposits returns copies of the deposit entries.
+func (op *batchDepositOperation) Deposits() []*BatchDepositEntry {
+	entries := make([]*BatchDepositEntry, len(op.deposits))
+	for i, e := range op.deposits {
+		entries[i] = e.Copy()
+	}
+	return entries
+}
+
+// TotalValue returns the sum of values of all deposits.
+func (op *batchDepositOperation) TotalValue() *big.Int {
+	total := new(big.Int)
+	for _, e := range op.deposits {
+		total.Add(total, e.Value)
+	}
+	return total
+}
@@ -0,0 +1,95 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+func TestBatchDepositData(t *testing.T) {
+	var (
+		pubkey             = common.HexToBlsPubKey("0x9728bc733c8fcedde0c3a33dac12da3ebbaa0eb74d813a34b600520e7976a260d85f057687e8c923d52c78715515348d")
+		creator_address    = common.HexToAddress("0xa7e558cc6efa1c41270ef4aa227b3dd6b4a3951e")
+		withdrawal_address = common.HexToAddress("0x6e9e76fa278190cfb2404e5923d3ccd7e8f6c777")
+		signature          = common.HexToBlsSig("0xb9221f2308c1e1655a8e1977f32241384fa77efedbb3079bcc9a95930152ee87" +
+			"f341134a4e59c3e312ee5c2197732ea30d9aac2993cc4aad75335009815d07a8735f96c6dde443ba3a10f5523c4d00f6b3a7b48af" +
+			"5a42795183ab5aa2f1b2dd1")
+		value1 = big.NewInt(1000)
+		value2 = big.NewInt(2000)
+	)
+
+	rules, err := NewDelegatingStakeRules(TestParamsDelegatingStakeRules())
+	testutils.AssertNoError(t, err)
+	dlgStake, err := NewDelegatingStakeData(rules, 0, &DelegatingStakeRules{})
+	testutils.AssertNoError(t, err)
+	deposit1, err := NewDepositOperation(pubkey, creator_address, withdrawal_address, signature, nil)
+	testutils.AssertNoError(t, err)
+	deposit2, err := NewDepositOperation(pubkey, withdrawal_address, creator_address, signature, dlgStake)
+	testutils.AssertNoError(t, err)
+
+	op, err := NewBatchDepositOperation([]*BatchDepositEntry{
+		{Deposit: deposit1, Value: value1},
+		{Deposit: deposit2, Value: value2},
+	})
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, big.NewInt(3000), op.TotalValue())
+
+	data, err := EncodeToBytes(op)
+	testutils.AssertNoError(t, err)
+	testutils.AssertNoError(t, checkOpCode(data, op))
+
+	decoded, err := DecodeBytes(data)
+	testutils.AssertNoError(t, err)
+	batch, ok := decoded.(BatchDeposit)
+	if !ok {
+		t.Fatalf("decoded operation is not batch deposit: %T", decoded)
+	}
+	testutils.AssertEqual(t, op.Deposits(), batch.Deposits())
+	testutils.AssertNoError(t, equalOpBytes(batch, data))
+
+	// truncated data
+	_, err = DecodeBytes(data[:len(data)-1])
+	testutils.AssertError(t, err, ErrBadDataLen)
+	// trailing data
+	_, err = DecodeBytes(append(data, 0x00))
+	testutils.AssertError(t, err, ErrBadDataLen)
+}
+
+func TestBatchDepositData_Errors(t *testing.T) {
+	var (
+		pubkey    = common.HexToBlsPubKey("0x9728bc733c8fcedde0c3a33dac12da3ebbaa0eb74d813a34b600520e7976a260d85f057687e8c923d52c78715515348d")
+		address   = common.HexToAddress("0xa7e558cc6efa1c41270ef4aa227b3dd6b4a3951e")
+		signature = common.BytesToBlsSig(testutils.RandomData(96))
+	)
+	deposit, err := NewDepositOperation(pubkey, address, address, signature, nil)
+	testutils.AssertNoError(t, err)
+
+	_, err = NewBatchDepositOperation(nil)
+	testutils.AssertError(t, err, ErrNoDeposits)
+
+	_, err = NewBatchDepositOperation([]*BatchDepositEntry{{Deposit: deposit}})
+	testutils.AssertError(t, err, ErrNoDepositValue)
+
+	tooMany := make([]*BatchDepositEntry, MaxBatchDeposits+1)
+	for i := range tooMany {
+		tooMany[i] = &BatchDepositEntry{Deposit: deposit, Value: big.NewInt(1)}
+	}
+	_, err = NewBatchDepositOperation(tooMany)
+	testutils.AssertError(t, err, ErrTooManyDeposits)
+}
@@ -0,0 +1,106 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"math/big"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+)
+
+type delegatorWithdrawalOperation struct {
+	creatorAddress   common.Address
+	delegatorAddress common.Address
+	amount           *big.Int
+}
+
+func (op *delegatorWithdrawalOperation) init(
+	creatorAddress common.Address,
+	delegatorAddress common.Address,
+	amount *big.Int,
+) error {
+	if creatorAddress == (common.Address{}) {
+		return ErrNoCreatorAddress
+	}
+
+	if delegatorAddress == (common.Address{}) {
+		return ErrNoDelegatorAddress
+	}
+
+	if amount == nil {
+		return ErrNoAmount
+	}
+
+	op.creatorAddress = creatorAddress
+	op.delegatorAddress = delegatorAddress
+	op.amount = amount
+
+	return nil
+}
+
+// NewDelegatorWithdrawalOperation creates a withdrawal operation
+// of the delegator's share of the validator stake.
+// Zero amount means withdrawal of the whole share.
+func NewDelegatorWithdrawalOperation(
+	validatorAddress common.Address,
+	delegatorAddress common.Address,
+	amount *big.Int,
+) (DelegatorWithdrawal, error) {
+	op := &delegatorWithdrawalOperation{}
+	if err := op.init(validatorAddress, delegatorAddress, amount); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+func (op *delegatorWithdrawalOperation) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, common.AddressLength*2)
+
+	data = append(data, op.creatorAddress.Bytes()...)
+	data = append(data, op.delegatorAddress.Bytes()...)
+
+	data = append(data, op.amount.Bytes()...)
+
+	return data, nil
+}
+
+func (op *delegatorWithdrawalOperation) UnmarshalBinary(data []byte) error {
+	if len(data) < common.AddressLength*2 {
+		return ErrBadDataLen
+	}
+	validatorAddress := common.BytesToAddress(data[:common.AddressLength])
+	delegatorAddress := common.BytesToAddress(data[common.AddressLength : common.AddressLength*2])
+
+	amount := new(big.Int).SetBytes(data[common.AddressLength*2:])
+
+	return op.init(validatorAddress, delegatorAddress, amount)
+}
+
+func (op *delegatorWithdrawalOperation) OpCode() Code {
+	return DelegatorWithdrawalCode
+}
+
+func (op *delegatorWithdrawalOperation) CreatorAddress() common.Address {
+	return op.creatorAddress
+}
+
+func (op *delegatorWithdrawalOperation) DelegatorAddress() common.Address {
+	return op.delegatorAddress
+}
+
+func (op *delegatorWithdrawalOperation) Amount() *big.Int {
+	return op.amount
+}
@@ -0,0 +1,122 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/status-im/keycard-go/hexutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+func TestDelegatorWithdrawalData(t *testing.T) {
+	var (
+		creatorAddress   = common.HexToAddress("0xa7e558cc6efa1c41270ef4aa227b3dd6b4a3951e")
+		delegatorAddress = common.HexToAddress("0x6e9e76fa278190cfb2404e5923d3ccd7e8f6c51d")
+		amount           = big.NewInt(50000)
+
+		opData = "f408a7e558cc6efa1c41270ef4aa227b3dd6b4a3951e6e9e76fa278190cfb2404e5923d3ccd7e8f6c51dc350"
+	)
+
+	type decodedOp struct {
+		creatorAddress   common.Address
+		delegatorAddress common.Address
+		amount           *big.Int
+	}
+
+	cases := []operationTestCase{
+		{
+			caseName: "OK",
+			decoded: decodedOp{
+				creatorAddress:   creatorAddress,
+				delegatorAddress: delegatorAddress,
+				amount:           amount,
+			},
+			encoded: hexutils.HexToBytes(opData),
+			errs:    []error{},
+		},
+		{
+			caseName: "ErrNoCreatorAddress",
+			decoded: decodedOp{
+				delegatorAddress: delegatorAddress,
+				amount:           amount,
+			},
+			encoded: hexutils.HexToBytes(""),
+			errs:    []error{ErrNoCreatorAddress},
+		},
+		{
+			caseName: "ErrNoDelegatorAddress",
+			decoded: decodedOp{
+				creatorAddress: creatorAddress,
+				amount:         amount,
+			},
+			encoded: hexutils.HexToBytes(""),
+			errs:    []error{ErrNoDelegatorAddress},
+		},
+		{
+			caseName: "ErrNoAmount",
+			decoded: decodedOp{
+				creatorAddress:   creatorAddress,
+				delegatorAddress: delegatorAddress,
+			},
+			encoded: hexutils.HexToBytes(""),
+			errs:    []error{ErrNoAmount},
+		},
+	}
+
+	operationEncode := func(b []byte, i interface{}) error {
+		o := i.(decodedOp)
+		createOp, err := NewDelegatorWithdrawalOperation(
+			o.creatorAddress,
+			o.delegatorAddress,
+			o.amount,
+		)
+		if err != nil {
+			return err
+		}
+
+		return equalOpBytes(createOp, b)
+	}
+
+	operationDecode := func(b []byte, i interface{}) error {
+		op, err := DecodeBytes(b)
+		testutils.AssertNoError(t, err)
+
+		o := i.(decodedOp)
+		opDecoded, ok := op.(DelegatorWithdrawal)
+		if !ok {
+			return errors.New("invalid operation type")
+		}
+		err = checkOpCode(b, opDecoded)
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, opDecoded.CreatorAddress(), o.creatorAddress)
+		testutils.AssertEqual(t, opDecoded.DelegatorAddress(), o.delegatorAddress)
+		if !testutils.BigIntEquals(opDecoded.Amount(), o.amount) {
+			t.Fatalf("\n\tExpect:\t%v\n\tGot:\t%v", opDecoded.Amount(), o.amount)
+		}
+
+		return nil
+	}
+
+	startSubTests(t, cases, operationEncode, operationDecode)
+}
+
+func TestDelegatorWithdrawalShortData(t *testing.T) {
+	_, err := DecodeBytes(hexutils.HexToBytes("f408a7e558cc6efa1c41270ef4aa227b3dd6b4a3951e"))
+	testutils.AssertError(t, err, ErrBadDataLen)
+}
@@ -118,12 +118,13 @@ func (s *SigningData) HashTreeRootWith(hh ssz.HashWalker) (err error) {
 	return
 }
 
-func VerifyDepositSig(
-	sig common.BlsSignature,
+// DepositSigningRoot computes the root of the deposit message
+// which is signed by the validator key.
+func DepositSigningRoot(
 	pk common.BlsPubKey,
 	creatorAddr common.Address,
 	withdrawalCred common.Address,
-) error {
+) ([32]byte, error) {
 	sigData := &DepositMessage{
 		PublicKey:             pk.Bytes(),
 		CreatorAddress:        creatorAddr.Bytes(),
@@ -132,9 +133,32 @@ func VerifyDepositSig(
 	}
 	sigDataRoot, err := sigData.HashTreeRoot()
 	if err != nil {
-		return err
+		return [32]byte{}, err
+	}
+	return (&SigningData{ObjectRoot: sigDataRoot[:], Domain: depositDomain()}).HashTreeRoot()
+}
+
+// SignDeposit signs the deposit message with the validator secret key.
+func SignDeposit(
+	sk *bls_sig.SecretKey,
+	creatorAddr common.Address,
+	withdrawalCred common.Address,
+) (common.BlsSignature, error) {
+	pk := common.BytesToBlsPubKey(sk.PublicKey().Marshal())
+	root, err := DepositSigningRoot(pk, creatorAddr, withdrawalCred)
+	if err != nil {
+		return common.BlsSignature{}, err
 	}
-	root, err := (&SigningData{ObjectRoot: sigDataRoot[:], Domain: depositDomain()}).HashTreeRoot()
+	return common.BytesToBlsSig(sk.Sign(root[:]).Marshal()), nil
+}
+
+func VerifyDepositSig(
+	sig common.BlsSignature,
+	pk common.BlsPubKey,
+	creatorAddr common.Address,
+	withdrawalCred common.Address,
+) error {
+	root, err := DepositSigningRoot(pk, creatorAddr, withdrawalCred)
 	if err != nil {
 		return err
 	}
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto/bls_sig"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
 )
 
@@ -31,3 +32,22 @@ func TestVerifyDepositSig_OK(t *testing.T) {
 	err := VerifyDepositSig(signature, pubkey, creator_address, withdrawal_address)
 	testutils.AssertNoError(t, err)
 }
+
+func TestSignDeposit_RoundTrip(t *testing.T) {
+	var (
+		creator_address    = common.HexToAddress("0x6e9e76fa278190cfb2404e5923d3ccd7e8f6c777")
+		withdrawal_address = common.HexToAddress("0xa7e558cc6efa1c41270ef4aa227b3dd6b4a3951e")
+	)
+	sk, err := bls_sig.RandKey()
+	testutils.AssertNoError(t, err)
+	pubkey := common.BytesToBlsPubKey(sk.PublicKey().Marshal())
+
+	signature, err := SignDeposit(sk, creator_address, withdrawal_address)
+	testutils.AssertNoError(t, err)
+
+	err = VerifyDepositSig(signature, pubkey, creator_address, withdrawal_address)
+	testutils.AssertNoError(t, err)
+
+	err = VerifyDepositSig(signature, pubkey, withdrawal_address, creator_address)
+	testutils.AssertError(t, err, ErrInvalidDepositSig)
+}
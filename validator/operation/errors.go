@@ -24,6 +24,7 @@ var (
 	ErrNoPubKey            = errors.New("pubkey is required")
 	ErrNoInitTxHash        = errors.New("initTxHash is required")
 	ErrNoCreatorAddress    = errors.New("creator_address is required")
+	ErrNoDelegatorAddress  = errors.New("delegator_address is required")
 	ErrNoWithdrawalAddress = errors.New("withdrawal_address is required")
 	ErrNoSignature         = errors.New("signature is required")
 	ErrNoAmount            = errors.New("amount is required")
@@ -42,4 +43,11 @@ var (
 	ErrDelegateForkRequire = errors.New("can not process transaction before fork of delegating stake")
 
 	ErrInvalidDepositSig = errors.New("invalid deposit signature")
+
+	ErrNoDeposits      = errors.New("deposits are required")
+	ErrTooManyDeposits = errors.New("too many deposits in batch")
+	ErrNoDepositValue  = errors.New("deposit value is required")
+
+	ErrBatchDepositForkRequire        = errors.New("can not process transaction before fork of batch deposit")
+	ErrDelegatorWithdrawalForkRequire = errors.New("can not process transaction before fork of delegator withdrawal")
 )
@@ -31,6 +31,13 @@ type Deposit interface {
 	DelegatingStake() *DelegatingStakeData
 }
 
+// BatchDeposit contains a list of validator deposits with per-entry values.
+type BatchDeposit interface {
+	Operation
+	Deposits() []*BatchDepositEntry
+	TotalValue() *big.Int
+}
+
 // ValidatorSync contains all attributes for validator sync op.
 type ValidatorSync interface {
 	Operation
@@ -58,3 +65,12 @@ type Withdrawal interface {
 	CreatorAddress() common.Address
 	Amount() *big.Int
 }
+
+// DelegatorWithdrawal is a withdrawal of the delegator's own share
+// of the stake of a validator with delegating stake.
+type DelegatorWithdrawal interface {
+	Operation
+	CreatorAddress() common.Address
+	DelegatorAddress() common.Address
+	Amount() *big.Int
+}
@@ -21,12 +21,14 @@ type Code byte
 
 // Token operation codes use invalid op codes of EVM instructions to prevent clashes.
 const (
-	DepositCode       = 0x01
-	ActivateCode      = 0x02
-	ExitCode          = 0x03
-	DeactivateCode    = 0x04
-	UpdateBalanceCode = 0x05
-	WithdrawalCode    = 0x06
+	DepositCode             = 0x01
+	ActivateCode            = 0x02
+	ExitCode                = 0x03
+	DeactivateCode          = 0x04
+	UpdateBalanceCode       = 0x05
+	WithdrawalCode          = 0x06
+	BatchDepositCode        = 0x07
+	DelegatorWithdrawalCode = 0x08
 )
 
 // Prefix for the encoded data field of a validator operation
@@ -81,6 +83,10 @@ func DecodeBytes(b []byte) (Operation, error) {
 		op = &exitOperation{}
 	case WithdrawalCode:
 		op = &withdrawalOperation{}
+	case BatchDepositCode:
+		op = &batchDepositOperation{}
+	case DelegatorWithdrawalCode:
+		op = &delegatorWithdrawalOperation{}
 	default:
 		return nil, ErrOpNotValid
 	}
@@ -115,6 +121,10 @@ func EncodeToBytes(op Operation) ([]byte, error) {
 		buf[1] = ExitCode
 	case *withdrawalOperation:
 		buf[1] = WithdrawalCode
+	case *batchDepositOperation:
+		buf[1] = BatchDepositCode
+	case *delegatorWithdrawalOperation:
+		buf[1] = DelegatorWithdrawalCode
 	}
 
 	buf = append(buf, b...)
@@ -14,8 +14,35 @@
 
 package operation
 
+import (
+	"encoding/binary"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+)
+
 func makeCopy(src []byte) []byte {
 	dst := make([]byte, len(src))
 	copy(dst, src)
 	return dst
 }
+
+// appendLenPrefixed appends data to buf prefixed with its length.
+func appendLenPrefixed(buf []byte, data []byte) []byte {
+	lenBin := make([]byte, common.Uint32Size)
+	binary.BigEndian.PutUint32(lenBin, uint32(len(data)))
+	buf = append(buf, lenBin...)
+	return append(buf, data...)
+}
+
+// readLenPrefixed reads length prefixed data from b and returns the rest of b.
+func readLenPrefixed(b []byte) (data []byte, rest []byte, err error) {
+	if len(b) < common.Uint32Size {
+		return nil, nil, ErrBadDataLen
+	}
+	dataLen := int(binary.BigEndian.Uint32(b[:common.Uint32Size]))
+	b = b[common.Uint32Size:]
+	if dataLen > len(b) {
+		return nil, nil, ErrBadDataLen
+	}
+	return b[:dataLen], b[dataLen:], nil
+}
@@ -64,8 +64,27 @@ var (
 	ErrInvalidAmount          = errors.New("invalid amount")
 	ErrMismatchDelegateData   = errors.New("validator deposit failed (mismatch delegate stake data)")
 	ErrSenderRejByDelegate    = errors.New("sender addresses rejected by delegating stake rules")
+	ErrMismatchBatchValue     = errors.New("tx value mismatch sum of batch deposit values")
+	ErrNoDelegatorStake       = errors.New("no stake of delegator")
+	ErrStakeBelowMinimum      = errors.New("validator stake falls below the effective balance")
 )
 
+// BatchDepositError reports the entry of a batch deposit operation
+// which failed to apply.
+type BatchDepositError struct {
+	Index   int
+	Creator common.Address
+	Err     error
+}
+
+func (e *BatchDepositError) Error() string {
+	return fmt.Sprintf("batch deposit entry %d (creator %#x) failed: %v", e.Index, e.Creator, e.Err)
+}
+
+func (e *BatchDepositError) Unwrap() error {
+	return e.Err
+}
+
 const (
 	//	// 1024 bytes
 	//	MetadataMaxSize = 1 << 10
@@ -96,7 +115,7 @@ type blockchain interface {
 	GetEraInfo() *era.EraInfo
 	Config() *params.ChainConfig
 	Database() ethdb.Database
-	GetValidatorSyncData(InitTxHash common.Hash) *types.ValidatorSync
+	GetValidatorSyncData(InitTxHash common.Hash, creator common.Address) *types.ValidatorSync
 	GetTransaction(txHash common.Hash) (tx *types.Transaction, blHash common.Hash, index uint64)
 	GetTransactionReceipt(txHash common.Hash) (rc *types.Receipt, blHash common.Hash, index uint64)
 	GetLastCoordinatedCheckpoint() *types.Checkpoint
@@ -120,6 +139,7 @@ type Processor struct {
 	eventEmmiter *txlog.EventEmmiter
 	storage      valStore.Storage
 	blockchain   blockchain
+	tracer       *vm.NativeOpFrame
 }
 
 // NewProcessor creates new validator processor
@@ -177,6 +197,9 @@ func (p *Processor) Call(caller Ref, toAddr common.Address, value *big.Int, msg
 	if err != nil {
 		return nil, err
 	}
+	if p.tracer != nil {
+		p.tracer.SetOp(OperationArgs(op))
+	}
 
 	nonce := p.state.GetNonce(caller.Address())
 	p.state.SetNonce(caller.Address(), nonce+1)
@@ -213,6 +236,28 @@ func (p *Processor) Call(caller Ref, toAddr common.Address, value *big.Int, msg
 				"blHash", p.ctx.BlockHash.Hex(),
 			)
 		}
+	case operation.BatchDeposit:
+		ret, err = p.validatorBatchDeposit(caller, toAddr, value, v, msg.TxHash())
+		if err != nil {
+			log.Error("Validator batch deposit: err",
+				"opCode", op.OpCode(),
+				"tx", msg.TxHash().Hex(),
+				"amount", value.String(),
+				"from", caller.Address(),
+				"deposits", len(v.Deposits()),
+				"blHash", p.ctx.BlockHash.Hex(),
+				"err", err,
+			)
+		} else {
+			log.Info("Validator batch deposit: success",
+				"opCode", op.OpCode(),
+				"tx", msg.TxHash().Hex(),
+				"amount", value.String(),
+				"from", caller.Address(),
+				"deposits", len(v.Deposits()),
+				"blHash", p.ctx.BlockHash.Hex(),
+			)
+		}
 	case operation.ValidatorSync:
 		ret, err = p.syncOpProcessing(v, msg)
 		if err != nil {
@@ -251,6 +296,29 @@ func (p *Processor) Call(caller Ref, toAddr common.Address, value *big.Int, msg
 				"exitAfterEpoch", fmt.Sprintf("%d", v.ExitAfterEpoch()),
 			)
 		}
+	// DelegatorWithdrawal extends the Withdrawal method set, so it must be matched first.
+	case operation.DelegatorWithdrawal:
+		ret, err = p.validatorDelegatorWithdrawal(caller, toAddr, v, msg.TxHash())
+		if err != nil {
+			log.Error("Validator delegator withdrawal: err",
+				"opCode", op.OpCode(),
+				"tx", msg.TxHash().Hex(),
+				"amount", v.Amount().String(),
+				"creator", v.CreatorAddress().Hex(),
+				"delegator", v.DelegatorAddress().Hex(),
+				"blHash", p.ctx.BlockHash.Hex(),
+				"err", err,
+			)
+		} else {
+			log.Info("Validator delegator withdrawal: success",
+				"opCode", op.OpCode(),
+				"tx", msg.TxHash().Hex(),
+				"amount", v.Amount().String(),
+				"creator", v.CreatorAddress().Hex(),
+				"delegator", v.DelegatorAddress().Hex(),
+				"blHash", p.ctx.BlockHash.Hex(),
+			)
+		}
 	case operation.Withdrawal:
 		ret, err = p.validatorWithdrawal(caller, toAddr, v, msg.TxHash())
 		if err != nil {
@@ -414,6 +482,27 @@ func (p *Processor) validatorDeposit(caller Ref, toAddr common.Address, value *b
 	return value.FillBytes(make([]byte, 32)), nil
 }
 
+// validatorBatchDeposit applies each deposit of the batch with the rules of a single deposit.
+// The batch is applied atomically: the failure of any entry fails the whole operation.
+func (p *Processor) validatorBatchDeposit(caller Ref, toAddr common.Address, value *big.Int, op operation.BatchDeposit, txHash common.Hash) ([]byte, error) {
+	if !p.blockchain.Config().IsForkSlotBatchDeposit(p.ctx.Slot) {
+		return nil, operation.ErrBatchDepositForkRequire
+	}
+	if value == nil || value.Cmp(op.TotalValue()) != 0 {
+		return nil, ErrMismatchBatchValue
+	}
+	for i, entry := range op.Deposits() {
+		if _, err := p.validatorDeposit(caller, toAddr, entry.Value, entry.Deposit, txHash); err != nil {
+			return nil, &BatchDepositError{
+				Index:   i,
+				Creator: entry.Deposit.CreatorAddress(),
+				Err:     err,
+			}
+		}
+	}
+	return value.FillBytes(make([]byte, 32)), nil
+}
+
 func (p *Processor) validatorExit(caller Ref, toAddr common.Address, op operation.Exit, txHash common.Hash) ([]byte, error) {
 	if !p.IsValidatorOp(&toAddr) {
 		return nil, ErrInvalidToAddress
@@ -675,6 +764,146 @@ func (p *Processor) validatorWithdrawal(caller Ref, toAddr common.Address, op op
 	return op.CreatorAddress().Bytes(), nil
 }
 
+// validatorDelegatorWithdrawal requests withdrawal of the delegator's share
+// of the stake of a validator with delegating stake.
+// The stake list of the validator is updated while update balance processing.
+func (p *Processor) validatorDelegatorWithdrawal(caller Ref, toAddr common.Address, op operation.DelegatorWithdrawal, txHash common.Hash) ([]byte, error) {
+	if !p.IsValidatorOp(&toAddr) {
+		return nil, ErrInvalidToAddress
+	}
+	if !p.blockchain.Config().IsForkSlotDelegatorWithdrawal(p.ctx.Slot) {
+		return nil, operation.ErrDelegatorWithdrawalForkRequire
+	}
+
+	// check amount can add to log
+	opAmount := new(big.Int).Set(op.Amount())
+	if !common.BnCanCastToUint64(new(big.Int).Div(opAmount, common.BigGwei)) {
+		return nil, ErrInvalidAmount
+	}
+
+	from := caller.Address()
+	if from != op.DelegatorAddress() {
+		return nil, ErrInvalidFromAddresses
+	}
+
+	validator, err := p.Storage().GetValidator(p.state, op.CreatorAddress())
+	if err != nil {
+		return nil, err
+	}
+	if validator == nil {
+		return nil, ErrUnknownValidator
+	}
+	if validator.GetActivationEra() == math.MaxUint64 {
+		return nil, ErrNotActivatedValidator
+	}
+
+	//withdrawal operations are limited to 1 op at time
+	//if operation already has been requested, check it expiration
+	if prevOpTx := validator.GetWithdrawalTx(); prevOpTx != nil {
+		rc, blHash, _ := p.blockchain.GetTransactionReceipt(*prevOpTx)
+		//check if prev tx in same block
+		if blHash != (common.Hash{}) && blHash == p.ctx.BlockHash {
+			return nil, ErrValOpBlocked
+		}
+		//check prev op success
+		if rc != nil && rc.Status == types.ReceiptStatusSuccessful {
+			//check prev operation expiration
+			prevHeader := p.blockchain.GetHeaderByHash(blHash)
+			expiration := p.blockchain.Config().ValidatorOpExpireSlots
+			if prevHeader != nil && p.ctx.Slot < prevHeader.Slot+expiration {
+				log.Error("Validator delegator withdrawal: op blocked",
+					"blockedByTx", prevOpTx.Hex(),
+					"opCode", op.OpCode(),
+					"amount", opAmount.String(),
+					"creator", op.CreatorAddress().Hex(),
+					"delegator", from.Hex(),
+					"blHash", p.ctx.BlockHash.Hex(),
+					"error", ErrValOpBlocked,
+				)
+				return nil, ErrValOpBlocked
+			}
+		}
+	}
+
+	isTrial := false
+	if validator.HasDelegatingStake() {
+		if isTrial, err = p.isValidatorTrialPeriod(validator); err != nil {
+			return nil, err
+		}
+	}
+	opAmount, err = ValidateDelegatorWithdrawal(validator, from, opAmount, isTrial, p.blockchain.Config().EffectiveBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	//update current validator's data version
+	validator = p.updateValidatorVersionBySlot(validator)
+	validator.SetWithdrawalTx(&txHash)
+	err = p.Storage().SetValidator(p.state, validator)
+	if err != nil {
+		return nil, err
+	}
+
+	// create tx log
+	amtGwei := new(big.Int).Div(opAmount, common.BigGwei).Uint64()
+	logData := txlog.PackWithdrawalLogData(validator.GetPubKey(), op.CreatorAddress(), validator.GetIndex(), amtGwei)
+	p.eventEmmiter.WithdrawalRequest(toAddr, logData)
+
+	return op.CreatorAddress().Bytes(), nil
+}
+
+// ValidateDelegatorWithdrawal checks the delegator withdrawal against
+// the delegator's stake and the actual delegating stake rules, if any.
+// While the validator is not exited, the rest of the stake must not be less than the effective balance.
+// Returns the amount to withdraw (zero amount means the whole delegator's stake).
+func ValidateDelegatorWithdrawal(
+	validator *valStore.Validator,
+	delegator common.Address,
+	amount *big.Int,
+	isTrial bool,
+	effectiveBalance *big.Int,
+) (*big.Int, error) {
+	//check delegating roles,
+	//the stake holders of validators without delegating stake can withdraw own stake
+	if validator.HasDelegatingStake() {
+		var actualRules = &validator.DelegatingStake.Rules
+		if isTrial {
+			actualRules = &validator.DelegatingStake.TrialRules
+		}
+		var isAllowed bool
+		for _, adr := range actualRules.Withdrawal() {
+			if adr == delegator {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return nil, ErrSenderRejByDelegate
+		}
+	}
+
+	stake := validator.StakeByAddress(delegator)
+	if stake.Sign() == 0 {
+		return nil, ErrNoDelegatorStake
+	}
+	// zero amount - withdrawal of the whole stake of delegator
+	if amount.Sign() == 0 {
+		amount = new(big.Int).Set(stake)
+	}
+	if stake.Cmp(amount) < 0 {
+		return nil, ErrInsufficientFundsForOp
+	}
+
+	if validator.GetExitEra() == math.MaxUint64 {
+		effectiveBalanceWei := new(big.Int).Mul(effectiveBalance, common.BigWat)
+		restStake := new(big.Int).Sub(validator.TotalStake(), amount)
+		if restStake.Cmp(effectiveBalanceWei) < 0 {
+			return nil, ErrStakeBelowMinimum
+		}
+	}
+	return amount, nil
+}
+
 func (p *Processor) syncOpProcessing(op operation.ValidatorSync, msg message) (ret []byte, err error) {
 	if err = ValidateValidatorSyncOp(p.blockchain, op, p.ctx.Slot, msg.TxHash()); err != nil {
 		log.Error("Invalid validator sync op",
@@ -721,7 +950,10 @@ func (p *Processor) validatorActivate(op operation.ValidatorSync) ([]byte, error
 
 	validator.SetActivationEra(opEra.Number + postpone)
 	validator.SetIndex(op.Index())
-	validator.UnsetStake()
+	//the stake list is kept for delegator withdrawals
+	if !p.blockchain.Config().IsForkSlotDelegatorWithdrawal(p.ctx.Slot) {
+		validator.UnsetStake()
+	}
 	err = p.Storage().SetValidator(p.state, validator)
 	if err != nil {
 		return nil, err
@@ -925,6 +1157,9 @@ func (p *Processor) validatorUpdateBalance(op operation.ValidatorSync) ([]byte,
 				validator.RmStakeByAddress(iTxFrom)
 			}
 		}
+	} else if dwOp := p.getDelegatorWithdrawalInitOp(op); dwOp != nil {
+		// Handle withdrawal of delegator's stake
+		return p.applyDelegatorWithdrawal(op, validator, dwOp)
 	} else if validator.HasDelegatingStake() {
 		// Handle delegate rules
 		return p.applyDelegatingStakeRules(op, validator)
@@ -958,6 +1193,89 @@ func (p *Processor) validatorUpdateBalance(op operation.ValidatorSync) ([]byte,
 	return op.Creator().Bytes(), nil
 }
 
+// getDelegatorWithdrawalInitOp returns the delegator withdrawal operation
+// which initiated the validator sync operation, if any.
+func (p *Processor) getDelegatorWithdrawalInitOp(op operation.ValidatorSync) operation.DelegatorWithdrawal {
+	if !p.blockchain.Config().IsForkSlotDelegatorWithdrawal(p.ctx.Slot) {
+		return nil
+	}
+	initTx, _, _ := p.blockchain.GetTransaction(op.InitTxHash())
+	if initTx == nil {
+		return nil
+	}
+	iop, err := operation.DecodeBytes(initTx.Data())
+	if err != nil {
+		return nil
+	}
+	dwOp, _ := iop.(operation.DelegatorWithdrawal)
+	return dwOp
+}
+
+// applyDelegatorWithdrawal transfers the withdrawn amount to the delegator
+// and subtracts it from the delegator's stake. The withdrawal is checked
+// against the rules and the stake list before any balance change,
+// the requests exceeding the limits are rejected.
+func (p *Processor) applyDelegatorWithdrawal(op operation.ValidatorSync, validator *valStore.Validator, dwOp operation.DelegatorWithdrawal) ([]byte, error) {
+	delegator := dwOp.DelegatorAddress()
+
+	isTrial := false
+	if validator.HasDelegatingStake() {
+		var err error
+		if isTrial, err = p.isValidatorTrialPeriod(validator); err != nil {
+			return nil, err
+		}
+	}
+	wStakeAmt, err := ValidateDelegatorWithdrawal(validator, delegator, op.Amount(), isTrial, p.blockchain.Config().EffectiveBalance)
+	if err == nil && wStakeAmt.Cmp(op.Amount()) != 0 {
+		err = ErrInvalidAmount
+	}
+	if err != nil {
+		log.Error("Validator update balance: delegator withdrawal rejected",
+			"opCode", op.OpCode(),
+			"InitTxHash", op.InitTxHash().Hex(),
+			"amount", op.Amount().String(),
+			"stake", validator.StakeByAddress(delegator).String(),
+			"delegator", delegator.Hex(),
+			"creator", op.Creator().Hex(),
+			"blHash", p.ctx.BlockHash.Hex(),
+			"error", err,
+		)
+		return nil, err
+	}
+
+	newStake, err := validator.SubtractStake(delegator, wStakeAmt)
+	if err != nil {
+		return nil, err
+	}
+	// rm validator stake if empty
+	if newStake.Sign() == 0 {
+		validator.RmStakeByAddress(delegator)
+	}
+	// update validator
+	err = p.Storage().SetValidator(p.state, validator)
+	if err != nil {
+		return nil, err
+	}
+
+	// transfer the withdrawn stake to delegator
+	p.state.AddBalance(delegator, wStakeAmt)
+
+	//add update balance tx log
+	logData, err := txlog.PackUpdateBalanceLogData(op.InitTxHash(), op.Creator(), op.ProcEpoch(), wStakeAmt)
+	if err != nil {
+		return nil, err
+	}
+	p.eventEmmiter.AddUpdateBalanceLog(p.GetValidatorsStateAddress(), logData, op.Creator(), op.InitTxHash(), &delegator)
+	//add delegator withdrawal tx log
+	logData, err = txlog.PackDelegatorWithdrawalLogData(op.InitTxHash(), op.Creator(), delegator, wStakeAmt, newStake)
+	if err != nil {
+		return nil, err
+	}
+	p.eventEmmiter.AddDelegatorWithdrawalLog(p.GetValidatorsStateAddress(), logData, op.Creator(), op.InitTxHash(), delegator)
+
+	return op.Creator().Bytes(), nil
+}
+
 func (p *Processor) applyDelegatingStakeRules(op operation.ValidatorSync, validator *valStore.Validator) ([]byte, error) {
 	log.Info("Validator update balance: apply delegate rules: start",
 		"opCode", op.OpCode(),
@@ -988,9 +1306,17 @@ func (p *Processor) applyDelegatingStakeRules(op operation.ValidatorSync, valida
 
 	opBalance := new(big.Int).Add(op.Balance(), op.Amount())
 
+	// the stake list is kept for delegator withdrawals,
+	// so the stake is shared by the rest stakes of delegators
+	// to not pay the withdrawn stakes twice.
+	shareByStakeList := bc.Config().IsForkSlotDelegatorWithdrawal(p.ctx.Slot) && validator.TotalStake().Sign() > 0
+
 	//Define withdrawals amounts of profit and stake
-	effectiveBalanceWei := new(big.Int).Mul(p.blockchain.Config().EffectiveBalance, common.BigWat)
-	profitBalance := new(big.Int).Sub(opBalance, effectiveBalanceWei)
+	stakeBalance := new(big.Int).Mul(p.blockchain.Config().EffectiveBalance, common.BigWat)
+	if shareByStakeList {
+		stakeBalance = validator.TotalStake()
+	}
+	profitBalance := new(big.Int).Sub(opBalance, stakeBalance)
 	if profitBalance.Sign() < 0 {
 		profitBalance = new(big.Int)
 	}
@@ -1029,7 +1355,43 @@ func (p *Processor) applyDelegatingStakeRules(op operation.ValidatorSync, valida
 			p.state.AddBalance(adr, amt)
 		}
 	}
-	if stakeOpAmt.Sign() > 0 {
+	if stakeOpAmt.Sign() > 0 && shareByStakeList {
+		for _, st := range validator.Stake {
+			amt := new(big.Int).Mul(stakeOpAmt, st.Sum)
+			amt.Div(amt, stakeBalance)
+			if amt.Cmp(st.Sum) > 0 {
+				amt = new(big.Int).Set(st.Sum)
+			}
+			upBalInfo = append(upBalInfo, &txlog.ShareRuleApplying{
+				Address:  st.Address,
+				RuleType: txlog.StakeShare,
+				IsTrial:  isTrial,
+				Amount:   amt,
+			})
+
+			log.Info("Validator update balance: apply delegate rules: up balance: StakeShare by stake",
+				"adr", st.Address.Hex(),
+				"isTrial", isTrial,
+				"Amount", amt.String(),
+				"InitTxHash", op.InitTxHash().Hex(),
+				"creator", op.Creator().Hex(),
+				"blHash", p.ctx.BlockHash.Hex(),
+			)
+
+			// transfer amount to delegator
+			p.state.AddBalance(st.Address, amt)
+			st.Sum = new(big.Int).Sub(st.Sum, amt)
+		}
+		// rm paid stakes
+		for _, st := range validator.Stake {
+			if st.Sum.Sign() == 0 {
+				validator.RmStakeByAddress(st.Address)
+			}
+		}
+		if err = p.Storage().SetValidator(p.state, validator); err != nil {
+			return nil, err
+		}
+	} else if stakeOpAmt.Sign() > 0 {
 		percent = new(big.Int).Div(stakeOpAmt, big.NewInt(100))
 		for adr, share := range actualRules.StakeShare() {
 			amt := new(big.Int).Mul(percent, big.NewInt(int64(share)))
@@ -1101,7 +1463,7 @@ func (p *Processor) isValidatorTrialPeriod(validator *valStore.Validator) (bool,
 
 // ValidateValidatorSyncOp validate validator sync op data with context of apply.
 func ValidateValidatorSyncOp(bc blockchain, valSyncOp operation.ValidatorSync, applySlot uint64, txHash common.Hash) error {
-	savedValSync := bc.GetValidatorSyncData(valSyncOp.InitTxHash())
+	savedValSync := bc.GetValidatorSyncData(valSyncOp.InitTxHash(), valSyncOp.Creator())
 	if savedValSync == nil {
 		return ErrNoSavedValSyncOp
 	}
@@ -1151,6 +1513,21 @@ func ValidateValidatorSyncOp(bc blockchain, valSyncOp operation.ValidatorSync, a
 		if initTxData.CreatorAddress() != valSyncOp.Creator() {
 			return ErrInvalidCreator
 		}
+	case operation.BatchDeposit:
+		// the batch deposit initiates the activations only
+		if valSyncOp.OpCode() != operation.ActivateCode {
+			return ErrInvalidOpCode
+		}
+		var inBatch bool
+		for _, entry := range initTxData.Deposits() {
+			if entry.Deposit.CreatorAddress() == valSyncOp.Creator() {
+				inBatch = true
+				break
+			}
+		}
+		if !inBatch {
+			return ErrInvalidCreator
+		}
 	case operation.Exit:
 		if valSyncOp.OpCode() == operation.ExitCode {
 			return ErrInvalidOpCode
@@ -1161,6 +1538,16 @@ func ValidateValidatorSyncOp(bc blockchain, valSyncOp operation.ValidatorSync, a
 		if initTxData.ExitAfterEpoch() != nil && *initTxData.ExitAfterEpoch() > valSyncOp.ProcEpoch() {
 			return ErrInvalidOpEpoch
 		}
+	case operation.DelegatorWithdrawal:
+		if valSyncOp.OpCode() == operation.DelegatorWithdrawalCode {
+			return ErrInvalidOpCode
+		}
+		if initTxData.CreatorAddress() != valSyncOp.Creator() {
+			return ErrInvalidCreator
+		}
+		if valSyncOp.Version() == operation.Ver1 && valSyncOp.Balance() == nil {
+			return operation.ErrNoBalance
+		}
 	case operation.Withdrawal:
 		if valSyncOp.OpCode() == operation.WithdrawalCode {
 			return ErrInvalidOpCode
@@ -15,6 +15,7 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
@@ -28,6 +29,7 @@ import (
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/vm"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto/bls_sig"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/operation"
@@ -624,6 +626,253 @@ func TestTestProcessorDeposit_DelegatingStake(t *testing.T) {
 	}
 }
 
+func TestProcessorBatchDeposit(t *testing.T) {
+	ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bc := NewMockblockchain(ctrl)
+	bc.EXPECT().Config().Return(testmodels.TestChainConfig).AnyTimes()
+
+	stateDb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	batchCtx := ctx
+	batchCtx.Slot = testmodels.TestChainConfig.ForkSlotBatchDeposit
+	processor := NewProcessor(batchCtx, stateDb, bc)
+	to := processor.GetValidatorsStateAddress()
+
+	newEntry := func(val *big.Int) *operation.BatchDepositEntry {
+		sk, err := bls_sig.RandKey()
+		testutils.AssertNoError(t, err)
+		creator := common.BytesToAddress(testutils.RandomData(20))
+		sig, err := operation.SignDeposit(sk, creator, withdrawalAddress)
+		testutils.AssertNoError(t, err)
+		dop, err := operation.NewDepositOperation(common.BytesToBlsPubKey(sk.PublicKey().Marshal()), creator, withdrawalAddress, sig, nil)
+		testutils.AssertNoError(t, err)
+		return &operation.BatchDepositEntry{Deposit: dop, Value: val}
+	}
+	newMsg := func(txHash common.Hash, entries ...*operation.BatchDepositEntry) message {
+		op, err := operation.NewBatchDepositOperation(entries)
+		testutils.AssertNoError(t, err)
+		data, err := operation.EncodeToBytes(op)
+		testutils.AssertNoError(t, err)
+		msg := NewMockmessage(ctrl)
+		msg.EXPECT().Data().AnyTimes().Return(data)
+		msg.EXPECT().TxHash().AnyTimes().Return(txHash)
+		return msg
+	}
+
+	bal, _ := new(big.Int).SetString("32000000000000000000000", 10)
+	processor.state.AddBalance(from, bal)
+
+	cases := []*testmodels.TestCase{
+		{
+			CaseName: "BatchDeposit: OK",
+			TestData: testmodels.TestData{
+				Caller: vm.AccountRef(from),
+				AddrTo: to,
+			},
+			Errs: []error{nil},
+			Fn: func(c *testmodels.TestCase) {
+				v := c.TestData.(testmodels.TestData)
+				entries := []*operation.BatchDepositEntry{newEntry(value), newEntry(new(big.Int).Mul(value, big.NewInt(2)))}
+				total := new(big.Int).Mul(value, big.NewInt(3))
+
+				msg := newMsg(common.Hash{0x01}, entries...)
+
+				balanceFromBfr := processor.state.GetBalance(from)
+				depositCountBfr := processor.getDepositCount()
+				call(t, processor, v.Caller, v.AddrTo, total, msg, c.Errs)
+
+				balDif := new(big.Int).Sub(balanceFromBfr, processor.state.GetBalance(from))
+				if !testutils.BigIntEquals(balDif, total) {
+					t.Errorf("Expected balance diff: %s\nactual: %s", total, balDif)
+				}
+				testutils.AssertEqual(t, depositCountBfr+uint64(len(entries)), processor.getDepositCount())
+				for _, e := range entries {
+					validator, err := processor.Storage().GetValidator(processor.state, e.Deposit.CreatorAddress())
+					testutils.AssertNoError(t, err)
+					if !testutils.BigIntEquals(validator.TotalStake(), e.Value) {
+						t.Errorf("Expected stake of validator %#x: %s\nactual: %s", e.Deposit.CreatorAddress(), e.Value, validator.TotalStake())
+					}
+				}
+			},
+		},
+		{
+			CaseName: "BatchDeposit: ErrMismatchBatchValue",
+			TestData: testmodels.TestData{
+				Caller: vm.AccountRef(from),
+				AddrTo: to,
+			},
+			Errs: []error{ErrMismatchBatchValue},
+			Fn: func(c *testmodels.TestCase) {
+				v := c.TestData.(testmodels.TestData)
+				msg := newMsg(common.Hash{0x02}, newEntry(value), newEntry(value))
+				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
+			},
+		},
+		{
+			CaseName: "BatchDeposit: entry failed, batch reverted",
+			TestData: testmodels.TestData{
+				Caller: vm.AccountRef(from),
+				AddrTo: to,
+			},
+			Errs: []error{ErrTooLowDepositValue},
+			Fn: func(c *testmodels.TestCase) {
+				v := c.TestData.(testmodels.TestData)
+				first := newEntry(value)
+				lowVal, _ := new(big.Int).SetString("1000000000000000000", 10)
+				total := new(big.Int).Add(value, lowVal)
+
+				msg := newMsg(common.Hash{0x03}, first, newEntry(lowVal))
+
+				balanceFromBfr := processor.state.GetBalance(from)
+				_, err := processor.Call(v.Caller, v.AddrTo, total, msg)
+				if !testutils.CheckError(err, c.Errs) {
+					t.Fatalf("Case failed\nwant errors: %s\nhave errors: %s", c.Errs, err)
+				}
+				var batchErr *BatchDepositError
+				if !errors.As(err, &batchErr) || batchErr.Index != 1 {
+					t.Fatalf("Expected failed batch entry 1\nactual: %v", err)
+				}
+				// first entry must be reverted
+				if processor.state.GetBalance(from).Cmp(balanceFromBfr) != 0 {
+					t.Errorf("Expected balance From: %s\nactual: %s", balanceFromBfr, processor.state.GetBalance(from))
+				}
+				validator, _ := processor.Storage().GetValidator(processor.state, first.Deposit.CreatorAddress())
+				testutils.AssertNil(t, validator)
+			},
+		},
+		{
+			CaseName: "BatchDeposit: ErrBatchDepositForkRequire",
+			TestData: testmodels.TestData{
+				Caller: vm.AccountRef(from),
+				AddrTo: to,
+			},
+			Errs: []error{operation.ErrBatchDepositForkRequire},
+			Fn: func(c *testmodels.TestCase) {
+				v := c.TestData.(testmodels.TestData)
+				beforeFork := NewProcessor(ctx, stateDb, bc)
+				msg := newMsg(common.Hash{0x04}, newEntry(value))
+				call(t, beforeFork, v.Caller, v.AddrTo, value, msg, c.Errs)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.CaseName, func(t *testing.T) {
+			c.Fn(c)
+		})
+	}
+}
+
+func TestProcessorBatchDepositActivate(t *testing.T) {
+	ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteEra(db, eraInfo.Number(), *eraInfo.GetEra())
+	bc := NewMockblockchain(ctrl)
+	bc.EXPECT().Config().AnyTimes().Return(testmodels.TestChainConfig)
+	bc.EXPECT().GetSlotInfo().AnyTimes().Return(&types.SlotInfo{
+		GenesisTime:    uint64(time.Now().Unix()),
+		SecondsPerSlot: testmodels.TestChainConfig.SecondsPerSlot,
+		SlotsPerEpoch:  testmodels.TestChainConfig.SlotsPerEpoch,
+	})
+	bc.EXPECT().GetEraInfo().AnyTimes().Return(&eraInfo)
+	bc.EXPECT().Database().AnyTimes().Return(db)
+	bc.EXPECT().EpochToEra(gomock.AssignableToTypeOf(uint64(0))).AnyTimes().Return(&era.Era{Number: 6})
+
+	stateDb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	batchCtx := ctx
+	batchCtx.Slot = testmodels.TestChainConfig.ForkSlotBatchDeposit
+	processor := NewProcessor(batchCtx, stateDb, bc)
+	to := processor.GetValidatorsStateAddress()
+
+	// deposit a batch of validators by a single tx
+	entries := make([]*operation.BatchDepositEntry, 3)
+	for i := range entries {
+		sk, err := bls_sig.RandKey()
+		testutils.AssertNoError(t, err)
+		creator := common.BytesToAddress(testutils.RandomData(20))
+		sig, err := operation.SignDeposit(sk, creator, withdrawalAddress)
+		testutils.AssertNoError(t, err)
+		dop, err := operation.NewDepositOperation(common.BytesToBlsPubKey(sk.PublicKey().Marshal()), creator, withdrawalAddress, sig, nil)
+		testutils.AssertNoError(t, err)
+		entries[i] = &operation.BatchDepositEntry{Deposit: dop, Value: value}
+	}
+	batchOp, err := operation.NewBatchDepositOperation(entries)
+	testutils.AssertNoError(t, err)
+	batchData, err := operation.EncodeToBytes(batchOp)
+	testutils.AssertNoError(t, err)
+	batchTx := types.NewTx(&types.AccessListTx{Data: batchData})
+	batchTxHash := batchTx.Hash()
+
+	bal, _ := new(big.Int).SetString("32000000000000000000000", 10)
+	processor.state.AddBalance(from, bal)
+	depositMsg := NewMockmessage(ctrl)
+	depositMsg.EXPECT().Data().AnyTimes().Return(batchData)
+	depositMsg.EXPECT().TxHash().AnyTimes().Return(batchTxHash)
+	call(t, processor, vm.AccountRef(from), to, batchOp.TotalValue(), depositMsg, []error{nil})
+
+	bc.EXPECT().GetTransaction(batchTxHash).AnyTimes().Return(batchTx, common.Hash{}, uint64(0))
+	bc.EXPECT().GetTransactionReceipt(batchTxHash).AnyTimes().Return(&types.Receipt{Status: types.ReceiptStatusSuccessful}, common.Hash{}, uint64(0))
+
+	// the sync operations initiated by the batch tx are kept by creator
+	saved := make(map[common.Hash]*types.ValidatorSync, len(entries))
+	bc.EXPECT().GetValidatorSyncData(batchTxHash, gomock.AssignableToTypeOf(common.Address{})).AnyTimes().DoAndReturn(
+		func(initTxHash common.Hash, creator common.Address) *types.ValidatorSync {
+			return saved[types.ValidatorSyncKey(initTxHash, creator)]
+		})
+	for i, e := range entries {
+		vs := &types.ValidatorSync{
+			OpType:     types.Activate,
+			ProcEpoch:  procEpoch,
+			Index:      uint64(i),
+			Creator:    e.Deposit.CreatorAddress(),
+			InitTxHash: batchTxHash,
+		}
+		saved[vs.Key()] = vs
+	}
+
+	for i, e := range entries {
+		activateOp, err := operation.NewValidatorSyncOperation(0, types.Activate, batchTxHash, procEpoch, uint64(i), e.Deposit.CreatorAddress(), nil, &withdrawalAddress, nil)
+		testutils.AssertNoError(t, err)
+		opData, err := operation.EncodeToBytes(activateOp)
+		testutils.AssertNoError(t, err)
+		msg := NewMockmessage(ctrl)
+		msg.EXPECT().Data().AnyTimes().Return(opData)
+		msg.EXPECT().TxHash().AnyTimes().Return(common.Hash{byte(i + 1)})
+		call(t, processor, vm.AccountRef(from), to, nil, msg, []error{nil})
+	}
+
+	valList := processor.Storage().GetValidatorsList(processor.state)
+	testutils.AssertEqual(t, len(entries), len(valList))
+	for i, e := range entries {
+		val, err := processor.Storage().GetValidator(processor.state, e.Deposit.CreatorAddress())
+		testutils.AssertNoError(t, err)
+		testutils.AssertEqual(t, uint64(i), val.GetIndex())
+		testutils.AssertEqual(t, testmodels.TestEra.Number+1, val.GetActivationEra())
+		testutils.AssertEqual(t, e.Deposit.CreatorAddress(), valList[i])
+	}
+
+	// the batch tx can not initiate other sync operations
+	creator := entries[0].Deposit.CreatorAddress()
+	deactivateOp, err := operation.NewValidatorSyncOperation(0, types.Deactivate, batchTxHash, procEpoch, 0, creator, nil, &withdrawalAddress, nil)
+	testutils.AssertNoError(t, err)
+	saved[types.ValidatorSyncKey(batchTxHash, creator)] = &types.ValidatorSync{
+		OpType:     types.Deactivate,
+		ProcEpoch:  procEpoch,
+		Index:      0,
+		Creator:    creator,
+		InitTxHash: batchTxHash,
+	}
+	opData, err := operation.EncodeToBytes(deactivateOp)
+	testutils.AssertNoError(t, err)
+	msg := NewMockmessage(ctrl)
+	msg.EXPECT().Data().AnyTimes().Return(opData)
+	msg.EXPECT().TxHash().AnyTimes().Return(common.Hash{0xff})
+	call(t, processor, vm.AccountRef(from), to, nil, msg, []error{ErrInvalidOpCode})
+}
+
 func TestProcessorActivate(t *testing.T) {
 	activateOperation, err := operation.NewValidatorSyncOperation(0, types.Activate, initTxHash, procEpoch, 0, testmodels.Addr2, nil, &withdrawalAddress, nil)
 	testutils.AssertNoError(t, err)
@@ -645,7 +894,7 @@ func TestProcessorActivate(t *testing.T) {
 	bc.EXPECT().GetEraInfo().AnyTimes().Return(&eraInfo)
 	bc.EXPECT().Database().AnyTimes().Return(db)
 	bc.EXPECT().GetValidatorSyncData(
-		gomock.AssignableToTypeOf(common.Hash{})).
+		gomock.AssignableToTypeOf(common.Hash{}), gomock.AssignableToTypeOf(common.Address{})).
 		AnyTimes().Return(&types.ValidatorSync{
 		OpType:     activateOperation.OpType(),
 		ProcEpoch:  activateOperation.ProcEpoch(),
@@ -1183,7 +1432,7 @@ func TestProcessorDeactivate(t *testing.T) {
 	})
 	bc.EXPECT().GetEraInfo().AnyTimes().Return(&eraInfo)
 	bc.EXPECT().Database().AnyTimes().Return(db)
-	bc.EXPECT().GetValidatorSyncData(gomock.AssignableToTypeOf(common.Hash{})).
+	bc.EXPECT().GetValidatorSyncData(gomock.AssignableToTypeOf(common.Hash{}), gomock.AssignableToTypeOf(common.Address{})).
 		AnyTimes().Return(&types.ValidatorSync{
 		OpType:     deactivateOp.OpType(),
 		ProcEpoch:  deactivateOp.ProcEpoch(),
@@ -1686,7 +1935,7 @@ func TestProcessorUpdateBalance(t *testing.T) {
 		SlotsPerEpoch:  testmodels.TestChainConfig.SlotsPerEpoch,
 	})
 	bc.EXPECT().GetValidatorSyncData(
-		gomock.AssignableToTypeOf(common.Hash{})).
+		gomock.AssignableToTypeOf(common.Hash{}), gomock.AssignableToTypeOf(common.Address{})).
 		AnyTimes().Return(&types.ValidatorSync{
 		OpType:     updateBalanceOperation.OpType(),
 		ProcEpoch:  updateBalanceOperation.ProcEpoch(),
@@ -1948,7 +2197,7 @@ func TestProcessorUpdateBalance_DelegatingStake(t *testing.T) {
 				testutils.AssertNoError(t, err)
 
 				bc.EXPECT().GetValidatorSyncData(
-					gomock.AssignableToTypeOf(common.Hash{}),
+					gomock.AssignableToTypeOf(common.Hash{}), gomock.AssignableToTypeOf(common.Address{}),
 				).Return(&types.ValidatorSync{
 					OpType:     updateBalanceOperation.OpType(),
 					ProcEpoch:  updateBalanceOperation.ProcEpoch(),
@@ -2080,7 +2329,7 @@ func TestProcessorUpdateBalance_DelegatingStake(t *testing.T) {
 				)
 				testutils.AssertNoError(t, err)
 				bc.EXPECT().GetValidatorSyncData(
-					gomock.AssignableToTypeOf(common.Hash{})).
+					gomock.AssignableToTypeOf(common.Hash{}), gomock.AssignableToTypeOf(common.Address{})).
 					AnyTimes().Return(&types.ValidatorSync{
 					OpType:     updateBalanceOperation.OpType(),
 					ProcEpoch:  updateBalanceOperation.ProcEpoch(),
@@ -2213,7 +2462,7 @@ func TestProcessorUpdateBalance_DelegatingStake(t *testing.T) {
 				testutils.AssertNoError(t, err)
 
 				bc.EXPECT().GetValidatorSyncData(
-					gomock.AssignableToTypeOf(common.Hash{})).
+					gomock.AssignableToTypeOf(common.Hash{}), gomock.AssignableToTypeOf(common.Address{})).
 					AnyTimes().Return(&types.ValidatorSync{
 					OpType:     updateBalanceOperation.OpType(),
 					ProcEpoch:  updateBalanceOperation.ProcEpoch(),
@@ -2363,7 +2612,7 @@ func TestProcessorValidatorSyncProcessing(t *testing.T) {
 			Fn: func(c *testmodels.TestCase) {
 				v := c.TestData.(testmodels.TestData)
 				valSyncData.Amount = nil
-				bc.EXPECT().GetValidatorSyncData(initTxHash).Return(&valSyncData)
+				bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).Return(&valSyncData)
 				processor.ctx.Slot = math.MaxUint64 - 1
 				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
 				processor.ctx.Slot = 0
@@ -2378,7 +2627,7 @@ func TestProcessorValidatorSyncProcessing(t *testing.T) {
 			Errs: []error{ErrNoSavedValSyncOp},
 			Fn: func(c *testmodels.TestCase) {
 				v := c.TestData.(testmodels.TestData)
-				bc.EXPECT().GetValidatorSyncData(initTxHash).Return(nil)
+				bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).Return(nil)
 				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
 			},
 		},
@@ -2394,7 +2643,7 @@ func TestProcessorValidatorSyncProcessing(t *testing.T) {
 				hash := common.BytesToHash(testutils.RandomStringInBytes(32))
 				valSyncData.TxHash = &hash
 				valSyncData.InitTxHash = initTxHash
-				bc.EXPECT().GetValidatorSyncData(initTxHash).Return(&valSyncData)
+				bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).Return(&valSyncData)
 				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
 				valSyncData.TxHash = &txHash
 			},
@@ -2409,7 +2658,7 @@ func TestProcessorValidatorSyncProcessing(t *testing.T) {
 			Fn: func(c *testmodels.TestCase) {
 				v := c.TestData.(testmodels.TestData)
 				valSyncData.OpType = types.Deactivate
-				bc.EXPECT().GetValidatorSyncData(initTxHash).Return(&valSyncData)
+				bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).Return(&valSyncData)
 				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
 				valSyncData.OpType = types.Activate
 			},
@@ -2424,7 +2673,7 @@ func TestProcessorValidatorSyncProcessing(t *testing.T) {
 			Fn: func(c *testmodels.TestCase) {
 				v := c.TestData.(testmodels.TestData)
 				valSyncData.Creator = withdrawalAddress
-				bc.EXPECT().GetValidatorSyncData(initTxHash).Return(&valSyncData)
+				bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).Return(&valSyncData)
 				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
 				valSyncData.Creator = testCreatorAddress
 			},
@@ -2439,7 +2688,7 @@ func TestProcessorValidatorSyncProcessing(t *testing.T) {
 			Fn: func(c *testmodels.TestCase) {
 				v := c.TestData.(testmodels.TestData)
 				valSyncData.Index = testIndex + 1
-				bc.EXPECT().GetValidatorSyncData(initTxHash).Return(&valSyncData)
+				bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).Return(&valSyncData)
 				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
 				valSyncData.Index = testIndex
 			},
@@ -2454,7 +2703,7 @@ func TestProcessorValidatorSyncProcessing(t *testing.T) {
 			Fn: func(c *testmodels.TestCase) {
 				v := c.TestData.(testmodels.TestData)
 				valSyncData.ProcEpoch = procEpoch + 1
-				bc.EXPECT().GetValidatorSyncData(initTxHash).Return(&valSyncData)
+				bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).Return(&valSyncData)
 				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
 				valSyncData.ProcEpoch = procEpoch
 			},
@@ -2469,7 +2718,7 @@ func TestProcessorValidatorSyncProcessing(t *testing.T) {
 			Fn: func(c *testmodels.TestCase) {
 				v := c.TestData.(testmodels.TestData)
 				valSyncData.Amount = testAmount.Add(testAmount, testAmount)
-				bc.EXPECT().GetValidatorSyncData(initTxHash).Return(&valSyncData)
+				bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).Return(&valSyncData)
 				call(t, processor, v.Caller, v.AddrTo, value, msg, c.Errs)
 			},
 		},
@@ -2595,3 +2844,458 @@ func call(t *testing.T, processor *Processor, Caller Ref, addrTo common.Address,
 
 	return res
 }
+
+func TestProcessorDelegatorWithdrawal(t *testing.T) {
+	ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		delegator      = common.HexToAddress("0x7777777777777777777777777777777777777777")
+		stakeHolder    = common.HexToAddress("0x4444444444444444444444444444444444444444")
+		delegatorStake = new(big.Int).Mul(big.NewInt(100), common.BigWat)
+		holderStake    = new(big.Int).Mul(big.NewInt(3150), common.BigWat)
+		txHash         = common.Hash{0x11}
+	)
+
+	dsProfitShare, dsStakeShare, dsExit, dsWithdrawal := operation.TestParamsDelegatingStakeRules()
+	rules, _ := operation.NewDelegatingStakeRules(dsProfitShare, dsStakeShare, dsExit, dsWithdrawal)
+	trialRules, _ := operation.NewDelegatingStakeRules(dsProfitShare, dsStakeShare, dsExit, dsWithdrawal)
+	delegateData, err := operation.NewDelegatingStakeData(rules, 321, trialRules)
+	testutils.AssertNoError(t, err)
+
+	initMock := func() (*Processor, common.Address) {
+		stateDb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		bc := NewMockblockchain(ctrl)
+		bc.EXPECT().Config().Return(testmodels.TestChainConfig).AnyTimes()
+		bc.EXPECT().GetSlotInfo().AnyTimes().Return(&types.SlotInfo{
+			GenesisTime:    uint64(time.Now().Unix()),
+			SecondsPerSlot: testmodels.TestChainConfig.SecondsPerSlot,
+			SlotsPerEpoch:  testmodels.TestChainConfig.SlotsPerEpoch,
+		})
+		db := rawdb.NewMemoryDatabase()
+		rawdb.WriteEra(db, eraInfo.Number(), *eraInfo.GetEra())
+		bc.EXPECT().Database().AnyTimes().Return(db)
+
+		processor := NewProcessor(ctx, stateDb, bc)
+		processor.ctx.Slot = eraInfo.GetEra().From * testmodels.TestChainConfig.SlotsPerEpoch
+		processor.ctx.Era = eraInfo.GetEra().Number
+
+		validator := storage.NewValidator(pubKey, testmodels.Addr1, &withdrawalAddress)
+		validator.ActivationEra = eraInfo.GetEra().Number
+		validator.DelegatingStake = delegateData
+		validator.AddStake(delegator, new(big.Int).Set(delegatorStake))
+		validator.AddStake(stakeHolder, new(big.Int).Set(holderStake))
+		err := processor.Storage().SetValidator(processor.state, validator)
+		testutils.AssertNoError(t, err)
+
+		return processor, processor.GetValidatorsStateAddress()
+	}
+
+	newMsg := func(from common.Address, amount *big.Int) message {
+		op, err := operation.NewDelegatorWithdrawalOperation(testmodels.Addr1, from, amount)
+		testutils.AssertNoError(t, err)
+		opData, err := operation.EncodeToBytes(op)
+		testutils.AssertNoError(t, err)
+		msg := NewMockmessage(ctrl)
+		msg.EXPECT().Data().AnyTimes().Return(opData)
+		msg.EXPECT().TxHash().AnyTimes().Return(txHash)
+		return msg
+	}
+
+	cases := []struct {
+		name     string
+		caller   common.Address
+		opFrom   common.Address
+		amount   *big.Int
+		slot     *uint64
+		errs     []error
+		expected *big.Int
+	}{
+		{
+			name:   "fork required",
+			caller: delegator,
+			opFrom: delegator,
+			amount: new(big.Int),
+			slot:   new(uint64),
+			errs:   []error{operation.ErrDelegatorWithdrawalForkRequire},
+		},
+		{
+			name:   "sender mismatch delegator",
+			caller: stakeHolder,
+			opFrom: delegator,
+			amount: new(big.Int),
+			errs:   []error{ErrInvalidFromAddresses},
+		},
+		{
+			name:   "rejected by rules",
+			caller: stakeHolder,
+			opFrom: stakeHolder,
+			amount: new(big.Int),
+			errs:   []error{ErrSenderRejByDelegate},
+		},
+		{
+			name:   "amount exceeds stake",
+			caller: delegator,
+			opFrom: delegator,
+			amount: new(big.Int).Add(delegatorStake, common.Big1),
+			errs:   []error{ErrInsufficientFundsForOp},
+		},
+		{
+			name:   "stake below effective balance",
+			caller: delegator,
+			opFrom: delegator,
+			amount: new(big.Int),
+			errs:   []error{ErrStakeBelowMinimum},
+		},
+		{
+			name:     "partial withdrawal OK",
+			caller:   delegator,
+			opFrom:   delegator,
+			amount:   new(big.Int).Mul(big.NewInt(50), common.BigWat),
+			errs:     []error{nil},
+			expected: new(big.Int).Mul(big.NewInt(50), common.BigGwei),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			processor, to := initMock()
+			if c.slot != nil {
+				processor.ctx.Slot = *c.slot
+			}
+			call(t, processor, vm.AccountRef(c.caller), to, nil, newMsg(c.opFrom, c.amount), c.errs)
+			if c.expected == nil {
+				return
+			}
+
+			validator, err := processor.Storage().GetValidator(processor.state, testmodels.Addr1)
+			testutils.AssertNoError(t, err)
+			testutils.AssertEqual(t, txHash, *validator.GetWithdrawalTx())
+			// stake list changes only while update balance
+			if !testutils.BigIntEquals(delegatorStake, validator.StakeByAddress(delegator)) {
+				t.Fatalf("unexpected delegator stake: %s", validator.StakeByAddress(delegator))
+			}
+
+			logs := processor.state.Logs()
+			testutils.AssertEqual(t, 1, len(logs))
+			testutils.AssertEqual(t, txlog.EvtWithdrawalLogSignature, logs[0].Topics[0])
+			_, creator, _, amtGwei, err := txlog.UnpackWithdrawalLogData(logs[0].Data)
+			testutils.AssertNoError(t, err)
+			testutils.AssertEqual(t, testmodels.Addr1, creator)
+			testutils.AssertEqual(t, c.expected.Uint64(), amtGwei)
+		})
+	}
+}
+
+func TestProcessorUpdateBalance_DelegatorWithdrawal(t *testing.T) {
+	ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		delegator      = common.HexToAddress("0x7777777777777777777777777777777777777777")
+		stakeHolder    = common.HexToAddress("0x4444444444444444444444444444444444444444")
+		delegatorStake = new(big.Int).Mul(big.NewInt(100), common.BigWat)
+		holderStake    = new(big.Int).Mul(big.NewInt(3200), common.BigWat)
+		balance        = new(big.Int).Mul(big.NewInt(3300), common.BigWat)
+	)
+
+	dsProfitShare, dsStakeShare, dsExit, dsWithdrawal := operation.TestParamsDelegatingStakeRules()
+	rules, _ := operation.NewDelegatingStakeRules(dsProfitShare, dsStakeShare, dsExit, dsWithdrawal)
+	trialRules, _ := operation.NewDelegatingStakeRules(dsProfitShare, dsStakeShare, dsExit, dsWithdrawal)
+	delegateData, err := operation.NewDelegatingStakeData(rules, 321, trialRules)
+	testutils.AssertNoError(t, err)
+
+	cases := []struct {
+		name        string
+		from        common.Address
+		noRules     bool
+		holderStake *big.Int
+		amount      *big.Int
+		balance     *big.Int
+		credited    *big.Int
+		restStake   *big.Int
+		err         error
+	}{
+		{
+			name:      "partial",
+			from:      delegator,
+			amount:    new(big.Int).Mul(big.NewInt(40), common.BigWat),
+			balance:   balance,
+			credited:  new(big.Int).Mul(big.NewInt(40), common.BigWat),
+			restStake: new(big.Int).Mul(big.NewInt(60), common.BigWat),
+		},
+		{
+			name:      "partial with balance below effective balance",
+			from:      delegator,
+			amount:    new(big.Int).Mul(big.NewInt(40), common.BigWat),
+			balance:   new(big.Int).Mul(big.NewInt(3199), common.BigWat),
+			credited:  new(big.Int).Mul(big.NewInt(40), common.BigWat),
+			restStake: new(big.Int).Mul(big.NewInt(60), common.BigWat),
+		},
+		{
+			name:      "whole stake",
+			from:      delegator,
+			amount:    new(big.Int).Set(delegatorStake),
+			balance:   balance,
+			credited:  new(big.Int).Set(delegatorStake),
+			restStake: new(big.Int),
+		},
+		{
+			name:      "amount exceeds stake",
+			from:      delegator,
+			amount:    new(big.Int).Mul(big.NewInt(101), common.BigWat),
+			balance:   balance,
+			credited:  new(big.Int),
+			restStake: new(big.Int).Set(delegatorStake),
+			err:       ErrInsufficientFundsForOp,
+		},
+		{
+			name:        "stake below minimum",
+			from:        delegator,
+			holderStake: new(big.Int).Mul(big.NewInt(3150), common.BigWat),
+			amount:      new(big.Int).Set(delegatorStake),
+			balance:     balance,
+			credited:    new(big.Int),
+			restStake:   new(big.Int).Set(delegatorStake),
+			err:         ErrStakeBelowMinimum,
+		},
+		{
+			name:      "rejected by rules",
+			from:      stakeHolder,
+			amount:    new(big.Int).Mul(big.NewInt(40), common.BigWat),
+			balance:   balance,
+			credited:  new(big.Int),
+			restStake: new(big.Int).Set(holderStake),
+			err:       ErrSenderRejByDelegate,
+		},
+		{
+			name:      "validator without delegating stake",
+			from:      stakeHolder,
+			noRules:   true,
+			amount:    new(big.Int).Mul(big.NewInt(40), common.BigWat),
+			balance:   balance,
+			credited:  new(big.Int).Mul(big.NewInt(40), common.BigWat),
+			restStake: new(big.Int).Mul(big.NewInt(3160), common.BigWat),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stateDb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			db := rawdb.NewMemoryDatabase()
+			rawdb.WriteEra(db, eraInfo.Number(), *eraInfo.GetEra())
+
+			dwOp, err := operation.NewDelegatorWithdrawalOperation(testmodels.Addr1, c.from, c.amount)
+			testutils.AssertNoError(t, err)
+			initTxData, err := operation.EncodeToBytes(dwOp)
+			testutils.AssertNoError(t, err)
+			initTx := types.NewTx(&types.AccessListTx{Data: initTxData})
+
+			upBalOp, err := operation.NewValidatorSyncOperation(
+				operation.Ver1,
+				types.UpdateBalance,
+				initTxHash,
+				procEpoch,
+				0,
+				testmodels.Addr1,
+				c.amount,
+				&withdrawalAddress,
+				c.balance,
+			)
+			testutils.AssertNoError(t, err)
+			opData, err := operation.EncodeToBytes(upBalOp)
+			testutils.AssertNoError(t, err)
+
+			msg := NewMockmessage(ctrl)
+			msg.EXPECT().TxHash().AnyTimes().Return(common.Hash{0x22})
+			msg.EXPECT().Data().AnyTimes().Return(opData)
+
+			bc := NewMockblockchain(ctrl)
+			bc.EXPECT().Config().Return(testmodels.TestChainConfig).AnyTimes()
+			bc.EXPECT().Database().AnyTimes().Return(db)
+			bc.EXPECT().GetSlotInfo().AnyTimes().Return(&types.SlotInfo{
+				GenesisTime:    uint64(time.Now().Unix()),
+				SecondsPerSlot: testmodels.TestChainConfig.SecondsPerSlot,
+				SlotsPerEpoch:  testmodels.TestChainConfig.SlotsPerEpoch,
+			})
+			bc.EXPECT().GetTransaction(initTxHash).AnyTimes().Return(initTx, common.Hash{}, uint64(0))
+			bc.EXPECT().GetTransactionReceipt(initTxHash).AnyTimes().Return(&types.Receipt{Status: types.ReceiptStatusSuccessful}, common.Hash{}, uint64(0))
+			bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).AnyTimes().Return(&types.ValidatorSync{
+				OpType:     upBalOp.OpType(),
+				ProcEpoch:  upBalOp.ProcEpoch(),
+				Index:      upBalOp.Index(),
+				Creator:    upBalOp.Creator(),
+				Amount:     upBalOp.Amount(),
+				Balance:    upBalOp.Balance(),
+				InitTxHash: initTxHash,
+			})
+
+			processor := NewProcessor(ctx, stateDb, bc)
+			processor.ctx.Slot = eraInfo.GetEra().From * testmodels.TestChainConfig.SlotsPerEpoch
+			processor.ctx.Era = eraInfo.GetEra().Number
+
+			hStake := holderStake
+			if c.holderStake != nil {
+				hStake = c.holderStake
+			}
+			validator := storage.NewValidator(pubKey, testmodels.Addr1, &withdrawalAddress)
+			validator.ActivationEra = eraInfo.GetEra().Number
+			if !c.noRules {
+				validator.DelegatingStake = delegateData
+			}
+			validator.SetVersion(storage.Ver1)
+			validator.SetWithdrawalTx(&initTxHash)
+			validator.AddStake(delegator, new(big.Int).Set(delegatorStake))
+			validator.AddStake(stakeHolder, new(big.Int).Set(hStake))
+			err = processor.Storage().SetValidator(processor.state, validator)
+			testutils.AssertNoError(t, err)
+
+			call(t, processor, vm.AccountRef(withdrawalAddress), processor.GetValidatorsStateAddress(), nil, msg, []error{c.err})
+
+			// the withdrawn stake is transferred to delegator only
+			if !testutils.BigIntEquals(c.credited, processor.state.GetBalance(c.from)) {
+				t.Fatalf("unexpected delegator balance: %s", processor.state.GetBalance(c.from))
+			}
+			if processor.state.GetBalance(withdrawalAddress).Sign() != 0 {
+				t.Fatalf("unexpected withdrawal address balance: %s", processor.state.GetBalance(withdrawalAddress))
+			}
+
+			validator, err = processor.Storage().GetValidator(processor.state, testmodels.Addr1)
+			testutils.AssertNoError(t, err)
+			if !testutils.BigIntEquals(c.restStake, validator.StakeByAddress(c.from)) {
+				t.Fatalf("unexpected delegator stake: %s", validator.StakeByAddress(c.from))
+			}
+			if c.err != nil {
+				return
+			}
+			testutils.AssertNil(t, validator.GetWithdrawalTx())
+
+			var dwLog *types.Log
+			for _, l := range processor.state.Logs() {
+				if l.Topics[0] == txlog.EvtDelegatorWithdrawalLogSignature {
+					dwLog = l
+				}
+			}
+			if dwLog == nil {
+				t.Fatal("delegator withdrawal log not found")
+			}
+			logData, err := txlog.UnpackDelegatorWithdrawalLogData(dwLog.Data)
+			testutils.AssertNoError(t, err)
+			testutils.AssertEqual(t, c.from, logData.DelegatorAddress)
+			if !testutils.BigIntEquals(c.restStake, logData.Stake) {
+				t.Fatalf("unexpected logged stake: %s", logData.Stake)
+			}
+		})
+	}
+}
+
+// Tests that the stake withdrawn by a delegator is not shared again
+// while the exit of validator.
+func TestProcessorUpdateBalance_DelegatingStakeList(t *testing.T) {
+	ctrl = gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		delegator   = common.HexToAddress("0x7777777777777777777777777777777777777777")
+		stakeHolder = common.HexToAddress("0x4444444444444444444444444444444444444444")
+		// the delegator has withdrawn 60 of 100
+		delegatorStake = new(big.Int).Mul(big.NewInt(40), common.BigWat)
+		holderStake    = new(big.Int).Mul(big.NewInt(3200), common.BigWat)
+		// exit balance: the rest stakes and profit
+		amount = new(big.Int).Mul(big.NewInt(3290), common.BigWat)
+		profit = new(big.Int).Mul(big.NewInt(50), common.BigWat)
+	)
+
+	wOp, err := operation.NewWithdrawalOperation(testmodels.Addr1, new(big.Int))
+	testutils.AssertNoError(t, err)
+	initTxData, err := operation.EncodeToBytes(wOp)
+	testutils.AssertNoError(t, err)
+	initTx := types.NewTx(&types.AccessListTx{Data: initTxData})
+
+	dsProfitShare, dsStakeShare, dsExit, dsWithdrawal := operation.TestParamsDelegatingStakeRules()
+	rules, _ := operation.NewDelegatingStakeRules(dsProfitShare, dsStakeShare, dsExit, dsWithdrawal)
+	trialRules, _ := operation.NewDelegatingStakeRules(dsProfitShare, dsStakeShare, dsExit, dsWithdrawal)
+	delegateData, err := operation.NewDelegatingStakeData(rules, 321, trialRules)
+	testutils.AssertNoError(t, err)
+
+	stateDb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteEra(db, eraInfo.Number(), *eraInfo.GetEra())
+
+	upBalOp, err := operation.NewValidatorSyncOperation(
+		operation.Ver1,
+		types.UpdateBalance,
+		initTxHash,
+		procEpoch,
+		0,
+		testmodels.Addr1,
+		amount,
+		&withdrawalAddress,
+		new(big.Int),
+	)
+	testutils.AssertNoError(t, err)
+	opData, err := operation.EncodeToBytes(upBalOp)
+	testutils.AssertNoError(t, err)
+
+	msg := NewMockmessage(ctrl)
+	msg.EXPECT().TxHash().AnyTimes().Return(common.Hash{0x22})
+	msg.EXPECT().Data().AnyTimes().Return(opData)
+
+	bc := NewMockblockchain(ctrl)
+	bc.EXPECT().Config().Return(testmodels.TestChainConfig).AnyTimes()
+	bc.EXPECT().Database().AnyTimes().Return(db)
+	bc.EXPECT().GetSlotInfo().AnyTimes().Return(&types.SlotInfo{
+		GenesisTime:    uint64(time.Now().Unix()),
+		SecondsPerSlot: testmodels.TestChainConfig.SecondsPerSlot,
+		SlotsPerEpoch:  testmodels.TestChainConfig.SlotsPerEpoch,
+	})
+	bc.EXPECT().GetTransaction(initTxHash).AnyTimes().Return(initTx, common.Hash{}, uint64(0))
+	bc.EXPECT().GetTransactionReceipt(initTxHash).AnyTimes().Return(&types.Receipt{Status: types.ReceiptStatusSuccessful}, common.Hash{}, uint64(0))
+	bc.EXPECT().GetValidatorSyncData(initTxHash, gomock.AssignableToTypeOf(common.Address{})).AnyTimes().Return(&types.ValidatorSync{
+		OpType:     upBalOp.OpType(),
+		ProcEpoch:  upBalOp.ProcEpoch(),
+		Index:      upBalOp.Index(),
+		Creator:    upBalOp.Creator(),
+		Amount:     upBalOp.Amount(),
+		Balance:    upBalOp.Balance(),
+		InitTxHash: initTxHash,
+	})
+
+	processor := NewProcessor(ctx, stateDb, bc)
+	processor.ctx.Slot = eraInfo.GetEra().From * testmodels.TestChainConfig.SlotsPerEpoch
+	processor.ctx.Era = eraInfo.GetEra().Number
+
+	validator := storage.NewValidator(pubKey, testmodels.Addr1, &withdrawalAddress)
+	validator.ActivationEra = eraInfo.GetEra().Number
+	validator.ExitEra = eraInfo.GetEra().Number
+	validator.DelegatingStake = delegateData
+	validator.SetVersion(storage.Ver1)
+	validator.SetWithdrawalTx(&initTxHash)
+	validator.AddStake(delegator, new(big.Int).Set(delegatorStake))
+	validator.AddStake(stakeHolder, new(big.Int).Set(holderStake))
+	err = processor.Storage().SetValidator(processor.state, validator)
+	testutils.AssertNoError(t, err)
+
+	call(t, processor, vm.AccountRef(withdrawalAddress), processor.GetValidatorsStateAddress(), nil, msg, []error{nil})
+
+	// the stake is shared by the rest stakes, the profit by the rules
+	if !testutils.BigIntEquals(delegatorStake, processor.state.GetBalance(delegator)) {
+		t.Fatalf("unexpected delegator balance: %s", processor.state.GetBalance(delegator))
+	}
+	if !testutils.BigIntEquals(holderStake, processor.state.GetBalance(stakeHolder)) {
+		t.Fatalf("unexpected stake holder balance: %s", processor.state.GetBalance(stakeHolder))
+	}
+	profitAdr := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	expProfit := new(big.Int).Div(new(big.Int).Mul(profit, big.NewInt(60)), big.NewInt(100))
+	if !testutils.BigIntEquals(expProfit, processor.state.GetBalance(profitAdr)) {
+		t.Fatalf("unexpected profit balance: %s", processor.state.GetBalance(profitAdr))
+	}
+	if share := processor.state.GetBalance(common.HexToAddress("0x5555555555555555555555555555555555555555")); share.Sign() != 0 {
+		t.Fatalf("unexpected stake share: %s", share)
+	}
+
+	validator, err = processor.Storage().GetValidator(processor.state, testmodels.Addr1)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, 0, len(validator.Stake))
+	testutils.AssertNil(t, validator.GetWithdrawalTx())
+}
@@ -22,10 +22,12 @@ import (
 	"time"
 
 	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/state"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/core/vm"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/ethdb"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/params"
 	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
@@ -58,12 +60,15 @@ type Storage interface {
 	IncrementDepositCount(stateDb vm.StateDB)
 
 	PrepareNextEraValidators(bc blockchain, era *era.Era)
+	ResetValidatorsCache(fromEra uint64)
+	RestoreEraValidators(bc blockchain, era *era.Era) error
 }
 
 type storage struct {
 	validatorsCache   *ValidatorsCache
 	config            *params.ChainConfig
 	processTransition map[uint64]struct{}
+	db                ethdb.KeyValueStore
 }
 
 func NewStorage(config *params.ChainConfig) Storage {
@@ -73,6 +78,17 @@ func NewStorage(config *params.ChainConfig) Storage {
 		processTransition: make(map[uint64]struct{}),
 	}
 }
+
+// NewStorageWithDatabase creates the storage which persists
+// the active validators of eras to the database to warm the cache up after restart.
+func NewStorageWithDatabase(config *params.ChainConfig, db ethdb.KeyValueStore) Storage {
+	return &storage{
+		validatorsCache:   NewCache(),
+		config:            config,
+		processTransition: make(map[uint64]struct{}),
+		db:                db,
+	}
+}
 func (s *storage) ValidatorsStateAddress() *common.Address {
 	return s.config.ValidatorsStateAddress
 }
@@ -163,8 +179,22 @@ func (s *storage) GetValidators(bc blockchain, slot uint64, tmpFromWhere string)
 
 	validators := s.validatorsCache.getAllActiveValidatorsByEra(slotEra.Number)
 	if validators != nil {
+		validatorsCacheHitMeter.Mark(1)
 		return validators, nil
 	}
+	validatorsCacheMissMeter.Mark(1)
+
+	if dbValidators := s.loadEraValidators(slotEra); dbValidators != nil {
+		validatorsCacheDbHitMeter.Mark(1)
+		s.validatorsCache.addAllActiveValidatorsByEra(slotEra.Number, dbValidators)
+
+		validators = make([]common.Address, len(dbValidators))
+		copy(validators, dbValidators)
+
+		return validators, nil
+	}
+
+	rebuildStart := time.Now()
 	log.Info("Get validators", "epoch", slotEpoch, "era", slotEra.Number, "root", slotEra.Root.Hex())
 
 	stateDb, _ := bc.StateAt(slotEra.Root)
@@ -183,6 +213,8 @@ func (s *storage) GetValidators(bc blockchain, slot uint64, tmpFromWhere string)
 	}
 
 	s.validatorsCache.addAllActiveValidatorsByEra(slotEra.Number, eraValidators)
+	s.persistEraValidators(slotEra.Number, slotEra.Root, eraValidators)
+	validatorsRebuildTimer.UpdateSince(rebuildStart)
 
 	log.Info("GetValidators", "callFunc", tmpFromWhere, "all", len(validators),
 		"active", len(eraValidators),
@@ -221,8 +253,10 @@ func (s *storage) GetCreatorsBySlot(bc blockchain, filter ...uint64) ([]common.A
 	if err != nil && err == ErrInvalidValidatorsFilter {
 		return nil, err
 	} else if err == nil {
+		creatorsCacheHitMeter.Mark(1)
 		return validators, nil
 	}
+	creatorsCacheMissMeter.Mark(1)
 
 	allValidators, err := s.GetValidators(bc, slot, "GetCreatorsBySlot")
 	if err != nil || len(allValidators) == 0 {
@@ -273,6 +307,7 @@ func (s *storage) GetCreatorsBySlot(bc blockchain, filter ...uint64) ([]common.A
 		log.Error("can`t add shuffled validators to cache", "error", err)
 		return nil, err
 	}
+	creatorsRebuildTimer.UpdateSince(start)
 
 	log.Info("^^^^^^^^^^^^ TIME",
 		"elapsed", common.PrettyDuration(time.Since(start)),
@@ -366,6 +401,14 @@ func (s *storage) GetActiveValidatorsCount(bc blockchain, slot uint64) (uint64,
 
 func (s *storage) PrepareNextEraValidators(bc blockchain, era *era.Era) {
 	s.processTransition[era.Number] = struct{}{}
+	rebuildStart := time.Now()
+
+	// clear the era data cached before to rebuild it from the era state.
+	s.validatorsCache.delAllActiveValidatorsFromEra(era.Number)
+	if s.db != nil {
+		rawdb.DeleteEraValidators(s.db, era.Number)
+	}
+
 	stateDb, _ := bc.StateAt(era.Root)
 
 	valList := s.GetValidatorsList(stateDb)
@@ -381,6 +424,14 @@ func (s *storage) PrepareNextEraValidators(bc blockchain, era *era.Era) {
 		}
 	}
 
+	if validators := s.validatorsCache.getAllActiveValidatorsByEra(era.Number); validators != nil {
+		s.persistEraValidators(era.Number, era.Root, validators)
+	}
+	if s.db != nil && era.Number >= cacheCapacity {
+		rawdb.DeleteEraValidators(s.db, era.Number-cacheCapacity)
+	}
+	validatorsRebuildTimer.UpdateSince(rebuildStart)
+
 	log.Info("Prepare next era validators",
 		"eraNumber", era.Number,
 		"eraRoot", era.Root,
@@ -92,6 +92,27 @@ func (c *ValidatorsCache) getAllActiveValidatorsByEra(era uint64) []common.Addre
 	return eraValidators
 }
 
+// delAllActiveValidatorsFromEra removes active validators of all eras starting from the given one.
+func (c *ValidatorsCache) delAllActiveValidatorsFromEra(fromEra uint64) {
+	c.allMu.Lock()
+	defer c.allMu.Unlock()
+
+	for e := range c.allActiveValidatorsCache {
+		if e >= fromEra {
+			delete(c.allActiveValidatorsCache, e)
+		}
+	}
+}
+
+// resetShuffledValidators removes all shuffled validators from the cache.
+func (c *ValidatorsCache) resetShuffledValidators() {
+	c.shuffledMu.Lock()
+	defer c.shuffledMu.Unlock()
+
+	c.shuffledValidatorsCache = make(map[uint64][][]common.Address)
+	c.shuffledSubnetValidatorsCache = make(map[uint64]map[uint64][][]common.Address)
+}
+
 //nolint:unused // subnets support
 func (c *ValidatorsCache) addSubnetValidators(epoch, subnet uint64, validators []common.Address) {
 	c.subnetMu.Lock()
@@ -0,0 +1,185 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"errors"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/crypto"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/log"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/metrics"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
+)
+
+var (
+	errBadCacheChecksum  = errors.New("bad cached validators checksum")
+	errCacheRootMismatch = errors.New("cached validators built from another era root")
+
+	validatorsCacheHitMeter     = metrics.NewRegisteredMeter("validator/storage/cache/hit", nil)
+	validatorsCacheMissMeter    = metrics.NewRegisteredMeter("validator/storage/cache/miss", nil)
+	validatorsCacheDbHitMeter   = metrics.NewRegisteredMeter("validator/storage/cache/db/hit", nil)
+	validatorsCacheInvalidMeter = metrics.NewRegisteredMeter("validator/storage/cache/db/invalid", nil)
+	validatorsRebuildTimer      = metrics.NewRegisteredTimer("validator/storage/cache/rebuild", nil)
+
+	creatorsCacheHitMeter  = metrics.NewRegisteredMeter("validator/storage/creators/hit", nil)
+	creatorsCacheMissMeter = metrics.NewRegisteredMeter("validator/storage/creators/miss", nil)
+	creatorsRebuildTimer   = metrics.NewRegisteredTimer("validator/storage/creators/rebuild", nil)
+)
+
+// eraValidatorsHeaderLen is the length of the era root and the checksum
+// preceding the validators addresses in the persisted data.
+const eraValidatorsHeaderLen = 2 * common.HashLength
+
+// eraValidatorsChecksum calculates the checksum of the persisted era validators.
+func eraValidatorsChecksum(root common.Hash, validators []common.Address) common.Hash {
+	data := make([][]byte, 0, len(validators)+1)
+	data = append(data, root.Bytes())
+	for _, addr := range validators {
+		data = append(data, addr.Bytes())
+	}
+
+	return crypto.Keccak256Hash(data...)
+}
+
+// marshalEraValidators encodes the active validators of era
+// as root + checksum + addresses.
+func marshalEraValidators(root common.Hash, validators []common.Address) []byte {
+	data := make([]byte, 0, eraValidatorsHeaderLen+len(validators)*common.AddressLength)
+	data = append(data, root.Bytes()...)
+	data = append(data, eraValidatorsChecksum(root, validators).Bytes()...)
+	for _, addr := range validators {
+		data = append(data, addr.Bytes()...)
+	}
+
+	return data
+}
+
+// unmarshalEraValidators decodes the persisted active validators of era
+// and verifies the checksum.
+func unmarshalEraValidators(data []byte) (common.Hash, []common.Address, error) {
+	if len(data) < eraValidatorsHeaderLen || (len(data)-eraValidatorsHeaderLen)%common.AddressLength != 0 {
+		return common.Hash{}, nil, errBadBinaryData
+	}
+
+	root := common.BytesToHash(data[:common.HashLength])
+	checksum := common.BytesToHash(data[common.HashLength:eraValidatorsHeaderLen])
+
+	validators := make([]common.Address, 0, (len(data)-eraValidatorsHeaderLen)/common.AddressLength)
+	for i := eraValidatorsHeaderLen; i < len(data); i += common.AddressLength {
+		validators = append(validators, common.BytesToAddress(data[i:i+common.AddressLength]))
+	}
+
+	if eraValidatorsChecksum(root, validators) != checksum {
+		return common.Hash{}, nil, errBadCacheChecksum
+	}
+
+	return root, validators, nil
+}
+
+// loadEraValidators reads the persisted active validators of era.
+// The data is verified by the checksum, which covers the order and the count
+// of the validators, and by the era root. Inconsistent data is removed
+// from the database, so the validators are rebuilt from the era state.
+func (s *storage) loadEraValidators(slotEra *era.Era) []common.Address {
+	if s.db == nil {
+		return nil
+	}
+
+	data := rawdb.ReadEraValidators(s.db, slotEra.Number)
+	if data == nil {
+		return nil
+	}
+
+	validators, err := checkEraValidators(slotEra, data)
+	if err != nil {
+		validatorsCacheInvalidMeter.Mark(1)
+		log.Warn("Drop inconsistent persisted era validators",
+			"era", slotEra.Number,
+			"root", slotEra.Root.Hex(),
+			"error", err,
+		)
+		rawdb.DeleteEraValidators(s.db, slotEra.Number)
+		return nil
+	}
+
+	return validators
+}
+
+// checkEraValidators decodes the persisted active validators of era
+// and checks they were built from the era root.
+func checkEraValidators(slotEra *era.Era, data []byte) ([]common.Address, error) {
+	root, validators, err := unmarshalEraValidators(data)
+	if err != nil {
+		return nil, err
+	}
+	if root != slotEra.Root {
+		return nil, errCacheRootMismatch
+	}
+
+	return validators, nil
+}
+
+// persistEraValidators writes the active validators of era to the database.
+func (s *storage) persistEraValidators(eraNumber uint64, root common.Hash, validators []common.Address) {
+	if s.db == nil {
+		return
+	}
+
+	rawdb.WriteEraValidators(s.db, eraNumber, marshalEraValidators(root, validators))
+}
+
+// ResetValidatorsCache removes cached validators of all eras starting from the given one
+// and all shuffled creators, so they will be rebuilt from the state.
+func (s *storage) ResetValidatorsCache(fromEra uint64) {
+	s.validatorsCache.delAllActiveValidatorsFromEra(fromEra)
+	s.validatorsCache.resetShuffledValidators()
+
+	if s.db != nil {
+		rawdb.DeleteEraValidatorsFrom(s.db, fromEra)
+	}
+
+	log.Info("Validators cache reset", "fromEra", fromEra)
+}
+
+// RestoreEraValidators builds the active validators of era from the state of the era root
+// and persists them with the era key. It is used to restore the validators
+// of the era retrieved by the checkpoint sync.
+func (s *storage) RestoreEraValidators(bc blockchain, e *era.Era) error {
+	stateDb, err := bc.StateAt(e.Root)
+	if err != nil {
+		return err
+	}
+
+	validators := make([]common.Address, 0)
+	for _, valAddress := range s.GetValidatorsList(stateDb) {
+		val, err := s.GetValidator(stateDb, valAddress)
+		if err != nil {
+			log.Error("can`t get validator from state", "error", err, "address", valAddress.Hex())
+			continue
+		}
+		if val.ActivationEra <= e.Number && val.ExitEra > e.Number {
+			validators = append(validators, val.GetAddress())
+		}
+	}
+
+	s.validatorsCache.delAllActiveValidatorsFromEra(e.Number)
+	s.validatorsCache.addAllActiveValidatorsByEra(e.Number, validators)
+	s.persistEraValidators(e.Number, e.Root, validators)
+
+	log.Info("Era validators restored", "era", e.Number, "root", e.Root.Hex(), "validators", len(validators))
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/rawdb"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/state"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/era"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/testmodels"
+)
+
+func TestEraValidatorsMarshaling(t *testing.T) {
+	root := common.HexToHash("0x1234")
+	data := marshalEraValidators(root, testmodels.InputValidators)
+
+	gotRoot, gotValidators, err := unmarshalEraValidators(data)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, root, gotRoot)
+	testutils.AssertEqual(t, testmodels.InputValidators, gotValidators)
+
+	data[len(data)-1] ^= 0xff
+	_, _, err = unmarshalEraValidators(data)
+	testutils.AssertError(t, err, errBadCacheChecksum)
+
+	_, _, err = unmarshalEraValidators(data[:len(data)-1])
+	testutils.AssertError(t, err, errBadBinaryData)
+}
+
+func TestGetValidators_PersistentCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := rawdb.NewMemoryDatabase()
+	stateDb, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	testutils.AssertNoError(t, err)
+
+	eraRoot := common.HexToHash("0x1234")
+	testEra := &era.Era{Number: 5, Root: eraRoot}
+
+	bc := NewMockblockchain(ctrl)
+	bc.EXPECT().GetSlotInfo().AnyTimes().Return(&types.SlotInfo{
+		GenesisTime:    uint64(time.Now().Unix()),
+		SecondsPerSlot: testmodels.TestChainConfig.SecondsPerSlot,
+		SlotsPerEpoch:  testmodels.TestChainConfig.SlotsPerEpoch,
+	})
+	bc.EXPECT().EpochToEra(gomock.AssignableToTypeOf(uint64(0))).AnyTimes().DoAndReturn(func(uint64) *era.Era {
+		return testEra
+	})
+	bc.EXPECT().StateAt(gomock.AssignableToTypeOf(eraRoot)).AnyTimes().Return(stateDb, nil)
+
+	store := NewStorageWithDatabase(testmodels.TestChainConfig, db)
+	store.SetValidatorsList(stateDb, testmodels.InputValidators)
+	for i, address := range testmodels.InputValidators {
+		err = store.SetValidator(stateDb, &Validator{
+			Address:       address,
+			Index:         uint64(i),
+			ActivationEra: 0,
+			ExitEra:       math.MaxUint64,
+		})
+		testutils.AssertNoError(t, err)
+	}
+
+	validators, err := store.GetValidators(bc, 0, "Tests")
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, testmodels.InputValidators, validators)
+
+	// the validators are persisted with the era key
+	data := rawdb.ReadEraValidators(db, testEra.Number)
+	if data == nil {
+		t.Fatal("expected persisted era validators")
+	}
+
+	// a restarted node loads the validators from the database
+	restarted := NewStorageWithDatabase(testmodels.TestChainConfig, db).(*storage)
+	testutils.AssertEqual(t, testmodels.InputValidators, restarted.loadEraValidators(testEra))
+
+	// inconsistent data is dropped on load
+	rawdb.WriteEraValidators(db, testEra.Number, marshalEraValidators(common.Hash{0x11}, testmodels.InputValidators))
+	testutils.AssertNil(t, restarted.loadEraValidators(testEra))
+	testutils.AssertNil(t, rawdb.ReadEraValidators(db, testEra.Number))
+
+	// the validators are rebuilt from the state and persisted again
+	validators, err = restarted.GetValidators(bc, 0, "Tests")
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, testmodels.InputValidators, validators)
+	testutils.AssertEqual(t, marshalEraValidators(eraRoot, testmodels.InputValidators), rawdb.ReadEraValidators(db, testEra.Number))
+
+	// rollback resets the cached eras
+	restarted.ResetValidatorsCache(testEra.Number)
+	testutils.AssertNil(t, rawdb.ReadEraValidators(db, testEra.Number))
+	testutils.AssertNil(t, restarted.validatorsCache.getAllActiveValidatorsByEra(testEra.Number))
+
+	// preparing the era rebuilds and persists its validators
+	restarted.PrepareNextEraValidators(bc, testEra)
+	testutils.AssertEqual(t, testmodels.InputValidators, restarted.validatorsCache.getAllActiveValidatorsByEra(testEra.Number))
+	testutils.AssertEqual(t, marshalEraValidators(eraRoot, testmodels.InputValidators), rawdb.ReadEraValidators(db, testEra.Number))
+}
+
+func TestLoadEraValidators_Checksum(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	testEra := &era.Era{Number: 5, Root: common.HexToHash("0x1234")}
+	store := NewStorageWithDatabase(testmodels.TestChainConfig, db).(*storage)
+
+	// the verified data is loaded without the era state
+	data := marshalEraValidators(testEra.Root, testmodels.InputValidators)
+	rawdb.WriteEraValidators(db, testEra.Number, data)
+	testutils.AssertEqual(t, testmodels.InputValidators, store.loadEraValidators(testEra))
+
+	// the validators are reordered
+	reordered := common.CopyBytes(data)
+	first := reordered[eraValidatorsHeaderLen : eraValidatorsHeaderLen+common.AddressLength]
+	second := reordered[eraValidatorsHeaderLen+common.AddressLength : eraValidatorsHeaderLen+2*common.AddressLength]
+	tmp := common.CopyBytes(first)
+	copy(first, second)
+	copy(second, tmp)
+	rawdb.WriteEraValidators(db, testEra.Number, reordered)
+	testutils.AssertNil(t, store.loadEraValidators(testEra))
+	testutils.AssertNil(t, rawdb.ReadEraValidators(db, testEra.Number))
+
+	// a validator is missed
+	rawdb.WriteEraValidators(db, testEra.Number, data[:len(data)-common.AddressLength])
+	testutils.AssertNil(t, store.loadEraValidators(testEra))
+	testutils.AssertNil(t, rawdb.ReadEraValidators(db, testEra.Number))
+}
@@ -252,17 +252,17 @@ func (mr *MockblockchainMockRecorder) GetTransactionReceipt(txHash interface{})
 }
 
 // GetValidatorSyncData mocks base method.
-func (m *Mockblockchain) GetValidatorSyncData(InitTxHash common.Hash) *types.ValidatorSync {
+func (m *Mockblockchain) GetValidatorSyncData(InitTxHash common.Hash, creator common.Address) *types.ValidatorSync {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetValidatorSyncData", InitTxHash)
+	ret := m.ctrl.Call(m, "GetValidatorSyncData", InitTxHash, creator)
 	ret0, _ := ret[0].(*types.ValidatorSync)
 	return ret0
 }
 
 // GetValidatorSyncData indicates an expected call of GetValidatorSyncData.
-func (mr *MockblockchainMockRecorder) GetValidatorSyncData(InitTxHash interface{}) *gomock.Call {
+func (mr *MockblockchainMockRecorder) GetValidatorSyncData(InitTxHash, creator interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValidatorSyncData", reflect.TypeOf((*Mockblockchain)(nil).GetValidatorSyncData), InitTxHash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValidatorSyncData", reflect.TypeOf((*Mockblockchain)(nil).GetValidatorSyncData), InitTxHash, creator)
 }
 
 // StartTransitionPeriod mocks base method.
@@ -53,22 +53,24 @@ func init() {
 
 	TestDb = rawdb.NewMemoryDatabase()
 	TestChainConfig = &params.ChainConfig{
-		ChainID:                big.NewInt(111111),
-		SecondsPerSlot:         4,
-		SlotsPerEpoch:          32,
-		ValidatorsStateAddress: &validatorsStateAddress,
-		ValidatorsPerSlot:      2,
-		EpochsPerEra:           22,
-		TransitionPeriod:       2,
-		ValidatorOpExpireSlots: 14400,
-		ForkSlotSubNet1:        9999999,
-		ForkSlotDelegate:       10,
-		ForkSlotPrefixFin:      10,
-		ForkSlotShanghai:       0,
-		ForkSlotValOpTracking:  100,
-		ForkSlotValSyncProc:    100,
-		StartEpochsPerEra:      0,
-		EffectiveBalance:       big.NewInt(3200),
+		ChainID:                     big.NewInt(111111),
+		SecondsPerSlot:              4,
+		SlotsPerEpoch:               32,
+		ValidatorsStateAddress:      &validatorsStateAddress,
+		ValidatorsPerSlot:           2,
+		EpochsPerEra:                22,
+		TransitionPeriod:            2,
+		ValidatorOpExpireSlots:      14400,
+		ForkSlotSubNet1:             9999999,
+		ForkSlotDelegate:            10,
+		ForkSlotPrefixFin:           10,
+		ForkSlotShanghai:            0,
+		ForkSlotValOpTracking:       100,
+		ForkSlotValSyncProc:         100,
+		ForkSlotBatchDeposit:        100,
+		ForkSlotDelegatorWithdrawal: 100,
+		StartEpochsPerEra:           0,
+		EffectiveBalance:            big.NewInt(3200),
 	}
 
 	TestEra = era.Era{
@@ -0,0 +1,134 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/vm"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/operation"
+	valStore "gitlab.waterfall.network/waterfall/protocol/gwat/validator/storage"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/txlog"
+)
+
+// Kinds of the validator state changes recorded into the trace frame.
+const (
+	validatorStateChange    = "validator"
+	validatorsListChange    = "validatorsList"
+	depositCountStateChange = "depositCount"
+)
+
+// WithTracer returns a copy of the processor which records the decoded
+// operations, the validator state changes, the balance movements and
+// the emitted logs into the trace frame.
+func (p *Processor) WithTracer(frame *vm.NativeOpFrame) *Processor {
+	state := vm.NewNativeOpStateDB(p.state, frame)
+	return &Processor{
+		ctx:          p.ctx,
+		state:        state,
+		eventEmmiter: txlog.NewEventEmmiter(state),
+		storage:      &tracingStorage{Storage: p.storage, frame: frame},
+		blockchain:   p.blockchain,
+		tracer:       frame,
+	}
+}
+
+// tracingStorage records the validator state changes into the trace frame.
+type tracingStorage struct {
+	valStore.Storage
+	frame *vm.NativeOpFrame
+}
+
+func (s *tracingStorage) SetValidator(stateDb vm.StateDB, val *valStore.Validator) error {
+	before, _ := s.Storage.GetValidator(stateDb, val.Address)
+	if err := s.Storage.SetValidator(stateDb, val); err != nil {
+		return err
+	}
+	s.frame.CaptureStateChange(validatorStateChange, val.Address, before, val.Copy())
+	return nil
+}
+
+func (s *tracingStorage) AddValidatorToList(stateDb vm.StateDB, index uint64, validator common.Address) {
+	s.Storage.AddValidatorToList(stateDb, index, validator)
+	s.frame.CaptureStateChange(validatorsListChange, validator, nil, hexutil.Uint64(index))
+}
+
+func (s *tracingStorage) IncrementDepositCount(stateDb vm.StateDB) {
+	before := s.Storage.GetDepositCount(stateDb)
+	s.Storage.IncrementDepositCount(stateDb)
+	var address common.Address
+	if valAddress := s.Storage.GetValidatorsStateAddress(); valAddress != nil {
+		address = *valAddress
+	}
+	s.frame.CaptureStateChange(depositCountStateChange, address, hexutil.Uint64(before), hexutil.Uint64(s.Storage.GetDepositCount(stateDb)))
+}
+
+// OperationArgs returns the name and the arguments of the validator operation.
+func OperationArgs(op operation.Operation) (string, map[string]interface{}) {
+	args := make(map[string]interface{})
+	switch v := op.(type) {
+	case operation.Deposit:
+		args["pubkey"] = v.PubKey()
+		args["creatorAddress"] = v.CreatorAddress()
+		args["withdrawalAddress"] = v.WithdrawalAddress()
+		if ds := v.DelegatingStake(); ds != nil {
+			args["delegatingStake"] = ds
+		}
+		return "deposit", args
+	case operation.BatchDeposit:
+		deposits := make([]map[string]interface{}, 0, len(v.Deposits()))
+		for _, entry := range v.Deposits() {
+			deposits = append(deposits, map[string]interface{}{
+				"pubkey":            entry.Deposit.PubKey(),
+				"creatorAddress":    entry.Deposit.CreatorAddress(),
+				"withdrawalAddress": entry.Deposit.WithdrawalAddress(),
+				"value":             (*hexutil.Big)(entry.Value),
+			})
+		}
+		args["deposits"] = deposits
+		args["totalValue"] = (*hexutil.Big)(v.TotalValue())
+		return "batchDeposit", args
+	case operation.ValidatorSync:
+		args["opType"] = hexutil.Uint64(v.OpType())
+		args["procEpoch"] = hexutil.Uint64(v.ProcEpoch())
+		args["index"] = hexutil.Uint64(v.Index())
+		args["creator"] = v.Creator()
+		args["initTxHash"] = v.InitTxHash()
+		if amount := v.Amount(); amount != nil {
+			args["amount"] = (*hexutil.Big)(amount)
+		}
+		if withdrawal := v.WithdrawalAddress(); withdrawal != nil {
+			args["withdrawalAddress"] = *withdrawal
+		}
+		return "validatorSync", args
+	case operation.Exit:
+		args["pubkey"] = v.PubKey()
+		args["creatorAddress"] = v.CreatorAddress()
+		if exitAfter := v.ExitAfterEpoch(); exitAfter != nil {
+			args["exitAfterEpoch"] = hexutil.Uint64(*exitAfter)
+		}
+		return "exit", args
+	case operation.DelegatorWithdrawal:
+		args["creatorAddress"] = v.CreatorAddress()
+		args["delegatorAddress"] = v.DelegatorAddress()
+		args["amount"] = (*hexutil.Big)(v.Amount())
+		return "delegatorWithdrawal", args
+	case operation.Withdrawal:
+		args["creatorAddress"] = v.CreatorAddress()
+		args["amount"] = (*hexutil.Big)(v.Amount())
+		return "withdrawal", args
+	}
+	return "unknown", args
+}
@@ -27,10 +27,11 @@ var (
 	EvtExitReqLogSignature    = crypto.Keccak256Hash([]byte("ExitRequestLog"))
 	EvtWithdrawalLogSignature = crypto.Keccak256Hash([]byte("WithdrawaRequestLog"))
 	//validator sync op
-	EvtActivateLogSignature      = crypto.Keccak256Hash([]byte("activate"))
-	EvtDeactivateLogSignature    = crypto.Keccak256Hash([]byte("deactivate"))
-	EvtUpdateBalanceLogSignature = crypto.Keccak256Hash([]byte("update-balance"))
-	EvtDelegatingStakeSignature  = crypto.Keccak256Hash([]byte("delegating-stake"))
+	EvtActivateLogSignature            = crypto.Keccak256Hash([]byte("activate"))
+	EvtDeactivateLogSignature          = crypto.Keccak256Hash([]byte("deactivate"))
+	EvtUpdateBalanceLogSignature       = crypto.Keccak256Hash([]byte("update-balance"))
+	EvtDelegatingStakeSignature        = crypto.Keccak256Hash([]byte("delegating-stake"))
+	EvtDelegatorWithdrawalLogSignature = crypto.Keccak256Hash([]byte("delegator-withdrawal"))
 )
 
 type logEntry struct {
@@ -22,14 +22,15 @@ import (
 )
 
 var topicsNameMap = map[common.Hash]string{
-	EvtDepositLogSignature:       "deposit",
-	EvtExitReqLogSignature:       "exit",
-	EvtWithdrawalLogSignature:    "withdrawal",
-	EvtActivateLogSignature:      "activate",
-	EvtDeactivateLogSignature:    "deactivate",
-	EvtUpdateBalanceLogSignature: "update-balance",
-	EvtDelegatingStakeSignature:  "delegating-stake",
-	types.EvtErrorLogSignature:   "error",
+	EvtDepositLogSignature:             "deposit",
+	EvtExitReqLogSignature:             "exit",
+	EvtWithdrawalLogSignature:          "withdrawal",
+	EvtActivateLogSignature:            "activate",
+	EvtDeactivateLogSignature:          "deactivate",
+	EvtUpdateBalanceLogSignature:       "update-balance",
+	EvtDelegatingStakeSignature:        "delegating-stake",
+	EvtDelegatorWithdrawalLogSignature: "delegator-withdrawal",
+	types.EvtErrorLogSignature:         "error",
 }
 
 type parsedDataFailed struct {
@@ -73,6 +74,14 @@ type parsedUpdateBalance struct {
 	Amount      string `json:"amount"`
 }
 
+type parsedDelegatorWithdrawal struct {
+	InitTxHash    string `json:"initTxHash"`
+	CreatorAddr   string `json:"creatorAddr"`
+	DelegatorAddr string `json:"delegatorAddr"`
+	Amount        string `json:"amount"`
+	Stake         string `json:"stake"`
+}
+
 type parsedDelegatingItm struct {
 	Address  string `json:"address"`
 	RuleType string `json:"ruleType"`
@@ -207,6 +216,21 @@ func LogToParsedLog(log *types.Log) *types.ParsedLog {
 			}
 		}
 		parsed.ParsedData = delegatingData
+	case EvtDelegatorWithdrawalLogSignature:
+		dwData, err := UnpackDelegatorWithdrawalLogData(log.Data)
+		if err != nil {
+			parsed.ParsedData = parsedDataFailed{
+				Error: fmt.Sprintf("log data parcing error='%s' topic=%s", err.Error(), getTopicName(topicOp)),
+			}
+			break
+		}
+		parsed.ParsedData = parsedDelegatorWithdrawal{
+			InitTxHash:    dwData.InitTxHash.Hex(),
+			CreatorAddr:   dwData.CreatorAddress.Hex(),
+			DelegatorAddr: dwData.DelegatorAddress.Hex(),
+			Amount:        dwData.Amount.String(),
+			Stake:         dwData.Stake.String(),
+		}
 	case types.EvtErrorLogSignature:
 		parsed.ParsedData = parsedDataFailed{
 			Error: string(log.Data),
@@ -0,0 +1,117 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txlog
+
+import (
+	"math/big"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rlp"
+)
+
+// DelegatorWithdrawalLogData records the change of the delegator's share
+// in the validator stake list.
+type DelegatorWithdrawalLogData struct {
+	InitTxHash       common.Hash
+	CreatorAddress   common.Address
+	DelegatorAddress common.Address
+	Amount           *big.Int
+	Stake            *big.Int
+}
+
+// MarshalBinary marshals a create operation to byte encoding
+func (d *DelegatorWithdrawalLogData) MarshalBinary() ([]byte, error) {
+	cmp := d.Copy()
+	if cmp == nil {
+		cmp = &DelegatorWithdrawalLogData{}
+	}
+	if cmp.Amount == nil {
+		cmp.Amount = new(big.Int)
+	}
+	if cmp.Stake == nil {
+		cmp.Stake = new(big.Int)
+	}
+	return rlp.EncodeToBytes(cmp)
+}
+
+// UnmarshalBinary unmarshals a create operation from byte encoding
+func (d *DelegatorWithdrawalLogData) UnmarshalBinary(b []byte) error {
+	return rlp.DecodeBytes(b, d)
+}
+
+func (d *DelegatorWithdrawalLogData) Copy() *DelegatorWithdrawalLogData {
+	if d == nil {
+		return nil
+	}
+	var amt, stake *big.Int
+	if d.Amount != nil {
+		amt = new(big.Int).Set(d.Amount)
+	}
+	if d.Stake != nil {
+		stake = new(big.Int).Set(d.Stake)
+	}
+	return &DelegatorWithdrawalLogData{
+		InitTxHash:       common.BytesToHash(d.InitTxHash.Bytes()),
+		CreatorAddress:   common.BytesToAddress(d.CreatorAddress.Bytes()),
+		DelegatorAddress: common.BytesToAddress(d.DelegatorAddress.Bytes()),
+		Amount:           amt,
+		Stake:            stake,
+	}
+}
+
+// PackDelegatorWithdrawalLogData packs the delegator withdrawal log.
+func PackDelegatorWithdrawalLogData(
+	initTxHash common.Hash,
+	creatorAddress common.Address,
+	delegatorAddress common.Address,
+	amount *big.Int,
+	stake *big.Int,
+) ([]byte, error) {
+	if amount == nil || stake == nil {
+		return nil, ErrNoAmount
+	}
+	logData := &DelegatorWithdrawalLogData{
+		InitTxHash:       initTxHash,
+		CreatorAddress:   creatorAddress,
+		DelegatorAddress: delegatorAddress,
+		Amount:           amount,
+		Stake:            stake,
+	}
+	return logData.MarshalBinary()
+}
+
+// UnpackDelegatorWithdrawalLogData unpacks the data from a delegator withdrawal log.
+func UnpackDelegatorWithdrawalLogData(bin []byte) (*DelegatorWithdrawalLogData, error) {
+	logData := &DelegatorWithdrawalLogData{}
+	if err := logData.UnmarshalBinary(bin); err != nil {
+		return nil, err
+	}
+	return logData, nil
+}
+
+func (e *EventEmmiter) AddDelegatorWithdrawalLog(stateValAdr common.Address, data []byte, creatorAdr common.Address, initTxHash common.Hash, delegatorAdr common.Address) {
+	topics := []common.Hash{
+		EvtDelegatorWithdrawalLogSignature,
+		creatorAdr.Hash(),
+		initTxHash,
+		delegatorAdr.Hash(),
+	}
+	e.state.AddLog(&types.Log{
+		Address: stateValAdr,
+		Topics:  topics,
+		Data:    data,
+	})
+}
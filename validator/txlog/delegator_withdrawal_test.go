@@ -0,0 +1,94 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txlog
+
+import (
+	"math/big"
+	"testing"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/tests/testutils"
+)
+
+func TestDelegatorWithdrawalLogData_Copy(t *testing.T) {
+	dwData := DelegatorWithdrawalLogData{
+		InitTxHash:       common.Hash{0x11},
+		CreatorAddress:   common.Address{0x22},
+		DelegatorAddress: common.Address{0x33},
+		Amount:           common.BigGwei,
+		Stake:            common.BigWat,
+	}
+
+	cmp := *dwData.Copy()
+	testutils.AssertEqual(t, dwData, cmp)
+
+	dwDataEmpty := DelegatorWithdrawalLogData{}
+	cmpEmpty := *dwDataEmpty.Copy()
+	testutils.AssertEqual(t, dwDataEmpty, cmpEmpty)
+}
+
+func TestPackUnpackDelegatorWithdrawalLogData(t *testing.T) {
+	var (
+		initTxHash       = common.Hash{0x11}
+		creatorAddress   = common.Address{0x22}
+		delegatorAddress = common.Address{0x33}
+		amount           = common.BigGwei
+		stake            = new(big.Int)
+	)
+	_, err := PackDelegatorWithdrawalLogData(initTxHash, creatorAddress, delegatorAddress, nil, stake)
+	testutils.AssertError(t, err, ErrNoAmount)
+
+	data, err := PackDelegatorWithdrawalLogData(initTxHash, creatorAddress, delegatorAddress, amount, stake)
+	testutils.AssertNoError(t, err)
+
+	unpacked, err := UnpackDelegatorWithdrawalLogData(data)
+	testutils.AssertNoError(t, err)
+	testutils.AssertEqual(t, &DelegatorWithdrawalLogData{
+		InitTxHash:       initTxHash,
+		CreatorAddress:   creatorAddress,
+		DelegatorAddress: delegatorAddress,
+		Amount:           amount,
+		Stake:            stake,
+	}, unpacked)
+}
+
+func TestLogToParsedLog_DelegatorWithdrawal(t *testing.T) {
+	data, err := PackDelegatorWithdrawalLogData(common.Hash{0x11}, common.Address{0x22}, common.Address{0x33}, common.BigGwei, common.BigWat)
+	testutils.AssertNoError(t, err)
+	txLog := &types.Log{
+		Address:     common.Address{0x11},
+		Topics:      []common.Hash{EvtDelegatorWithdrawalLogSignature, common.Hash{0x44}},
+		Data:        data,
+		BlockNumber: 100,
+		TxHash:      common.Hash{0x22},
+		TxIndex:     55,
+		BlockHash:   common.Hash{0x33},
+		Index:       261,
+	}
+
+	expParsed := txLog.ToParsedLog()
+	expParsed.ParsedTopics = []string{"delegator-withdrawal", common.Hash{0x44}.Hex()}
+	expParsed.ParsedData = parsedDelegatorWithdrawal{
+		InitTxHash:    common.Hash{0x11}.Hex(),
+		CreatorAddr:   common.Address{0x22}.Hex(),
+		DelegatorAddr: common.Address{0x33}.Hex(),
+		Amount:        common.BigGwei.String(),
+		Stake:         common.BigWat.String(),
+	}
+
+	gotParsed := LogToParsedLog(txLog)
+	testutils.AssertEqual(t, expParsed, gotParsed)
+}
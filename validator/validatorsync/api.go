@@ -0,0 +1,238 @@
+// Copyright 2024   Blue Wave Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validatorsync
+
+import (
+	"errors"
+	"fmt"
+
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/common/hexutil"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/core/types"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/rpc"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator"
+	"gitlab.waterfall.network/waterfall/protocol/gwat/validator/operation"
+)
+
+var (
+	ErrNotAssignedCreator = errors.New("node is not assigned creator")
+)
+
+// APIBackend provides the validator sync API with access to the node.
+type APIBackend interface {
+	Backend
+	Etherbase() (common.Address, error)
+}
+
+// ProcessingTx describes the transaction which processed the validator sync operation.
+type ProcessingTx struct {
+	Hash      common.Hash     `json:"hash"`
+	BlockHash *common.Hash    `json:"blockHash"`
+	Slot      *hexutil.Uint64 `json:"slot"`
+	Status    *hexutil.Uint64 `json:"status"`
+}
+
+// ValidatorSyncInfo describes the state of a validator sync operation.
+type ValidatorSyncInfo struct {
+	Op *types.ValidatorSync `json:"op"`
+	// Pending is true if the operation is in the not processed list.
+	Pending bool `json:"pending"`
+	// Selected is true if the operation is currently processable by block creators.
+	Selected bool `json:"selected"`
+	// InitSlot is the slot of the block containing the initial tx.
+	InitSlot *hexutil.Uint64 `json:"initSlot"`
+	// Age is the number of slots since the initial tx.
+	Age         *hexutil.Uint64 `json:"age"`
+	ExpireSlots hexutil.Uint64  `json:"expireSlots"`
+	Expired     bool            `json:"expired"`
+	// ProcessingTx is the transaction which processed the operation.
+	ProcessingTx *ProcessingTx `json:"processingTx"`
+	// Rejection is the reason of rejection of the operation by validation.
+	Rejection string `json:"rejection,omitempty"`
+}
+
+// PublicValidatorSyncAPI provides an API to inspect validator sync operations.
+type PublicValidatorSyncAPI struct {
+	b APIBackend
+}
+
+// NewPublicValidatorSyncAPI creates a new validator sync API.
+func NewPublicValidatorSyncAPI(b APIBackend) *PublicValidatorSyncAPI {
+	return &PublicValidatorSyncAPI{b}
+}
+
+// ValidatorSync_Pending returns the not processed validator sync operations.
+func (s *PublicValidatorSyncAPI) ValidatorSync_Pending() []*ValidatorSyncInfo {
+	bc := s.b.BlockChain()
+	selected := GetPendingValidatorSyncData(bc)
+	ops := bc.GetNotProcessedValidatorSyncData()
+	res := make([]*ValidatorSyncInfo, 0, len(ops))
+	for _, vs := range ops {
+		res = append(res, getValidatorSyncInfo(bc, vs.Copy(), true, selected))
+	}
+	return res
+}
+
+// ValidatorSync_Get returns the validator sync operation by hash of initial tx and creator.
+func (s *PublicValidatorSyncAPI) ValidatorSync_Get(initTxHash common.Hash, creator common.Address) (*ValidatorSyncInfo, error) {
+	bc := s.b.BlockChain()
+	selected := GetPendingValidatorSyncData(bc)
+	if vs := bc.GetNotProcessedValidatorSyncData()[types.ValidatorSyncKey(initTxHash, creator)]; vs != nil {
+		return getValidatorSyncInfo(bc, vs.Copy(), true, selected), nil
+	}
+	vs := bc.GetValidatorSyncData(initTxHash, creator)
+	if vs == nil {
+		return nil, core.ErrValSyncOpNF
+	}
+	return getValidatorSyncInfo(bc, vs.Copy(), false, nil), nil
+}
+
+// PrivateValidatorSyncAPI provides an API to manage validator sync operations.
+type PrivateValidatorSyncAPI struct {
+	b APIBackend
+}
+
+// NewPrivateValidatorSyncAPI creates a new validator sync admin API.
+func NewPrivateValidatorSyncAPI(b APIBackend) *PrivateValidatorSyncAPI {
+	return &PrivateValidatorSyncAPI{b}
+}
+
+// ValidatorSyncRequeue returns the validator sync operation to the not processed list.
+func (api *PrivateValidatorSyncAPI) ValidatorSyncRequeue(initTxHash common.Hash, creator common.Address) (*ValidatorSyncInfo, error) {
+	if err := api.checkAssignedCreator(); err != nil {
+		return nil, err
+	}
+	bc := api.b.BlockChain()
+	vs, err := bc.RequeueValidatorSyncData(initTxHash, creator)
+	if err != nil {
+		return nil, err
+	}
+	return getValidatorSyncInfo(bc, vs.Copy(), true, GetPendingValidatorSyncData(bc)), nil
+}
+
+// ValidatorSyncDrop removes the validator sync operation from the not processed list.
+func (api *PrivateValidatorSyncAPI) ValidatorSyncDrop(initTxHash common.Hash, creator common.Address) (*ValidatorSyncInfo, error) {
+	if err := api.checkAssignedCreator(); err != nil {
+		return nil, err
+	}
+	bc := api.b.BlockChain()
+	vs, err := bc.DropValidatorSyncData(initTxHash, creator)
+	if err != nil {
+		return nil, err
+	}
+	return getValidatorSyncInfo(bc, vs.Copy(), false, nil), nil
+}
+
+// checkAssignedCreator checks the node's creator is assigned to create
+// blocks in the current slot and its account is available locally.
+func (api *PrivateValidatorSyncAPI) checkAssignedCreator() error {
+	coinbase, err := api.b.Etherbase()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotAssignedCreator, err)
+	}
+	if err = api.b.CreatorAuthorize(coinbase); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotAssignedCreator, err)
+	}
+	bc := api.b.BlockChain()
+	creators, err := bc.ValidatorStorage().GetCreatorsBySlot(bc, bc.GetSlotInfo().CurrentSlot())
+	if err != nil {
+		return err
+	}
+	for _, v := range creators {
+		if v == coinbase {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %#x", ErrNotAssignedCreator, coinbase)
+}
+
+// GetAPIs provides api access
+func GetAPIs(backend APIBackend) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "wat",
+			Version:   "1.0",
+			Service:   NewPublicValidatorSyncAPI(backend),
+			Public:    true,
+		},
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewPrivateValidatorSyncAPI(backend),
+		},
+	}
+}
+
+// getValidatorSyncInfo describes the validator sync operation,
+// selected are the operations currently processable by block creators.
+func getValidatorSyncInfo(bc *core.BlockChain, vs *types.ValidatorSync, pending bool, selected map[common.Hash]*types.ValidatorSync) *ValidatorSyncInfo {
+	si := bc.GetSlotInfo()
+	currSlot := si.CurrentSlot()
+	expireSlots := bc.Config().ValidatorOpExpireSlots
+
+	info := &ValidatorSyncInfo{
+		Op:          vs,
+		Pending:     pending,
+		Selected:    pending && selected[vs.Key()] != nil,
+		ExpireSlots: hexutil.Uint64(expireSlots),
+	}
+
+	// age of op by slot of initial tx
+	if _, blHash, _ := bc.GetTransactionReceipt(vs.InitTxHash); blHash != (common.Hash{}) {
+		if header := bc.GetHeaderByHash(blHash); header != nil {
+			initSlot := hexutil.Uint64(header.Slot)
+			info.InitSlot = &initSlot
+			if currSlot >= header.Slot {
+				age := hexutil.Uint64(currSlot - header.Slot)
+				info.Age = &age
+				info.Expired = uint64(age) >= expireSlots
+			}
+		}
+	}
+
+	var txHash common.Hash
+	if vs.TxHash != nil {
+		txHash = *vs.TxHash
+		info.ProcessingTx = getProcessingTx(bc, txHash)
+	}
+
+	opVer := getValSyncVersionBySlot(bc.Config(), currSlot)
+	// withdrawal address is not a subject of validation
+	op, err := operation.NewValidatorSyncOperation(opVer, vs.OpType, vs.InitTxHash, vs.ProcEpoch, vs.Index, vs.Creator, vs.Amount, &common.Address{}, vs.Balance)
+	if err == nil {
+		err = validator.ValidateValidatorSyncOp(bc, op, currSlot, txHash)
+	}
+	if err != nil {
+		info.Rejection = err.Error()
+	}
+	return info
+}
+
+func getProcessingTx(bc *core.BlockChain, txHash common.Hash) *ProcessingTx {
+	res := &ProcessingTx{Hash: txHash}
+	rc, blHash, _ := bc.GetTransactionReceipt(txHash)
+	if rc == nil {
+		return res
+	}
+	status := hexutil.Uint64(rc.Status)
+	res.Status = &status
+	res.BlockHash = &blHash
+	if header := bc.GetHeaderByHash(blHash); header != nil {
+		slot := hexutil.Uint64(header.Slot)
+		res.Slot = &slot
+	}
+	return res
+}
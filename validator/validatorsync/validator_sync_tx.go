@@ -242,7 +242,7 @@ func GetPendingValidatorSyncData(bc *core.BlockChain) map[common.Hash]*types.Val
 		if vs.TxHash != nil {
 			continue
 		}
-		saved := bc.GetValidatorSyncData(vs.InitTxHash)
+		saved := bc.GetValidatorSyncData(vs.InitTxHash, vs.Creator)
 		if saved != nil {
 			log.Info("=== ValidatorSync: GetPendingValidatorSyncData === saved",
 				"slot", si.CurrentSlot(),